@@ -0,0 +1,199 @@
+package main
+
+import (
+	clangresolver "Magma/src/clang"
+	"Magma/src/comp_err"
+	compilerpipeline "Magma/src/compiler_pipeline"
+	"Magma/src/docgen"
+	"Magma/src/makeabs"
+	"Magma/src/shared"
+	magmatarget "Magma/src/target"
+	"Magma/src/types"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// doctestTimeout bounds each executed example so a hanging one fails instead
+// of stalling the run.
+const doctestTimeout = 30 * time.Second
+
+// doctestMode is a boolean flag which optionally takes a value:
+// `--doctest` compiles every doc example and `--doctest=run` also runs them.
+type doctestMode struct{ mode *string }
+
+func (d doctestMode) IsBoolFlag() bool { return true }
+
+func (d doctestMode) String() string {
+	if d.mode == nil {
+		return ""
+	}
+	return *d.mode
+}
+
+func (d doctestMode) Set(value string) error {
+	switch value {
+	case "true", "compile":
+		*d.mode = "compile"
+	case "run":
+		*d.mode = "run"
+	case "false":
+		*d.mode = ""
+	default:
+		return fmt.Errorf("invalid --doctest value %q (expected compile or run)", value)
+	}
+	return nil
+}
+
+// runDoctests compiles the `@example` blocks of the input module and of the
+// modules --doc would document with it, and with `--doctest=run` executes
+// them. Failures are reported at the example's doc comment.
+func runDoctests(opts options) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	absPath, err := makeabs.MakeAbs(opts.inputFile, cwd+"/a.b")
+	if err != nil {
+		return err
+	}
+	s, err := shared.MakeShared(cwd, opts.stdRoot)
+	if err != nil {
+		return err
+	}
+	if _, err = compilerpipeline.Parse(s, absPath); err != nil {
+		return err
+	}
+	target := s.Target
+	if opts.doctest == "run" {
+		clangPath, _, err := clangresolver.Resolve("")
+		if err != nil {
+			return err
+		}
+		if target, err = magmatarget.Resolve(clangPath, opts.target); err != nil {
+			return err
+		}
+		opts.target, opts.targetOS = target.Triple, string(target.OS)
+	}
+	dir, err := os.MkdirTemp("", "magma-doctest-*")
+	if err != nil {
+		return fmt.Errorf("create doctest directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	examples := docgen.Examples(s, absPath)
+	failed := 0
+	for i, example := range examples {
+		path := filepath.Join(dir, fmt.Sprintf("example%d.mg", i))
+		if err := os.WriteFile(path, []byte(example.Program), 0666); err != nil {
+			return fmt.Errorf("write doc example: %w", err)
+		}
+		ir, err := compileDoctest(opts, target, path)
+		if err == nil && opts.doctest == "run" {
+			err = runDoctest(opts, example, filepath.Join(dir, fmt.Sprintf("example%d", i)), ir)
+		}
+		if err != nil {
+			failed++
+			reportDoctest(example, path, err)
+		}
+	}
+	fmt.Printf("doctest: %d passed, %d failed\n", len(examples)-failed, failed)
+	if failed != 0 {
+		return fmt.Errorf("%d of %d doc examples failed", failed, len(examples))
+	}
+	return nil
+}
+
+// compileDoctest runs an example program through every checking stage and
+// lowers it, as a compilation of the program would.
+func compileDoctest(opts options, target magmatarget.Target, path string) ([]byte, error) {
+	s, err := shared.MakeShared(filepath.Dir(path), opts.stdRoot)
+	if err != nil {
+		return nil, err
+	}
+	s.ErrorTraceSlots = opts.errorTraceSlots
+	s.Target = target
+	parsed, err := compilerpipeline.Parse(s, path)
+	if err != nil {
+		return nil, err
+	}
+	specialized, err := compilerpipeline.Specialize(parsed)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := compilerpipeline.Link(specialized)
+	if err != nil {
+		return nil, err
+	}
+	typed, err := compilerpipeline.CheckTypes(linked)
+	if err != nil {
+		return nil, err
+	}
+	validated, err := compilerpipeline.ValidateLowering(typed)
+	if err != nil {
+		return nil, err
+	}
+	ready, err := compilerpipeline.CheckSafety(validated, opts.safetyWarnings)
+	if err != nil {
+		return nil, err
+	}
+	return compilerpipeline.LowerReachable(ready)
+}
+
+// runDoctest builds the example as an executable at out and runs it. A
+// non-zero exit status fails the example.
+func runDoctest(opts options, example *docgen.Example, out string, ir []byte) error {
+	if opts.targetOS == "windows" {
+		out += ".exe"
+	}
+	opts.emit, opts.opt, opts.out = "exe", 0, out
+	if err := emitOutput(opts, ir, nil, nil); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), doctestTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, out).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	message := "doc example failed when run: " + err.Error()
+	if ctx.Err() != nil {
+		message = fmt.Sprintf("doc example did not finish within %s", doctestTimeout)
+	}
+	return &types.Diagnostic{
+		Stage:      "doctest",
+		Ctx:        example.File,
+		FilePath:   example.File.FilePath,
+		Token:      types.Token{Pos: types.FilePos{Line: example.Line, Col: 1}},
+		Message:    message,
+		ShortDesc:  message,
+		Additional: strings.TrimSpace(string(bytes.ToValidUTF8(output, []byte("?")))),
+	}
+}
+
+// reportDoctest prints the diagnostics of a failed example at its doc
+// comment. Failures without a source location are attributed to the
+// example's first line.
+func reportDoctest(example *docgen.Example, path string, err error) {
+	diagnostics := comp_err.Diagnostics(err)
+	if len(diagnostics) == 0 {
+		message := "doc example failed: " + err.Error()
+		diagnostics = append(diagnostics, &types.Diagnostic{
+			Stage:     "doctest",
+			Ctx:       example.File,
+			FilePath:  example.File.FilePath,
+			Token:     types.Token{Pos: types.FilePos{Line: example.Line, Col: 1}},
+			Message:   message,
+			ShortDesc: message,
+		})
+	}
+	for _, diagnostic := range diagnostics {
+		example.Locate(diagnostic, path)
+		comp_err.FprintDiagnostic(os.Stderr, diagnostic)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"Magma/src/types"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// reportInstantiations prints every declaration specialized by monomorph,
+// largest emitted IR first. Each entry is followed by the chain of call sites
+// that requested it, from the nearest one out to a non-generic declaration.
+func reportInstantiations(w io.Writer, records []*types.Instantiation) {
+	bySymbol := make(map[string]*types.Instantiation, len(records))
+	folded, total := 0, 0
+	for _, record := range records {
+		bySymbol[record.Symbol] = record
+		if record.FoldedInto != "" {
+			folded++
+		} else {
+			total += record.IrBytes
+		}
+	}
+	sorted := slices.Clone(records)
+	slices.SortStableFunc(sorted, func(a, b *types.Instantiation) int {
+		return cmp.Or(cmp.Compare(b.IrBytes, a.IrBytes), cmp.Compare(a.Name, b.Name))
+	})
+
+	fmt.Fprintf(w, "\nGeneric instantiations: %d, %d folded, %d bytes of IR\n", len(records), folded, total)
+	for _, record := range sorted {
+		size := fmt.Sprintf("%d bytes", record.IrBytes)
+		switch {
+		case record.FoldedInto != "":
+			into := record.FoldedInto
+			if target := bySymbol[into]; target != nil {
+				into = target.Name
+			}
+			size += ", folded into " + into
+		case record.IrBytes == 0:
+			size = "not emitted"
+		}
+		fmt.Fprintf(w, "  %s %s (%s)\n", record.Kind, record.Name, size)
+
+		seen := map[*types.Instantiation]bool{}
+		indent := "    "
+		for site := record; site != nil && !seen[site]; site = bySymbol[site.Requester] {
+			seen[site] = true
+			if site.File == "" {
+				break
+			}
+			fmt.Fprintf(w, "%srequested at %s:%d:%d", indent, site.File, site.Pos.Line, site.Pos.Col)
+			if site.RequesterName != "" {
+				fmt.Fprintf(w, " in %s", site.RequesterName)
+			}
+			fmt.Fprintln(w)
+			indent += "  "
+		}
+	}
+}
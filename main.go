@@ -17,6 +17,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,34 +31,51 @@ const usage = `usage: magma [options] <input-file>
 options:
   --debug                 print compiler diagnostics
   --timings               print compilation phase timings
+  --report-instantiations print every generic instantiation with its call sites
   --version, -v           print the compiler version
   --out, -o <path>        output path (default depends on --emit)
   --emit, -e <kind>       llvm, object, or exe (default llvm)
   --opt, -O <0-3>         LLVM optimization level (default 3)
   --error-trace-slots <n> trace slots per runtime shard (default 1024)
   --safety-warnings       downgrade memory-safety diagnostics to warnings
+  --explain-safety        show how the ownership checker reached each finding
+  --explain <code>        describe a diagnostic code
+  --color <when>          color diagnostics: auto, always, or never (default auto)
+  --disable-warnings <l>  suppress warnings by comma-separated codes or stages
+  --enable-warnings <l>   report codes or stages despite --disable-warnings
+  --warnings-as-errors[=<l>]
+                          fail on all warnings, or on the listed codes or stages
   --null-context          use null allocator and executor adapters for roots
   --target <triple>       compilation target (default: Clang native target)
   --std <directory>       override the Magma standard-library directory
   --lsp                   run the Magma language server over stdio
+  --doc <directory>       write Markdown and HTML documentation instead of compiling
+  --doctest[=run]         compile, or also run, the @example blocks of the input
   --clang-version, -cv    print the resolved Clang version and path`
 
 type options struct {
 	inputFile       string
 	debug           bool
 	timings         bool
+	instantiations  bool
 	version         bool
 	out             string
 	emit            string
 	opt             int
 	errorTraceSlots uint64
 	safetyWarnings  bool
+	explainSafety   bool
+	explain         string
+	color           comp_err.ColorMode
+	warningPolicy   types.WarningPolicy
 	nullContext     bool
 	clangVersion    bool
 	target          string
 	targetOS        string
 	stdRoot         string
 	lsp             bool
+	doc             string
+	doctest         string
 }
 
 func parseArgs(args []string) (options, error) {
@@ -67,6 +85,7 @@ func parseArgs(args []string) (options, error) {
 	flags.SetOutput(io.Discard)
 	flags.BoolVar(&opts.debug, "debug", false, "print compiler diagnostics")
 	flags.BoolVar(&opts.timings, "timings", false, "print compilation phase timings")
+	flags.BoolVar(&opts.instantiations, "report-instantiations", false, "print generic instantiations")
 	flags.BoolVar(&opts.version, "version", false, "print compiler version")
 	flags.BoolVar(&opts.version, "v", false, "print compiler version")
 	flags.StringVar(&opts.out, "out", "", "output path")
@@ -77,17 +96,30 @@ func parseArgs(args []string) (options, error) {
 	flags.IntVar(&opts.opt, "O", 3, "optimization level")
 	flags.Uint64Var(&opts.errorTraceSlots, "error-trace-slots", 1024, "error trace slots per runtime shard")
 	flags.BoolVar(&opts.safetyWarnings, "safety-warnings", false, "downgrade memory-safety diagnostics to warnings")
+	flags.BoolVar(&opts.explainSafety, "explain-safety", false, "explain ownership-safety diagnostics")
+	flags.StringVar(&opts.explain, "explain", "", "describe a diagnostic code")
+	color := flags.String("color", "auto", "color diagnostics")
+	flags.Var(warningList{&opts.warningPolicy.Disabled}, "disable-warnings", "suppress warning codes or stages")
+	flags.Var(warningList{&opts.warningPolicy.Enabled}, "enable-warnings", "report warning codes or stages")
+	flags.Var(warningsAsErrors{&opts.warningPolicy}, "warnings-as-errors", "promote warnings to errors")
 	flags.BoolVar(&opts.nullContext, "null-context", false, "use null root context")
 	flags.BoolVar(&opts.clangVersion, "clang-version", false, "print the resolved Clang version")
 	flags.BoolVar(&opts.clangVersion, "cv", false, "print the resolved Clang version")
 	flags.StringVar(&opts.target, "target", "", "target triple or architecture")
 	flags.StringVar(&opts.stdRoot, "std", "", "standard-library directory")
 	flags.BoolVar(&opts.lsp, "lsp", false, "run the language server over stdio")
+	flags.StringVar(&opts.doc, "doc", "", "documentation output directory")
+	flags.Var(doctestMode{&opts.doctest}, "doctest", "compile or run doc examples")
 	if err := flags.Parse(args); err != nil {
 		return options{}, err
 	}
+	mode, err := comp_err.ParseColorMode(*color)
+	if err != nil {
+		return options{}, err
+	}
+	opts.color = mode
 
-	if opts.version || opts.clangVersion || opts.lsp {
+	if opts.version || opts.clangVersion || opts.lsp || opts.explain != "" {
 		if flags.NArg() != 0 {
 			return options{}, fmt.Errorf("information commands do not accept an input file")
 		}
@@ -96,6 +128,9 @@ func parseArgs(args []string) (options, error) {
 	if flags.NArg() != 1 {
 		return options{}, fmt.Errorf("expected exactly one input file, got %d", flags.NArg())
 	}
+	if opts.doc != "" && opts.doctest != "" {
+		return options{}, fmt.Errorf("--doc and --doctest cannot be combined")
+	}
 	opts.emit = strings.ToLower(opts.emit)
 	switch opts.emit {
 	case "llvm", "ll":
@@ -135,6 +170,7 @@ func wrappedMain() error {
 		return err
 	}
 	debug.SetEnabled(opts.debug)
+	comp_err.SetColor(opts.color, os.Stderr)
 	timings := newCompilationTimings(opts.timings)
 	defer timings.report(os.Stderr)
 	stop := timings.start("Preparation", "standard library discovery")
@@ -153,6 +189,9 @@ func wrappedMain() error {
 		fmt.Printf("Magma %s\n", compilerVersion())
 		return nil
 	}
+	if opts.explain != "" {
+		return printExplanation(os.Stdout, opts.explain)
+	}
 	if opts.clangVersion {
 		path, version, err := clangresolver.Resolve("")
 		if err != nil {
@@ -161,6 +200,12 @@ func wrappedMain() error {
 		fmt.Printf("Clang %s (%s)\n", version, path)
 		return nil
 	}
+	if opts.doc != "" {
+		return writeDocumentation(opts)
+	}
+	if opts.doctest != "" {
+		return runDoctests(opts)
+	}
 	stop = timings.start("Preparation", "Clang and target resolution")
 	clangPath, _, err := clangresolver.Resolve("")
 	if err != nil {
@@ -204,6 +249,9 @@ func wrappedMain() error {
 	}
 	s.ErrorTraceSlots = opts.errorTraceSlots
 	s.NullContext = opts.nullContext
+	s.ExplainSafety = opts.explainSafety
+	s.WarningPolicy = opts.warningPolicy
+	comp_err.SetExplain(opts.explainSafety)
 	s.Target = target
 	stop()
 
@@ -237,6 +285,9 @@ func wrappedMain() error {
 	if e != nil {
 		return e
 	}
+	stop = timings.start("Checks", "dead code analysis")
+	compilerpipeline.CheckDeadCode(typed)
+	stop()
 	stop = timings.start("Checks", "lowering validation")
 	validated, e := compilerpipeline.ValidateLowering(typed)
 	stop()
@@ -249,10 +300,14 @@ func wrappedMain() error {
 	if e != nil {
 		return e
 	}
-	for i := range s.Warnings {
-		comp_err.FprintDiagnostic(os.Stderr, &s.Warnings[i])
+	printWarnings(s)
+	if e = compilerpipeline.CheckWarnings(ready); e != nil {
+		return e
 	}
 
+	stop = timings.start("Back end", "devirtualization")
+	compilerpipeline.Devirtualize(ready)
+	stop()
 	stop = timings.start("Back end", "LLVM IR lowering")
 	irStr, e := compilerpipeline.LowerReachable(ready)
 	stop()
@@ -260,6 +315,10 @@ func wrappedMain() error {
 		return e
 	}
 
+	if opts.instantiations {
+		reportInstantiations(os.Stderr, s.Instantiations)
+	}
+
 	//debug.Printf("LLVM IR:\n%s\n", irStr)
 	debug.Printf("Successful lowering to LLVM\n")
 
@@ -269,6 +328,109 @@ func wrappedMain() error {
 	return e
 }
 
+// writeDocumentation generates documentation for the input module. It only
+// parses, so it needs neither Clang nor a `mod main` root.
+func writeDocumentation(opts options) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	absPath, err := makeabs.MakeAbs(opts.inputFile, cwd+"/a.b")
+	if err != nil {
+		return err
+	}
+	s, err := shared.MakeShared(cwd, opts.stdRoot)
+	if err != nil {
+		return err
+	}
+	s.WarningPolicy = opts.warningPolicy
+	parsed, err := compilerpipeline.Parse(s, absPath)
+	if err != nil {
+		return err
+	}
+	err = compilerpipeline.Document(parsed, absPath, opts.doc)
+	printWarnings(s)
+	return err
+}
+
+func printWarnings(s *types.SharedState) {
+	for i := range s.Warnings {
+		if s.Warnings[i].Severity == types.SeverityWarning {
+			comp_err.FprintDiagnostic(os.Stderr, &s.Warnings[i])
+		}
+	}
+}
+
+// warningStages are the compiler stages which report warnings.
+var warningStages = []string{"type checking", "dead code analysis", "ownership checking", "documentation"}
+
+// warningList accumulates comma-separated warning codes and stage names.
+type warningList struct{ set *map[string]bool }
+
+func (l warningList) String() string {
+	if l.set == nil {
+		return ""
+	}
+	names := make([]string, 0, len(*l.set))
+	for name := range *l.set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func (l warningList) Set(value string) error {
+	if *l.set == nil {
+		*l.set = map[string]bool{}
+	}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := comp_err.Explanation(name); !ok && !slices.Contains(warningStages, name) {
+			return fmt.Errorf("unknown warning code or stage %q (codes: %s; stages: %s)", name, strings.Join(comp_err.ExplainedCodes(), ", "), strings.Join(warningStages, ", "))
+		}
+		(*l.set)[name] = true
+	}
+	return nil
+}
+
+// warningsAsErrors is a boolean flag which optionally takes a warning list:
+// `--warnings-as-errors` promotes every warning, and
+// `--warnings-as-errors=<list>` promotes only the listed codes and stages.
+type warningsAsErrors struct{ policy *types.WarningPolicy }
+
+func (w warningsAsErrors) IsBoolFlag() bool { return true }
+
+func (w warningsAsErrors) String() string {
+	if w.policy == nil {
+		return ""
+	}
+	if w.policy.AllAsErrors {
+		return "true"
+	}
+	return warningList{&w.policy.AsErrors}.String()
+}
+
+func (w warningsAsErrors) Set(value string) error {
+	switch value {
+	case "true":
+		w.policy.AllAsErrors = true
+		return nil
+	case "false":
+		w.policy.AllAsErrors = false
+		return nil
+	}
+	return warningList{&w.policy.AsErrors}.Set(value)
+}
+
+func printExplanation(out io.Writer, code string) error {
+	text, ok := comp_err.Explanation(code)
+	if !ok {
+		return fmt.Errorf("unknown diagnostic code %q (documented codes: %s)", code, strings.Join(comp_err.ExplainedCodes(), ", "))
+	}
+	fmt.Fprintf(out, "[%s]\n\n%s\n", code, strings.TrimSpace(text))
+	return nil
+}
+
 func nativeLibraries(s *types.SharedState) []string {
 	seen := map[string]bool{}
 	for _, file := range s.Files {
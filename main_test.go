@@ -1,6 +1,8 @@
 package main
 
 import (
+	"Magma/src/comp_err"
+	"Magma/src/types"
 	"bytes"
 	"os"
 	"path/filepath"
@@ -144,6 +146,53 @@ func TestNullContextOption(t *testing.T) {
 	}
 }
 
+func TestExplainOptions(t *testing.T) {
+	opts, err := parseArgs([]string{"--explain-safety", "input.mg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.explainSafety {
+		t.Fatal("--explain-safety was not retained")
+	}
+	opts, err = parseArgs([]string{"--explain", "use-after-move"})
+	if err != nil {
+		t.Fatalf("--explain is an information command: %v", err)
+	}
+	if opts.explain != "use-after-move" {
+		t.Fatalf("explain = %q, want use-after-move", opts.explain)
+	}
+	if _, err := parseArgs([]string{"--explain", "cleanup", "input.mg"}); err == nil {
+		t.Fatal("--explain accepted an input file")
+	}
+}
+
+func TestColorOption(t *testing.T) {
+	opts, err := parseArgs([]string{"input.mg"})
+	if err != nil || opts.color != comp_err.ColorAuto {
+		t.Fatalf("default color = %v, %v; want auto", opts.color, err)
+	}
+	opts, err = parseArgs([]string{"--color=never", "input.mg"})
+	if err != nil || opts.color != comp_err.ColorNever {
+		t.Fatalf("--color=never = %v, %v", opts.color, err)
+	}
+	if _, err := parseArgs([]string{"--color", "sometimes", "input.mg"}); err == nil || !strings.Contains(err.Error(), "always") {
+		t.Fatalf("invalid --color error = %v", err)
+	}
+}
+
+func TestPrintExplanation(t *testing.T) {
+	var output bytes.Buffer
+	if err := printExplanation(&output, "missing-move"); err != nil {
+		t.Fatal(err)
+	}
+	if got := output.String(); !strings.HasPrefix(got, "[missing-move]") || !strings.Contains(got, "move resource") {
+		t.Fatalf("explanation = %q", got)
+	}
+	if err := printExplanation(&output, "no-such-code"); err == nil || !strings.Contains(err.Error(), "use-after-move") {
+		t.Fatalf("unknown code error = %v, want list of documented codes", err)
+	}
+}
+
 func TestLanguageServerAcceptsSafetyWarningsPolicy(t *testing.T) {
 	opts, err := parseArgs([]string{"--safety-warnings", "--lsp"})
 	if err != nil {
@@ -183,6 +232,22 @@ func TestTargetOption(t *testing.T) {
 	}
 }
 
+func TestDocOption(t *testing.T) {
+	opts, err := parseArgs([]string{"--doc", "docs/api", "--warnings-as-errors=documentation", "lib.mg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.doc != "docs/api" || opts.inputFile != "lib.mg" {
+		t.Fatalf("doc = %q, input = %q", opts.doc, opts.inputFile)
+	}
+	if !opts.warningPolicy.AsErrors["documentation"] {
+		t.Fatal("documentation was not accepted as a warning stage")
+	}
+	if _, err := parseArgs([]string{"--doc", "docs/api"}); err == nil {
+		t.Fatal("--doc accepted a missing input file")
+	}
+}
+
 func TestStandardLibraryOptionIsOptional(t *testing.T) {
 	if _, err := parseArgs([]string{"input.mg"}); err != nil {
 		t.Fatalf("parseArgs without --std: %v", err)
@@ -191,3 +256,112 @@ func TestStandardLibraryOptionIsOptional(t *testing.T) {
 		t.Fatalf("LSP parseArgs without --std: %v", err)
 	}
 }
+
+func TestWarningPolicyOptions(t *testing.T) {
+	opts, err := parseArgs([]string{"--disable-warnings", "type checking,unused-import", "--enable-warnings", "numeric-conversion", "--warnings-as-errors=cleanup", "input.mg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := opts.warningPolicy
+	if !policy.Disabled["type checking"] || !policy.Disabled["unused-import"] || !policy.Enabled["numeric-conversion"] || !policy.AsErrors["cleanup"] || policy.AllAsErrors {
+		t.Fatalf("warning policy = %#v", policy)
+	}
+	opts, err = parseArgs([]string{"--warnings-as-errors", "input.mg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.warningPolicy.AllAsErrors || opts.inputFile != "input.mg" {
+		t.Fatalf("bare --warnings-as-errors = %#v", opts)
+	}
+	if _, err := parseArgs([]string{"--disable-warnings", "no-such-code", "input.mg"}); err == nil || !strings.Contains(err.Error(), "unused-import") {
+		t.Fatalf("unknown warning code error = %v, want list of codes", err)
+	}
+}
+
+func TestDoctestOption(t *testing.T) {
+	opts, err := parseArgs([]string{"--doctest", "lib.mg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.doctest != "compile" {
+		t.Fatalf("doctest = %q, want compile", opts.doctest)
+	}
+	opts, err = parseArgs([]string{"--doctest=run", "lib.mg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.doctest != "run" {
+		t.Fatalf("doctest = %q, want run", opts.doctest)
+	}
+	if _, err := parseArgs([]string{"--doctest=later", "lib.mg"}); err == nil {
+		t.Fatal("--doctest accepted an unknown mode")
+	}
+	if _, err := parseArgs([]string{"--doctest", "--doc", "out", "lib.mg"}); err == nil {
+		t.Fatal("--doctest was combined with --doc")
+	}
+}
+
+func TestDoctestsReportFailingExamples(t *testing.T) {
+	stdRoot, err := filepath.Abs("std")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "sizes.mg")
+	source := `mod sizes
+
+# Doubles n.
+# @example
+#   four := sizes.double(2)
+pub double(n u64) u64:
+    ret n * 2
+..
+
+# Halves n.
+# @example
+#   two := sizes.halve(missing)
+pub halve(n u64) u64:
+    ret n / 2
+..
+`
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err = runDoctests(options{inputFile: path, stdRoot: stdRoot, doctest: "compile", errorTraceSlots: 1024})
+	if err == nil || err.Error() != "1 of 2 doc examples failed" {
+		t.Fatalf("runDoctests() = %v, want one failed example", err)
+	}
+}
+
+func TestReportInstantiationsOption(t *testing.T) {
+	opts, err := parseArgs([]string{"--report-instantiations", "input.mg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.instantiations {
+		t.Fatal("--report-instantiations was not retained")
+	}
+}
+
+func TestInstantiationReportListsChainsAndFolds(t *testing.T) {
+	records := []*types.Instantiation{
+		{Kind: "struct", Name: "main.Box[u8]", Symbol: "main.Box__g__u8", Requester: "main.wrap__g__u8", RequesterName: "main.wrap[u8]", File: "main.mg", Pos: types.FilePos{Line: 4, Col: 17}, IrBytes: 40},
+		{Kind: "function", Name: "main.wrap[u8]", Symbol: "main.wrap__g__u8", Requester: "main.main", RequesterName: "main.main", File: "main.mg", Pos: types.FilePos{Line: 9, Col: 5}, IrBytes: 300},
+		{Kind: "function", Name: "main.pass[u16]", Symbol: "main.pass__g__u16", Requester: "main.main", RequesterName: "main.main", File: "main.mg", Pos: types.FilePos{Line: 10, Col: 5}, IrBytes: 120, FoldedInto: "main.wrap__g__u8"},
+	}
+	var output bytes.Buffer
+	reportInstantiations(&output, records)
+	got := output.String()
+	for _, want := range []string{
+		"Generic instantiations: 3, 1 folded, 340 bytes of IR",
+		"  function main.wrap[u8] (300 bytes)\n    requested at main.mg:9:5 in main.main\n",
+		"  struct main.Box[u8] (40 bytes)\n    requested at main.mg:4:17 in main.wrap[u8]\n      requested at main.mg:9:5 in main.main\n",
+		"  function main.pass[u16] (120 bytes, folded into main.wrap[u8])",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("instantiation report missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "wrap[u8] (300") > strings.Index(got, "Box[u8] (40") {
+		t.Errorf("report is not ordered by IR size:\n%s", got)
+	}
+}
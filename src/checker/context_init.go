@@ -3,6 +3,7 @@ package checker
 import (
 	"Magma/src/comp_err"
 	t "Magma/src/types"
+	"slices"
 )
 
 type contextInitState struct {
@@ -72,7 +73,7 @@ func checkContextExpr(state *contextInitState, expr t.NodeExpr, assignmentTarget
 			}
 		}
 	case *t.NodeExprStructInit:
-		for _, field := range n.Fields {
+		for _, field := range append(slices.Clone(n.Fields), n.Defaults...) {
 			if err := checkContextExpr(state, field.Expression, false); err != nil {
 				return err
 			}
@@ -113,6 +114,8 @@ func checkContextExpr(state *contextInitState, expr t.NodeExpr, assignmentTarget
 		return checkContextExpr(state, n.Expr, false)
 	case *t.NodeExprMove:
 		return checkContextExpr(state, n.Expr, false)
+	case *t.NodeExprCast:
+		return checkContextExpr(state, n.Expr, false)
 	}
 	return nil
 }
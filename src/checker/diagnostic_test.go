@@ -211,22 +211,107 @@ test() void:
 	}
 }
 
-func TestNamedFunctionArgumentsAreRejected(t *testing.T) {
-	diagnostic, message := checkSource(t, `mod test
+func TestNamedArgumentDiagnostics(t *testing.T) {
+	for _, test := range []struct {
+		call, message, token, additional string
+	}{
+		{"consume(1, limt=2)", "function 'consume' has no parameter named 'limt'", "limt", "did you mean 'limit'?"},
+		{"consume(1, value=2)", "argument 'value' to 'consume' is passed more than once", "value", ""},
+		{"consume(limit=2)", "missing argument 'value' to 'consume'", "consume", "pass it positionally or as `value=value`"},
+		{"consume()", "missing argument 'value' to 'consume'", "consume", "pass it positionally or as `value=value`"},
+	} {
+		diagnostic, message := checkSource(t, `mod test
+
+consume(value u64, limit u64 = 8) void:
+..
 
-consume(value u64) void:
+test() void:
+    `+test.call+`
 ..
+`)
+		if !strings.Contains(message, test.message) {
+			t.Errorf("%s: diagnostic = %q, want %q", test.call, message, test.message)
+			continue
+		}
+		if diagnostic.Token.Repr != test.token {
+			t.Errorf("%s: diagnostic token = %q, want %q", test.call, diagnostic.Token.Repr, test.token)
+		}
+		if test.additional != "" && diagnostic.Additional != test.additional {
+			t.Errorf("%s: additional = %q, want %q", test.call, diagnostic.Additional, test.additional)
+		}
+	}
+}
+
+func TestStructFieldDefaultDiagnostics(t *testing.T) {
+	for _, test := range []struct {
+		construct, message, additional string
+	}{
+		{"Holder(count=2)", "missing field 'handle' in 'Holder' constructor", "field 'handle' of type 'Handle' owns a resource and has no default, so every constructor must pass it"},
+		{"Holder(handle=Handle(fd=1))", "missing field 'label' in 'Holder' constructor", "pass `label=value` or declare a default for the field"},
+	} {
+		diagnostic, message := checkSource(t, `mod test
+
+Handle(fd u64)
+destr Handle.close() void:
+..
+
+Holder(handle Handle, count u64 = 1, label u64)
 
 test() void:
-    consume(value=1)
+    holder := `+test.construct+`
 ..
 `)
+		if !strings.Contains(message, test.message) {
+			t.Errorf("%s: diagnostic = %q, want %q", test.construct, message, test.message)
+			continue
+		}
+		if diagnostic.Additional != test.additional {
+			t.Errorf("%s: additional = %q, want %q", test.construct, diagnostic.Additional, test.additional)
+		}
+	}
 
-	if !strings.Contains(message, "named arguments are not supported in function calls") {
-		t.Fatalf("diagnostic = %q, want named-argument rejection", message)
+	_, message := checkSource(t, `mod test
+
+Options(verbose bool = 3)
+
+test() void:
+    options := Options()
+..
+`)
+	if !strings.Contains(message, "default value of 'verbose' has type") || !strings.Contains(message, "the field expects 'bool'") {
+		t.Fatalf("diagnostic = %q, want a field default type mismatch", message)
 	}
-	if diagnostic.Additional == "" {
-		t.Fatal("named-argument diagnostic should explain positional syntax")
+}
+
+func TestAtomicIntrinsicDiagnostics(t *testing.T) {
+	for _, test := range []struct {
+		statement, message string
+	}{
+		{"atomic.load(addrof count, order)", "ordering of 'atomic.load[u64]' must be an ordering constant"},
+		{"atomic.load(addrof count, 9)", "ordering of 'atomic.load[u64]' must be an ordering constant"},
+		{"atomic.load(addrof count, atomic.RELEASE)", "'atomic.load[u64]' cannot use RELEASE ordering"},
+		{"atomic.store(addrof count, 1, atomic.ACQUIRE)", "'atomic.store[u64]' cannot use ACQUIRE ordering"},
+		{"atomic.compareExchange(addrof count, addrof count, 1, atomic.SEQ_CST, atomic.ACQ_REL)", "'atomic.compareExchange[u64]' cannot use ACQ_REL ordering"},
+		{"atomic.fence(atomic.RELAXED)", "'atomic.fence' cannot use RELAXED ordering"},
+		{"atomic.fetchAdd(addrof flag, true, atomic.RELAXED)", "'atomic.fetchAdd[bool]' cannot operate on 'bool'"},
+		{"atomic.fetchOr[ptr](addrof slot, slot, atomic.RELAXED)", "'atomic.fetchOr[ptr]' cannot operate on 'ptr'"},
+		{"atomic.load[u32](addrof count, atomic.RELAXED)", "'atomic.load[u32]' operates on 'u32', but the place holds 'u64'"},
+		{"loader := atomic.fence", "intrinsic 'atomic.fence' cannot be used as a function value"},
+	} {
+		_, message := checkSource(t, `mod test
+
+use "std:atomic" atomic
+
+test(order u8) void:
+    count u64 = 0
+    flag := false
+    slot ptr = none
+    `+test.statement+`
+..
+`)
+		if !strings.Contains(message, test.message) {
+			t.Errorf("%s: diagnostic = %q, want %q", test.statement, message, test.message)
+		}
 	}
 }
 
@@ -0,0 +1,120 @@
+package checker
+
+import (
+	"Magma/src/comp_err"
+	t "Magma/src/types"
+	"fmt"
+	"slices"
+)
+
+// clBindArguments puts the arguments of a direct call in parameter order. A
+// `name=value` argument selects its parameter by name, and a parameter the
+// call leaves out takes a copy of its default value. Once bound, the defaults
+// are ordinary arguments: they are type checked at the call and passed under
+// the same ownership rules as the arguments written there.
+func clBindArguments(c *ctx, call *t.NodeExprCall) error {
+	named := len(call.ArgNames) != 0
+	if call.IsFuncPointer || call.AssociatedFnDef == nil {
+		for _, name := range call.ArgNames {
+			if name.Repr != "" {
+				return comp_err.CompilationErrorToken(c.FileCtx, &name, fmt.Sprintf("cannot pass named argument '%s' to function value '%s'", name.Repr, callDisplayName(call)), "named arguments require a direct call to a declared function")
+			}
+		}
+		return nil
+	}
+
+	params := call.AssociatedFnDef.Class.ArgsNode.Args
+	if len(params) > 0 && params[0].Name == "this" {
+		params = params[1:]
+	}
+	if !named && len(call.Args) >= len(params) {
+		return nil
+	}
+
+	bound := make([]t.NodeExpr, len(params))
+	boundNames := make([]t.Token, len(params))
+	for i, arg := range call.Args {
+		if i >= len(call.ArgNames) || call.ArgNames[i].Repr == "" {
+			if i >= len(params) {
+				return comp_err.CompilationErrorToken(c.FileCtx, &call.Tk, fmt.Sprintf("function '%s' expects %d argument(s), but got %d", callDisplayName(call), len(params), len(call.Args)), "")
+			}
+			bound[i] = arg
+			continue
+		}
+		name := call.ArgNames[i]
+		index := -1
+		names := make([]string, len(params))
+		for j, param := range params {
+			names[j] = param.Name
+			if param.Name == name.Repr {
+				index = j
+			}
+		}
+		if index < 0 {
+			return comp_err.CompilationErrorToken(c.FileCtx, &name, fmt.Sprintf("function '%s' has no parameter named '%s'", callDisplayName(call), name.Repr), comp_err.Suggestion(name.Repr, names))
+		}
+		if bound[index] != nil {
+			return comp_err.CompilationErrorToken(c.FileCtx, &name, fmt.Sprintf("argument '%s' to '%s' is passed more than once", name.Repr, callDisplayName(call)), "each parameter takes a single argument")
+		}
+		bound[index], boundNames[index] = arg, name
+	}
+
+	defaulted := slices.ContainsFunc(params, func(param t.NodeArg) bool { return param.Default != nil })
+	for i, param := range params {
+		if bound[i] != nil || param.Default != nil {
+			continue
+		}
+		if !named && !defaulted {
+			// The type checker reports a short call to a function without
+			// defaults by its argument count.
+			return nil
+		}
+		return comp_err.CompilationErrorToken(c.FileCtx, &call.Tk, fmt.Sprintf("missing argument '%s' to '%s'", param.Name, callDisplayName(call)), fmt.Sprintf("pass it positionally or as `%s=value`", param.Name))
+	}
+	for i, param := range params {
+		if bound[i] == nil {
+			bound[i] = cloneDefault(param.Default, call.Tk)
+			if e := clExpr(c, bound[i], false); e != nil {
+				return e
+			}
+		}
+	}
+	call.Args = bound
+	if named {
+		call.ArgNames = boundNames
+	}
+	return nil
+}
+
+// clDefaultValue checks the default value of a parameter or field against its
+// type where it is declared, so that a use which relies on it cannot fail.
+func clDefaultValue(c *ctx, arg *t.NodeArg, kind string) error {
+	if e := clExpr(c, arg.Default, false); e != nil {
+		return e
+	}
+	if e := ctExpr(c, arg.Default); e != nil {
+		return e
+	}
+	if !compatibleInitializer(arg.TypeNode, arg.Default) {
+		return comp_err.CompilationErrorToken(c.FileCtx, &arg.Tk, fmt.Sprintf("default value of '%s' has type '%s', but the %s expects '%s'", arg.Name, flattenType(arg.Default.GetInferredType()), kind, flattenType(arg.TypeNode)), "")
+	}
+	return nil
+}
+
+// cloneDefault copies a default value for one call. The copy is positioned at
+// the call, which is where any diagnostic about the passed value belongs.
+func cloneDefault(expr t.NodeExpr, at t.Token) t.NodeExpr {
+	moved := func(tk t.Token) t.Token {
+		tk.Pos, tk.End = at.Pos, at.End
+		return tk
+	}
+	switch n := expr.(type) {
+	case *t.NodeExprLit:
+		return &t.NodeExprLit{Tk: moved(n.Tk), Value: n.Value, LitType: n.LitType}
+	case *t.NodeExprUnary:
+		return &t.NodeExprUnary{Tk: moved(n.Tk), Operator: n.Operator, Operand: cloneDefault(n.Operand, at)}
+	case *t.NodeExprBinary:
+		return &t.NodeExprBinary{Tk: moved(n.Tk), Operator: n.Operator, Left: cloneDefault(n.Left, at), Right: cloneDefault(n.Right, at)}
+	}
+	return expr
+}
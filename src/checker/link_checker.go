@@ -25,32 +25,67 @@ func clResolveImplementations(c *ctx, st *t.StructDef) error {
 		seen[key] = true
 		implementation.Proto = proto
 		implementation.Owner = st
-		for _, requirement := range proto.Methods {
-			method := st.Funcs[requirement.Name]
-			if method == nil {
-				return comp_err.CompilationErrorToken(c.FileCtx, &implementation.Tk, fmt.Sprintf("type '%s' does not implement '%s': missing method '%s'", st.Name, proto.Name, requirement.Name), fmt.Sprintf("declare `%s.%s` with the prototype signature", st.Name, requirement.Name))
-			}
-			if method.ContextABI != requirement.ContextABI {
-				return comp_err.CompilationErrorToken(c.FileCtx, &implementation.Tk, fmt.Sprintf("method '%s.%s' has an incompatible context calling convention", st.Name, requirement.Name), "match the prototype method's noctx modifier")
-			}
-			actual := method.Class.ArgsNode.Args
-			if len(actual) == 0 || len(actual)-1 != len(requirement.Args) {
-				return comp_err.CompilationErrorToken(c.FileCtx, &implementation.Tk, fmt.Sprintf("method '%s.%s' does not satisfy '%s.%s': expected %d argument(s), got %d", st.Name, requirement.Name, proto.Name, requirement.Name, len(requirement.Args), max(0, len(actual)-1)), "")
-			}
-			for i := range requirement.Args {
-				if !sameType(actual[i+1].TypeNode, requirement.Args[i].TypeNode) {
-					return comp_err.CompilationErrorToken(c.FileCtx, &actual[i+1].Tk, fmt.Sprintf("method '%s.%s' parameter %d has type '%s', expected '%s'", st.Name, requirement.Name, i+1, t.DisplayType(actual[i+1].TypeNode), t.DisplayType(requirement.Args[i].TypeNode)), "")
-				}
+	}
+	// Implementing a prototype implements its bases, whose vtables the
+	// prototype's vtable points to.
+	for i := 0; i < len(st.Implements); i++ {
+		implementation := st.Implements[i]
+		for _, base := range implementation.Proto.Extends {
+			if base.Proto == nil || seen[base.Proto.Module+"."+base.Proto.Name] {
+				continue
 			}
-			if !sameType(method.ReturnType, requirement.Ret) {
-				return comp_err.CompilationErrorToken(c.FileCtx, &implementation.Tk, fmt.Sprintf("method '%s.%s' returns '%s', expected '%s'", st.Name, requirement.Name, t.DisplayType(method.ReturnType), t.DisplayType(requirement.Ret)), "")
+			seen[base.Proto.Module+"."+base.Proto.Name] = true
+			st.Implements = append(st.Implements, &t.ProtoImpl{Type: base.Type, Proto: base.Proto, Owner: st, Tk: implementation.Tk, Implied: true})
+		}
+	}
+	for _, implementation := range st.Implements {
+		if err := clCheckImplementation(c, st, implementation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func clCheckImplementation(c *ctx, st *t.StructDef, implementation *t.ProtoImpl) error {
+	proto := implementation.Proto
+	for _, requirement := range proto.Methods {
+		method := st.Funcs[requirement.Name]
+		if method == nil && requirement.Default != nil {
+			continue
+		}
+		if method == nil {
+			return comp_err.CompilationErrorToken(c.FileCtx, &implementation.Tk, fmt.Sprintf("type '%s' does not implement '%s': missing method '%s'", st.Name, proto.Name, requirement.Name), fmt.Sprintf("declare `%s.%s` with the prototype signature", st.Name, requirement.Name))
+		}
+		if method.ContextABI != requirement.ContextABI {
+			return comp_err.CompilationErrorToken(c.FileCtx, &implementation.Tk, fmt.Sprintf("method '%s.%s' has an incompatible context calling convention", st.Name, requirement.Name), "match the prototype method's noctx modifier")
+		}
+		actual := method.Class.ArgsNode.Args
+		if len(actual) == 0 || len(actual)-1 != len(requirement.Args) {
+			return comp_err.CompilationErrorToken(c.FileCtx, &implementation.Tk, fmt.Sprintf("method '%s.%s' does not satisfy '%s.%s': expected %d argument(s), got %d", st.Name, requirement.Name, proto.Name, requirement.Name, len(requirement.Args), max(0, len(actual)-1)), "")
+		}
+		for i := range requirement.Args {
+			if !sameType(actual[i+1].TypeNode, requirement.Args[i].TypeNode) {
+				return comp_err.CompilationErrorToken(c.FileCtx, &actual[i+1].Tk, fmt.Sprintf("method '%s.%s' parameter %d has type '%s', expected '%s'", st.Name, requirement.Name, i+1, t.DisplayType(actual[i+1].TypeNode), t.DisplayType(requirement.Args[i].TypeNode)), "")
 			}
 		}
+		if !sameType(method.ReturnType, requirement.Ret) {
+			return comp_err.CompilationErrorToken(c.FileCtx, &implementation.Tk, fmt.Sprintf("method '%s.%s' returns '%s', expected '%s'", st.Name, requirement.Name, t.DisplayType(method.ReturnType), t.DisplayType(requirement.Ret)), "")
+		}
 	}
 	return nil
 }
 
 func clFuncDef(c *ctx, fnDef *t.NodeFuncDef) error {
+	if fnDef.Derived {
+		// The members of a generic instance are declared before its struct, and
+		// unsupported fields must be reported before the member bodies.
+		if owner := clDerivedOwner(c, fnDef); owner != nil {
+			if e := clStructDef(c, owner); e != nil {
+				return e
+			}
+		}
+	}
+
 	var scope *t.Scope = nil
 
 	for _, f := range c.CurrScope.DeclFuncs {
@@ -72,11 +107,17 @@ func clFuncDef(c *ctx, fnDef *t.NodeFuncDef) error {
 		}
 	}
 
-	for _, arg := range fnDef.Class.ArgsNode.Args {
+	for i := range fnDef.Class.ArgsNode.Args {
+		arg := &fnDef.Class.ArgsNode.Args[i]
 		e := clTypeForUsage(c, arg.TypeNode, typeUsageValue, "a function parameter type")
 		if e != nil {
 			return e
 		}
+		if arg.Default != nil {
+			if e := clDefaultValue(c, arg, "parameter"); e != nil {
+				return e
+			}
+		}
 	}
 
 	e := clTypeForUsage(c, fnDef.ReturnType, typeUsageReturn, "a function return type")
@@ -92,11 +133,63 @@ func clFuncDef(c *ctx, fnDef *t.NodeFuncDef) error {
 }
 
 func clStructDef(c *ctx, stDef *t.NodeStructDef) error {
-	for _, arg := range stDef.Class.ArgsNode.Args {
+	for i := range stDef.Class.ArgsNode.Args {
+		arg := &stDef.Class.ArgsNode.Args[i]
 		e := clTypeForUsage(c, arg.TypeNode, typeUsageValue, "a struct field type")
 		if e != nil {
 			return e
 		}
+		if arg.Default != nil {
+			if e := clDefaultValue(c, arg, "field"); e != nil {
+				return e
+			}
+		}
+	}
+	if def := c.GlobalNode.StructDefs[flattenName(stDef.Class.NameNode)]; def != nil {
+		return clDerivedFields(c, def, stDef.Class.ArgsNode.Args)
+	}
+	return nil
+}
+
+func clDerivedOwner(c *ctx, fnDef *t.NodeFuncDef) *t.NodeStructDef {
+	name, ok := fnDef.Class.NameNode.(*t.NodeNameComposite)
+	if !ok {
+		return nil
+	}
+	for _, decl := range c.GlobalNode.Declarations {
+		if st, ok := decl.(*t.NodeStructDef); ok && flattenName(st.Class.NameNode) == name.Parts[0] {
+			return st
+		}
+	}
+	return nil
+}
+
+// clDerivedFields checks that every field supports the operations derived on
+// its struct: a derived member calls the member of the same name on each
+// field, through a borrow of the field. Generic structs are checked once per
+// instantiation.
+func clDerivedFields(c *ctx, def *t.StructDef, fields []t.NodeArg) error {
+	for _, derive := range def.Derives {
+		for _, field := range fields {
+			reason := ""
+			switch field.TypeNode.KindNode.(type) {
+			case *t.NodeTypePointer, *t.NodeTypeRfc:
+				reason = "pointer fields cannot be derived"
+			case *t.NodeTypeSlice:
+				reason = "slice fields cannot be derived"
+			case *t.NodeTypeFunc:
+				reason = "function fields cannot be derived"
+			default:
+				if _, _, _, _, e := clResolveMemberFunc(c, field.TypeNode, derive.Method); e != nil {
+					reason = fmt.Sprintf("declare `%s` on the field type, or derive \"%s\" on it", derive.Method, derive.Name)
+				}
+			}
+			if reason != "" {
+				return comp_err.CompilationErrorToken(c.FileCtx, &field.Tk,
+					fmt.Sprintf("field '%s' of type '%s' does not support derived '%s'", field.Name, t.DisplayType(field.TypeNode), derive.Name),
+					reason)
+			}
+		}
 	}
 	return nil
 }
@@ -196,6 +289,9 @@ func clGlobal(c *ctx, gl *t.NodeGlobal) error {
 			}
 		}
 	}
+	if err := clResolveProtos(c, gl); err != nil {
+		return err
+	}
 	for _, st := range gl.StructDefs {
 		if err := clResolveImplementations(c, st); err != nil {
 			return err
@@ -219,6 +315,7 @@ func CheckLinks(s *t.SharedState) error {
 		},
 		PrimitiveMethods: map[string]primitiveMethod{},
 		AliasStack:       map[string]bool{},
+		ResolvedProtos:   map[*t.ProtoDef]bool{},
 	}
 
 	// Sorted by dependency resolution order
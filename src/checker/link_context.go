@@ -21,6 +21,9 @@ type ctx struct {
 
 	CurrScope  *t.Scope
 	AliasStack map[string]bool
+	// ResolvedProtos are the prototypes whose bases and inherited
+	// requirements have been resolved.
+	ResolvedProtos map[*t.ProtoDef]bool
 }
 
 type primitiveMethod struct {
@@ -51,6 +54,23 @@ func (e *privateSymbolError) Error() string {
 	return fmt.Sprintf("%s '%s.%s' is private and cannot be used from another module", e.kind, e.module, e.name)
 }
 
+// unknownNameDiagnostic reports an unresolved name with a suggestion for a
+// misspelling. A qualified name whose qualifier is not an import alias of the
+// file is coded as a missing import and reported at the qualifier.
+func unknownNameDiagnostic(c *ctx, name t.NodeName, typeName bool, description, additional string) error {
+	if composite, ok := name.(*t.NodeNameComposite); ok && len(composite.Parts) > 1 && c.GlobalNode != nil {
+		if _, imported := c.GlobalNode.ImportAlias[composite.Parts[0]]; !imported {
+			token := lastNameToken(name)
+			if len(composite.Tokens) > 0 {
+				token = &composite.Tokens[0]
+			}
+			additional = comp_err.WithSuggestion(additional, composite.Parts[0], qualifierCandidates(c))
+			return comp_err.CompilationErrorCode(c.FileCtx, token, "missing-import", description, additional)
+		}
+	}
+	return comp_err.CompilationErrorToken(c.FileCtx, lastNameToken(name), description, nameSuggestion(c, name, typeName, additional))
+}
+
 func privateSymbolDiagnostic(c *ctx, token *t.Token, err error) error {
 	private, ok := err.(*privateSymbolError)
 	if !ok {
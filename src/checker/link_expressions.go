@@ -7,6 +7,14 @@ import (
 )
 
 func clExprCall(c *ctx, call *t.NodeExprCall) error {
+	if e := clResolveCall(c, call); e != nil {
+		return e
+	}
+	return clBindArguments(c, call)
+}
+
+// clResolveCall links the callee and the arguments of a call as written.
+func clResolveCall(c *ctx, call *t.NodeExprCall) error {
 	var ownerExpr t.Node = nil
 	var nameExpr *t.NodeExprName = nil
 
@@ -23,12 +31,7 @@ func clExprCall(c *ctx, call *t.NodeExprCall) error {
 		}
 
 		if !found {
-			return comp_err.CompilationErrorToken(
-				c.FileCtx,
-				lastNameToken(n.Name),
-				fmt.Sprintf("unknown function '%s'", flattenName(n.Name)),
-				"",
-			)
+			return unknownNameDiagnostic(c, n.Name, false, fmt.Sprintf("unknown function '%s'", flattenName(n.Name)), "")
 		}
 
 		if isSsa {
@@ -276,6 +279,11 @@ func clExpr(c *ctx, expr t.NodeExpr, lvalue bool) error {
 		return clExpr(c, n.Expr, lvalue)
 	case *t.NodeExprMove:
 		return clExpr(c, n.Expr, false)
+	case *t.NodeExprCast:
+		if e := clTypeForUsage(c, n.Type, typeUsageValue, "a cast target type"); e != nil {
+			return e
+		}
+		return clExpr(c, n.Expr, false)
 	case *t.NodeExprCall:
 		return clExprCall(c, n)
 	case *t.NodeExprStructInit:
@@ -300,7 +308,7 @@ func clExpr(c *ctx, expr t.NodeExpr, lvalue bool) error {
 			seen[field.Name] = true
 			fieldType, ok := def.Fields[field.Name]
 			if !ok {
-				return comp_err.CompilationErrorToken(c.FileCtx, &field.Tk, fmt.Sprintf("type '%s' has no field named '%s'", def.Name, field.Name), "")
+				return comp_err.CompilationErrorToken(c.FileCtx, &field.Tk, fmt.Sprintf("type '%s' has no field named '%s'", def.Name, field.Name), memberSuggestion(def, field.Name, true, ""))
 			}
 			field.FieldIndex = def.FieldNb[field.Name]
 			field.FieldType = fieldType
@@ -308,10 +316,28 @@ func clExpr(c *ctx, expr t.NodeExpr, lvalue bool) error {
 				return e
 			}
 		}
+		// An omitted field takes its declared default. A field without one must
+		// be passed; for a field which owns a resource, a zeroed value would be
+		// destroyed without ever holding one.
+		n.Defaults = n.Defaults[:0]
 		for _, name := range def.FieldOrder {
-			if !seen[name] {
-				return comp_err.CompilationErrorToken(c.FileCtx, &n.Tk, fmt.Sprintf("missing field '%s' in '%s' constructor", name, def.Name), "all struct fields must be initialized")
+			if seen[name] {
+				continue
+			}
+			fieldType := def.Fields[name]
+			value := def.Defaults[name]
+			if value == nil {
+				hint := fmt.Sprintf("pass `%s=value` or declare a default for the field", name)
+				if clOwnsResource(c, fieldType) {
+					hint = fmt.Sprintf("field '%s' of type '%s' owns a resource and has no default, so every constructor must pass it", name, flattenType(fieldType))
+				}
+				return comp_err.CompilationErrorToken(c.FileCtx, &n.Tk, fmt.Sprintf("missing field '%s' in '%s' constructor", name, def.Name), hint)
 			}
+			value = cloneDefault(value, n.Tk)
+			if e := clExpr(c, value, false); e != nil {
+				return e
+			}
+			n.Defaults = append(n.Defaults, t.NodeStructFieldInit{Tk: n.Tk, Name: name, Expression: value, FieldIndex: def.FieldNb[name], FieldType: fieldType})
 		}
 		return nil
 	case *t.NodeExprProtoView:
@@ -347,17 +373,27 @@ func clExpr(c *ctx, expr t.NodeExpr, lvalue bool) error {
 				return nil
 			}
 		}
+		if owner.IsProto && owner.Proto != nil {
+			if path, ok := protoUpcastPath(owner.Proto, protoStruct.Proto); ok {
+				n.Upcast = path
+				n.Implementation = path[len(path)-1]
+				n.InfType = n.ProtoType
+				return nil
+			}
+		}
 		return comp_err.CompilationErrorToken(c.FileCtx, &n.Tk, fmt.Sprintf("type '%s' does not implement prototype '%s'", owner.Name, protoStruct.Proto.Name), "")
 	case *t.NodeExprTry:
+		previousBoundary := c.ErrorBoundary
+		c.ErrorBoundary = 1
+		defer func() { c.ErrorBoundary = previousBoundary }()
+		if cast, ok := n.Call.(*t.NodeExprCast); ok {
+			return clExpr(c, cast, false)
+		}
 		call, ok := n.Call.(*t.NodeExprCall)
 		if !ok {
 			return fmt.Errorf("try requires a throwing function call")
 		}
-		previousBoundary := c.ErrorBoundary
-		c.ErrorBoundary = 1
-		err := clExprCall(c, call)
-		c.ErrorBoundary = previousBoundary
-		return err
+		return clExprCall(c, call)
 	case *t.NodeExprSubscript:
 		return clExprSubscript(c, n)
 	case *t.NodeExprMemberAccess:
@@ -436,3 +472,19 @@ func clExpr(c *ctx, expr t.NodeExpr, lvalue bool) error {
 	}
 	return nil
 }
+
+// clOwnsResource reports whether a value of valueType carries a destruction
+// obligation: it is owned, or its struct declares a destructor.
+func clOwnsResource(c *ctx, valueType *t.NodeType) bool {
+	if valueType == nil {
+		return false
+	}
+	if valueType.Owned || valueType.Destructor != nil {
+		return true
+	}
+	if absolute, ok := valueType.KindNode.(*t.NodeTypeAbsolute); ok {
+		definition, e := clGetStructDefFromAbsolute(c, absolute.AbsoluteName)
+		return e == nil && definition != nil && len(definition.Destructors) != 0
+	}
+	return false
+}
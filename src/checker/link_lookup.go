@@ -336,9 +336,10 @@ func clGetFuncDefFromName(c *ctx, nameNode t.NodeName) (*t.NodeFuncDef, error) {
 		if composite, compositeOK := nameNode.(*t.NodeNameComposite); compositeOK && len(composite.Tokens) > 0 {
 			token = &composite.Tokens[0]
 		}
-		return nil, comp_err.CompilationErrorToken(
+		return nil, comp_err.CompilationErrorCode(
 			c.FileCtx,
 			token,
+			"missing-import",
 			fmt.Sprintf("unknown module alias '%s'", lookup.moduleAlias),
 			"import the module or use an alias declared in this file",
 		)
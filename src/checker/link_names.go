@@ -51,7 +51,7 @@ func clVarNameChainValidAtOffset(c *ctx, scope *t.Scope, source *t.NodeExprName,
 			c.FileCtx,
 			memberNameTokenAtOffset(source, 0, memberTokenOffset),
 			fmt.Sprintf("type '%s' has no member function '%s'", primitive, name.Parts[0]),
-			"",
+			primitiveMemberSuggestion(c, primitive, name.Parts[0]),
 		)
 	}
 
@@ -122,7 +122,7 @@ func clVarNameChainValidAtOffset(c *ctx, scope *t.Scope, source *t.NodeExprName,
 					c.FileCtx,
 					memberNameTokenAtOffset(source, i+1, memberTokenOffset),
 					fmt.Sprintf("type '%s' has no member function '%s'", primitive, next),
-					"",
+					primitiveMemberSuggestion(c, primitive, next),
 				)
 			}
 
@@ -155,7 +155,7 @@ func clVarNameChainValidAtOffset(c *ctx, scope *t.Scope, source *t.NodeExprName,
 			c.FileCtx,
 			memberNameTokenAtOffset(source, i, memberTokenOffset),
 			fmt.Sprintf("type '%s' has no member named '%s'", structDef.Name, part),
-			"",
+			memberSuggestion(structDef, part, false, ""),
 		)
 	}
 
@@ -360,7 +360,7 @@ func clName(c *ctx, name *t.NodeExprName, expected entryType, lvalue bool) error
 		if lvalue {
 			description = fmt.Sprintf("unknown variable '%s'", flattenName(name.Name))
 		}
-		return comp_err.CompilationErrorToken(c.FileCtx, lastNameToken(name.Name), description, "")
+		return unknownNameDiagnostic(c, name.Name, false, description, "")
 	}
 
 	if isSsa {
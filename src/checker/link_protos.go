@@ -0,0 +1,176 @@
+package checker
+
+import (
+	"Magma/src/comp_err"
+	scopeinfo "Magma/src/scope_info"
+	t "Magma/src/types"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// clResolveProtos resolves the prototypes declared by gl in name order.
+func clResolveProtos(c *ctx, gl *t.NodeGlobal) error {
+	names := make([]string, 0, len(gl.StructDefs))
+	for name, def := range gl.StructDefs {
+		if def.IsProto && def.Proto != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := clResolveProto(c, gl.StructDefs[name], map[*t.ProtoDef]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clResolveProto resolves the bases named by `extends`, declares a dispatcher
+// for every requirement the prototype inherits, and checks its default
+// bodies. Bases declared in the same module are resolved first; imported ones
+// were resolved when their module was linked.
+func clResolveProto(c *ctx, def *t.StructDef, resolving map[*t.ProtoDef]bool) error {
+	proto := def.Proto
+	if c.ResolvedProtos[proto] {
+		return nil
+	}
+	resolving[proto] = true
+	defer delete(resolving, proto)
+
+	seen := map[*t.ProtoDef]bool{}
+	for i, base := range proto.Extends {
+		if err := clType(c, base.Type); err != nil {
+			return err
+		}
+		baseStruct, err := clGetStructDefFromType(c, base.Type)
+		if err != nil || baseStruct == nil || !baseStruct.IsProto || baseStruct.Proto == nil {
+			return comp_err.CompilationErrorToken(c.FileCtx, &base.Tk, fmt.Sprintf("type '%s' is not a prototype", t.DisplayType(base.Type)), "only types declared with `proto` may follow `extends`")
+		}
+		if resolving[baseStruct.Proto] {
+			return comp_err.CompilationErrorToken(c.FileCtx, &base.Tk, fmt.Sprintf("prototype '%s' cannot extend '%s', which extends it", proto.Name, baseStruct.Name), "")
+		}
+		if seen[baseStruct.Proto] {
+			return comp_err.CompilationErrorToken(c.FileCtx, &base.Tk, fmt.Sprintf("prototype '%s' is extended more than once", baseStruct.Name), "")
+		}
+		seen[baseStruct.Proto] = true
+		if baseStruct.Module == def.Module {
+			if err := clResolveProto(c, baseStruct, resolving); err != nil {
+				return err
+			}
+		}
+		base.Proto = baseStruct.Proto
+		base.Owner = def
+		for _, inherited := range slices.Concat(base.Proto.Methods, base.Proto.Inherited) {
+			if err := clInheritRequirement(c, def, i, inherited); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, method := range proto.Methods {
+		if method.Default == nil {
+			continue
+		}
+		if err := clMatchRequirement(c, method.Default, method, lastNameToken(method.Default.Class.NameNode), fmt.Sprintf("default body of '%s.%s'", def.Name, method.Name)); err != nil {
+			return err
+		}
+	}
+	c.ResolvedProtos[proto] = true
+	return nil
+}
+
+// clInheritRequirement makes requirement, declared by or inherited through
+// the base at index base, a requirement of def, unless def declares it.
+func clInheritRequirement(c *ctx, def *t.StructDef, base int, requirement *t.ProtoMethod) error {
+	proto := def.Proto
+	baseTk := proto.Extends[base].Tk
+	baseName := proto.Extends[base].Proto.Name
+	if own := proto.MethodMap[requirement.Name]; own != nil {
+		return clMatchRequirement(c, own.FnDef, requirement, &own.Tk, fmt.Sprintf("prototype method '%s.%s'", def.Name, own.Name))
+	}
+	for _, existing := range proto.Inherited {
+		if existing.Name != requirement.Name {
+			continue
+		}
+		if protoRequirementRoot(existing) == protoRequirementRoot(requirement) {
+			return nil
+		}
+		return comp_err.CompilationErrorToken(c.FileCtx, &baseTk,
+			fmt.Sprintf("prototype '%s' inherits method '%s' from both '%s' and '%s'", def.Name, requirement.Name, proto.Extends[existing.Base].Proto.Name, baseName),
+			fmt.Sprintf("declare `%s` in '%s' with one signature satisfying both", requirement.Name, def.Name))
+	}
+	if def.Funcs[requirement.Name] != nil {
+		return comp_err.CompilationErrorToken(c.FileCtx, lastNameToken(def.Funcs[requirement.Name].Class.NameNode),
+			fmt.Sprintf("member '%s.%s' conflicts with the method '%s' inherits from '%s'", def.Name, requirement.Name, def.Name, baseName), "")
+	}
+
+	this := &t.NodeType{KindNode: &t.NodeTypePointer{Kind: &t.NodeTypeAbsolute{AbsoluteName: def.Module + "." + def.Name}}}
+	args := []t.NodeArg{{Name: "this", TypeNode: this}}
+	inherited := &t.ProtoMethod{Name: requirement.Name, Ret: cloneAliasType(requirement.Ret), ContextABI: requirement.ContextABI, Tk: baseTk, Proto: proto, Origin: requirement, Base: base}
+	for _, arg := range requirement.Args {
+		inherited.Args = append(inherited.Args, t.NodeArg{Name: arg.Name, Tk: arg.Tk, TypeNode: cloneAliasType(arg.TypeNode)})
+	}
+	args = append(args, inherited.Args...)
+	fn := &t.NodeFuncDef{
+		Class:         t.NodeGenericClass{NameNode: &t.NodeNameComposite{Parts: []string{def.Name, requirement.Name}, Tokens: []t.Token{baseTk, baseTk}}, ArgsNode: t.NodeArgList{Args: args}},
+		ReturnType:    inherited.Ret,
+		AbsName:       def.Module + "." + def.Name + "." + requirement.Name,
+		IsMember:      true,
+		ContextABI:    requirement.ContextABI,
+		ProtoDispatch: inherited,
+	}
+	inherited.FnDef = fn
+	proto.Inherited = append(proto.Inherited, inherited)
+	def.Funcs[requirement.Name] = fn
+	c.GlobalNode.FuncDefs[def.Name+"."+requirement.Name] = fn
+	c.GlobalNode.Declarations = append(c.GlobalNode.Declarations, fn)
+	return scopeinfo.DeclareFunction(c.FileCtx, c.ScopeTree, fn)
+}
+
+// clMatchRequirement checks that fn, a member of a prototype, has the
+// signature of requirement.
+func clMatchRequirement(c *ctx, fn *t.NodeFuncDef, requirement *t.ProtoMethod, tk *t.Token, subject string) error {
+	expected := fmt.Sprintf("%s.%s", requirement.Proto.Name, requirement.Name)
+	if fn.ContextABI != requirement.ContextABI {
+		return comp_err.CompilationErrorToken(c.FileCtx, tk, fmt.Sprintf("%s has an incompatible context calling convention", subject), fmt.Sprintf("match the noctx modifier of '%s'", expected))
+	}
+	actual := fn.Class.ArgsNode.Args
+	if len(actual) == 0 || len(actual)-1 != len(requirement.Args) {
+		return comp_err.CompilationErrorToken(c.FileCtx, tk, fmt.Sprintf("%s does not match '%s': expected %d argument(s), got %d", subject, expected, len(requirement.Args), max(0, len(actual)-1)), "")
+	}
+	for i := range requirement.Args {
+		if !sameType(actual[i+1].TypeNode, requirement.Args[i].TypeNode) {
+			return comp_err.CompilationErrorToken(c.FileCtx, tk, fmt.Sprintf("%s parameter %d has type '%s', expected '%s' as in '%s'", subject, i+1, t.DisplayType(actual[i+1].TypeNode), t.DisplayType(requirement.Args[i].TypeNode), expected), "")
+		}
+	}
+	if !sameType(fn.ReturnType, requirement.Ret) {
+		return comp_err.CompilationErrorToken(c.FileCtx, tk, fmt.Sprintf("%s returns '%s', expected '%s' as in '%s'", subject, t.DisplayType(fn.ReturnType), t.DisplayType(requirement.Ret), expected), "")
+	}
+	return nil
+}
+
+// protoRequirementRoot is the requirement an inherited one dispatches to.
+func protoRequirementRoot(method *t.ProtoMethod) *t.ProtoMethod {
+	for method.Origin != nil {
+		method = method.Origin
+	}
+	return method
+}
+
+// protoUpcastPath returns the `extends` relations leading from proto to base,
+// or false when proto does not extend base.
+func protoUpcastPath(proto, base *t.ProtoDef) ([]*t.ProtoImpl, bool) {
+	for _, relation := range proto.Extends {
+		if relation.Proto == nil {
+			continue
+		}
+		if relation.Proto == base {
+			return []*t.ProtoImpl{relation}, true
+		}
+		if rest, ok := protoUpcastPath(relation.Proto, base); ok {
+			return append([]*t.ProtoImpl{relation}, rest...), true
+		}
+	}
+	return nil, false
+}
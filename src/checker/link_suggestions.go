@@ -0,0 +1,150 @@
+package checker
+
+import (
+	"Magma/src/comp_err"
+	magmatypes "Magma/src/magma_types"
+	t "Magma/src/types"
+	"strings"
+)
+
+// nameSuggestion adds a "did you mean" note for an unresolved name. An
+// unqualified name is compared with the names visible from the current scope;
+// a qualified one with the public declarations of its module. Type names are
+// compared only with types.
+func nameSuggestion(c *ctx, name t.NodeName, typeName bool, additional string) string {
+	if c.GlobalNode == nil {
+		return additional
+	}
+	parsed := parseName(name)
+	if !parsed.HasParts {
+		if typeName {
+			return comp_err.WithSuggestion(additional, parsed.First, typeCandidates(c.GlobalNode, false))
+		}
+		return comp_err.WithSuggestion(additional, parsed.First, valueCandidates(c))
+	}
+	moduleName, consumed, err := resolveModuleName(c, parsed)
+	if err != nil || consumed != len(parsed.Parts) {
+		return additional
+	}
+	module := c.ModuleBundle.Modules[moduleName]
+	if module == nil {
+		return additional
+	}
+	candidates := typeCandidates(module, true)
+	if !typeName {
+		candidates = append(candidates, globalCandidates(module, true)...)
+		candidates = append(candidates, functionCandidates(module, true)...)
+	}
+	return comp_err.WithSuggestion(additional, parsed.Parts[len(parsed.Parts)-1], candidates)
+}
+
+// memberSuggestion adds a "did you mean" note for an unknown field or method
+// of a struct.
+func memberSuggestion(definition *t.StructDef, member string, fields bool, additional string) string {
+	candidates := make([]string, 0, len(definition.Fields)+len(definition.Funcs))
+	for field := range definition.Fields {
+		candidates = append(candidates, field)
+	}
+	if !fields {
+		for method := range definition.Funcs {
+			candidates = append(candidates, method)
+		}
+	}
+	return comp_err.WithSuggestion(additional, member, candidates)
+}
+
+// primitiveMemberSuggestion adds a "did you mean" note for an unknown method
+// of a primitive type.
+func primitiveMemberSuggestion(c *ctx, primitive, member string) string {
+	candidates := []string{}
+	for key := range c.PrimitiveMethods {
+		if method, ok := strings.CutPrefix(key, primitive+"."); ok {
+			candidates = append(candidates, method)
+		}
+	}
+	return comp_err.Suggestion(member, candidates)
+}
+
+// qualifierCandidates are the names which may qualify another: the import
+// aliases of the file and the variables in scope.
+func qualifierCandidates(c *ctx) []string {
+	candidates := []string{}
+	for alias := range c.GlobalNode.ImportAlias {
+		candidates = append(candidates, alias)
+	}
+	for scope := c.CurrScope; scope != nil; scope = scope.Parent {
+		for name := range scope.DeclVars {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+// valueCandidates are the unqualified names an expression may refer to: the
+// variables and functions of the enclosing scopes and the module's own
+// functions, globals, and structs.
+func valueCandidates(c *ctx) []string {
+	candidates := []string{}
+	for scope := c.CurrScope; scope != nil; scope = scope.Parent {
+		for name := range scope.DeclVars {
+			candidates = append(candidates, name)
+		}
+		for name := range scope.DeclFuncs {
+			candidates = append(candidates, name)
+		}
+	}
+	candidates = append(candidates, functionCandidates(c.GlobalNode, false)...)
+	candidates = append(candidates, globalCandidates(c.GlobalNode, false)...)
+	return append(candidates, typeCandidates(c.GlobalNode, false)...)
+}
+
+// functionCandidates are a module's free functions, leaving out members and
+// generic instances; with public set, only the exported ones.
+func functionCandidates(module *t.NodeGlobal, public bool) []string {
+	candidates := []string{}
+	for name, function := range module.FuncDefs {
+		if !strings.Contains(name, ".") && !strings.Contains(name, "__") && (function.IsPublic || !public) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+func globalCandidates(module *t.NodeGlobal, public bool) []string {
+	candidates := []string{}
+	for _, declaration := range module.Declarations {
+		var variable *t.NodeExprVarDef
+		switch node := declaration.(type) {
+		case *t.NodeConstDef:
+			variable = node.VarDef
+		case *t.NodeExprVarDef:
+			variable = node
+		}
+		if variable != nil && (variable.IsPublic || !public) {
+			candidates = append(candidates, flattenName(variable.Name))
+		}
+	}
+	return candidates
+}
+
+// typeCandidates are a module's structs, prototypes, and type aliases, and
+// for the module itself, the basic types.
+func typeCandidates(module *t.NodeGlobal, public bool) []string {
+	candidates := []string{}
+	for name, definition := range module.StructDefs {
+		if !strings.Contains(name, "__") && (definition.IsPublic || !public) {
+			candidates = append(candidates, name)
+		}
+	}
+	for name, alias := range module.TypeAliases {
+		if alias.IsPublic || !public {
+			candidates = append(candidates, name)
+		}
+	}
+	if !public {
+		for name := range magmatypes.BasicTypes {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
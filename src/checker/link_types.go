@@ -119,12 +119,7 @@ func clTypeKind(c *ctx, parentType *t.NodeType, kind t.NodeTypeKind, topLevel bo
 					AbsoluteName: sd.Module + "." + sd.Name,
 				}, nil
 			}
-			return nil, comp_err.CompilationErrorToken(
-				c.FileCtx,
-				lastNameToken(n.NameNode),
-				fmt.Sprintf("unknown type '%s'", flattenName(n.NameNode)),
-				"",
-			)
+			return nil, unknownNameDiagnostic(c, n.NameNode, true, fmt.Sprintf("unknown type '%s'", flattenName(n.NameNode)), "")
 		case *t.NodeNameComposite:
 			sd, e := clGetStructDefFromModule(c, parseName(nn))
 
@@ -140,12 +135,7 @@ func clTypeKind(c *ctx, parentType *t.NodeType, kind t.NodeTypeKind, topLevel bo
 			if private, ok := e.(*privateSymbolError); ok {
 				return nil, comp_err.CompilationErrorToken(c.FileCtx, lastNameToken(n.NameNode), private.Error(), "add 'pub' to the struct declaration to export it")
 			}
-			return nil, comp_err.CompilationErrorToken(
-				c.FileCtx,
-				lastNameToken(n.NameNode),
-				fmt.Sprintf("unknown type '%s'", flattenName(n.NameNode)),
-				"",
-			)
+			return nil, unknownNameDiagnostic(c, n.NameNode, true, fmt.Sprintf("unknown type '%s'", flattenName(n.NameNode)), "")
 		}
 	case *t.NodeTypeSlice:
 		newT, e := clTypeKind(c, parentType, n.ElemKind, false)
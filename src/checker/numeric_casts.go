@@ -0,0 +1,77 @@
+package checker
+
+import (
+	"fmt"
+
+	"Magma/src/comp_err"
+	t "Magma/src/types"
+)
+
+// ctExprCast validates an explicit numeric cast. The cast's result has exactly
+// the target type, so the implicit-conversion warning never fires for it.
+func ctExprCast(c *ctx, n *t.NodeExprCast) error {
+	if err := ctExpr(c, n.Expr); err != nil {
+		return err
+	}
+	actual := n.Expr.GetInferredType()
+	from, fromOK := numericDescriptor(actual)
+	to, toOK := numericDescriptor(n.Type)
+	if !fromOK || !toOK {
+		return comp_err.CompilationErrorSpan(
+			c.FileCtx,
+			&n.Tk,
+			castTypeToken(n),
+			fmt.Sprintf("cannot cast value of type '%s' to '%s'", flattenType(actual), flattenType(n.Type)),
+			"`as` converts between numeric types; use std:cast for pointer conversions",
+		)
+	}
+	mode := t.CastModeToRepr[n.Mode]
+	if n.Mode != t.CastPlain && to.IsFloat {
+		return comp_err.CompilationErrorSpan(
+			c.FileCtx,
+			&n.Tk,
+			castTypeToken(n),
+			fmt.Sprintf("%s cast to '%s' requires an integer target type", mode, flattenType(n.Type)),
+			"use a plain `as` cast to convert to a floating-point type",
+		)
+	}
+	if n.Mode == t.CastWrapping && from.IsFloat {
+		return comp_err.CompilationErrorSpan(
+			c.FileCtx,
+			&n.Tk,
+			castTypeToken(n),
+			fmt.Sprintf("wrapping cast from '%s' requires an integer operand", flattenType(actual)),
+			"use a saturating or checked cast to convert a floating-point value",
+		)
+	}
+	if n.Mode == t.CastChecked {
+		if c.ErrorBoundary == 0 {
+			return comp_err.CompilationErrorSpan(
+				c.FileCtx,
+				&n.Tk,
+				castTypeToken(n),
+				fmt.Sprintf("checked cast to '%s' can fail and its error must be handled", flattenType(n.Type)),
+				fmt.Sprintf("use `try value as checked %s`, or a saturating cast to clamp instead", flattenType(n.Type)),
+			)
+		}
+		n.ErrorMode = uint8(c.ErrorBoundary)
+	}
+	n.InfType = n.Type
+	return nil
+}
+
+// castTypeToken returns the last token of the cast's target type so cast
+// diagnostics underline the whole `as [mode] T` suffix.
+func castTypeToken(n *t.NodeExprCast) *t.Token {
+	if named, ok := n.Type.KindNode.(*t.NodeTypeNamed); ok {
+		switch name := named.NameNode.(type) {
+		case *t.NodeNameSingle:
+			return &name.Tk
+		case *t.NodeNameComposite:
+			if len(name.Tokens) > 0 {
+				return &name.Tokens[len(name.Tokens)-1]
+			}
+		}
+	}
+	return &n.Tk
+}
@@ -0,0 +1,79 @@
+package checker_test
+
+import (
+	"Magma/src/checker"
+	"Magma/src/join"
+	"Magma/src/monomorph"
+	"Magma/src/pipeline"
+	"Magma/src/shared"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExplicitCastsSilenceConversionWarnings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.mg")
+	source := `mod main
+
+alias Small = i16
+
+consume(value i16) void:
+    ret
+..
+
+narrow(value i64, ratio f64) !i16:
+    local i8 = value as wrapping i8
+    consume(value as Small)
+    clamped := ratio as saturating u32
+    ret try value as checked i16
+..
+`
+	if err := os.WriteFile(path, []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+	state, err := shared.MakeShared(dir, filepath.Join("..", "..", "std"))
+	if err == nil {
+		err = pipeline.DoMain(state, path)
+	}
+	if err = join.JoinCompilationUnits(state, err); err == nil {
+		err = monomorph.Run(state)
+	}
+	if err == nil {
+		err = checker.CheckLinks(state)
+	}
+	if err == nil {
+		err = checker.TypeChecker(state)
+	}
+	if err != nil {
+		t.Fatalf("type check: %v", err)
+	}
+	for _, warning := range state.Warnings {
+		if warning.FilePath == path {
+			t.Errorf("unexpected warning at explicit cast: %+v", warning)
+		}
+	}
+}
+
+func TestInvalidCastDiagnostics(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"non-numeric operand": {body: `value := "text" as u8`, want: "cannot cast value of type 'str' to 'u8'"},
+		"non-numeric target":  {body: "value := 1 as bool", want: "cannot cast value of type 'i64' to 'bool'"},
+		"float wrapping":      {body: "ratio f64 = 1.5\n    value := ratio as wrapping u8", want: "wrapping cast from 'f64' requires an integer operand"},
+		"float target":        {body: "count u64 = 1\n    value := count as saturating f32", want: "saturating cast to 'f32' requires an integer target type"},
+		"unhandled checked":   {body: "count u64 = 1\n    value := count as checked u8", want: "checked cast to 'u8' can fail and its error must be handled"},
+		"try plain":           {body: "count u64 = 1\n    value := try count as u8", want: "cannot use 'try' with a cast that cannot fail"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, message := checkSource(t, "mod test\n\nrun() !void:\n    "+test.body+"\n..\n")
+			if !strings.Contains(message, test.want) {
+				t.Fatalf("diagnostic = %q, want it to contain %q", message, test.want)
+			}
+		})
+	}
+}
@@ -46,8 +46,9 @@ func warnNumericConversion(c *ctx, target *t.NodeType, expr t.NodeExpr, context
 	if !risky {
 		return
 	}
-	c.Shared.Warnings = append(c.Shared.Warnings, t.Diagnostic{
+	(*t.SharedState)(c.Shared).Warn(t.Diagnostic{
 		Severity: t.SeverityWarning,
+		Code:     "numeric-conversion",
 		Stage:    "type checking",
 		Ctx:      c.FileCtx,
 		FilePath: c.FileCtx.FilePath,
@@ -59,7 +60,7 @@ func warnNumericConversion(c *ctx, target *t.NodeType, expr t.NodeExpr, context
 func isNumericLiteralExpression(expr t.NodeExpr) bool {
 	switch node := expr.(type) {
 	case *t.NodeExprLit:
-		return node.LitType == t.TokLitNum
+		return node.LitType == t.TokLitNum || node.LitType == t.TokLitChar
 	case *t.NodeExprUnary:
 		return isNumericLiteralExpression(node.Operand)
 	case *t.NodeExprBinary:
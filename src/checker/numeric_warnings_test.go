@@ -70,3 +70,46 @@ mask(value u32) u32:
 		}
 	}
 }
+
+func TestCharacterLiteralsAreBytesOrCodePoints(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.mg")
+	source := `mod main
+
+classify() void:
+    ascii := 'a'
+    wide := 'é'
+    byte u8 = '\xFF'
+    small u8 = ascii
+    narrowed u8 = wide
+..
+`
+	if err := os.WriteFile(path, []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+	state, err := shared.MakeShared(dir, filepath.Join("..", "..", "std"))
+	if err == nil {
+		err = pipeline.DoMain(state, path)
+	}
+	if err = join.JoinCompilationUnits(state, err); err == nil {
+		err = monomorph.Run(state)
+	}
+	if err == nil {
+		err = checker.CheckLinks(state)
+	}
+	if err == nil {
+		err = checker.TypeChecker(state)
+	}
+	if err != nil {
+		t.Fatalf("type check: %v", err)
+	}
+	warnings := []types.Warning{}
+	for _, warning := range state.Warnings {
+		if warning.FilePath == path && warning.Code == "numeric-conversion" {
+			warnings = append(warnings, warning)
+		}
+	}
+	if len(warnings) != 1 || warnings[0].Token.Pos.Line != 8 || !strings.Contains(warnings[0].Message, "'u32' to 'u8'") {
+		t.Fatalf("warnings = %+v, want only the u32 code point narrowing", warnings)
+	}
+}
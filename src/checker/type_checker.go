@@ -59,6 +59,11 @@ func isSimpleConstInitializer(expr t.NodeExpr) bool {
 				return false
 			}
 		}
+		for _, field := range n.Defaults {
+			if !isSimpleConstInitializer(field.Expression) {
+				return false
+			}
+		}
 		return true
 	default:
 		return false
@@ -2,8 +2,6 @@ package checker
 
 import (
 	t "Magma/src/types"
-	"strconv"
-	"strings"
 )
 
 func sameType(a *t.NodeType, b *t.NodeType) bool {
@@ -25,6 +23,9 @@ func compatibleInitializer(expected *t.NodeType, expr t.NodeExpr) bool {
 	if lit, ok := expr.(*t.NodeExprLit); ok && lit.LitType == t.TokLitNum && isNumberType(expected) {
 		return true
 	}
+	if lit, ok := expr.(*t.NodeExprLit); ok && lit.LitType == t.TokLitChar && isIntegerType(expected) {
+		return true
+	}
 	return false
 }
 
@@ -72,16 +73,7 @@ func compatibleNativeCallback(expected, actual *t.NodeType, expr t.NodeExpr) boo
 func constArrayIndex(expr t.NodeExpr) (uint64, bool) {
 	switch n := expr.(type) {
 	case *t.NodeExprLit:
-		if n.LitType != t.TokLitNum {
-			return 0, false
-		}
-		repr := strings.ReplaceAll(n.Value, "_", "")
-		base := 10
-		if strings.HasPrefix(repr, "0x") || strings.HasPrefix(repr, "0X") || strings.HasPrefix(repr, "0b") || strings.HasPrefix(repr, "0B") || strings.HasPrefix(repr, "0o") || strings.HasPrefix(repr, "0O") {
-			base = 0
-		}
-		value, err := strconv.ParseUint(repr, base, 64)
-		return value, err == nil
+		return n.UintValue()
 	case *t.NodeExprName:
 		variable, ok := n.AssociatedNode.(*t.NodeExprVarDef)
 		if !ok || !variable.IsConst || variable.Initializer == nil {
@@ -77,6 +77,8 @@ func expressionSourceToken(expr t.NodeExpr) *t.Token {
 		return &n.Tk
 	case *t.NodeExprMove:
 		return &n.Tk
+	case *t.NodeExprCast:
+		return expressionSourceToken(n.Expr)
 	case *t.NodeExprAssign:
 		return &n.Tk
 	case *t.NodeExprVarDefAssign:
@@ -204,6 +204,8 @@ func ctExprWithUsage(c *ctx, expr t.NodeExpr, valueUsed bool) error {
 		}
 		n.InfType = n.Expr.GetInferredType()
 		return nil
+	case *t.NodeExprCast:
+		return ctExprCast(c, n)
 	case *t.NodeExprCall:
 		//fmt.Printf("call: %s\n", flattenCallee(n.Callee))
 
@@ -271,7 +273,11 @@ func ctExprWithUsage(c *ctx, expr t.NodeExpr, valueUsed bool) error {
 			warnNumericConversion(c, expectedArgs[i], a, fmt.Sprintf("argument %d", i+1))
 		}
 
-		if !n.IsMemberFunc {
+		if n.AssociatedFnDef != nil && n.AssociatedFnDef.Intrinsic != t.IntrinsicNone {
+			if e := ctIntrinsicCall(c, n); e != nil {
+				return e
+			}
+		} else if !n.IsMemberFunc {
 			e := ctExpr(c, n.Callee)
 			if e != nil {
 				return e
@@ -296,9 +302,10 @@ func ctExprWithUsage(c *ctx, expr t.NodeExpr, valueUsed bool) error {
 			n.InfType = &unwrapped
 		}
 		if valueUsed && n.InfType != nil && n.InfType.Throws {
-			return comp_err.CompilationErrorToken(
+			return comp_err.CompilationErrorCode(
 				c.FileCtx,
 				&n.Tk,
+				"unhandled-error",
 				fmt.Sprintf("cannot use the return value of throwing call '%s' without handling its error", callDisplayName(n)),
 				"use `try` to propagate the error or destructure the call into value and error bindings",
 			)
@@ -313,8 +320,16 @@ func ctExprWithUsage(c *ctx, expr t.NodeExpr, valueUsed bool) error {
 		}
 		return nil
 	case *t.NodeExprStructInit:
+		// Defaults were checked against their fields where they are declared;
+		// checking the copies again types their literals for this constructor.
+		fields := make([]*t.NodeStructFieldInit, 0, len(n.Fields)+len(n.Defaults))
 		for i := range n.Fields {
-			field := &n.Fields[i]
+			fields = append(fields, &n.Fields[i])
+		}
+		for i := range n.Defaults {
+			fields = append(fields, &n.Defaults[i])
+		}
+		for _, field := range fields {
 			if field.FieldType == nil {
 				return fmt.Errorf("constructor field '%s' was not resolved", field.Name)
 			}
@@ -368,6 +383,16 @@ func ctExprWithUsage(c *ctx, expr t.NodeExpr, valueUsed bool) error {
 		switch n.LitType {
 		case t.TokLitNum:
 			n.InfType = makeNamedType("i64")
+			if n.IsFloat() {
+				n.InfType = makeNamedType("f64")
+			}
+			return nil
+		case t.TokLitChar:
+			// ASCII characters are bytes; wider code points need a u32.
+			n.InfType = makeNamedType("u8")
+			if value, _ := n.UintValue(); value > 0x7F {
+				n.InfType = makeNamedType("u32")
+			}
 			return nil
 		case t.TokLitStr:
 			n.InfType = makeNamedType("str")
@@ -400,6 +425,9 @@ func ctExprWithUsage(c *ctx, expr t.NodeExpr, valueUsed bool) error {
 		case *t.NodeExprVarDefAssign:
 			n.InfType = n2.GetInferredType()
 		case *t.NodeFuncDef:
+			if n2.Intrinsic != t.IntrinsicNone {
+				return comp_err.CompilationErrorToken(c.FileCtx, &n.Tk, fmt.Sprintf("intrinsic '%s' cannot be used as a function value", expressionDisplayName(n)), "the compiler expands each call in place, so an intrinsic has no address; wrap the call in a function")
+			}
 			n.InfType = makeFuncPtrTypeFromDef(n2)
 		default:
 			return fmt.Errorf("name node pointing to invalid node type, failed to infer type")
@@ -596,8 +624,17 @@ func ctExprWithUsage(c *ctx, expr t.NodeExpr, valueUsed bool) error {
 		if e != nil {
 			return e
 		}
+		cast, isCast := n.Call.(*t.NodeExprCast)
 		call, ok := n.Call.(*t.NodeExprCall)
-		if !ok || call.ThrowingType == nil || !call.ThrowingType.Throws {
+		if isCast && cast.Mode != t.CastChecked {
+			return comp_err.CompilationErrorToken(
+				c.FileCtx,
+				&n.Tk,
+				"cannot use 'try' with a cast that cannot fail",
+				"remove 'try' or write a checked cast: `value as checked T`",
+			)
+		}
+		if !isCast && (!ok || call.ThrowingType == nil || !call.ThrowingType.Throws) {
 			return comp_err.CompilationErrorToken(
 				c.FileCtx,
 				&n.Tk,
@@ -606,14 +643,15 @@ func ctExprWithUsage(c *ctx, expr t.NodeExpr, valueUsed bool) error {
 			)
 		}
 		if c.CurrentTypeFunc != nil && (c.CurrentTypeFunc.ReturnType == nil || !c.CurrentTypeFunc.ReturnType.Throws) {
-			return comp_err.CompilationErrorToken(
+			return comp_err.CompilationErrorCode(
 				c.FileCtx,
 				&n.Tk,
+				"non-throwing-function",
 				"cannot use 'try' inside a non-throwing function",
 				"mark the enclosing function's return type with '!' or handle the error explicitly",
 			)
 		}
-		n.InfType = call.InfType
+		n.InfType = n.Call.GetInferredType()
 		return nil
 	case *t.NodeExprDestructureAssign:
 		previousBoundary := c.ErrorBoundary
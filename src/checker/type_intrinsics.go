@@ -0,0 +1,114 @@
+package checker
+
+import (
+	"Magma/src/comp_err"
+	t "Magma/src/types"
+	"fmt"
+)
+
+const orderingHint = "pass one of atomic.RELAXED, atomic.ACQUIRE, atomic.RELEASE, atomic.ACQ_REL, or atomic.SEQ_CST"
+
+// ctIntrinsicCall validates a call to a compiler-known function, whose
+// arguments have already been checked against its parameters, and decodes its
+// ordering arguments for lowering.
+func ctIntrinsicCall(c *ctx, call *t.NodeExprCall) error {
+	fn := call.AssociatedFnDef
+	if name, ok := call.Callee.(*t.NodeExprName); ok {
+		name.InfType = makeFuncPtrTypeFromDef(fn)
+	}
+
+	count := t.AtomicOrderingCount(fn.Intrinsic)
+	call.Orderings = make([]t.AtomicOrdering, count)
+	for i := range count {
+		arg := call.Args[len(call.Args)-count+i]
+		value, ok := constArrayIndex(arg)
+		if !ok || value > uint64(t.AtomicSeqCst) {
+			return comp_err.CompilationErrorToken(c.FileCtx, expressionSourceToken(arg), fmt.Sprintf("ordering of '%s' must be an ordering constant", callDisplayName(call)), orderingHint)
+		}
+		call.Orderings[i] = t.AtomicOrdering(value)
+	}
+	if e := ctAtomicOrderings(c, call); e != nil {
+		return e
+	}
+	if fn.Intrinsic == t.IntrinsicAtomicFence {
+		return nil
+	}
+
+	valueType := getBoxedType(fn.Class.ArgsNode.Args[0].TypeNode)
+	if e := ctAtomicValueType(c, call, valueType); e != nil {
+		return e
+	}
+	// Pointers are interchangeable as arguments, so the places are compared
+	// with the operated type here.
+	places := []t.NodeExpr{call.Args[0]}
+	if fn.Intrinsic == t.IntrinsicAtomicCompareExchange || fn.Intrinsic == t.IntrinsicAtomicCompareExchangeWeak {
+		places = append(places, call.Args[1])
+	}
+	for _, place := range places {
+		var placeType *t.NodeType
+		if address, ok := place.(*t.NodeExprAddrof); ok {
+			placeType = address.Expr.GetInferredType()
+		} else if _, ok := place.GetInferredType().KindNode.(*t.NodeTypePointer); ok {
+			placeType = getBoxedType(place.GetInferredType())
+		}
+		if placeType != nil && !sameType(placeType, valueType) {
+			return comp_err.CompilationErrorToken(c.FileCtx, expressionSourceToken(place), fmt.Sprintf("'%s' operates on '%s', but the place holds '%s'", callDisplayName(call), flattenType(valueType), flattenType(placeType)), "an atomic operation must access the place with its own type")
+		}
+	}
+	return nil
+}
+
+// ctAtomicValueType accepts the types which fit a single atomic instruction:
+// integers of at most 64 bits, pointers, and bool.
+func ctAtomicValueType(c *ctx, call *t.NodeExprCall, valueType *t.NodeType) error {
+	kind := call.AssociatedFnDef.Intrinsic
+	descriptor, _ := numericDescriptor(valueType)
+	integer := isIntegerType(valueType) && descriptor.ByteSize <= 64
+	var accepted bool
+	var expected string
+	switch kind {
+	case t.IntrinsicAtomicFetchAdd, t.IntrinsicAtomicFetchSub:
+		accepted, expected = integer, "an integer"
+	case t.IntrinsicAtomicFetchAnd, t.IntrinsicAtomicFetchOr, t.IntrinsicAtomicFetchXor:
+		accepted, expected = integer || isBoolType(valueType), "an integer or bool"
+	default:
+		accepted, expected = integer || isBoolType(valueType) || isPointerType(valueType), "an integer, pointer, or bool"
+	}
+	if !accepted {
+		return comp_err.CompilationErrorToken(c.FileCtx, &call.Tk, fmt.Sprintf("'%s' cannot operate on '%s'", callDisplayName(call), flattenType(valueType)), fmt.Sprintf("the place must hold %s of at most 64 bits", expected))
+	}
+	return nil
+}
+
+// ctAtomicOrderings rejects the orderings an operation cannot have: a load
+// does not release, a store does not acquire, and a fence must order
+// something.
+func ctAtomicOrderings(c *ctx, call *t.NodeExprCall) error {
+	invalid := func(index int, hint string) error {
+		arg := call.Args[len(call.Args)-len(call.Orderings)+index]
+		return comp_err.CompilationErrorToken(c.FileCtx, expressionSourceToken(arg), fmt.Sprintf("'%s' cannot use %s ordering", callDisplayName(call), orderingName(call.Orderings[index])), hint)
+	}
+	switch call.AssociatedFnDef.Intrinsic {
+	case t.IntrinsicAtomicLoad:
+		if call.Orderings[0] == t.AtomicRelease || call.Orderings[0] == t.AtomicAcqRel {
+			return invalid(0, "a load may be RELAXED, ACQUIRE, or SEQ_CST")
+		}
+	case t.IntrinsicAtomicStore:
+		if call.Orderings[0] == t.AtomicAcquire || call.Orderings[0] == t.AtomicAcqRel {
+			return invalid(0, "a store may be RELAXED, RELEASE, or SEQ_CST")
+		}
+	case t.IntrinsicAtomicCompareExchange, t.IntrinsicAtomicCompareExchangeWeak:
+		if call.Orderings[1] == t.AtomicRelease || call.Orderings[1] == t.AtomicAcqRel {
+			return invalid(1, "a failed exchange only loads, so its ordering may be RELAXED, ACQUIRE, or SEQ_CST")
+		}
+	case t.IntrinsicAtomicFence:
+		if call.Orderings[0] == t.AtomicRelaxed {
+			return invalid(0, "a fence may be ACQUIRE, RELEASE, ACQ_REL, or SEQ_CST")
+		}
+	}
+	return nil
+}
+
+func orderingName(ordering t.AtomicOrdering) string {
+	return [...]string{"RELAXED", "ACQUIRE", "RELEASE", "ACQ_REL", "SEQ_CST"}[ordering]
+}
@@ -115,9 +115,10 @@ func ctBoundedStmt(c *ctx, stmt *t.NodeStmtBounded) error {
 }
 func ctThrow(c *ctx, throw *t.NodeStmtThrow) error {
 	if c.CurrentTypeFunc != nil && (c.CurrentTypeFunc.ReturnType == nil || !c.CurrentTypeFunc.ReturnType.Throws) {
-		return comp_err.CompilationErrorToken(
+		return comp_err.CompilationErrorCode(
 			c.FileCtx,
 			&throw.Tk,
+			"non-throwing-function",
 			"cannot use 'throw' inside a non-throwing function",
 			"mark the enclosing function's return type with '!' or return normally",
 		)
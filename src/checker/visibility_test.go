@@ -154,6 +154,52 @@ main() void:
 	}
 }
 
+func TestNamedArgumentsCallImportedFunctions(t *testing.T) {
+	library := `mod library
+pub Pool(size u64)
+pub spawn(count u64, limit u64 = 8) u64:
+    ret count + limit
+..
+pub twice[T](value T) T:
+    ret value
+..
+`
+	main := `mod main
+use "library.mg" lib
+
+main() void:
+    count := lib.spawn(count=2)
+    limit := lib.twice[u64](value=count)
+    pool := lib.Pool(size=lib.spawn(limit=limit, count=1))
+..
+`
+	if err := checkModules(t, library, main); err != nil {
+		t.Fatalf("named arguments to imported functions failed: %v", err)
+	}
+}
+
+func TestEmptyConstructorsOfImportedStructs(t *testing.T) {
+	library := `mod library
+pub Conf(limit u64 = 7, retries u64 = 3)
+pub Box[T](value T = 0)
+`
+	main := `mod main
+use "library.mg" lib
+
+main() void:
+    conf := lib.Conf()
+    box := lib.Box[u64]()
+    local := Local()
+    conf.limit = box.value + local.count
+..
+
+Local(count u64 = 1)
+`
+	if err := checkModules(t, library, main); err != nil {
+		t.Fatalf("empty constructors failed: %v", err)
+	}
+}
+
 func TestGenericFunctionValue(t *testing.T) {
 	main := `mod main
 identity[T](value T) T:
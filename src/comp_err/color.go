@@ -0,0 +1,67 @@
+package comp_err
+
+import (
+	"fmt"
+	"os"
+)
+
+// ColorMode selects whether diagnostics are rendered with ANSI colors.
+type ColorMode uint8
+
+const (
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+// ParseColorMode accepts the values of the `--color` command-line option.
+func ParseColorMode(value string) (ColorMode, error) {
+	switch value {
+	case "auto":
+		return ColorAuto, nil
+	case "always":
+		return ColorAlways, nil
+	case "never":
+		return ColorNever, nil
+	}
+	return ColorAuto, fmt.Errorf("invalid --color value %q (expected auto, always, or never)", value)
+}
+
+var colorEnabled bool
+
+// SetColor enables or disables colored rendering for subsequent diagnostics.
+// In auto mode colors are used only when out is a terminal and the NO_COLOR
+// environment variable is unset or empty; an explicit mode always wins.
+func SetColor(mode ColorMode, out *os.File) {
+	switch mode {
+	case ColorAlways:
+		colorEnabled = true
+	case ColorNever:
+		colorEnabled = false
+	default:
+		colorEnabled = os.Getenv("NO_COLOR") == "" && isTerminal(out)
+	}
+}
+
+func isTerminal(file *os.File) bool {
+	if file == nil {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+const (
+	ansiBold    = "1"
+	ansiRed     = "1;31"
+	ansiMagenta = "1;35"
+	ansiCyan    = "1;36"
+)
+
+// paint wraps text in an ANSI SGR sequence when colors are enabled.
+func paint(style, text string) string {
+	if !colorEnabled || text == "" {
+		return text
+	}
+	return "\x1b[" + style + "m" + text + "\x1b[0m"
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -72,6 +73,22 @@ func CompilationErrorToken(ctx *types.FileCtx, tk *types.Token, shortDesc, addit
 	}
 }
 
+// CompilationErrorCode reports an error carrying a stable Diagnostic.Code,
+// which editors key quick fixes off.
+func CompilationErrorCode(ctx *types.FileCtx, tk *types.Token, code, shortDesc, additional string) error {
+	diagnostic := CompilationErrorToken(ctx, tk, shortDesc, additional).(*types.Diagnostic)
+	diagnostic.Code = code
+	return diagnostic
+}
+
+// CompilationErrorSpan reports an error covering every source character from
+// the start of first to the end of last.
+func CompilationErrorSpan(ctx *types.FileCtx, first, last *types.Token, shortDesc, additional string) error {
+	diagnostic := CompilationErrorToken(ctx, first, shortDesc, additional).(*types.Diagnostic)
+	_, diagnostic.End = last.Span()
+	return diagnostic
+}
+
 // EnsureDiagnostic attaches source provenance to an otherwise opaque pass
 // error. Existing diagnostics are preserved so recursive walkers can apply
 // this at successively broader syntax boundaries without losing the innermost
@@ -122,12 +139,49 @@ func Diagnostics(err error) []*types.Diagnostic {
 }
 
 func printLine(out io.Writer, ctx *types.FileCtx, line int) {
+	printIndentedLine(out, ctx, line, "")
+}
+
+func printIndentedLine(out io.Writer, ctx *types.FileCtx, line int, indent string) {
+	if lineText, ok := sourceLine(ctx, line); ok {
+		fmt.Fprintf(out, "%s%d| %s\n", indent, line, lineText)
+	}
+}
+
+func sourceLine(ctx *types.FileCtx, line int) (string, bool) {
 	lines := bytes.Split(ctx.Content, []byte{'\n'})
 	if line < 1 || line > len(lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(string(lines[line-1]), "\r"), true
+}
+
+// printSpan prints the first line of an exclusive span and underlines it with
+// `^~~~`. Spans continuing onto later lines are underlined to the end of the
+// first line. Tabs before the span are repeated so the caret stays aligned.
+func printSpan(out io.Writer, ctx *types.FileCtx, start, end types.FilePos, style string) {
+	lineText, ok := sourceLine(ctx, int(start.Line))
+	if !ok {
 		return
 	}
-	lineText := strings.TrimSuffix(string(lines[line-1]), "\r")
-	fmt.Fprintf(out, "%d| %s\n", line, lineText)
+	fmt.Fprintf(out, "%d| %s\n", start.Line, lineText)
+	runes := []rune(lineText)
+	first := min(int(max(start.Col, 1))-1, len(runes))
+	last := len(runes)
+	if end.Line == start.Line {
+		last = min(int(end.Col)-1, len(runes))
+	}
+	width := max(last-first, 1)
+	var pad strings.Builder
+	for _, r := range runes[:first] {
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	gutter := strings.Repeat(" ", len(strconv.Itoa(int(start.Line))))
+	fmt.Fprintf(out, "%s| %s%s\n", gutter, pad.String(), paint(style, "^"+strings.Repeat("~", width-1)))
 }
 
 func Fprint(out io.Writer, err error) bool {
@@ -176,19 +230,25 @@ func Fprint(out io.Writer, err error) bool {
 
 func FprintDiagnostic(out io.Writer, diagnostic *types.Diagnostic) {
 	description := strings.NewReplacer("'\r\n'", "newline", "'\n'", "newline", "'\r'", "newline").Replace(diagnostic.Error())
-	severity := "error"
+	severity, style := "error", ansiRed
 	if diagnostic.Severity == types.SeverityWarning {
-		severity = "warning"
+		severity, style = "warning", ansiMagenta
 	}
 	stage := ""
 	if diagnostic.Stage != "" {
 		stage = " [" + diagnostic.Stage + "]"
 	}
-	fmt.Fprintf(out, "%s:l%d:c%d: %s%s: %s\n", diagnostic.FilePath, diagnostic.Token.Pos.Line, diagnostic.Token.Pos.Col, severity, stage, description)
+	code := ""
+	if diagnostic.Code != "" {
+		code = " [" + diagnostic.Code + "]"
+	}
+	location := fmt.Sprintf("%s:l%d:c%d:", diagnostic.FilePath, diagnostic.Token.Pos.Line, diagnostic.Token.Pos.Col)
+	fmt.Fprintf(out, "%s %s%s: %s%s\n", paint(ansiBold, location), paint(style, severity), stage, description, code)
 	if diagnostic.Ctx != nil {
-		line := int(diagnostic.Token.Pos.Line)
+		start, end := diagnostic.Span()
+		line := int(start.Line)
 		printLine(out, diagnostic.Ctx, line-1)
-		printLine(out, diagnostic.Ctx, line)
+		printSpan(out, diagnostic.Ctx, start, end, style)
 		printLine(out, diagnostic.Ctx, line+1)
 	}
 	if diagnostic.Additional != "" {
@@ -197,6 +257,37 @@ func FprintDiagnostic(out io.Writer, diagnostic *types.Diagnostic) {
 	if diagnostic.Cause != nil {
 		fmt.Fprintf(out, "caused by: %v\n", diagnostic.Cause)
 	}
+	for _, related := range diagnostic.Related {
+		path := related.FilePath
+		if path == "" {
+			path = diagnostic.FilePath
+		}
+		location := fmt.Sprintf("%s:l%d:c%d:", path, related.Token.Pos.Line, related.Token.Pos.Col)
+		fmt.Fprintf(out, "%s %s: %s\n", paint(ansiBold, location), paint(ansiCyan, "note"), related.Message)
+		if diagnostic.Ctx != nil && path == diagnostic.FilePath {
+			start, end := related.Token.Span()
+			printSpan(out, diagnostic.Ctx, start, end, ansiCyan)
+		}
+	}
+	if len(diagnostic.Trace) != 0 {
+		fmt.Fprintln(out, "how the checker reached this conclusion:")
+		for _, event := range diagnostic.Trace {
+			fmt.Fprintf(out, "  l%d:c%d: %s\n", event.Token.Pos.Line, event.Token.Pos.Col, event.Message)
+			if diagnostic.Ctx != nil && (event.FilePath == "" || event.FilePath == diagnostic.FilePath) {
+				printIndentedLine(out, diagnostic.Ctx, int(event.Token.Pos.Line), "    ")
+			}
+		}
+	}
+	if text, ok := firstExplanation(diagnostic.Code); ok {
+		fmt.Fprintf(out, "about [%s]:\n", diagnostic.Code)
+		for _, line := range strings.Split(text, "\n") {
+			if line == "" {
+				fmt.Fprintln(out)
+				continue
+			}
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
 	fmt.Fprintln(out)
 }
 
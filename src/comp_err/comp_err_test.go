@@ -71,3 +71,109 @@ func TestEnsureDiagnosticAddsSourceAndPreservesExistingDiagnostic(t *testing.T)
 		t.Fatal("existing source diagnostic was replaced")
 	}
 }
+
+func TestFprintDiagnosticRendersTraceAndExplainsEachCodeOnce(t *testing.T) {
+	SetExplain(true)
+	explained = map[string]bool{}
+	defer SetExplain(false)
+	ctx := &types.FileCtx{FilePath: "main.mg", Content: []byte("value := open()\nuse(value)\n")}
+	diagnostic := &types.Diagnostic{
+		Severity: types.SeverityError, Code: "use-after-move", Ctx: ctx, FilePath: "main.mg",
+		Token: types.Token{Pos: types.FilePos{Line: 2, Col: 5}}, Message: "used after transfer",
+		Trace: []types.DiagnosticRelated{{FilePath: "main.mg", Token: types.Token{Pos: types.FilePos{Line: 1, Col: 1}}, Message: "'value' acquires ownership"}},
+	}
+	var output bytes.Buffer
+	FprintDiagnostic(&output, diagnostic)
+	FprintDiagnostic(&output, diagnostic)
+	text := output.String()
+	for _, expected := range []string{"how the checker reached this conclusion:", "  l1:c1: 'value' acquires ownership", "    1| value := open()", "about [use-after-move]:"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("output missing %q:\n%s", expected, text)
+		}
+	}
+	if count := strings.Count(text, "about [use-after-move]:"); count != 1 {
+		t.Fatalf("long-form explanation printed %d times, want once:\n%s", count, text)
+	}
+}
+
+func TestEverySafetyCodeHasAnExplanation(t *testing.T) {
+	for _, code := range []string{"missing-move", "use-after-move", "double-consume", "unproven-bounds", "unsafe-required", "invalid-provenance", "live-loan", "memory-safety", "cleanup"} {
+		if _, ok := Explanation(code); !ok {
+			t.Errorf("code %q has no explanation", code)
+		}
+	}
+}
+
+func TestFprintDiagnosticUnderlinesSpanAndPrintsRelatedNotes(t *testing.T) {
+	ctx := &types.FileCtx{FilePath: "main.mg", Content: []byte("first := take()\n\tuse(first)\n")}
+	diagnostic := &types.Diagnostic{
+		Severity: types.SeverityError, Code: "use-after-move", Ctx: ctx, FilePath: "main.mg",
+		Token:   types.Token{Repr: "first", Pos: types.FilePos{Line: 2, Col: 6}},
+		Message: "used after transfer",
+		Related: []types.DiagnosticRelated{
+			{Token: types.Token{Repr: "take", Pos: types.FilePos{Line: 1, Col: 10}, End: types.FilePos{Line: 1, Col: 16}}, Message: "moved here"},
+			{FilePath: "other.mg", Token: types.Token{Pos: types.FilePos{Line: 4, Col: 2}}, Message: "declared here"},
+		},
+	}
+	var output bytes.Buffer
+	FprintDiagnostic(&output, diagnostic)
+	text := output.String()
+	for _, expected := range []string{
+		"2| \tuse(first)\n | \t    ^~~~~\n",
+		"main.mg:l1:c10: note: moved here\n1| first := take()\n | " + strings.Repeat(" ", 9) + "^~~~~~\n",
+		"other.mg:l4:c2: note: declared here\n",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("output missing %q:\n%s", expected, text)
+		}
+	}
+	if strings.Contains(text, "\x1b[") {
+		t.Fatalf("colors rendered while disabled:\n%q", text)
+	}
+}
+
+func TestDiagnosticEndExtendsUnderlineAcrossTokens(t *testing.T) {
+	ctx := &types.FileCtx{FilePath: "main.mg", Content: []byte("value := 300 as u8\n")}
+	err := CompilationErrorSpan(ctx, &types.Token{Repr: "300", Pos: types.FilePos{Line: 1, Col: 10}}, &types.Token{Repr: "u8", Pos: types.FilePos{Line: 1, Col: 17}}, "bad cast", "")
+	var output bytes.Buffer
+	Fprint(&output, err)
+	if !strings.Contains(output.String(), " | "+strings.Repeat(" ", 9)+"^~~~~~~~~\n") {
+		t.Fatalf("span was not underlined to the last token:\n%s", output.String())
+	}
+}
+
+func TestColorModes(t *testing.T) {
+	defer SetColor(ColorNever, nil)
+	t.Setenv("NO_COLOR", "1")
+	SetColor(ColorAlways, nil)
+	if got := paint(ansiRed, "error"); got != "\x1b[1;31merror\x1b[0m" {
+		t.Fatalf("--color=always ignored: %q", got)
+	}
+	SetColor(ColorAuto, nil)
+	if got := paint(ansiRed, "error"); got != "error" {
+		t.Fatalf("auto mode colored a non-terminal: %q", got)
+	}
+	if _, err := ParseColorMode("sometimes"); err == nil {
+		t.Fatal("invalid color mode accepted")
+	}
+}
+
+func TestSuggestionPicksTheClosestPlausibleName(t *testing.T) {
+	candidates := []string{"count", "close", "name", "errors"}
+	for name, want := range map[string]string{
+		"nmae":   "did you mean 'name'?",
+		"coutn":  "did you mean 'count'?",
+		"erors":  "did you mean 'errors'?",
+		"clsoe":  "did you mean 'close'?",
+		"zzz":    "",
+		"name":   "",
+		"counts": "did you mean 'count'?",
+	} {
+		if got := Suggestion(name, candidates); got != want {
+			t.Errorf("Suggestion(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if got := WithSuggestion("declare it first", "nmae", candidates); got != "declare it first; did you mean 'name'?" {
+		t.Fatalf("WithSuggestion = %q", got)
+	}
+}
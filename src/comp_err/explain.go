@@ -0,0 +1,231 @@
+package comp_err
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// explanations are the long-form descriptions shown by `--explain <code>` and
+// beneath explained diagnostics. Keys are stable Diagnostic.Code values.
+var explanations = map[string]string{
+	"missing-move": `A named owner was used in an ownership-transfer position without 'move'.
+
+Passing a local to a '$T' parameter, returning it from a '$T' function, or
+storing it in an ownership field transfers it. Because the source stops being
+usable afterwards, the transfer must be spelled out:
+
+    consume(move resource)
+
+Fresh owned temporaries, such as the direct result of an owning call, may flow
+into a transfer position without 'move'.`,
+
+	"use-after-move": `A value is read after its ownership may have been transferred.
+
+The checker follows every path to the use. If any of them moved, consumed, or
+destroyed the value, the use is rejected. Branches which disagree are merged
+conservatively: a value consumed in only one branch is "possibly consumed"
+after the join. Move the use before the transfer, reinitialize the value on
+every path, or restructure the branches so they agree.`,
+
+	"double-consume": `A value may be consumed more than once.
+
+Calling a destructor, moving the value, or passing it to a '$T' parameter
+consumes it. A second consumption on any path is rejected, including a
+consumption in a loop body which repeats on the next iteration, and a transfer
+while a deferred destructor is still pending for the same value.`,
+
+	"unproven-bounds": `A slice subscript is not proven to be in range.
+
+Ordinary subscripts require a dominating proof that the index is below the
+slice count, established by a comparison, a 'for' bound, a constant extent, or
+a 'bounded' entry guard:
+
+    bounded i < values.count():
+        total = total + values[i]
+    ..
+
+Inside 'unsafe:' the subscript is authorized without a proof.`,
+
+	"unsafe-required": `An operation whose validity the compiler cannot establish is outside 'unsafe:'.
+
+Inline LLVM, dereferencing a pointer of unknown provenance, and subscripting a
+pointer without a proven extent must be placed in a lexical 'unsafe:' block.
+The block permits only that operation; ownership, bounds, and escape checks
+remain active inside it.`,
+
+	"invalid-provenance": `A pointer or stack-backed slice outlives the storage it refers to.
+
+Pointers made with 'addrof' and slices made with 'array' remember their source
+place. Returning them from the source frame, storing them in longer-lived
+owners, or using storage after its allocator implementation was destroyed is
+rejected. Copy the data into owned storage or keep the source alive for as long
+as the pointer is used.`,
+
+	"live-loan": `A place is changed while a pointer or view into it is still used.
+
+Taking 'addrof' a place, slicing its storage, or deriving storage or an
+allocator interface from an allocator borrows it for as long as the result is
+used later. Assigning, moving, or destroying the borrowed place before that
+last use is rejected, because the pointer would observe the change or outlive
+the storage. Finish using the pointer first, or take it again after the
+change. With '--explain-safety' the trace shows where each loan was taken.`,
+
+	"memory-safety": `An ownership or lifetime rule was violated.
+
+This code covers safety findings without a more specific classification, such
+as completion-bearing handles which would outlive their source storage, or
+mismatched allocator operations. The message and related
+locations identify the conflicting operations.`,
+
+	"cleanup": `An owned destructible value is not consumed on every exit path.
+
+Destructors are never inserted automatically. Call one of the type's
+destructors on every path that leaves the value's scope, commonly with 'defer'
+right after acquisition:
+
+    file := try file.open(a, path, mode)
+    defer file.close()
+
+This is a resource-hygiene warning rather than an error. For destructured
+throwing calls the value exists only on success, so check the error first.`,
+
+	"numeric-conversion": `An implicit numeric conversion may change the value.
+
+Narrowing integers, converting between signed and unsigned representations of
+the same width, converting floating-point values to integers, and converting
+integers to floating-point representations which are not wider all may lose
+data or change the meaning of the value. Numeric literals are typed by their
+context and are not reported. Write an explicit cast such as 'value as u8' or
+'try value as checked u8' where the change is intended, or mark the line with a '# @allow("numeric-conversion")' comment.`,
+
+	"unused-function": `A private function is never called or used as a function value.
+
+Only references from other declarations count, so a function which only calls
+itself is still unused. Public functions, members, destructors, entry points,
+and '@export_name' functions are never reported. Remove the function, make it
+'pub', or keep it deliberately:
+
+    @allow("unused-function")
+    helper() void:
+    ..`,
+
+	"unused-global": `A private global variable or constant is never read or written.
+
+Remove the declaration, make it 'pub', or mark it with
+'@allow("unused-global")'.`,
+
+	"unused-import": `A 'use' declaration introduces an alias which never qualifies a name.
+
+Remove the declaration, or mark it with '@allow("unused-import")' when the
+module is imported only for the declarations it contributes to the build.
+Public re-exports ('pub use') are never reported.`,
+
+	"unused-variable": `A local variable is declared but never referenced.
+
+Remove the declaration, keeping any call in its initializer which is needed for
+its effect, or prefix the name with '_' to mark it intentionally unused.`,
+
+	"unused-parameter": `A parameter is never referenced in the function body.
+
+Parameters are part of a function's signature, so callers and function-value
+types may require them even when the body does not. Prefix the name with '_'
+to mark it intentionally unused, or remove it and update the callers. Member
+receivers and parameters of '@export_name' functions are never reported, nor
+are the parameters of prototype requirements, of methods which implement a
+prototype, of '@derive' members, and of functions used as values, whose
+signatures are dictated by a prototype or function type.`,
+
+	"unreachable-code": `A statement follows one which always leaves the block.
+
+Statements after 'ret', 'throw', 'break', or 'continue', or after a conditional
+whose every branch leaves the block, can never run. The related location names
+the statement which leaves the block. Remove the unreachable statements or move
+them before it.`,
+
+	"missing-docs": `A public declaration has no documentation comment.
+
+'--doc' reports public functions, structs, prototypes, aliases, constants, and
+globals, and the methods of public types, which have no '#' comment block on
+the lines directly above them:
+
+    # Returns the larger of a and b.
+    pub max(a u64, b u64) u64:
+    ..
+
+Document the declaration, or mark it with '@allow("missing-docs")'.`,
+
+	"unknown-doc-param": `A '@param' tag names no parameter of the documented function.
+
+The tag's first word must be a parameter name from the signature. A method's
+receiver, 'this', is not a documented parameter. The tag usually outlived a
+rename or a removed parameter; update or delete it.`,
+
+	"missing-import": `A qualified name uses a module alias which the file does not declare.
+
+In 'errors.ok()' or 'errors.Trace', the part before the dot must be an alias
+introduced by a 'use' declaration of the same file:
+
+    use "std:errors" errors
+
+Imports are not inherited from other modules. Add the declaration, or correct
+the alias if it was misspelled.`,
+
+	"unhandled-error": `The value of a throwing call is used without handling its error.
+
+A call whose return type is marked with '!' produces either a value or an
+error. Its value may only be used once the error is dealt with: propagate it
+with 'try', which requires a throwing enclosing function,
+
+    n := try parse(text)
+
+or destructure the call and inspect the error yourself:
+
+    n, err := parse(text)`,
+
+	"non-throwing-function": `'throw' or 'try' appears in a function which cannot throw.
+
+Both leave the function with an error, so its return type must be marked with
+'!', as in 'load() !u64'. Mark the function throwing, which makes every caller
+handle its error, or handle the error locally by destructuring the call.`,
+}
+
+var (
+	explainEnabled bool
+	explainedM     sync.Mutex
+	explained      = map[string]bool{}
+)
+
+// SetExplain enables explain-mode rendering: diagnostic traces are always
+// printed when present, and each code's long-form description is appended once.
+func SetExplain(enabled bool) { explainEnabled = enabled }
+
+// Explanation returns the long-form description of a diagnostic code.
+func Explanation(code string) (string, bool) {
+	text, ok := explanations[code]
+	return text, ok
+}
+
+// ExplainedCodes returns every code with a long-form description, sorted.
+func ExplainedCodes() []string {
+	codes := make([]string, 0, len(explanations))
+	for code := range explanations {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func firstExplanation(code string) (string, bool) {
+	text, ok := explanations[code]
+	if !ok || !explainEnabled {
+		return "", false
+	}
+	explainedM.Lock()
+	defer explainedM.Unlock()
+	if explained[code] {
+		return "", false
+	}
+	explained[code] = true
+	return strings.TrimSpace(text), true
+}
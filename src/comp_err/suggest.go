@@ -0,0 +1,78 @@
+package comp_err
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Suggestion returns a "did you mean" note naming the candidate closest to
+// name, or "" when no candidate is a plausible misspelling.
+func Suggestion(name string, candidates []string) string {
+	if closest, ok := ClosestName(name, candidates); ok {
+		return DidYouMean(closest)
+	}
+	return ""
+}
+
+// DidYouMean formats the note suggesting replacement, which editors apply to
+// the diagnostic's span.
+func DidYouMean(replacement string) string {
+	return fmt.Sprintf("did you mean '%s'?", replacement)
+}
+
+// ClosestName returns the candidate closest to name by edit distance. A
+// candidate is plausible within one edit per three characters of name, and an
+// adjacent transposition counts as one edit. Ties go to the candidate which
+// sorts first.
+func ClosestName(name string, candidates []string) (string, bool) {
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+	best, bestDistance := "", max(1, len([]rune(name))/3)+1
+	for _, candidate := range sorted {
+		if candidate == name || candidate == "" {
+			continue
+		}
+		if distance := editDistance(name, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, best != ""
+}
+
+// WithSuggestion appends the suggestion for name to an additional note.
+func WithSuggestion(additional, name string, candidates []string) string {
+	suggestion := Suggestion(name, candidates)
+	switch {
+	case suggestion == "":
+		return additional
+	case additional == "":
+		return suggestion
+	}
+	return additional + "; " + suggestion
+}
+
+// editDistance is the optimal string alignment distance between a and b.
+func editDistance(a, b string) int {
+	left, right := []rune(a), []rune(b)
+	previous2 := make([]int, len(right)+1)
+	previous := make([]int, len(right)+1)
+	current := make([]int, len(right)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(left); i++ {
+		current[0] = i
+		for j := 1; j <= len(right); j++ {
+			cost := 1
+			if left[i-1] == right[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && left[i-1] == right[j-2] && left[i-2] == right[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(right)]
+}
@@ -7,7 +7,10 @@ package compilerpipeline
 import (
 	"Magma/src/checker"
 	"Magma/src/comp_err"
+	deadcode "Magma/src/dead_code"
 	destroychecker "Magma/src/destroy_checker"
+	"Magma/src/devirtualize"
+	"Magma/src/docgen"
 	ircleaner "Magma/src/ir_cleaner"
 	"Magma/src/join"
 	llvmir "Magma/src/llvm_ir"
@@ -84,6 +87,12 @@ func CheckTypes(program LinkedProgram) (TypedProgram, error) {
 	return TypedProgram{state: program.state}, nil
 }
 
+// CheckDeadCode reports unused private declarations, imports, locals, and
+// parameters, and unreachable statements as warnings. It never fails.
+func CheckDeadCode(program TypedProgram) {
+	deadcode.Run(program.state)
+}
+
 func ValidateLowering(program TypedProgram) (ValidatedProgram, error) {
 	if err := loweringvalidate.Validate(program.state); err != nil {
 		return ValidatedProgram{}, comp_err.AtStage("lowering validation", err)
@@ -101,6 +110,40 @@ func CheckSafety(program ValidatedProgram, warningMode bool) (SafetyCheckedProgr
 	return SafetyCheckedProgram{state: program.state}, nil
 }
 
+// CheckWarnings fails when the warning policy promoted any reported warning to
+// an error. It runs after every checking stage so one compilation reports all
+// promoted warnings together.
+func CheckWarnings(program SafetyCheckedProgram) error {
+	return promotedWarnings(program.state)
+}
+
+// Document writes reference documentation for the parsed root and its imports
+// into outDir. It reports missing and mismatched docs as warnings and fails
+// when the warning policy promoted any of them.
+func Document(program ParsedProgram, rootPath, outDir string) error {
+	if err := docgen.Generate(program.state, rootPath, outDir); err != nil {
+		return comp_err.AtStage("documentation", err)
+	}
+	return promotedWarnings(program.state)
+}
+
+func promotedWarnings(state *types.SharedState) error {
+	var promoted []error
+	for _, warning := range state.Warnings {
+		if warning.Severity == types.SeverityError {
+			copy := warning
+			promoted = append(promoted, &copy)
+		}
+	}
+	return comp_err.AtStage("warning policy", comp_err.Join(promoted...))
+}
+
+// Devirtualize marks prototype calls whose implementation is known in the
+// whole program so lowering calls it directly. It never fails.
+func Devirtualize(program SafetyCheckedProgram) {
+	devirtualize.Run(program.state)
+}
+
 // Lower emits and cleans LLVM IR. IrWrite retains its own defensive contract
 // validation for direct users outside this pipeline.
 func Lower(program SafetyCheckedProgram) ([]byte, error) {
@@ -4,8 +4,12 @@ import (
 	"Magma/src/comp_err"
 	"Magma/src/shared"
 	"Magma/src/types"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -70,6 +74,77 @@ main() !void:
 `)
 }
 
+func TestCallArgumentsInferNestedGenericParameters(t *testing.T) {
+	validated := validateTestProgram(t, `mod main
+use "std:pair" pair
+use "std:sort" sort
+Box[T](value T)
+Box[T].map[U](f (T) U) Box[U]:
+    ret Box[U](value=f(this.value))
+..
+second[A, B](p pair.Pair[A, B]*) B:
+    ret p.second
+..
+half(value u64) f64:
+    ret value as f64 / 2.0
+..
+compare(a u64, b u64) i64:
+    ret 0
+..
+pick[T](a T, b T) T:
+    ret b
+..
+main() void:
+    size u64 = 4
+    p := pair.new(size, 1.5)
+    ratio := second(addrof p)
+    b := Box[u64](value=size)
+    halved := b.map(half)
+    items := array u64[2]
+    sort.insertion(items, compare)
+    wide := pick(2, size)
+..
+`)
+	ready, err := CheckSafety(validated, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lower(ready); err != nil {
+		t.Fatalf("lower inferred calls: %v", err)
+	}
+}
+
+func TestCallArgumentInferenceReportsConflictsAndGaps(t *testing.T) {
+	for _, test := range []struct {
+		source  string
+		line    uint32
+		message string
+		hint    string
+	}{
+		{"size u64 = 1\n    text := \"x\"\n    value := pick(size, text)", 12, "type parameter 'T' of 'pick' is inferred as 'u64' from argument 1 but as 'str' from argument 2", "make the types agree"},
+		{"made := pair.new(true, make())", 10, "cannot infer generic type parameter 'T' of 'make'", "make[T](...)"},
+	} {
+		parsed, _ := testProgram(t, `mod main
+use "std:pair" pair
+pick[T](a T, b T) T:
+    ret b
+..
+make[T]() T*:
+    ret none
+..
+main() void:
+    `+test.source+`
+..
+`)
+		_, err := Specialize(*parsed)
+		diagnostics := comp_err.Diagnostics(err)
+		if len(diagnostics) != 1 || diagnostics[0].Token.Pos.Line != test.line ||
+			diagnostics[0].Message != test.message || !strings.Contains(diagnostics[0].Additional, test.hint) {
+			t.Fatalf("diagnostics = %#v, want %q on line %d", diagnostics, test.message, test.line)
+		}
+	}
+}
+
 func TestInferredMethodResultResolvesGenericReceiverMember(t *testing.T) {
 	validateTestProgram(t, `mod main
 Future[T](value T)
@@ -435,6 +510,82 @@ func TestSafetyWarningsDowngradeButPreserveMoveSemantics(t *testing.T) {
 	}
 }
 
+func TestExplainSafetyTracesEventsAcrossBranches(t *testing.T) {
+	const source = ownershipProgramPrefix + `main(flag bool) void:
+    value $Resource = makeResource()
+    if flag:
+        consume(move value)
+    ..
+    value.close()
+..
+`
+	validated := validateTestProgram(t, source)
+	_, err := CheckSafety(validated, false)
+	diagnostics := comp_err.Diagnostics(err)
+	if len(diagnostics) == 0 || diagnostics[0].Trace != nil {
+		t.Fatalf("diagnostics without explain mode = %#v, want an untraced error", diagnostics)
+	}
+
+	parsed, _ := testProgram(t, source)
+	parsed.State().ExplainSafety = true
+	specialized, _ := Specialize(*parsed)
+	linked, _ := Link(specialized)
+	typed, _ := CheckTypes(linked)
+	validated, err = ValidateLowering(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CheckSafety(validated, false)
+	diagnostics = comp_err.Diagnostics(err)
+	if len(diagnostics) != 1 || diagnostics[0].Code != "double-consume" {
+		t.Fatalf("diagnostics = %#v, want one double-consume", diagnostics)
+	}
+	var events []string
+	for _, event := range diagnostics[0].Trace {
+		events = append(events, fmt.Sprintf("%d: %s", event.Token.Pos.Line, event.Message))
+	}
+	want := []string{
+		"12: 'value' acquires ownership",
+		"14: 'value' is consumed (explicit move)",
+		"13: paths through this if join with 'value' consumed on one path and owned on another, so it is treated as possibly consumed",
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("trace =\n%s\nwant\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestExplainSafetyTracesLiveLoanToItsAcquisition(t *testing.T) {
+	parsed, _ := testProgram(t, `mod main
+main() u64:
+    value u64 = 1
+    pointer u64* = addrof value
+    value = 2
+    ret *pointer
+..
+`)
+	parsed.State().ExplainSafety = true
+	specialized, _ := Specialize(*parsed)
+	linked, _ := Link(specialized)
+	typed, _ := CheckTypes(linked)
+	validated, err := ValidateLowering(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CheckSafety(validated, false)
+	diagnostics := comp_err.Diagnostics(err)
+	if len(diagnostics) != 1 || diagnostics[0].Code != "live-loan" {
+		t.Fatalf("diagnostics = %#v, want one live-loan", diagnostics)
+	}
+	var events []string
+	for _, event := range diagnostics[0].Trace {
+		events = append(events, fmt.Sprintf("%d: %s", event.Token.Pos.Line, event.Message))
+	}
+	want := "4: 'pointer' takes a pointer to 'value', which stays live while 'pointer' is used"
+	if strings.Join(events, "\n") != want {
+		t.Fatalf("trace =\n%s\nwant\n%s", strings.Join(events, "\n"), want)
+	}
+}
+
 func TestCleanupLeakRemainsWarningInFatalMode(t *testing.T) {
 	validated := validateTestProgram(t, ownershipProgramPrefix+`main() void:
     value $Resource = makeResource()
@@ -924,6 +1075,116 @@ writeAfterPropagation(value u64*, failure error) !void:
 	}
 }
 
+const errorPredicateProgramPrefix = ownershipProgramPrefix + `openResource() !$Resource:
+    ret Resource(value=1)
+..
+`
+
+func TestUserErrorPredicateRefinesConditionalOwnership(t *testing.T) {
+	validated := validateTestProgram(t, errorPredicateProgramPrefix+`failed(err error) bool:
+    ret err.nok()
+..
+succeeded(err error) bool:
+    failure := failed(err)
+    ret failure == false
+..
+main() void:
+    value, err := openResource()
+    if failed(err):
+        ret
+    ..
+    value.close()
+    other, otherErr := openResource()
+    if succeeded(otherErr) == false:
+        ret
+    ..
+    other.close()
+..
+`)
+	if _, err := CheckSafety(validated, false); err != nil {
+		t.Fatalf("user predicate was not recognized: %v", err)
+	}
+	if warnings := validated.State().Warnings; len(warnings) != 0 {
+		t.Fatalf("user predicate left conditional ownership unrefined: %#v", warnings)
+	}
+}
+
+func TestStructWrappedErrorPredicateRefinesConditionalOwnership(t *testing.T) {
+	validated := validateTestProgram(t, errorPredicateProgramPrefix+`Status(failed bool, code u32)
+classify(err error) Status:
+    ret Status(failed=err.nok(), code=err.code())
+..
+main() void:
+    value, err := openResource()
+    if classify(err).failed:
+        ret
+    ..
+    value.close()
+..
+`)
+	if _, err := CheckSafety(validated, false); err != nil {
+		t.Fatalf("struct-wrapped predicate was not recognized: %v", err)
+	}
+	if warnings := validated.State().Warnings; len(warnings) != 0 {
+		t.Fatalf("struct-wrapped predicate left conditional ownership unrefined: %#v", warnings)
+	}
+}
+
+func TestOneSidedUserErrorPredicateRefinesOnlyItsProvenOutcome(t *testing.T) {
+	const isTimeout = `isTimeout(err error) bool:
+    ret err.nok() && err.code() == 110
+..
+`
+	validated := validateTestProgram(t, errorPredicateProgramPrefix+isTimeout+`main() void:
+    value, err := openResource()
+    if isTimeout(err):
+        ret
+    ..
+    if err.nok():
+        ret
+    ..
+    value.close()
+..
+`)
+	if _, err := CheckSafety(validated, false); err != nil {
+		t.Fatalf("one-sided predicate failed safety: %v", err)
+	}
+	if warnings := validated.State().Warnings; len(warnings) != 0 {
+		t.Fatalf("true outcome of a failure-implying predicate was not refined: %#v", warnings)
+	}
+
+	// A false result does not prove success, so the owner remains conditional.
+	validated = validateTestProgram(t, errorPredicateProgramPrefix+isTimeout+`main() void:
+    value, err := openResource()
+    if isTimeout(err):
+        ret
+    ..
+    value.close()
+..
+`)
+	if _, err := CheckSafety(validated, false); err == nil {
+		t.Fatalf("false outcome of a one-sided predicate was refined")
+	}
+}
+
+func TestThrowingHelperIsNotAnErrorPredicate(t *testing.T) {
+	validated := validateTestProgram(t, errorPredicateProgramPrefix+`failed(err error) !bool:
+    ret err.nok()
+..
+main() !void:
+    value, err := openResource()
+    if try failed(err):
+        ret
+    ..
+    value.close()
+..
+`)
+	_, err := CheckSafety(validated, false)
+	if err == nil && len(validated.State().Warnings) == 0 {
+		t.Fatalf("throwing helper refined conditional ownership")
+	}
+}
+
 func TestVisibleIdentityHelperPreservesPointerProvenance(t *testing.T) {
 	validated := validateTestProgram(t, `mod main
 identity(value u64*) u64*:
@@ -1365,3 +1626,524 @@ func TestRequireMainModuleIsAnExplicitStage(t *testing.T) {
 		t.Fatal("expected non-main root module to be rejected")
 	}
 }
+
+func typedTestProgram(t *testing.T, source string) TypedProgram {
+	t.Helper()
+	parsed, _ := testProgram(t, source)
+	specialized, err := Specialize(*parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked, err := Link(specialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typed, err := CheckTypes(linked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return typed
+}
+
+func TestDeadCodeWarningsCarryStableCodes(t *testing.T) {
+	typed := typedTestProgram(t, `mod main
+use "std:strings" strs
+use "std:io" io
+LIMIT := 3
+helper(x u64, ignored u64, _reserved u64) u64:
+    ret x
+    x = 3
+..
+recursive(n u64) u64:
+    ret recursive(n)
+..
+@allow("unused-function")
+kept() void:
+..
+pick(flag bool) u64:
+    if flag:
+        ret 1
+    else:
+        ret 2
+    ..
+    ret 3
+..
+main() void:
+    a u64 = 4
+    b := helper(a, 2, 3)
+    _quiet := pick(true)
+    io.print("x")
+    loop true:
+        break
+        a = 1
+    ..
+..
+`)
+	CheckDeadCode(typed)
+	got := []string{}
+	for _, warning := range typed.State().Warnings {
+		if warning.Stage == "dead code analysis" {
+			got = append(got, fmt.Sprintf("%d:%s", warning.Token.Pos.Line, warning.Code))
+		}
+	}
+	want := []string{"2:unused-import", "4:unused-global", "5:unused-parameter", "7:unreachable-code", "9:unused-function", "21:unreachable-code", "25:unused-variable", "30:unreachable-code"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("dead-code warnings = %v, want %v", got, want)
+	}
+}
+
+func TestUnusedParameterSkipsSignaturesFixedElsewhere(t *testing.T) {
+	typed := typedTestProgram(t, `mod main
+proto Sink(write(value u64) void)
+Null impl Sink(count u64)
+Null.write(value u64) void:
+..
+constant(v u64) u64:
+    ret 1
+..
+apply(f (u64) u64, x u64) u64:
+    ret f(x)
+..
+ignored(v u64) u64:
+    ret 1
+..
+main() void:
+    null := Null(count=0)
+    sink Sink = null.proto()
+    sink.write(1)
+    result := apply(constant, 2)
+    other := ignored(result)
+    _quiet := other
+..
+@derive("json")
+Empty()
+proto Both extends Sink()
+pub Pair impl Both(count u64)
+Pair.write(value u64) void:
+..
+`)
+	CheckDeadCode(typed)
+	got := []string{}
+	for _, warning := range typed.State().Warnings {
+		got = append(got, fmt.Sprintf("%d:%d:%s", warning.Token.Pos.Line, warning.Token.Pos.Col, warning.Code))
+	}
+	want := []string{"12:9:unused-parameter"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("dead-code warnings = %v, want %v", got, want)
+	}
+}
+
+func TestAllowDirectiveSuppressesDeadCodeInsideDeclaration(t *testing.T) {
+	typed := typedTestProgram(t, `mod main
+@allow("unused-variable", "unreachable-code")
+main() void:
+    value := 1
+    ret
+    other := 2
+..
+`)
+	CheckDeadCode(typed)
+	if warnings := typed.State().Warnings; len(warnings) != 0 {
+		t.Fatalf("allowed dead code was reported: %#v", warnings)
+	}
+}
+
+func TestIntrinsicDeclarationParametersAreNotReportedUnused(t *testing.T) {
+	typed := typedTestProgram(t, `mod main
+@intrinsic("atomic.fence")
+fence(order u8) void:
+..
+main() void:
+    fence(4)
+..
+`)
+	CheckDeadCode(typed)
+	if warnings := typed.State().Warnings; len(warnings) != 0 {
+		t.Fatalf("intrinsic declaration was reported: %#v", warnings)
+	}
+}
+
+func TestWarningPolicyAndPragmasApplyToEveryProducer(t *testing.T) {
+	parsed, _ := testProgram(t, `mod main
+narrow(value i64) i16:
+    ret value # @allow("numeric-conversion")
+..
+shrink(value i64) i8:
+    ret value
+..
+main() void:
+    unused := narrow(1)
+    # @allow("unused-variable")
+    quiet := shrink(2)
+..
+`)
+	state := parsed.State()
+	state.WarningPolicy = types.WarningPolicy{AsErrors: map[string]bool{"unused-variable": true}}
+	specialized, err := Specialize(*parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked, err := Link(specialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typed, err := CheckTypes(linked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	CheckDeadCode(typed)
+	validated, err := ValidateLowering(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ready, err := CheckSafety(validated, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, warning := range state.Warnings {
+		got = append(got, fmt.Sprintf("%d:%s:%v", warning.Token.Pos.Line, warning.Code, warning.Severity == types.SeverityError))
+	}
+	if strings.Join(got, " ") != "6:numeric-conversion:false 9:unused-variable:true" {
+		t.Fatalf("warnings = %v", got)
+	}
+	err = CheckWarnings(ready)
+	if diagnostics := comp_err.Diagnostics(err); len(diagnostics) != 1 || diagnostics[0].Code != "unused-variable" {
+		t.Fatalf("promoted warnings = %v", err)
+	}
+}
+
+func TestDocumentWritesCrossLinkedPagesAndReportsDocWarnings(t *testing.T) {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"geometry.mg": `mod geometry
+# Plane geometry helpers.
+
+use "shapes.mg" shapes
+
+# Returns the area of r.
+# @param rect rectangle to measure
+pub area(r shapes.Rect) u64:
+    ret r.width * r.height
+..
+
+pub perimeter(r shapes.Rect) u64:
+    ret 2 * (r.width + r.height)
+..
+`,
+		"shapes.mg": `mod shapes
+
+# An axis-aligned rectangle.
+pub Rect(
+    width u64
+    height u64
+)
+
+# Scales both sides.
+# @param factor multiplier
+Rect.scale(factor u64) void:
+    this.width = this.width * factor
+    this.height = this.height * factor
+..
+
+# Largest supported side.
+pub const MAX_SIDE u64 = 1024
+
+Hidden(
+    value u64
+)
+`,
+	}
+	for name, source := range sources {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdRoot, err := filepath.Abs(filepath.Join("..", "..", "std"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := shared.MakeShared(dir, stdRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, "geometry.mg")
+	parsed, err := Parse(state, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "docs")
+	if err := Document(parsed, root, out); err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, warning := range state.Warnings {
+		got = append(got, fmt.Sprintf("%s:%d:%s", filepath.Base(warning.FilePath), warning.Token.Pos.Line, warning.Code))
+	}
+	want := []string{"geometry.mg:8:unknown-doc-param", "geometry.mg:12:missing-docs"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("documentation warnings = %v, want %v", got, want)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	shapes := read("shapes.md")
+	for _, want := range []string{"# `shapes`", "pub Rect(\n    width u64\n    height u64\n)", "#### `Rect.scale`", "Rect.scale(factor u64) void", "pub const MAX_SIDE u64", "Largest supported side."} {
+		if !strings.Contains(shapes, want) {
+			t.Errorf("shapes.md does not contain %q:\n%s", want, shapes)
+		}
+	}
+	if strings.Contains(shapes, "Hidden") {
+		t.Errorf("shapes.md documents a private struct:\n%s", shapes)
+	}
+	if index := read("index.md"); !strings.Contains(index, "[`geometry`](geometry.md) — Plane geometry helpers.") {
+		t.Errorf("index.md does not list the root module:\n%s", index)
+	}
+	if page := read(filepath.Join("html", "geometry.html")); !strings.Contains(page, `pub area(r <a href="shapes.html#Rect">shapes.Rect</a>) u64`) {
+		t.Errorf("geometry.html does not link the imported type:\n%s", page)
+	}
+	if index := read(filepath.Join("html", "search-index.js")); !strings.Contains(index, `"url": "shapes.html#Rect.scale"`) {
+		t.Errorf("search index does not contain the method:\n%s", index)
+	}
+}
+
+func TestDocumentFailsOnPromotedDocWarnings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lib.mg")
+	if err := os.WriteFile(path, []byte("mod lib\npub answer() u64:\n    ret 42\n..\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdRoot, err := filepath.Abs(filepath.Join("..", "..", "std"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := shared.MakeShared(dir, stdRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.WarningPolicy.AsErrors = map[string]bool{"documentation": true}
+	parsed, err := Parse(state, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Document(parsed, path, filepath.Join(dir, "docs"))
+	if diagnostics := comp_err.Diagnostics(err); len(diagnostics) != 1 || diagnostics[0].Code != "missing-docs" {
+		t.Fatalf("promoted warnings = %v", err)
+	}
+}
+
+func TestDerivedMembersLinkPerInstanceAndLower(t *testing.T) {
+	validated := validateTestProgram(t, `mod main
+@derive("eq", "hash", "format", "json")
+Pair[T](left T, right T, label str)
+main() void:
+    a := Pair[f64](left=1.5, right=2.0, label="a")
+    b := Pair[u8](left=1, right=2, label="b")
+    same := a.eq(addrof a) && b.hash() == b.hash()
+..
+`)
+	ready, err := CheckSafety(validated, false)
+	if err != nil {
+		t.Fatalf("derived members failed the safety check: %v", err)
+	}
+	if _, err := Lower(ready); err != nil {
+		t.Fatalf("lower derived members: %v", err)
+	}
+}
+
+func TestDeriveReportsUnsupportedFieldAtTheField(t *testing.T) {
+	parsed, _ := testProgram(t, `mod main
+Plain(value u8)
+@derive("eq")
+Holder(
+    count u32
+    plain Plain
+)
+main() void:
+..
+`)
+	specialized, err := Specialize(*parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Link(specialized)
+	diagnostics := comp_err.Diagnostics(err)
+	if len(diagnostics) != 1 || diagnostics[0].Token.Pos.Line != 6 ||
+		!strings.Contains(diagnostics[0].Message, "field 'plain' of type 'Plain' does not support derived 'eq'") {
+		t.Fatalf("diagnostics = %#v", diagnostics)
+	}
+}
+
+func TestInstantiationsRecordSitesAndFoldIdenticalBodies(t *testing.T) {
+	validated := validateTestProgram(t, `mod main
+A(x u64)
+B(y u8)
+Box[T](value T)
+pass[T](p T*) T*:
+    ret p
+..
+wrap[T](v T) Box[T]:
+    ret Box[T](value=v)
+..
+main() void:
+    a := A(x=1)
+    b := B(y=2)
+    pa := pass(addrof a)
+    pb := pass(addrof b)
+    wa := wrap(addrof a)
+    wb := wrap(addrof b)
+..
+`)
+	ready, err := CheckSafety(validated, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ir, err := Lower(ready)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := map[string]*types.Instantiation{}
+	for _, record := range ready.State().Instantiations {
+		records[record.Name] = record
+	}
+	passA, passB := records["main.pass[main.A]"], records["main.pass[main.B]"]
+	wrapA, wrapB := records["main.wrap[main.A*]"], records["main.wrap[main.B*]"]
+	boxA := records["main.Box[main.A*]"]
+	if passA == nil || passB == nil || wrapA == nil || wrapB == nil || boxA == nil {
+		t.Fatalf("missing instantiations in %v", slices.Collect(maps.Keys(records)))
+	}
+	if passA.Pos.Line != 14 || passA.RequesterName != "main.main" || !strings.HasSuffix(passA.File, ".mg") {
+		t.Fatalf("pass[A] site = %s:%d in %q", passA.File, passA.Pos.Line, passA.RequesterName)
+	}
+	if boxA.Requester != wrapA.Symbol || boxA.Pos.Line != 8 {
+		t.Fatalf("Box[A*] requested by %q at line %d, want %q at line 8", boxA.Requester, boxA.Pos.Line, wrapA.Symbol)
+	}
+
+	// Both pass instances lower to the same body and fold; the wrap
+	// instances return different struct types and stay distinct.
+	if passA.IrBytes == 0 || passB.IrBytes == 0 {
+		t.Fatalf("pass sizes = %d, %d", passA.IrBytes, passB.IrBytes)
+	}
+	kept := passA.FoldedInto
+	if kept == "" {
+		kept = passA.Symbol
+	}
+	if passB.FoldedInto != kept && passB.Symbol != kept {
+		t.Fatalf("pass instances were not folded: %q, %q", passA.FoldedInto, passB.FoldedInto)
+	}
+	if wrapA.FoldedInto != "" || wrapB.FoldedInto != "" {
+		t.Fatalf("wrap instances folded: %q, %q", wrapA.FoldedInto, wrapB.FoldedInto)
+	}
+	for _, record := range []*types.Instantiation{passA, passB} {
+		if record.FoldedInto != "" && strings.Contains(string(ir), "@"+record.Symbol+"(") {
+			t.Errorf("folded %s is still referenced", record.Symbol)
+		}
+	}
+}
+
+// lowerDevirtualized lowers source after devirtualization and returns the body
+// of each function in the main module by its source name.
+func lowerDevirtualized(t *testing.T, source string) map[string]string {
+	t.Helper()
+	ready, err := CheckSafety(validateTestProgram(t, source), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Devirtualize(ready)
+	ir, err := Lower(ready)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodies := map[string]string{}
+	for _, match := range regexp.MustCompile(`(?ms)^define [^@]*@main_\w*\.(\S+?)\(.*?^}$`).FindAllStringSubmatch(string(ir), -1) {
+		bodies[match[1]] = match[0]
+	}
+	return bodies
+}
+
+func TestDevirtualizeSoleImplementation(t *testing.T) {
+	bodies := lowerDevirtualized(t, `mod main
+proto Reader(
+    read() u64
+    twice() u64
+)
+Reader.twice() u64:
+    ret this.read() * 2
+..
+Box impl Reader(value u64)
+Box.read() u64:
+    ret this.value
+..
+consume(reader Reader) u64:
+    ret reader.read() + reader.twice()
+..
+main() void:
+    box := Box(value=1)
+    consume(box.proto[Reader]())
+..
+`)
+	consume := bodies["consume"]
+	for _, want := range []string{`call i64 @main_\w*\.Box\.read\(`, `call i64 @main_\w*\.Reader\.__default_twice\(`} {
+		if !regexp.MustCompile(want).MatchString(consume) {
+			t.Fatalf("consume does not match %q:\n%s", want, consume)
+		}
+	}
+	if strings.Contains(consume, ".Reader.read(") {
+		t.Fatalf("consume still dispatches through the vtable:\n%s", consume)
+	}
+}
+
+func TestDevirtualizeLocalViewWithKnownImplementation(t *testing.T) {
+	bodies := lowerDevirtualized(t, `mod main
+proto Reader(read() u64)
+Box impl Reader(value u64)
+Box.read() u64:
+    ret this.value
+..
+Other impl Reader(value u64)
+Other.read() u64:
+    ret this.value + 1
+..
+consume(reader Reader) u64:
+    ret reader.read()
+..
+known() u64:
+    box := Box(value=1)
+    reader := box.proto[Reader]()
+    ret reader.read()
+..
+reassigned() u64:
+    box := Box(value=1)
+    other := Other(value=2)
+    reader := box.proto[Reader]()
+    reader = other.proto[Reader]()
+    ret reader.read()
+..
+main() void:
+    box := Box(value=1)
+    other := Other(value=2)
+    consume(box.proto[Reader]())
+    consume(other.proto[Reader]())
+    known()
+    reassigned()
+..
+`)
+	direct := regexp.MustCompile(`call i64 @main_\w*\.Box\.read\(`)
+	dispatched := regexp.MustCompile(`call i64 @main_\w*\.Reader\.read\(`)
+	if !direct.MatchString(bodies["known"]) || dispatched.MatchString(bodies["known"]) {
+		t.Fatalf("known view is not called directly:\n%s", bodies["known"])
+	}
+	for _, name := range []string{"consume", "reassigned"} {
+		if !dispatched.MatchString(bodies[name]) {
+			t.Fatalf("%s should dispatch through the vtable:\n%s", name, bodies[name])
+		}
+	}
+}
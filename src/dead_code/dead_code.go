@@ -0,0 +1,459 @@
+// Package deadcode reports unused private declarations, imports, locals, and
+// parameters, and statements which control flow can never reach.
+//
+// The pass runs on the type-checked program, where every name is resolved to
+// its declaration. Its findings are warnings and never fail compilation. The
+// standard library is not analyzed: its private helpers are selected per
+// platform, so an unused helper on one target is routinely used on another.
+package deadcode
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"Magma/src/types"
+)
+
+const stage = "dead code analysis"
+
+type checker struct {
+	shared   *types.SharedState
+	file     *types.FileCtx
+	seen     map[string]bool
+	warnings []types.Diagnostic
+	// fixed holds the functions whose parameter lists are dictated from
+	// outside their bodies.
+	fixed map[*types.NodeFuncDef]bool
+}
+
+// Run reports dead-code warnings for every user compilation unit, ordered by
+// file and source position.
+func Run(shared *types.SharedState) {
+	paths := make([]string, 0, len(shared.Files))
+	for path, file := range shared.Files {
+		if file != nil && file.GlNode != nil && !isStandardLibrary(shared, path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	fixed := fixedSignatures(shared, paths)
+	for _, path := range paths {
+		c := &checker{shared: shared, file: shared.Files[path], seen: map[string]bool{}, fixed: fixed}
+		c.check()
+		sort.SliceStable(c.warnings, func(i, j int) bool {
+			left, right := c.warnings[i].Token.Pos, c.warnings[j].Token.Pos
+			return left.Line < right.Line || left.Line == right.Line && left.Col < right.Col
+		})
+		for _, warning := range c.warnings {
+			shared.Warn(warning)
+		}
+	}
+}
+
+func isStandardLibrary(shared *types.SharedState, path string) bool {
+	if shared.StdRoot == "" {
+		return false
+	}
+	relative, err := filepath.Rel(shared.StdRoot, path)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+func (c *checker) check() {
+	used := map[types.Node]bool{}
+	for _, declaration := range c.file.GlNode.Declarations {
+		owner, _ := declaration.(*types.NodeFuncDef)
+		inspect(declaration, owner, func(node any) {
+			for _, target := range references(node) {
+				if target != types.Node(owner) {
+					used[target] = true
+				}
+			}
+		})
+	}
+	for _, declaration := range c.file.GlNode.Declarations {
+		switch node := declaration.(type) {
+		case *types.NodeFuncDef:
+			if unusedCandidate(node) && !used[node] {
+				c.warn("unused-function", nameToken(node.Class.NameNode), fmt.Sprintf("private function '%s' is never used", nameText(node.Class.NameNode)), "remove it, make it `pub`, or mark it with `@allow(\"unused-function\")`", nil)
+			}
+			// External and intrinsic declarations have no body to use their
+			// parameters.
+			if !node.IsExternal && node.Intrinsic == types.IntrinsicNone {
+				c.function(node)
+			}
+		case *types.NodeExprVarDef:
+			if !node.IsPublic && !used[node] {
+				c.warn("unused-global", nameToken(node.Name), fmt.Sprintf("private global '%s' is never used", nameText(node.Name)), "remove it or make it `pub`", nil)
+			}
+		case *types.NodeConstDef:
+			if node.VarDef != nil && !node.VarDef.IsPublic && !used[node.VarDef] {
+				c.warn("unused-global", nameToken(node.VarDef.Name), fmt.Sprintf("private constant '%s' is never used", nameText(node.VarDef.Name)), "remove it or make it `pub`", nil)
+			}
+		}
+	}
+	c.imports()
+}
+
+// unusedCandidate selects declarations whose only possible users are calls and
+// function values in their own module. Members may be reached through
+// prototype dispatch or destructor inference, and entry points, exports, and
+// specialized generic instances are used by construction.
+func unusedCandidate(function *types.NodeFuncDef) bool {
+	return !function.IsPublic && !function.IsMember && !function.IsDestructor && !function.IsEntryPoint &&
+		!function.IsExternal && function.ExportName == "" && function.DisplayName == "" && function.ProtoDispatch == nil
+}
+
+func (c *checker) function(function *types.NodeFuncDef) {
+	locals := []*types.NodeExprVarDef{}
+	usedNames := map[string]bool{}
+	used := map[*types.NodeExprVarDef]bool{}
+	inlineLlvm := false
+	inspect(function, function, func(node any) {
+		switch node := node.(type) {
+		case *types.NodeLlvm:
+			inlineLlvm = true
+		case *types.NodeExprVarDef:
+			if !node.IsImplicitContext && !node.IsGlobal && node != function.ImplicitContext {
+				locals = append(locals, node)
+			}
+		case *types.NodeExprDestructureAssign:
+			locals = append(locals, &node.ValueDef, &node.ErrDef)
+		}
+		for _, target := range references(node) {
+			if variable, ok := target.(*types.NodeExprVarDef); ok {
+				used[variable] = true
+				usedNames[nameText(variable.Name)] = true
+			}
+		}
+	})
+	c.body(&function.Body)
+	// Inline LLVM names locals and arguments by their SSA spelling, which the
+	// resolver never sees.
+	if inlineLlvm {
+		return
+	}
+	for _, local := range locals {
+		name := nameText(local.Name)
+		if used[local] || strings.HasPrefix(name, "_") {
+			continue
+		}
+		c.warn("unused-variable", nameToken(local.Name), fmt.Sprintf("local variable '%s' is never used", name), "remove it or prefix the name with '_' to mark it intentionally unused", nil)
+	}
+	// Shadowing is rejected, so a parameter name identifies the parameter
+	// everywhere in the function body.
+	for i, argument := range function.Class.ArgsNode.Args {
+		if function.IsMember && i == 0 || function.ExportName != "" || c.fixed[function] || usedNames[argument.Name] || strings.HasPrefix(argument.Name, "_") {
+			continue
+		}
+		c.warn("unused-parameter", argument.Tk, fmt.Sprintf("parameter '%s' is never used", argument.Name), "prefix the name with '_' to mark it intentionally unused", nil)
+	}
+}
+
+// fixedSignatures collects the functions whose parameters a body may not need
+// but cannot drop: prototype requirements and their default bodies, methods
+// which fill a prototype slot, members synthesized by `@derive`, and functions
+// used as values, whose type is fixed by the function type they are passed as.
+func fixedSignatures(shared *types.SharedState, paths []string) map[*types.NodeFuncDef]bool {
+	fixed := map[*types.NodeFuncDef]bool{}
+	for _, path := range paths {
+		global := shared.Files[path].GlNode
+		for _, declaration := range global.Declarations {
+			if function, ok := declaration.(*types.NodeFuncDef); ok && function.Derived {
+				fixed[function] = true
+			}
+		}
+		for _, definition := range global.StructDefs {
+			if definition.Proto != nil {
+				for _, requirement := range definition.Proto.Methods {
+					fixed[requirement.FnDef], fixed[requirement.Default] = true, true
+				}
+				// A dispatcher for an inherited requirement shares the
+				// parameter tokens of the base prototype's declaration.
+				for _, requirement := range definition.Proto.Inherited {
+					fixed[requirement.FnDef] = true
+				}
+			}
+			for _, implementation := range definition.Implements {
+				if implementation.Proto == nil {
+					continue
+				}
+				for _, requirements := range [][]*types.ProtoMethod{implementation.Proto.Methods, implementation.Proto.Inherited} {
+					for _, requirement := range requirements {
+						if method := definition.Funcs[requirement.Name]; method != nil {
+							fixed[method] = true
+						}
+					}
+				}
+			}
+		}
+		callees := map[types.NodeExpr]bool{}
+		for _, declaration := range global.Declarations {
+			owner, _ := declaration.(*types.NodeFuncDef)
+			inspect(declaration, owner, func(node any) {
+				switch node := node.(type) {
+				case *types.NodeExprCall:
+					callees[node.Callee] = true
+				case *types.NodeExprName:
+					if function, ok := node.AssociatedNode.(*types.NodeFuncDef); ok && !callees[node] {
+						fixed[function] = true
+					}
+				}
+			})
+		}
+	}
+	delete(fixed, nil)
+	return fixed
+}
+
+// body reports the first unreachable statement of a block and of each nested
+// block which is itself reachable.
+func (c *checker) body(body *types.NodeBody) {
+	var exit types.Token
+	var exitMessage string
+	for _, statement := range body.Statements {
+		if exitMessage != "" {
+			if start, ok := c.statementToken(statement); ok {
+				c.warn("unreachable-code", start, "statement is unreachable", "remove it or move it before the statement which leaves the block", []types.DiagnosticRelated{{FilePath: c.file.FilePath, Token: exit, Message: exitMessage}})
+			}
+			return
+		}
+		c.nested(statement)
+		exit, exitMessage = terminator(statement)
+	}
+}
+
+func (c *checker) nested(statement types.NodeStatement) {
+	switch node := statement.(type) {
+	case *types.NodeStmtIf:
+		c.body(&node.Body)
+		for next := node.NextCondStmt; next != nil; {
+			switch branch := next.(type) {
+			case *types.NodeStmtIf:
+				c.body(&branch.Body)
+				next = branch.NextCondStmt
+			case *types.NodeStmtElse:
+				c.body(&branch.Body)
+				next = nil
+			default:
+				next = nil
+			}
+		}
+	case *types.NodeStmtWhile:
+		c.body(&node.Body)
+	case *types.NodeStmtFor:
+		c.body(&node.Body)
+	case *types.NodeStmtBounded:
+		c.body(&node.Body)
+	case *types.NodeStmtUnsafe:
+		c.body(&node.Body)
+	case *types.NodeStmtDefer:
+		if node.IsBody {
+			c.body(&node.Body)
+		}
+	}
+}
+
+// terminator returns the location which makes every following statement in
+// the same block unreachable, or an empty message when control may continue.
+func terminator(statement types.NodeStatement) (types.Token, string) {
+	switch node := statement.(type) {
+	case *types.NodeStmtRet:
+		return node.Tk, "the function returns here"
+	case *types.NodeStmtThrow:
+		return node.Tk, "the function throws here"
+	case *types.NodeStmtBreak:
+		return node.Tk, "the loop is left here"
+	case *types.NodeStmtContinue:
+		return node.Tk, "the loop continues here"
+	case *types.NodeStmtUnsafe:
+		return blockTerminator(&node.Body)
+	case *types.NodeStmtBounded:
+		return blockTerminator(&node.Body)
+	case *types.NodeStmtIf:
+		if _, message := blockTerminator(&node.Body); message == "" {
+			return types.Token{}, ""
+		}
+		for next := node.NextCondStmt; ; {
+			switch branch := next.(type) {
+			case *types.NodeStmtIf:
+				if _, message := blockTerminator(&branch.Body); message == "" {
+					return types.Token{}, ""
+				}
+				next = branch.NextCondStmt
+			case *types.NodeStmtElse:
+				if _, message := blockTerminator(&branch.Body); message == "" {
+					return types.Token{}, ""
+				}
+				return node.Tk, "every branch of this conditional leaves the block"
+			default:
+				// Without an else branch the condition may be false.
+				return types.Token{}, ""
+			}
+		}
+	}
+	return types.Token{}, ""
+}
+
+func blockTerminator(body *types.NodeBody) (types.Token, string) {
+	for _, statement := range body.Statements {
+		if token, message := terminator(statement); message != "" {
+			return token, message
+		}
+	}
+	return types.Token{}, ""
+}
+
+func (c *checker) imports() {
+	aliases := make([]string, 0, len(c.file.ImportAlias))
+	for alias := range c.file.ImportAlias {
+		if !c.file.GlNode.PublicImportAlias[alias] {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		declaration, used := importUse(c.file.Tokens, alias)
+		if !used && declaration.Pos.Line != 0 {
+			c.warn("unused-import", declaration, fmt.Sprintf("import '%s' is never used", alias), "remove the `use` declaration", nil)
+		}
+	}
+}
+
+// importUse finds the alias token of the `use` declaration and reports whether
+// the alias qualifies any name. Scanning tokens rather than resolved names also
+// counts uses inside platform-pruned declarations, which never reach the AST.
+func importUse(tokens []types.Token, alias string) (types.Token, bool) {
+	var declaration types.Token
+	used := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.KeywType == types.KwUse {
+			if i+2 < len(tokens) && tokens[i+1].Type == types.TokLitStr && tokens[i+2].Type == types.TokName && tokens[i+2].Repr == alias {
+				declaration = tokens[i+2]
+			}
+			i += 2
+			continue
+		}
+		if token.Type == types.TokName && token.Repr == alias && i+1 < len(tokens) && tokens[i+1].KeywType == types.KwDot {
+			used = true
+		}
+	}
+	return declaration, used
+}
+
+func (c *checker) warn(code string, token types.Token, message, additional string, related []types.DiagnosticRelated) {
+	key := fmt.Sprintf("%s:%d:%d", code, token.Pos.Line, token.Pos.Col)
+	if token.Pos.Line == 0 || c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.warnings = append(c.warnings, types.Diagnostic{
+		Severity:   types.SeverityWarning,
+		Code:       code,
+		Stage:      stage,
+		Ctx:        c.file,
+		FilePath:   c.file.FilePath,
+		Token:      token,
+		Message:    message,
+		ShortDesc:  message,
+		Additional: additional,
+		Related:    related,
+	})
+}
+
+// references returns the declarations a resolved node names.
+func references(node any) []types.Node {
+	switch node := node.(type) {
+	case *types.NodeExprName:
+		switch target := node.AssociatedNode.(type) {
+		case *types.NodeExprVarDefAssign:
+			if target.VarDef != nil {
+				return []types.Node{target.VarDef}
+			}
+		case nil:
+		default:
+			return []types.Node{target}
+		}
+	case *types.NodeExprCall:
+		if node.AssociatedFnDef != nil {
+			return []types.Node{node.AssociatedFnDef}
+		}
+	}
+	return nil
+}
+
+// inspect visits every AST node reachable from root other than owner's
+// siblings. Types and declarations are not entered: they name declarations
+// but never refer to values.
+func inspect(root any, owner *types.NodeFuncDef, visit func(any)) {
+	types.WalkAST(root, func(node any) bool {
+		switch node := node.(type) {
+		case *types.NodeFuncDef:
+			if node != owner {
+				return false
+			}
+		case *types.NodeType, *types.StructDef, *types.ProtoDef, *types.ProtoMethod, *types.ProtoImpl, *types.Scope, *types.FileCtx, *types.NodeGlobal:
+			return false
+		}
+		visit(node)
+		return true
+	})
+}
+
+// statementToken returns the first token on the line where a statement
+// starts. Statements occupy whole lines, so this is the statement's first token
+// even when the AST records only an operator or keyword position.
+func (c *checker) statementToken(statement types.NodeStatement) (types.Token, bool) {
+	line := uint32(0)
+	inspect(statement, nil, func(node any) {
+		value := reflect.Indirect(reflect.ValueOf(node))
+		if value.Kind() != reflect.Struct {
+			return
+		}
+		for i := 0; i < value.NumField(); i++ {
+			var tokens []types.Token
+			switch field := value.Field(i).Interface().(type) {
+			case types.Token:
+				tokens = []types.Token{field}
+			case []types.Token:
+				tokens = field
+			}
+			for _, token := range tokens {
+				if token.Pos.Line != 0 && (line == 0 || token.Pos.Line < line) {
+					line = token.Pos.Line
+				}
+			}
+		}
+	})
+	for _, token := range c.file.Tokens {
+		if token.Pos.Line == line && token.KeywType != types.KwNewline {
+			return token, true
+		}
+	}
+	return types.Token{}, false
+}
+
+func nameToken(name types.NodeName) types.Token {
+	switch name := name.(type) {
+	case *types.NodeNameSingle:
+		return name.Tk
+	case *types.NodeNameComposite:
+		if len(name.Tokens) != 0 {
+			return name.Tokens[len(name.Tokens)-1]
+		}
+	}
+	return types.Token{}
+}
+
+func nameText(name types.NodeName) string {
+	switch name := name.(type) {
+	case *types.NodeNameSingle:
+		return name.Name
+	case *types.NodeNameComposite:
+		return strings.Join(name.Parts, ".")
+	}
+	return ""
+}
@@ -40,6 +40,8 @@ type Diagnostic struct {
 	Safety  bool
 	Code    string
 	Related []types.DiagnosticRelated
+	// Trace is populated only in explain mode; see flow.history.
+	Trace []types.DiagnosticRelated
 }
 
 type flow struct {
@@ -67,6 +69,10 @@ type flow struct {
 	// completion-bearing handle. Unlike an ordinary loan, the dependency lasts
 	// until the handle is consumed by its destructor (join/await/remove/close).
 	retentions map[placeKey]pointerProvenance
+	// history is the explain-mode event chain of each tracked local. It stays
+	// nil unless explanations were requested, so ordinary analysis does not pay
+	// for copying it at every branch.
+	history map[*types.NodeExprVarDef][]types.DiagnosticRelated
 }
 
 type pointerProvenance struct {
@@ -116,11 +122,13 @@ type analyzer struct {
 	allocationReturns   map[*types.NodeFuncDef][]int
 	allocatorProto      *types.ProtoDef
 	consumePtrOrigins   map[*types.NodeFuncDef][]int
+	errorPredicates     map[*types.NodeFuncDef]map[string]errorPredicateSummary
 	futureUses          map[*types.NodeExprVarDef]bool
 	unsafeDepth         int
 	destructorReceivers map[*types.NodeExprVarDef]bool
 	staticExtents       map[*types.NodeExprVarDef]uint64
 	currentFunction     *types.NodeFuncDef
+	explain             bool
 }
 
 const (
@@ -171,6 +179,12 @@ func cloneFlow(in flow) flow {
 		copy.allocations = append([]allocatorOrigin(nil), retention.allocations...)
 		out.retentions[key] = copy
 	}
+	if in.history != nil {
+		out.history = map[*types.NodeExprVarDef][]types.DiagnosticRelated{}
+		for variable, events := range in.history {
+			out.history[variable] = append([]types.DiagnosticRelated(nil), events...)
+		}
+	}
 	for _, scope := range in.scopes {
 		copyScope := deferScope{locals: map[*types.NodeExprVarDef]bool{}, deferred: append([]*types.NodeStmtDefer(nil), scope.deferred...)}
 		for variable := range scope.locals {
@@ -287,6 +301,8 @@ func expressionToken(expr types.NodeExpr) types.Token {
 		return node.Tk
 	case *types.NodeExprMove:
 		return node.Tk
+	case *types.NodeExprCast:
+		return node.Tk
 	case *types.NodeExprCall:
 		return node.Tk
 	case *types.NodeExprMemberAccess:
@@ -551,6 +567,7 @@ func (a *analyzer) setAllocatorFact(out *flow, destination place.Place, value ty
 	}
 	if fact, ok := a.allocatorFactForExpr(out, value); ok {
 		out.allocators[key] = fact
+		a.recordLoans(out, destination, expressionToken(value), nil, fact.origins, "an interface to")
 	}
 }
 
@@ -843,6 +860,23 @@ func (a *analyzer) setProvenance(out *flow, destination place.Place, value types
 	}
 	if hasProvenance {
 		out.provenance[key] = provenance
+		a.recordLoans(out, destination, expressionToken(value), provenance.sources, provenance.allocations, "storage from")
+	}
+}
+
+// recordLoans notes in the explain-mode history of each borrowed place where
+// holder starts to depend on it, which is where a later live-loan conflict
+// begins.
+func (a *analyzer) recordLoans(out *flow, holder place.Place, token types.Token, sources []place.Place, allocations []allocatorOrigin, allocatorRelation string) {
+	for _, source := range sources {
+		if source.Root != holder.Root {
+			a.record(out, source.Root, token, "'%s' takes a pointer to '%s', which stays live while '%s' is used", placeName(holder), placeName(source), placeName(holder))
+		}
+	}
+	for _, allocation := range allocations {
+		if allocation.owner.Root != holder.Root {
+			a.record(out, allocation.owner.Root, token, "'%s' holds %s allocator '%s', which stays live while '%s' is used", placeName(holder), allocatorRelation, placeName(allocation.owner), placeName(holder))
+		}
 	}
 }
 
@@ -1027,7 +1061,7 @@ func (a *analyzer) checkLiveLoans(out *flow, changed place.Place, token types.To
 		for _, source := range provenance.sources {
 			for _, actual := range changedPlaces {
 				if source.Root == actual.Root && source.Overlaps(actual) {
-					a.safetyError(token, fmt.Sprintf("cannot %s '%s' while a pointer to it remains live", action, placeName(actual)))
+					a.tracedSafetyError(out, actual.Root, token, fmt.Sprintf("cannot %s '%s' while a pointer to it remains live", action, placeName(actual)), types.Token{}, "")
 					break
 				}
 			}
@@ -1040,7 +1074,7 @@ func (a *analyzer) checkLiveLoans(out *flow, changed place.Place, token types.To
 			}
 			for _, actual := range changedPlaces {
 				if allocation.owner.Root == actual.Root && allocation.owner.Overlaps(actual) {
-					a.safetyError(token, fmt.Sprintf("cannot %s allocator '%s' while derived storage remains live", action, placeName(actual)))
+					a.tracedSafetyError(out, actual.Root, token, fmt.Sprintf("cannot %s allocator '%s' while derived storage remains live", action, placeName(actual)), types.Token{}, "")
 					break
 				}
 			}
@@ -1053,7 +1087,7 @@ func (a *analyzer) checkLiveLoans(out *flow, changed place.Place, token types.To
 		for _, origin := range fact.origins {
 			for _, actual := range changedPlaces {
 				if origin.owner.Root == actual.Root && origin.owner.Overlaps(actual) {
-					a.safetyError(token, fmt.Sprintf("cannot %s '%s' while an allocator interface to it remains live", action, placeName(actual)))
+					a.tracedSafetyError(out, actual.Root, token, fmt.Sprintf("cannot %s '%s' while an allocator interface to it remains live", action, placeName(actual)), types.Token{}, "")
 					break
 				}
 			}
@@ -1063,11 +1097,10 @@ func (a *analyzer) checkLiveLoans(out *flow, changed place.Place, token types.To
 
 func literalUint(expr types.NodeExpr) (uint64, bool) {
 	literal, ok := expr.(*types.NodeExprLit)
-	if !ok || literal.LitType != types.TokLitNum || strings.HasPrefix(literal.Value, "-") {
+	if !ok {
 		return 0, false
 	}
-	value, err := strconv.ParseUint(literal.Value, 0, 64)
-	return value, err == nil
+	return literal.UintValue()
 }
 
 func (a *analyzer) diagnostic(token types.Token, message string, safety bool) {
@@ -1075,6 +1108,10 @@ func (a *analyzer) diagnostic(token types.Token, message string, safety bool) {
 }
 
 func (a *analyzer) diagnosticRelated(token types.Token, message string, safety bool, related []types.DiagnosticRelated) {
+	a.diagnosticTraced(token, message, safety, related, nil)
+}
+
+func (a *analyzer) diagnosticTraced(token types.Token, message string, safety bool, related, trace []types.DiagnosticRelated) {
 	key := fmt.Sprintf("%s\x00%d\x00%d\x00%t\x00%s", a.file.FilePath, token.Pos.Line, token.Pos.Col, safety, message)
 	if a.seen[key] {
 		return
@@ -1089,6 +1126,7 @@ func (a *analyzer) diagnosticRelated(token types.Token, message string, safety b
 		Safety:   safety,
 		Code:     diagnosticCode(message, safety),
 		Related:  related,
+		Trace:    trace,
 	})
 }
 
@@ -1107,6 +1145,8 @@ func diagnosticCode(message string, safety bool) string {
 		return "double-consume"
 	case strings.Contains(message, "unsafe"):
 		return "unsafe-required"
+	case strings.Contains(message, "remains live"):
+		return "live-loan"
 	case strings.Contains(message, "pointer") || strings.Contains(message, "provenance") || strings.Contains(message, "escape"):
 		return "invalid-provenance"
 	default:
@@ -1122,6 +1162,59 @@ func (a *analyzer) safetyErrorRelated(token types.Token, message string, prior t
 	a.diagnosticRelated(token, message, true, related)
 }
 
+// tracedSafetyError is safetyErrorRelated for a diagnostic about one tracked
+// local, carrying that local's explain-mode history.
+func (a *analyzer) tracedSafetyError(out *flow, variable *types.NodeExprVarDef, token types.Token, message string, prior types.Token, priorMessage string) {
+	related := []types.DiagnosticRelated(nil)
+	if prior.Pos.Line != 0 {
+		related = []types.DiagnosticRelated{{FilePath: a.file.FilePath, Token: prior, Message: priorMessage}}
+	}
+	a.diagnosticTraced(token, message, true, related, a.trace(out, variable))
+}
+
+func (a *analyzer) tracedWarn(out *flow, variable *types.NodeExprVarDef, token types.Token, message string) {
+	a.diagnosticTraced(token, message, false, nil, a.trace(out, variable))
+}
+
+func (a *analyzer) trace(out *flow, variable *types.NodeExprVarDef) []types.DiagnosticRelated {
+	if !a.explain || out == nil {
+		return nil
+	}
+	return append([]types.DiagnosticRelated(nil), out.history[variable]...)
+}
+
+// record appends an explain-mode event to a tracked local's history.
+func (a *analyzer) record(out *flow, variable *types.NodeExprVarDef, token types.Token, format string, args ...any) {
+	if !a.explain || variable == nil {
+		return
+	}
+	if token.Pos.Line == 0 {
+		token = variableToken(variable)
+	}
+	if out.history == nil {
+		out.history = map[*types.NodeExprVarDef][]types.DiagnosticRelated{}
+	}
+	events := out.history[variable]
+	// Histories are copied on clone, but clip anyway so sibling flows can never
+	// observe each other's appends through a shared backing array.
+	out.history[variable] = append(events[:len(events):len(events)], types.DiagnosticRelated{FilePath: a.file.FilePath, Token: token, Message: fmt.Sprintf(format, args...)})
+}
+
+func stateDescription(state State) string {
+	switch state {
+	case stateLive:
+		return "owned"
+	case stateConsumed:
+		return "consumed"
+	case stateMaybeConsumed:
+		return "possibly consumed"
+	case stateConditional:
+		return "owned only if its producing call succeeded"
+	default:
+		return "not owned"
+	}
+}
+
 func rangeExprKey(expr types.NodeExpr) string {
 	switch node := expr.(type) {
 	case *types.NodeExprName:
@@ -1396,7 +1489,7 @@ func knownFailingError(expr types.NodeExpr) bool {
 
 func knownNonNegative(expr types.NodeExpr) bool {
 	literal, ok := expr.(*types.NodeExprLit)
-	return ok && literal.LitType == types.TokLitNum && !strings.HasPrefix(literal.Value, "-")
+	return ok && (literal.LitType == types.TokLitChar || literal.LitType == types.TokLitNum && !strings.HasPrefix(literal.Value, "-"))
 }
 
 func invalidateVariableRanges(out *flow, variable *types.NodeExprVarDef) {
@@ -1611,7 +1704,7 @@ func (a *analyzer) useAt(out *flow, variable *types.NodeExprVarDef, token types.
 		if prior.Pos.Line != 0 {
 			message += fmt.Sprintf(" (previous consumption at line %d, column %d)", prior.Pos.Line, prior.Pos.Col)
 		}
-		a.safetyErrorRelated(token, message, prior, "ownership was transferred here")
+		a.tracedSafetyError(out, variable, token, message, prior, "ownership was transferred here")
 	}
 }
 
@@ -1637,7 +1730,7 @@ func (a *analyzer) usePlace(out *flow, resolved place.Place, token types.Token)
 		if prior.Pos.Line != 0 {
 			message += fmt.Sprintf(" (previous move at line %d, column %d)", prior.Pos.Line, prior.Pos.Col)
 		}
-		a.safetyErrorRelated(token, message, prior, "ownership place was moved here")
+		a.tracedSafetyError(out, resolved.Root, token, message, prior, "ownership place was moved here")
 	}
 }
 
@@ -1669,7 +1762,7 @@ func (a *analyzer) movePlace(out *flow, resolved place.Place, token types.Token)
 	a.checkLiveLoans(out, resolved, token, "move")
 	if len(resolved.Projections) == 0 {
 		if !a.tracked(out, resolved.Root) {
-			a.safetyError(token, fmt.Sprintf("cannot move borrowed or unowned value '%s'", variableName(resolved.Root)))
+			a.tracedSafetyError(out, resolved.Root, token, fmt.Sprintf("cannot move borrowed or unowned value '%s'", variableName(resolved.Root)), types.Token{}, "")
 			return false
 		}
 		if _, absent := a.absentOrigin(out, resolved); absent {
@@ -1698,13 +1791,14 @@ func (a *analyzer) movePlace(out *flow, resolved place.Place, token types.Token)
 		if prior.Pos.Line != 0 {
 			message += fmt.Sprintf("; previous move was at line %d, column %d", prior.Pos.Line, prior.Pos.Col)
 		}
-		a.safetyErrorRelated(token, message, prior, "ownership place was first moved here")
+		a.tracedSafetyError(out, resolved.Root, token, message, prior, "ownership place was first moved here")
 		return false
 	}
 	if out.absent == nil {
 		out.absent = map[placeKey]types.Token{}
 	}
 	out.absent[keyFor(resolved)] = token
+	a.record(out, resolved.Root, token, "ownership place '%s' is moved out", placeName(resolved))
 	root := place.Place{Root: resolved.Root}
 	if a.allOwnedFieldsAbsent(out, root) {
 		out.states[resolved.Root] = stateConsumed
@@ -1741,7 +1835,7 @@ func (a *analyzer) consumeAt(out *flow, variable *types.NodeExprVarDef, reason s
 	}
 	state, exists := out.states[variable]
 	if !exists || state == stateBorrowed {
-		a.safetyError(token, fmt.Sprintf("borrowed destructible value '%s' cannot be consumed (%s)", variableName(variable), reason))
+		a.tracedSafetyError(out, variable, token, fmt.Sprintf("borrowed destructible value '%s' cannot be consumed (%s)", variableName(variable), reason), types.Token{}, "")
 		return
 	}
 	if state != stateLive {
@@ -1750,7 +1844,7 @@ func (a *analyzer) consumeAt(out *flow, variable *types.NodeExprVarDef, reason s
 		if prior.Pos.Line != 0 {
 			message += fmt.Sprintf("; previous consumption was at line %d, column %d", prior.Pos.Line, prior.Pos.Col)
 		}
-		a.safetyErrorRelated(token, message, prior, "value was first consumed here")
+		a.tracedSafetyError(out, variable, token, message, prior, "value was first consumed here")
 		return
 	}
 	if out.deferred[variable] {
@@ -1759,9 +1853,10 @@ func (a *analyzer) consumeAt(out *flow, variable *types.NodeExprVarDef, reason s
 		if prior.Pos.Line != 0 {
 			message += fmt.Sprintf(" (defer scheduled at line %d, column %d)", prior.Pos.Line, prior.Pos.Col)
 		}
-		a.safetyErrorRelated(token, message, prior, "deferred destruction was scheduled here")
+		a.tracedSafetyError(out, variable, token, message, prior, "deferred destruction was scheduled here")
 	}
 	out.states[variable] = stateConsumed
+	a.record(out, variable, token, "'%s' is consumed (%s)", variableName(variable), reason)
 	clearRetention(out, place.Place{Root: variable})
 	if out.consumedAt == nil {
 		out.consumedAt = map[*types.NodeExprVarDef]types.Token{}
@@ -1793,6 +1888,8 @@ func (a *analyzer) borrowExpr(out *flow, expr types.NodeExpr) {
 	case *types.NodeExprUnary:
 		a.validateDereference(out, node)
 		a.borrowExpr(out, node.Operand)
+	case *types.NodeExprCast:
+		a.borrowExpr(out, node.Expr)
 	case *types.NodeExprMemberAccess:
 		// Validate every projected operation in the target (notably a
 		// subscript) before using the combined place. Treating the combined
@@ -2032,6 +2129,7 @@ func (a *analyzer) expression(out *flow, expr types.NodeExpr) {
 				out.conditions = map[*types.NodeExprVarDef]*types.NodeExprVarDef{}
 			}
 			out.conditions[&node.ValueDef] = &node.ErrDef
+			a.record(out, &node.ValueDef, node.Call.Tk, "'%s' is owned only if this call succeeds, as reported by '%s'", variableName(&node.ValueDef), variableName(&node.ErrDef))
 			a.addLocal(out, &node.ValueDef)
 		}
 	default:
@@ -2124,12 +2222,12 @@ func (a *analyzer) checkTryFailure(out *flow) {
 	a.unwindTryFailure(&failure)
 }
 
-func (a *analyzer) setDestinationOwnership(out *flow, destination *types.NodeExprVarDef, owned bool) {
+func (a *analyzer) setDestinationOwnership(out *flow, destination *types.NodeExprVarDef, owned bool, token types.Token) {
 	if !a.destructible(destination.Type) {
 		return
 	}
 	if state, exists := out.states[destination]; exists && state == stateLive {
-		a.warn(variableToken(destination), fmt.Sprintf("assignment overwrites live destructible value '%s'", variableName(destination)))
+		a.tracedWarn(out, destination, variableToken(destination), fmt.Sprintf("assignment overwrites live destructible value '%s'", variableName(destination)))
 	}
 	delete(out.deferred, destination)
 	delete(out.deferredAt, destination)
@@ -2141,6 +2239,7 @@ func (a *analyzer) setDestinationOwnership(out *flow, destination *types.NodeExp
 	}
 	if owned {
 		out.states[destination] = stateLive
+		a.record(out, destination, token, "'%s' acquires ownership", variableName(destination))
 	} else {
 		out.states[destination] = stateBorrowed
 	}
@@ -2212,7 +2311,7 @@ func (a *analyzer) valueInto(out *flow, destination *types.NodeExprVarDef, value
 		// from their return annotation.
 		owned = true
 	}
-	a.setDestinationOwnership(out, destination, owned)
+	a.setDestinationOwnership(out, destination, owned, expressionToken(value))
 	if destination != nil {
 		setRetention(out, place.Place{Root: destination}, retention, owned && retained)
 	}
@@ -2261,7 +2360,7 @@ func (a *analyzer) assignment(out *flow, assignment *types.NodeExprAssign) {
 		// Rebinding invalidates only relations which depend on that value or its
 		// descriptor; unrelated dominating facts remain available.
 		invalidateVariableRanges(out, destination)
-		a.setDestinationOwnership(out, destination, owned)
+		a.setDestinationOwnership(out, destination, owned, expressionToken(assignment.Right))
 		setRetention(out, resolved, retention, owned && retained)
 		if resolved, ok := resolvedPlace(assignment.Left); ok {
 			a.reinitializePlace(out, resolved)
@@ -2395,15 +2494,62 @@ func mergeFlows(left, right flow) flow {
 			delete(out.conditions, variable)
 		}
 	}
+	for variable, events := range right.history {
+		if out.history == nil {
+			out.history = map[*types.NodeExprVarDef][]types.DiagnosticRelated{}
+		}
+		out.history[variable] = mergeHistory(out.history[variable], events)
+	}
+	return out
+}
+
+// mergeHistory keeps the shared prefix once and then each path's own events.
+func mergeHistory(left, right []types.DiagnosticRelated) []types.DiagnosticRelated {
+	out := append([]types.DiagnosticRelated(nil), left...)
+	for _, event := range right {
+		found := false
+		for _, existing := range left {
+			if existing.Token.Pos == event.Token.Pos && existing.Message == event.Message {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, event)
+		}
+	}
 	return out
 }
 
+// join merges two control-flow paths. In explain mode it records, at token, a
+// conservative-merge event for every local whose ownership differed by path.
+func (a *analyzer) join(left, right flow, token types.Token, construct string) flow {
+	merged := mergeFlows(left, right)
+	if !a.explain || left.terminated || right.terminated {
+		return merged
+	}
+	for variable, state := range merged.states {
+		leftState, exists := left.states[variable]
+		if !exists {
+			leftState = stateBorrowed
+		}
+		rightState, exists := right.states[variable]
+		if !exists {
+			rightState = stateBorrowed
+		}
+		if leftState != rightState {
+			a.record(&merged, variable, token, "paths through this %s join with '%s' %s on one path and %s on another, so it is treated as %s", construct, variableName(variable), stateDescription(leftState), stateDescription(rightState), stateDescription(state))
+		}
+	}
+	return merged
+}
+
 func (a *analyzer) checkExit(out *flow) {
 	exit := cloneFlow(*out)
 	a.unwindTo(&exit, 0, false)
 	for variable, state := range exit.states {
 		if (state == stateLive || state == stateMaybeConsumed || state == stateConditional) && !a.destructorReceivers[variable] {
-			a.warn(variableToken(variable), fmt.Sprintf("destructible value '%s' is not consumed on every exit path", variableName(variable)))
+			a.tracedWarn(&exit, variable, variableToken(variable), fmt.Sprintf("destructible value '%s' is not consumed on every exit path", variableName(variable)))
 		}
 	}
 }
@@ -2412,6 +2558,7 @@ func (a *analyzer) runDeferred(out *flow, deferred *types.NodeStmtDefer) {
 	if owner := deferredDestructorOwner(deferred); owner != nil {
 		delete(out.deferred, owner)
 		delete(out.deferredAt, owner)
+		a.record(out, owner, expressionToken(deferred.Expression), "pending deferred destructor of '%s' runs at scope exit", variableName(owner))
 	}
 	if deferred.IsBody {
 		a.body(out, &deferred.Body)
@@ -2518,7 +2665,7 @@ func (a *analyzer) unwindScope(out *flow, checkLocals bool, failing bool) {
 		}
 		state, tracked := out.states[variable]
 		if checkLocals && tracked && (state == stateLive || state == stateMaybeConsumed || state == stateConditional) && !a.destructorReceivers[variable] {
-			a.warn(variableToken(variable), fmt.Sprintf("destructible value '%s' is not consumed on every scope exit path", variableName(variable)))
+			a.tracedWarn(out, variable, variableToken(variable), fmt.Sprintf("destructible value '%s' is not consumed on every scope exit path", variableName(variable)))
 		}
 		delete(out.states, variable)
 		delete(out.deferred, variable)
@@ -2560,21 +2707,128 @@ func (a *analyzer) unwindTryFailure(out *flow) {
 	}
 }
 
-func errorPredicate(expr types.NodeExpr) (*types.NodeExprVarDef, bool, bool) {
-	call, ok := expr.(*types.NodeExprCall)
-	if !ok || call.AssociatedFnDef == nil || call.AssociatedFnDef.ErrorPredicate == types.ErrorPredicateNone {
-		return nil, false, false
+// errorFact is what one outcome of a boolean condition proves about an error
+// variable. Outcome uses the errorFacts encoding: 1 = OK, -1 = non-OK.
+type errorFact struct {
+	variable *types.NodeExprVarDef
+	outcome  int8
+}
+
+func (f errorFact) known() bool { return f.variable != nil && f.outcome != 0 }
+
+// errorPredicateFact is a summary fact expressed in terms of the callee's
+// parameter list. Member receivers occupy parameter zero.
+type errorPredicateFact struct {
+	param   int
+	outcome int8
+}
+
+// errorPredicateSummary records which error parameter each result of a user
+// predicate refines. Summaries are keyed by result field; "" denotes the bool
+// result of the function itself.
+type errorPredicateSummary struct {
+	onTrue, onFalse errorPredicateFact
+}
+
+func (f errorPredicateFact) known() bool { return f.outcome != 0 }
+
+func firstKnownFact(left, right errorFact) errorFact {
+	if left.known() {
+		return left
 	}
-	var owner *types.NodeExprVarDef
-	if call.MemberOwnerExpr != nil {
-		owner = directVariable(call.MemberOwnerExpr)
-	} else if call.MemberOwnerName != nil {
-		owner = directVariable(call.MemberOwnerName)
+	return right
+}
+
+func sharedFact(left, right errorFact) errorFact {
+	if left.known() && left == right {
+		return left
+	}
+	return errorFact{}
+}
+
+func callArgument(call *types.NodeExprCall, param int) types.NodeExpr {
+	if call.AssociatedFnDef != nil && call.AssociatedFnDef.IsMember {
+		if param == 0 {
+			return callReceiver(call)
+		}
+		param--
 	}
-	if owner == nil {
-		return nil, false, false
+	if param < 0 || param >= len(call.Args) {
+		return nil
 	}
-	return owner, call.AssociatedFnDef.ErrorPredicate == types.ErrorPredicateOk, true
+	return call.Args[param]
+}
+
+func summarizedCallFacts(call *types.NodeExprCall, field string, summaries map[*types.NodeFuncDef]map[string]errorPredicateSummary) (errorFact, errorFact) {
+	summary, ok := summaries[call.AssociatedFnDef][field]
+	if !ok {
+		return errorFact{}, errorFact{}
+	}
+	resolve := func(fact errorPredicateFact) errorFact {
+		if !fact.known() {
+			return errorFact{}
+		}
+		variable := directVariable(callArgument(call, fact.param))
+		if variable == nil {
+			return errorFact{}
+		}
+		return errorFact{variable: variable, outcome: fact.outcome}
+	}
+	return resolve(summary.onTrue), resolve(summary.onFalse)
+}
+
+// predicateFacts evaluates a boolean condition into the error facts proven by
+// its true and false outcomes. The built-in err.ok()/err.nok() predicates are
+// the base case; inferred summaries let user helpers forward those facts,
+// comparisons with a bool literal may invert them, and `&&` and `||` combine
+// them conservatively. Locals maps bool locals of a
+// summarized helper body to their own facts and is nil at ordinary call sites.
+func predicateFacts(expr types.NodeExpr, summaries map[*types.NodeFuncDef]map[string]errorPredicateSummary, locals map[*types.NodeExprVarDef][2]errorFact) (errorFact, errorFact) {
+	switch node := expr.(type) {
+	case *types.NodeExprCall:
+		if node.AssociatedFnDef == nil {
+			return errorFact{}, errorFact{}
+		}
+		if node.AssociatedFnDef.ErrorPredicate != types.ErrorPredicateNone {
+			owner := directVariable(callReceiver(node))
+			if owner == nil {
+				return errorFact{}, errorFact{}
+			}
+			if node.AssociatedFnDef.ErrorPredicate == types.ErrorPredicateOk {
+				return errorFact{variable: owner, outcome: 1}, errorFact{variable: owner, outcome: -1}
+			}
+			return errorFact{variable: owner, outcome: -1}, errorFact{variable: owner, outcome: 1}
+		}
+		return summarizedCallFacts(node, "", summaries)
+	case *types.NodeExprMemberAccess:
+		if call, ok := node.Target.(*types.NodeExprCall); ok && call.AssociatedFnDef != nil {
+			return summarizedCallFacts(call, node.Member, summaries)
+		}
+	case *types.NodeExprName:
+		if variable, ok := node.AssociatedNode.(*types.NodeExprVarDef); ok && len(node.MemberAccesses) == 0 {
+			if facts, ok := locals[variable]; ok {
+				return facts[0], facts[1]
+			}
+		}
+	case *types.NodeExprBinary:
+		if literal, ok := node.Right.(*types.NodeExprLit); ok && literal.LitType == types.TokLitBool && (node.Operator == types.KwCmpEq || node.Operator == types.KwCmpNeq) {
+			onTrue, onFalse := predicateFacts(node.Left, summaries, locals)
+			if (literal.Value == "true") != (node.Operator == types.KwCmpEq) {
+				return onFalse, onTrue
+			}
+			return onTrue, onFalse
+		}
+		leftTrue, leftFalse := predicateFacts(node.Left, summaries, locals)
+		rightTrue, rightFalse := predicateFacts(node.Right, summaries, locals)
+		switch node.Operator {
+		case types.KwAndAnd:
+			// Both operands hold on true; only a fact shared by both holds on false.
+			return firstKnownFact(leftTrue, rightTrue), sharedFact(leftFalse, rightFalse)
+		case types.KwOrOr:
+			return sharedFact(leftTrue, rightTrue), firstKnownFact(leftFalse, rightFalse)
+		}
+	}
+	return errorFact{}, errorFact{}
 }
 
 func refineConditionalOwnership(in flow, errVariable *types.NodeExprVarDef, success bool) flow {
@@ -2592,28 +2846,38 @@ func refineConditionalOwnership(in flow, errVariable *types.NodeExprVarDef, succ
 	return out
 }
 
-func predicateFlows(in flow, expr types.NodeExpr) (flow, flow) {
-	errVariable, successOnTrue, ok := errorPredicate(expr)
-	if !ok {
-		return cloneFlow(in), cloneFlow(in)
+func refineErrorFact(in flow, fact errorFact) flow {
+	if !fact.known() {
+		return cloneFlow(in)
 	}
-	truth := refineConditionalOwnership(in, errVariable, successOnTrue)
-	falsehood := refineConditionalOwnership(in, errVariable, !successOnTrue)
-	truth.errorFacts[errVariable] = -1
-	if successOnTrue {
-		truth.errorFacts[errVariable] = 1
+	out := refineConditionalOwnership(in, fact.variable, fact.outcome > 0)
+	out.errorFacts[fact.variable] = fact.outcome
+	return out
+}
+
+func (a *analyzer) predicateFlows(in flow, expr types.NodeExpr) (flow, flow) {
+	onTrue, onFalse := predicateFacts(expr, a.errorPredicates, nil)
+	truth, falsehood := refineErrorFact(in, onTrue), refineErrorFact(in, onFalse)
+	a.recordRefinement(&truth, onTrue, expressionToken(expr), "true")
+	a.recordRefinement(&falsehood, onFalse, expressionToken(expr), "false")
+	return truth, falsehood
+}
+
+func (a *analyzer) recordRefinement(out *flow, fact errorFact, token types.Token, outcome string) {
+	if !a.explain || !fact.known() {
+		return
 	}
-	falsehood.errorFacts[errVariable] = 1
-	if successOnTrue {
-		falsehood.errorFacts[errVariable] = -1
+	for variable, condition := range out.conditions {
+		if condition == fact.variable {
+			a.record(out, variable, token, "where this condition is %s, '%s' is %s", outcome, variableName(variable), stateDescription(out.states[variable]))
+		}
 	}
-	return truth, falsehood
 }
 
 func (a *analyzer) conditional(out *flow, statement *types.NodeStmtIf) {
 	a.borrowExpr(out, statement.CondExpr)
 	branches := []flow{}
-	first, remaining := predicateFlows(*out, statement.CondExpr)
+	first, remaining := a.predicateFlows(*out, statement.CondExpr)
 	a.addRangePredicates(&first, statement.CondExpr, true, false)
 	a.addRangePredicates(&remaining, statement.CondExpr, false, false)
 	a.body(&first, &statement.Body)
@@ -2624,7 +2888,7 @@ func (a *analyzer) conditional(out *flow, statement *types.NodeStmtIf) {
 	for next != nil {
 		switch branch := next.(type) {
 		case *types.NodeStmtIf:
-			candidate, falseFlow := predicateFlows(remaining, branch.CondExpr)
+			candidate, falseFlow := a.predicateFlows(remaining, branch.CondExpr)
 			a.addRangePredicates(&candidate, branch.CondExpr, true, false)
 			a.addRangePredicates(&falseFlow, branch.CondExpr, false, false)
 			a.borrowExpr(&candidate, branch.CondExpr)
@@ -2645,7 +2909,7 @@ func (a *analyzer) conditional(out *flow, statement *types.NodeStmtIf) {
 	}
 	merged := branches[0]
 	for _, branch := range branches[1:] {
-		merged = mergeFlows(merged, branch)
+		merged = a.join(merged, branch, statement.Tk, "if")
 	}
 	*out = merged
 }
@@ -2733,6 +2997,7 @@ func (a *analyzer) statement(out *flow, statement types.NodeStatement) {
 			a.checkExit(&failure)
 			if errVariable != nil {
 				*out = refineConditionalOwnership(*out, errVariable, true)
+				a.recordRefinement(out, errorFact{variable: errVariable, outcome: 1}, node.Tk, "not thrown")
 			}
 		} else {
 			a.unwindTo(out, 0, true)
@@ -2764,15 +3029,15 @@ func (a *analyzer) statement(out *flow, statement types.NodeStatement) {
 			} else {
 				*out = breaks[0]
 				for _, broken := range breaks[1:] {
-					*out = mergeFlows(*out, broken)
+					*out = a.join(*out, broken, node.Tk, "loop")
 				}
 			}
 		} else {
 			for _, next := range a.loopNext[loopIndex] {
-				*out = mergeFlows(*out, next)
+				*out = a.join(*out, next, node.Tk, "loop")
 			}
 			for _, broken := range breaks {
-				*out = mergeFlows(*out, broken)
+				*out = a.join(*out, broken, node.Tk, "loop")
 			}
 		}
 		a.loopBreaks = a.loopBreaks[:loopIndex]
@@ -2809,10 +3074,10 @@ func (a *analyzer) statement(out *flow, statement types.NodeStatement) {
 		breaks := a.loopBreaks[loopIndex]
 		nextFlows := a.loopNext[loopIndex]
 		for _, next := range nextFlows {
-			*out = mergeFlows(*out, next)
+			*out = a.join(*out, next, node.Tk, "for loop")
 		}
 		for _, broken := range breaks {
-			*out = mergeFlows(*out, broken)
+			*out = a.join(*out, broken, node.Tk, "for loop")
 		}
 		a.loopBreaks = a.loopBreaks[:loopIndex]
 		a.loopNext = a.loopNext[:loopIndex]
@@ -2864,6 +3129,7 @@ func (a *analyzer) statement(out *flow, statement types.NodeStatement) {
 				out.deferredAt = map[*types.NodeExprVarDef]types.Token{}
 			}
 			out.deferredAt[owner] = expressionToken(node.Expression)
+			a.record(out, owner, expressionToken(node.Expression), "destructor of '%s' is deferred until its scope exits", variableName(owner))
 		}
 	}
 }
@@ -2916,6 +3182,8 @@ func collectExprUses(expr types.NodeExpr, out map[*types.NodeExprVarDef]bool) {
 		collectExprUses(node.Expr, out)
 	case *types.NodeExprMove:
 		collectExprUses(node.Expr, out)
+	case *types.NodeExprCast:
+		collectExprUses(node.Expr, out)
 	case *types.NodeExprMemberAccess:
 		collectExprUses(node.Target, out)
 	case *types.NodeExprSubscript:
@@ -3026,6 +3294,7 @@ func (a *analyzer) function(function *types.NodeFuncDef) {
 			if variable != nil && variable.Type != nil && variable.Type.Owned && a.destructible(variable.Type) {
 				out.states[variable] = stateLive
 				out.scopes[0].locals[variable] = true
+				a.record(&out, variable, argument.Tk, "parameter '%s' receives ownership from the caller", argument.Name)
 			}
 		}
 	}
@@ -3500,6 +3769,103 @@ func inferLeadingDestructorEffects(shared *types.SharedState) map[*types.NodeFun
 	return summaries
 }
 
+func boolValueType(node *types.NodeType) bool {
+	if node == nil {
+		return false
+	}
+	named, ok := node.KindNode.(*types.NodeTypeNamed)
+	if !ok {
+		return false
+	}
+	single, ok := named.NameNode.(*types.NodeNameSingle)
+	return ok && single.Name == "bool"
+}
+
+func errorPredicateBody(function *types.NodeFuncDef, parameters map[*types.NodeExprVarDef]int, summaries map[*types.NodeFuncDef]map[string]errorPredicateSummary) map[string]errorPredicateSummary {
+	locals := map[*types.NodeExprVarDef][2]errorFact{}
+	summarize := func(expr types.NodeExpr) (errorPredicateSummary, bool) {
+		toParameter := func(fact errorFact) errorPredicateFact {
+			if index, ok := parameters[fact.variable]; ok && fact.known() {
+				return errorPredicateFact{param: index, outcome: fact.outcome}
+			}
+			return errorPredicateFact{}
+		}
+		onTrue, onFalse := predicateFacts(expr, summaries, locals)
+		summary := errorPredicateSummary{onTrue: toParameter(onTrue), onFalse: toParameter(onFalse)}
+		return summary, summary.onTrue.known() || summary.onFalse.known()
+	}
+	statements := function.Body.Statements
+	for index, statement := range statements {
+		switch node := statement.(type) {
+		case *types.NodeStmtExpr:
+			definition, ok := node.Expression.(*types.NodeExprVarDefAssign)
+			if !ok {
+				return nil
+			}
+			onTrue, onFalse := predicateFacts(definition.AssignExpr, summaries, locals)
+			locals[definition.VarDef] = [2]errorFact{onTrue, onFalse}
+		case *types.NodeStmtRet:
+			if index != len(statements)-1 {
+				return nil
+			}
+			out := map[string]errorPredicateSummary{}
+			if boolValueType(function.ReturnType) {
+				if summary, ok := summarize(node.Expression); ok {
+					out[""] = summary
+				}
+			} else if init, ok := node.Expression.(*types.NodeExprStructInit); ok {
+				for _, field := range init.Fields {
+					if summary, ok := summarize(field.Expression); ok {
+						out[field.Name] = summary
+					}
+				}
+			}
+			if len(out) == 0 {
+				return nil
+			}
+			return out
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// inferErrorPredicates summarizes small non-throwing helpers whose bool result,
+// or a bool field of whose constructed struct result, is derived from an error
+// parameter through err.ok()/err.nok() or another summarized helper. Such
+// helpers then refine conditional ownership exactly like the built-ins. Bodies
+// are limited to local definitions followed by one return, so the summarized
+// result is a function of the arguments alone.
+func inferErrorPredicates(shared *types.SharedState) map[*types.NodeFuncDef]map[string]errorPredicateSummary {
+	summaries := map[*types.NodeFuncDef]map[string]errorPredicateSummary{}
+	// Iterate because a helper may forward the result of another helper.
+	for iteration := 0; iteration <= linkedFunctionCount(shared); iteration++ {
+		changed := false
+		for _, file := range shared.Files {
+			for _, declaration := range file.GlNode.Declarations {
+				function, ok := declaration.(*types.NodeFuncDef)
+				if !ok || function.IsExternal || function.ProtoDispatch != nil || function.ReturnType == nil || function.ReturnType.Throws {
+					continue
+				}
+				summary := errorPredicateBody(function, functionParameterRoots(file, function), summaries)
+				if !reflect.DeepEqual(summary, summaries[function]) {
+					if summary == nil {
+						delete(summaries, function)
+					} else {
+						summaries[function] = summary
+					}
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return summaries
+}
+
 // Check runs the analysis, annotates authorized subscripts, and returns all
 // ownership/range diagnostics.
 func Check(shared *types.SharedState) []Diagnostic {
@@ -3509,6 +3875,7 @@ func Check(shared *types.SharedState) []Diagnostic {
 	diagnostics := []Diagnostic{}
 	returnOrigins := inferReturnOrigins(shared)
 	consumePtrOrigins := inferLeadingDestructorEffects(shared)
+	errorPredicates := inferErrorPredicates(shared)
 	var allocatorProto *types.ProtoDef
 	for _, file := range shared.Files {
 		if file.ModuleName == "allocator" {
@@ -3521,7 +3888,7 @@ func Check(shared *types.SharedState) []Diagnostic {
 	allocatorReturns := inferAllocatorReturns(shared, allocatorProto)
 	allocationReturns := inferAllocationReturns(shared, allocatorProto, allocatorReturns)
 	for _, file := range shared.Files {
-		a := &analyzer{shared: shared, file: file, seen: map[string]bool{}, returnOrigins: returnOrigins, allocatorReturns: allocatorReturns, allocationReturns: allocationReturns, allocatorProto: allocatorProto, consumePtrOrigins: consumePtrOrigins, futureUses: map[*types.NodeExprVarDef]bool{}, destructorReceivers: map[*types.NodeExprVarDef]bool{}, staticExtents: map[*types.NodeExprVarDef]uint64{}}
+		a := &analyzer{shared: shared, file: file, explain: shared.ExplainSafety, seen: map[string]bool{}, returnOrigins: returnOrigins, allocatorReturns: allocatorReturns, allocationReturns: allocationReturns, allocatorProto: allocatorProto, consumePtrOrigins: consumePtrOrigins, errorPredicates: errorPredicates, futureUses: map[*types.NodeExprVarDef]bool{}, destructorReceivers: map[*types.NodeExprVarDef]bool{}, staticExtents: map[*types.NodeExprVarDef]uint64{}}
 		validateDestructors(a, file.GlNode)
 		for _, declaration := range file.GlNode.Declarations {
 			if function, ok := declaration.(*types.NodeFuncDef); ok {
@@ -3548,10 +3915,11 @@ func Run(shared *types.SharedState, warningMode bool) error {
 			Token:    diagnostic.Token,
 			Message:  diagnostic.Message,
 			Related:  diagnostic.Related,
+			Trace:    diagnostic.Trace,
 		}
 		if !diagnostic.Safety || warningMode {
 			out.Severity = types.SeverityWarning
-			shared.Warnings = append(shared.Warnings, out)
+			shared.Warn(out)
 			continue
 		}
 		copy := out
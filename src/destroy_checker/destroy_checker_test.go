@@ -174,7 +174,7 @@ func TestOwnedReturnEvaluatesConsumingCallArguments(t *testing.T) {
 }
 
 func TestErrorPredicateRefinesConditionalOwnership(t *testing.T) {
-	_, resourceType := fixture()
+	a, resourceType := fixture()
 	value := &types.NodeExprVarDef{Name: &types.NodeNameSingle{Name: "value"}, Type: resourceType}
 	errVariable := &types.NodeExprVarDef{Name: &types.NodeNameSingle{Name: "err"}, Type: &types.NodeType{KindNode: &types.NodeTypeNamed{NameNode: &types.NodeNameSingle{Name: "error"}}}}
 	out := flow{
@@ -188,7 +188,7 @@ func TestErrorPredicateRefinesConditionalOwnership(t *testing.T) {
 		MemberOwnerName: name(errVariable),
 	}
 
-	failure, success := predicateFlows(out, predicate)
+	failure, success := a.predicateFlows(out, predicate)
 	if failure.states[value] != stateBorrowed {
 		t.Fatalf("nok true state = %v, want borrowed/absent", failure.states[value])
 	}
@@ -197,6 +197,33 @@ func TestErrorPredicateRefinesConditionalOwnership(t *testing.T) {
 	}
 }
 
+func TestErrorPredicateConjunctionProvesOnlyItsTrueOutcome(t *testing.T) {
+	errType := &types.NodeType{KindNode: &types.NodeTypeNamed{NameNode: &types.NodeNameSingle{Name: "error"}}}
+	errVariable := &types.NodeExprVarDef{Name: &types.NodeNameSingle{Name: "err"}, Type: errType}
+	nok := &types.NodeExprCall{AssociatedFnDef: &types.NodeFuncDef{ErrorPredicate: types.ErrorPredicateNok}, IsMemberFunc: true, MemberOwnerName: name(errVariable)}
+	other := &types.NodeExprCall{AssociatedFnDef: &types.NodeFuncDef{}}
+	conjunction := &types.NodeExprBinary{Operator: types.KwAndAnd, Left: nok, Right: other}
+
+	onTrue, onFalse := predicateFacts(conjunction, nil, nil)
+	if onTrue != (errorFact{variable: errVariable, outcome: -1}) {
+		t.Fatalf("true fact = %+v, want failure of err", onTrue)
+	}
+	if onFalse.known() {
+		t.Fatalf("false fact = %+v, want none for a one-sided conjunction", onFalse)
+	}
+
+	helper := &types.NodeFuncDef{}
+	call := &types.NodeExprCall{AssociatedFnDef: helper, Args: []types.NodeExpr{name(errVariable)}}
+	summaries := map[*types.NodeFuncDef]map[string]errorPredicateSummary{
+		helper: {"": {onTrue: errorPredicateFact{param: 0, outcome: -1}}},
+	}
+	negated := &types.NodeExprBinary{Operator: types.KwCmpEq, Left: call, Right: &types.NodeExprLit{LitType: types.TokLitBool, Value: "false"}}
+	onTrue, onFalse = predicateFacts(negated, summaries, nil)
+	if onTrue.known() || onFalse != (errorFact{variable: errVariable, outcome: -1}) {
+		t.Fatalf("negated summary facts = %+v / %+v", onTrue, onFalse)
+	}
+}
+
 func TestImplicitFieldTransferRequiresMove(t *testing.T) {
 	a, resourceType := fixture()
 	aggregate := &types.NodeExprVarDef{Name: &types.NodeNameSingle{Name: "aggregate"}, Type: resourceType}
@@ -0,0 +1,146 @@
+// Package devirtualize replaces prototype dispatch by direct calls where the
+// implementation behind a view is known.
+//
+// The pass runs on the checked program, which is complete: every prototype
+// relation in it is known. A call through a view reaches a single function
+// when its prototype has exactly one implementation in the program, or when
+// the view is a local initialized by `proto()` in the calling function and
+// never assigned or borrowed afterwards. Such calls are marked with
+// NodeExprCall.Devirtualized, and lowering then calls the implementation
+// directly, which LLVM may inline.
+package devirtualize
+
+import "Magma/src/types"
+
+// Run marks the devirtualizable prototype calls of every function.
+func Run(shared *types.SharedState) {
+	implementations := map[*types.ProtoDef][]*types.StructDef{}
+	for _, file := range shared.Files {
+		if file == nil || file.GlNode == nil {
+			continue
+		}
+		for _, definition := range file.GlNode.StructDefs {
+			// Generic templates are never lowered; their instances are
+			// definitions of their own.
+			if len(definition.TypeParams) != 0 {
+				continue
+			}
+			for _, relation := range definition.Implements {
+				if relation != nil && relation.Proto != nil {
+					implementations[relation.Proto] = append(implementations[relation.Proto], definition)
+				}
+			}
+		}
+	}
+	for _, file := range shared.Files {
+		if file == nil || file.GlNode == nil {
+			continue
+		}
+		for _, declaration := range file.GlNode.Declarations {
+			if function, ok := declaration.(*types.NodeFuncDef); ok && function.Body.Statements != nil {
+				devirtualizeFunction(function, implementations)
+			}
+		}
+	}
+}
+
+func devirtualizeFunction(function *types.NodeFuncDef, implementations map[*types.ProtoDef][]*types.StructDef) {
+	views := map[*types.NodeExprVarDef]*types.NodeExprProtoView{}
+	escaped := map[*types.NodeExprVarDef]bool{}
+	var calls []*types.NodeExprCall
+	inspect(&function.Body, func(node any) {
+		switch node := node.(type) {
+		case *types.NodeExprVarDefAssign:
+			// An upcast names the relation between two prototypes, not the
+			// implementation behind the view it converts.
+			if view, ok := node.AssignExpr.(*types.NodeExprProtoView); ok && node.VarDef != nil && view.Implementation != nil && len(view.Upcast) == 0 {
+				views[node.VarDef] = view
+			}
+		case *types.NodeExprAssign:
+			if local := localVariable(node.Left); local != nil {
+				escaped[local] = true
+			}
+		case *types.NodeExprAddrof:
+			if local := localVariable(node.Expr); local != nil {
+				escaped[local] = true
+			}
+		case *types.NodeExprCall:
+			if node.IsMemberFunc && node.AssociatedFnDef != nil && node.AssociatedFnDef.ProtoDispatch != nil {
+				calls = append(calls, node)
+			}
+		}
+	})
+
+	for _, call := range calls {
+		method := call.AssociatedFnDef.ProtoDispatch
+		var implementation *types.StructDef
+		if view := knownView(call, views, escaped); view != nil && view.Implementation.Proto == method.Proto {
+			implementation = view.Implementation.Owner
+		} else if candidates := implementations[method.Proto]; len(candidates) == 1 {
+			implementation = candidates[0]
+		}
+		if implementation != nil {
+			call.Devirtualized = target(implementation, method)
+		}
+	}
+}
+
+// knownView returns the `proto()` expression which initialized the local view
+// a call is made on, provided the local has not been changed since.
+func knownView(call *types.NodeExprCall, views map[*types.NodeExprVarDef]*types.NodeExprProtoView, escaped map[*types.NodeExprVarDef]bool) *types.NodeExprProtoView {
+	if call.MemberOwnerExpr != nil || call.MemberOwnerName == nil || call.MemberOwnerIsPtr || len(call.MemberOwnerName.MemberAccesses) != 0 {
+		return nil
+	}
+	local := localVariable(call.MemberOwnerName)
+	if local == nil || escaped[local] {
+		return nil
+	}
+	return views[local]
+}
+
+// target is the function implementation provides for method. A requirement
+// left to its default body is reached through the default, which takes the
+// view; an inherited one is left to dispatch, because its default takes a
+// view of the base.
+func target(implementation *types.StructDef, method *types.ProtoMethod) *types.NodeFuncDef {
+	if concrete := implementation.Funcs[method.Name]; concrete != nil {
+		return concrete
+	}
+	if method.Origin == nil {
+		return method.Default
+	}
+	return nil
+}
+
+// localVariable returns the local variable expression names, itself or through
+// member accesses.
+func localVariable(expression types.NodeExpr) *types.NodeExprVarDef {
+	name, ok := expression.(*types.NodeExprName)
+	if !ok {
+		return nil
+	}
+	var variable *types.NodeExprVarDef
+	switch node := name.AssociatedNode.(type) {
+	case *types.NodeExprVarDef:
+		variable = node
+	case *types.NodeExprVarDefAssign:
+		variable = node.VarDef
+	}
+	if variable == nil || variable.IsGlobal {
+		return nil
+	}
+	return variable
+}
+
+// inspect visits every AST node reachable from root without entering other
+// declarations or types.
+func inspect(root any, visit func(any)) {
+	types.WalkAST(root, func(node any) bool {
+		switch node.(type) {
+		case *types.NodeFuncDef, *types.NodeType, *types.StructDef, *types.ProtoDef, *types.ProtoMethod, *types.ProtoImpl, *types.Scope, *types.FileCtx, *types.NodeGlobal:
+			return false
+		}
+		visit(node)
+		return true
+	})
+}
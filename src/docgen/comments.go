@@ -0,0 +1,300 @@
+// Package docgen parses Magma doc comments and generates reference
+// documentation from them.
+package docgen
+
+import (
+	"Magma/src/types"
+	"fmt"
+	"strings"
+)
+
+// Documentation is one parsed doc-comment block.
+type Documentation struct {
+	description []string
+	params      []docParam
+	returns     string
+	warnings    []string
+	notes       []string
+	complexity  []string
+	throws      []string
+	ownership   []string
+	safety      []string
+	mustCall    []string
+	platforms   []string
+	deprecated  []string
+	see         []string
+	examples    []string
+	// exampleLines holds the line of the first line of each example: an
+	// index into the block for ParseBlock, a one-based source line for
+	// ParseSource.
+	exampleLines []uint32
+}
+
+type docParam struct{ name, text string }
+
+// ParseSource collects the documentation blocks of a source file. Declaration
+// docs are keyed by the one-based line of the unindented declaration which
+// immediately follows an unindented `#` block, past any directive lines. The
+// block directly after the `mod` line documents the module itself.
+func ParseSource(source string) (map[uint32]Documentation, Documentation) {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	result := map[uint32]Documentation{}
+	var module Documentation
+	moduleLine := -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "mod ") && strings.TrimLeft(line, " \t") == line {
+			moduleLine = i
+			break
+		}
+	}
+	for i := 0; i < len(lines); {
+		// Indented comments belong to function bodies and are never docs.
+		if !strings.HasPrefix(lines[i], "#") {
+			i++
+			continue
+		}
+		start := i
+		comments := []string{}
+		for i < len(lines) && strings.HasPrefix(lines[i], "#") {
+			text := strings.TrimPrefix(lines[i], "#")
+			comments = append(comments, strings.TrimPrefix(text, " "))
+			i++
+		}
+		doc := ParseBlock(comments)
+		for j := range doc.exampleLines {
+			doc.exampleLines[j] += uint32(start) + 1
+		}
+		if start == moduleLine+1 {
+			module = doc
+			continue
+		}
+		// Directives such as `@derive(...)` may sit between the block and
+		// the declaration they apply to.
+		next := i
+		for next < len(lines) && strings.HasPrefix(lines[next], "@") {
+			next++
+		}
+		if next < len(lines) && strings.TrimSpace(lines[next]) != "" && strings.TrimLeft(lines[next], " \t") == lines[next] {
+			// Source and token lines are one-based.
+			result[uint32(next+1)] = doc
+		}
+	}
+	return result, module
+}
+
+// ParseBlock parses the comment lines of one block, with their `#` prefix
+// removed, into a description and tagged sections.
+func ParseBlock(lines []string) Documentation {
+	doc := Documentation{}
+	// A tag owns every following comment line until the next tag. Folding the
+	// block first keeps the individual tag parsers simple while preserving
+	// intentional line breaks (especially for examples and warnings).
+	folded := make([]string, 0, len(lines))
+	starts := make([]int, 0, len(lines))
+	for i, line := range lines {
+		if len(folded) != 0 && strings.HasPrefix(folded[len(folded)-1], "@") && !strings.HasPrefix(line, "@") {
+			folded[len(folded)-1] += "\n" + line
+			continue
+		}
+		folded = append(folded, line)
+		starts = append(starts, i)
+	}
+	lines = folded
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if rest, ok := strings.CutPrefix(line, "@param "); ok {
+			name, text, _ := strings.Cut(strings.TrimSpace(rest), " ")
+			doc.params = append(doc.params, docParam{name: name, text: strings.TrimSpace(text)})
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@returns "); ok {
+			doc.returns = strings.TrimSpace(rest)
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@return "); ok {
+			doc.returns = strings.TrimSpace(rest)
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@warning "); ok {
+			doc.warnings = append(doc.warnings, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@note "); ok {
+			doc.notes = append(doc.notes, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@complexity "); ok {
+			doc.complexity = append(doc.complexity, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@throws "); ok {
+			doc.throws = append(doc.throws, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@ownership "); ok {
+			doc.ownership = append(doc.ownership, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@safety "); ok {
+			doc.safety = append(doc.safety, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@mustcall "); ok {
+			doc.mustCall = append(doc.mustCall, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@platform "); ok {
+			doc.platforms = append(doc.platforms, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@deprecated "); ok {
+			doc.deprecated = append(doc.deprecated, strings.TrimSpace(rest))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "@see "); ok {
+			doc.see = append(doc.see, strings.TrimSpace(rest))
+			continue
+		}
+		if line == "@example" || strings.HasPrefix(line, "@example ") || strings.HasPrefix(line, "@example\n") {
+			example := strings.TrimPrefix(line, "@example")
+			first := 0
+			for _, text := range strings.Split(example, "\n") {
+				if strings.TrimSpace(text) != "" {
+					break
+				}
+				first++
+			}
+			doc.exampleLines = append(doc.exampleLines, uint32(starts[i]+first))
+			exampleLines := strings.Split(strings.Trim(example, "\n"), "\n")
+			for i := range exampleLines {
+				exampleLines[i] = strings.TrimPrefix(exampleLines[i], "  ")
+			}
+			doc.examples = append(doc.examples, strings.TrimSpace(strings.Join(exampleLines, "\n")))
+			continue
+		}
+		doc.description = append(doc.description, line)
+	}
+	return doc
+}
+
+// Param returns the `@param` text documenting name, or "" when the block does
+// not describe it.
+func (d Documentation) Param(name string) string {
+	for _, param := range d.params {
+		if param.name == name {
+			return param.text
+		}
+	}
+	return ""
+}
+
+// Markdown renders the documentation as used by hovers and generated pages.
+func (d Documentation) Markdown() string {
+	parts := []string{}
+	if text := strings.TrimSpace(strings.Join(d.description, "\n")); text != "" {
+		parts = append(parts, text)
+	}
+	for _, text := range d.deprecated {
+		parts = append(parts, blockquote("Deprecated", text))
+	}
+	for _, text := range d.warnings {
+		parts = append(parts, blockquote("⚠ Warning", text))
+	}
+	for _, text := range d.safety {
+		parts = append(parts, blockquote("Safety", text))
+	}
+	for _, text := range d.notes {
+		parts = append(parts, blockquote("Note", text))
+	}
+	if len(d.params) != 0 {
+		lines := []string{"**Parameters**"}
+		for _, param := range d.params {
+			line := fmt.Sprintf("- `%s`", param.name)
+			if param.text != "" {
+				line += " — " + param.text
+			}
+			lines = append(lines, line)
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+	if d.returns != "" {
+		parts = append(parts, "**Returns:** "+d.returns)
+	}
+	if len(d.throws) != 0 {
+		parts = append(parts, markdownList("Throws", d.throws, false))
+	}
+	if len(d.ownership) != 0 {
+		parts = append(parts, markdownList("Ownership", d.ownership, false))
+	}
+	if len(d.mustCall) != 0 {
+		parts = append(parts, markdownList("Must call", d.mustCall, true))
+	}
+	if len(d.complexity) != 0 {
+		parts = append(parts, markdownList("Complexity", d.complexity, false))
+	}
+	if len(d.platforms) != 0 {
+		parts = append(parts, markdownList("Platforms", d.platforms, false))
+	}
+	for _, example := range d.examples {
+		parts = append(parts, "**Example**\n\n```magma\n"+example+"\n```")
+	}
+	if len(d.see) != 0 {
+		quoted := make([]string, 0, len(d.see))
+		for _, symbol := range d.see {
+			quoted = append(quoted, "`"+symbol+"`")
+		}
+		parts = append(parts, "**See also:** "+strings.Join(quoted, ", "))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func blockquote(title, text string) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) == 0 {
+		return "> **" + title + ":**"
+	}
+	lines[0] = "> **" + title + ":** " + lines[0]
+	for i := 1; i < len(lines); i++ {
+		lines[i] = "> " + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func markdownList(title string, values []string, codeValues bool) string {
+	if len(values) == 1 {
+		value := values[0]
+		if codeValues {
+			value = "`" + value + "`"
+		}
+		return "**" + title + ":** " + value
+	}
+	lines := []string{"**" + title + "**"}
+	for _, value := range values {
+		if codeValues {
+			value = "`" + value + "`"
+		}
+		lines = append(lines, "- "+value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DeclarationLine returns the line ParseSource keys the docs of a declaration
+// with this name by.
+func DeclarationLine(name types.NodeName) uint32 {
+	return nameToken(name).Pos.Line
+}
+
+func nameToken(name types.NodeName) types.Token {
+	switch node := name.(type) {
+	case *types.NodeNameSingle:
+		return node.Tk
+	case *types.NodeNameComposite:
+		if len(node.Tokens) != 0 {
+			// For member declarations the owner token may be inherited from a
+			// generic type node. The final token is the declared member itself and
+			// therefore identifies the line immediately following its docs.
+			return node.Tokens[len(node.Tokens)-1]
+		}
+	}
+	return types.Token{}
+}
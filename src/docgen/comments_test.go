@@ -0,0 +1,124 @@
+package docgen
+
+import (
+	"Magma/src/types"
+	"strings"
+	"testing"
+)
+
+func TestParseDocumentation(t *testing.T) {
+	source := `mod strings
+# String helpers.
+
+# Frees an allocated string.
+# @param a allocator
+# @param s allocated slice
+# @returns nothing
+pub free(a Allocator, s $str) void:
+    # This body comment must not become documentation.
+    s.free(a)
+..
+`
+	byLine, module := ParseSource(source)
+	if got, want := module.Markdown(), "String helpers."; got != want {
+		t.Fatalf("module docs = %q, want %q", got, want)
+	}
+	got := byLine[8].Markdown()
+	for _, want := range []string{"Frees an allocated string.", "`a` — allocator", "`s` — allocated slice", "**Returns:** nothing"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("declaration docs %q do not contain %q", got, want)
+		}
+	}
+	if len(byLine) != 1 {
+		t.Fatalf("parsed %d declaration doc blocks, want 1", len(byLine))
+	}
+}
+
+func TestModuleDocumentationMustImmediatelyFollowModule(t *testing.T) {
+	_, module := ParseSource("mod sample\n\n# Not module docs.\nThing()\n")
+	if got := module.Markdown(); got != "" {
+		t.Fatalf("module docs = %q, want none", got)
+	}
+}
+
+func TestDocumentationSkipsDirectivesBeforeDeclaration(t *testing.T) {
+	byLine, _ := ParseSource("mod sample\n\n# A point.\n@derive(\"eq\")\n@allow(\"unused-function\")\nPoint(x i32)\n")
+	if got := byLine[6].Markdown(); got != "A point." {
+		t.Fatalf("docs = %q, want the block above the directives", got)
+	}
+}
+
+func TestDocumentationTagsRenderAsMarkdown(t *testing.T) {
+	doc := ParseBlock([]string{
+		"Allocates a resource.",
+		"@warning The memory is uninitialized.",
+		"@note Allocation depends on the backend.",
+		"@complexity O(N) for zeroing.",
+		"@throws outOfMemory if allocation fails",
+		"@ownership The caller owns the return value.",
+		"@safety byteCount must fit in addressable memory.",
+		"@mustcall free",
+		"@platform windows, linux",
+		"@deprecated Use allocZero instead.",
+		"@see allocZero",
+		"@example",
+		"  block := try alloc(a, 16)",
+		"  a.free(block)",
+	})
+	got := doc.Markdown()
+	wants := []string{
+		"> **⚠ Warning:** The memory is uninitialized.",
+		"> **Note:** Allocation depends on the backend.",
+		"**Complexity:** O(N) for zeroing.",
+		"**Throws:** outOfMemory if allocation fails",
+		"**Ownership:** The caller owns the return value.",
+		"> **Safety:** byteCount must fit in addressable memory.",
+		"**Must call:** `free`",
+		"**Platforms:** windows, linux",
+		"> **Deprecated:** Use allocZero instead.",
+		"**See also:** `allocZero`",
+		"```magma\nblock := try alloc(a, 16)\na.free(block)\n```",
+	}
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered docs do not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestMethodDocumentationUsesMemberTokenLine(t *testing.T) {
+	methodName := &types.NodeNameComposite{
+		Parts: []string{"Allocator", "alloc"},
+		Tokens: []types.Token{
+			{Repr: "Allocator", Type: types.TokName, Pos: types.FilePos{Line: 12, Col: 1}},
+			{Repr: "alloc", Type: types.TokName, Pos: types.FilePos{Line: 12, Col: 11}},
+		},
+	}
+	if got, want := DeclarationLine(methodName), uint32(12); got != want {
+		t.Fatalf("method documentation line = %d, want %d", got, want)
+	}
+}
+
+func TestTagConsumesLinesUntilNextTag(t *testing.T) {
+	doc := ParseBlock([]string{
+		"Returns a str from a pointer and a length in bytes.",
+		"@warning This ties the lifetime of the input pointer to the output Magma str,",
+		"if the input pointer is deallocated, it will result in invalid reads.",
+		"Prefer using fromPtr when unsure about lifetimes.",
+		"@complexity O(1)",
+		"@param s input string",
+	})
+	got := doc.Markdown()
+	warning := "> **⚠ Warning:** This ties the lifetime of the input pointer to the output Magma str,\n" +
+		"> if the input pointer is deallocated, it will result in invalid reads.\n" +
+		"> Prefer using fromPtr when unsure about lifetimes."
+	if !strings.Contains(got, warning) {
+		t.Fatalf("multiline warning was not kept together:\n%s", got)
+	}
+	if !strings.Contains(got, "**Complexity:** O(1)") || !strings.Contains(got, "`s` — input string") {
+		t.Fatalf("following tags were not parsed independently:\n%s", got)
+	}
+	if strings.Contains(got, "output Magma str,\nif the input") {
+		t.Fatalf("warning continuation leaked into plain description:\n%s", got)
+	}
+}
@@ -0,0 +1,571 @@
+package docgen
+
+import (
+	"Magma/src/types"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const stage = "documentation"
+
+// segment is a run of signature text. Target, when set, is the generated
+// HTML location of the declaration the text names.
+type segment struct {
+	text   string
+	target string
+}
+
+type signature []segment
+
+func (s signature) String() string {
+	var out strings.Builder
+	for _, part := range s {
+		out.WriteString(part.text)
+	}
+	return out.String()
+}
+
+// entry is one documented declaration. Methods are listed under their type.
+type entry struct {
+	name      string
+	kind      string
+	signature signature
+	doc       Documentation
+	members   []*entry
+}
+
+type section struct {
+	title   string
+	entries []*entry
+}
+
+// module is one documented compilation unit. Title is its import spelling,
+// such as `std/net/tcp` or `geometry/shapes`, and page is the stem of its
+// generated files.
+type module struct {
+	file     *types.FileCtx
+	title    string
+	page     string
+	doc      Documentation
+	sections []section
+}
+
+type generator struct {
+	state   *types.SharedState
+	modules []*module
+	byPath  map[string]*module
+}
+
+// Generate writes reference documentation for the root compilation unit and
+// the modules it imports into outDir: one Markdown page per module with an
+// index, and an HTML site under outDir/html with cross-linked signatures and a
+// search index. Standard-library imports are documented only when the root is
+// itself part of the standard library.
+//
+// Public declarations without docs and `@param` tags which name no parameter
+// are reported as warnings on state.
+func Generate(state *types.SharedState, rootPath, outDir string) error {
+	g := &generator{state: state, byPath: map[string]*module{}}
+	g.collect(rootPath)
+	for _, m := range g.modules {
+		g.document(m)
+	}
+	if err := os.MkdirAll(filepath.Join(outDir, "html"), 0777); err != nil {
+		return fmt.Errorf("create documentation directory: %w", err)
+	}
+	files := map[string]string{"index.md": g.markdownIndex()}
+	for _, m := range g.modules {
+		files[m.page+".md"] = m.markdown()
+		files[filepath.Join("html", m.page+".html")] = g.htmlModule(m)
+	}
+	files[filepath.Join("html", "index.html")] = g.htmlIndex()
+	files[filepath.Join("html", "search-index.js")] = g.searchIndex()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(outDir, name), []byte(content), 0666); err != nil {
+			return fmt.Errorf("write documentation: %w", err)
+		}
+	}
+	return nil
+}
+
+// collect selects the modules reachable from the root through `use`
+// declarations and assigns their titles and page names.
+func (g *generator) collect(rootPath string) {
+	rootInStd := g.inStandardLibrary(rootPath)
+	base := filepath.Dir(rootPath)
+	if rootInStd {
+		base = g.state.StdRoot
+	}
+	seen := map[string]bool{}
+	pending := []string{rootPath}
+	for len(pending) != 0 {
+		path := pending[0]
+		pending = pending[1:]
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		file := g.state.Files[path]
+		if file == nil || file.GlNode == nil || g.inStandardLibrary(path) != rootInStd {
+			continue
+		}
+		stem, err := filepath.Rel(base, strings.TrimSuffix(path, filepath.Ext(path)))
+		if err != nil || stem == ".." || strings.HasPrefix(stem, ".."+string(filepath.Separator)) {
+			stem = file.ModuleName
+		}
+		stem = filepath.ToSlash(stem)
+		m := &module{file: file, title: stem, page: strings.ReplaceAll(stem, "/", "-")}
+		if rootInStd {
+			m.title = "std/" + stem
+		}
+		g.modules = append(g.modules, m)
+		g.byPath[path] = m
+		pending = append(pending, file.Imports...)
+	}
+	sort.Slice(g.modules, func(i, j int) bool { return g.modules[i].title < g.modules[j].title })
+	used := map[string]bool{"index": true}
+	for _, m := range g.modules {
+		page := m.page
+		for n := 2; used[page]; n++ {
+			page = fmt.Sprintf("%s-%d", m.page, n)
+		}
+		used[page] = true
+		m.page = page
+	}
+}
+
+func (g *generator) inStandardLibrary(path string) bool {
+	if g.state.StdRoot == "" {
+		return false
+	}
+	relative, err := filepath.Rel(g.state.StdRoot, path)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// document builds the sections of a module from its public declarations, in
+// source order, and reports documentation warnings for them.
+func (g *generator) document(m *module) {
+	byLine, moduleDoc := ParseSource(string(m.file.Content))
+	m.doc = moduleDoc
+	root := m.file.GlNode
+	var typeEntries, aliases, constants, globals, functions []*entry
+	owners := map[string]*entry{}
+	for _, declaration := range root.Declarations {
+		switch node := declaration.(type) {
+		case *types.NodeStructDef:
+			name := flattenName(node.Class.NameNode)
+			definition := root.StructDefs[name]
+			if !node.IsPublic || definition == nil {
+				continue
+			}
+			item := &entry{name: name, kind: "struct", doc: byLine[DeclarationLine(node.Class.NameNode)]}
+			if definition.IsProto {
+				item.kind = "proto"
+			}
+			item.signature = g.structSignature(m.file, node, definition)
+			g.checkMissing(m, item, nameToken(node.Class.NameNode))
+			owners[name] = item
+			typeEntries = append(typeEntries, item)
+		case *types.NodeFuncDef:
+			if node.ProtoDispatch != nil {
+				continue
+			}
+			name := flattenName(node.Class.NameNode)
+			item := &entry{name: name, kind: "function", signature: g.functionSignature(m.file, node), doc: byLine[DeclarationLine(node.Class.NameNode)]}
+			if composite, ok := node.Class.NameNode.(*types.NodeNameComposite); ok && node.IsMember {
+				item.kind = "method"
+				if owner := owners[composite.Parts[0]]; owner != nil {
+					if node.Derived {
+						// Derived members share the directive's line and
+						// have no comment block of their own.
+						item.doc = ParseBlock([]string{derivedDocs[composite.Parts[len(composite.Parts)-1]]})
+						owner.members = append(owner.members, item)
+						continue
+					}
+					g.checkFunction(m, item, node)
+					owner.members = append(owner.members, item)
+					continue
+				}
+				// Methods of primitive types are visible wherever the module
+				// is; methods of private structs are not.
+				if root.StructDefs[composite.Parts[0]] != nil {
+					continue
+				}
+			} else if !node.IsPublic {
+				continue
+			}
+			g.checkFunction(m, item, node)
+			functions = append(functions, item)
+		case *types.NodeTypeAlias:
+			if node.Alias == nil || !node.Alias.IsPublic {
+				continue
+			}
+			item := &entry{name: node.Alias.Name, kind: "alias", doc: byLine[node.Alias.Tk.Pos.Line]}
+			b := &signatureBuilder{g: g, file: m.file}
+			b.text("pub alias " + node.Alias.Name + " = ")
+			b.typ(node.Alias.Target)
+			item.signature = b.out
+			g.checkMissing(m, item, node.Alias.Tk)
+			aliases = append(aliases, item)
+		case *types.NodeConstDef:
+			if node.VarDef == nil || !node.VarDef.IsPublic {
+				continue
+			}
+			item := g.variable(m, node.VarDef, "constant", "pub const ", byLine)
+			constants = append(constants, item)
+		case *types.NodeExprVarDef:
+			if !node.IsPublic {
+				continue
+			}
+			item := g.variable(m, node, "global", "pub ", byLine)
+			globals = append(globals, item)
+		}
+	}
+	for _, s := range []section{{"Types", typeEntries}, {"Aliases", aliases}, {"Constants", constants}, {"Globals", globals}, {"Functions", functions}} {
+		if len(s.entries) != 0 {
+			m.sections = append(m.sections, s)
+		}
+	}
+}
+
+// derivedDocs describe the members synthesized by `@derive`, by member name.
+var derivedDocs = map[string]string{
+	"eq":        "Reports whether every field equals the same field of other. Derived by `@derive(\"eq\")`.",
+	"hash":      "Combines the hashes of every field. Derived by `@derive(\"hash\")`.",
+	"format":    "Appends `Name(field=value, ...)` to f. Derived by `@derive(\"format\")`.",
+	"writeJson": "Writes a JSON object with one member per field. Derived by `@derive(\"json\")`.",
+}
+
+func (g *generator) variable(m *module, variable *types.NodeExprVarDef, kind, prefix string, byLine map[uint32]Documentation) *entry {
+	name := flattenName(variable.Name)
+	b := &signatureBuilder{g: g, file: m.file}
+	b.text(prefix + name)
+	if variable.Type != nil {
+		b.text(" ")
+		b.typ(variable.Type)
+	}
+	item := &entry{name: name, kind: kind, signature: b.out, doc: byLine[DeclarationLine(variable.Name)]}
+	g.checkMissing(m, item, nameToken(variable.Name))
+	return item
+}
+
+// checkMissing reports a public declaration without docs. Declarations
+// without a source token, such as external bindings, cannot carry docs.
+func (g *generator) checkMissing(m *module, item *entry, token types.Token) {
+	if item.doc.Markdown() != "" || token.Pos.Line == 0 {
+		return
+	}
+	g.warn(m, "missing-docs", token,
+		fmt.Sprintf("public %s '%s' has no documentation", item.kind, item.name),
+		"add a '#' comment block on the lines directly above the declaration")
+}
+
+// checkFunction also reports `@param` tags naming no parameter of the
+// function. The receiver of a method is not a documented parameter.
+func (g *generator) checkFunction(m *module, item *entry, function *types.NodeFuncDef) {
+	token := nameToken(function.Class.NameNode)
+	g.checkMissing(m, item, token)
+	names := []string{}
+	for i, arg := range function.Class.ArgsNode.Args {
+		if i == 0 && function.IsMember {
+			continue
+		}
+		names = append(names, arg.Name)
+	}
+	for _, param := range item.doc.params {
+		if slices.Contains(names, param.name) {
+			continue
+		}
+		additional := fmt.Sprintf("'%s' takes no parameters", item.name)
+		if len(names) != 0 {
+			additional = "parameters: " + strings.Join(names, ", ")
+		}
+		g.warn(m, "unknown-doc-param", token,
+			fmt.Sprintf("@param '%s' does not name a parameter of '%s'", param.name, item.name),
+			additional)
+	}
+}
+
+func (g *generator) warn(m *module, code string, token types.Token, message, additional string) {
+	g.state.Warn(types.Diagnostic{
+		Severity:   types.SeverityWarning,
+		Code:       code,
+		Stage:      stage,
+		Ctx:        m.file,
+		FilePath:   m.file.FilePath,
+		Token:      token,
+		Message:    message,
+		ShortDesc:  message,
+		Additional: additional,
+	})
+}
+
+// typeTarget returns the HTML location documenting a named type, or "" when
+// the type is a primitive, a type parameter, or not documented.
+func (g *generator) typeTarget(file *types.FileCtx, name types.NodeName) string {
+	path, simple := file.FilePath, ""
+	switch node := name.(type) {
+	case *types.NodeNameSingle:
+		simple = node.Name
+	case *types.NodeNameComposite:
+		if len(node.Parts) != 2 {
+			return ""
+		}
+		path, simple = file.ImportAlias[node.Parts[0]], node.Parts[1]
+	}
+	m := g.byPath[path]
+	if m == nil {
+		return ""
+	}
+	root := m.file.GlNode
+	definition, isStruct := root.StructDefs[simple]
+	alias, isAlias := root.TypeAliases[simple]
+	if isStruct && definition.IsPublic || isAlias && alias.IsPublic {
+		return m.page + ".html#" + simple
+	}
+	return ""
+}
+
+// signatureBuilder renders declarations in Magma syntax.
+type signatureBuilder struct {
+	g    *generator
+	file *types.FileCtx
+	out  signature
+}
+
+func (b *signatureBuilder) text(text string) {
+	if n := len(b.out); n != 0 && b.out[n-1].target == "" {
+		b.out[n-1].text += text
+		return
+	}
+	b.out = append(b.out, segment{text: text})
+}
+
+func (b *signatureBuilder) typ(node *types.NodeType) {
+	if node == nil {
+		return
+	}
+	if node.Throws {
+		b.text("!")
+	}
+	function, isFunction := node.KindNode.(*types.NodeTypeFunc)
+	if isFunction && function.ContextABI == types.ContextABIContextless {
+		b.text("noctx ")
+	}
+	if node.Owned {
+		b.text("$")
+	}
+	if isFunction {
+		b.functionType(function)
+		return
+	}
+	b.kind(node.KindNode)
+}
+
+func (b *signatureBuilder) kind(kind types.NodeTypeKind) {
+	switch node := kind.(type) {
+	case *types.NodeTypeNamed:
+		name := flattenName(node.NameNode)
+		// Derived members name std:derive's re-exports through a hidden
+		// alias; show them as the module the user knows.
+		name = strings.TrimPrefix(name, types.DeriveImport+".")
+		if target := b.g.typeTarget(b.file, node.NameNode); target != "" {
+			b.out = append(b.out, segment{text: name, target: target})
+		} else {
+			b.text(name)
+		}
+		b.typeArgs(node.GenericArgs)
+	case *types.NodeTypePointer:
+		b.kind(node.Kind)
+		b.text("*")
+	case *types.NodeTypeRfc:
+		b.kind(node.Kind)
+		b.text("$")
+	case *types.NodeTypeSlice:
+		b.kind(node.ElemKind)
+		b.text("[]")
+	case *types.NodeTypeFunc:
+		if node.ContextABI == types.ContextABIContextless {
+			b.text("noctx ")
+		}
+		b.functionType(node)
+	case *types.NodeTypeCompilerKnown:
+		b.text(`@compiler_known_type("` + node.Name + `")`)
+	default:
+		b.text(types.DisplayType(&types.NodeType{KindNode: kind}))
+	}
+}
+
+func (b *signatureBuilder) typeArgs(args []*types.NodeType) {
+	if len(args) == 0 {
+		return
+	}
+	b.text("[")
+	for i, arg := range args {
+		if i != 0 {
+			b.text(", ")
+		}
+		b.typ(arg)
+	}
+	b.text("]")
+}
+
+func (b *signatureBuilder) functionType(function *types.NodeTypeFunc) {
+	b.text("(")
+	for i, arg := range function.Args {
+		if i != 0 {
+			b.text(", ")
+		}
+		b.typ(arg)
+	}
+	b.text(") ")
+	b.typ(function.RetType)
+}
+
+func (b *signatureBuilder) args(args []types.NodeArg) {
+	b.text("(")
+	for i, arg := range args {
+		if i != 0 {
+			b.text(", ")
+		}
+		b.text(arg.Name + " ")
+		b.typ(arg.TypeNode)
+		if arg.DefaultSource != "" {
+			b.text(" = " + arg.DefaultSource)
+		}
+	}
+	b.text(")")
+}
+
+func (g *generator) functionSignature(file *types.FileCtx, function *types.NodeFuncDef) signature {
+	b := &signatureBuilder{g: g, file: file}
+	if function.IsPublic && !function.IsMember {
+		b.text("pub ")
+	}
+	args := function.Class.ArgsNode.Args
+	name := flattenName(function.Class.NameNode)
+	switch {
+	case function.IsExternal:
+		b.text("ext " + name + " " + function.NoAliasName)
+	case function.IsMember:
+		if function.IsDestructor {
+			b.text("destr ")
+		}
+		if function.ContextABI == types.ContextABIContextless {
+			b.text("noctx ")
+		}
+		owner, method, _ := strings.Cut(name, ".")
+		b.text(owner + typeParams(function.Class.OwnerTypeParams) + "." + method)
+		args = args[1:]
+	default:
+		if function.ContextABI == types.ContextABIContextless {
+			b.text("noctx ")
+		}
+		b.text(name)
+	}
+	b.text(typeParams(function.Class.TypeParams))
+	b.args(args)
+	b.text(" ")
+	b.typ(function.ReturnType)
+	return b.out
+}
+
+func (g *generator) structSignature(file *types.FileCtx, node *types.NodeStructDef, definition *types.StructDef) signature {
+	b := &signatureBuilder{g: g, file: file}
+	b.text("pub ")
+	if definition.IsProto {
+		b.text("proto ")
+	}
+	b.text(definition.Name + typeParams(node.Class.TypeParams))
+	if definition.IsProto {
+		for i, base := range definition.Proto.Extends {
+			if i == 0 {
+				b.text(" extends ")
+			} else {
+				b.text(", ")
+			}
+			b.typ(base.Type)
+		}
+	}
+	wroteImpl := false
+	for _, implementation := range definition.Implements {
+		if implementation.Implied {
+			continue
+		}
+		if !wroteImpl {
+			b.text(" impl")
+			wroteImpl = true
+		}
+		b.text(" ")
+		b.typ(implementation.Type)
+	}
+	b.text("(")
+	if definition.IsProto {
+		for _, method := range definition.Proto.Methods {
+			b.text("\n    ")
+			if method.ContextABI == types.ContextABIContextless {
+				b.text("noctx ")
+			}
+			b.text(method.Name)
+			b.args(method.Args)
+			b.text(" ")
+			b.typ(method.Ret)
+		}
+	} else {
+		for _, field := range node.Class.ArgsNode.Args {
+			b.text("\n    " + field.Name + " ")
+			b.typ(field.TypeNode)
+			if field.DefaultSource != "" {
+				b.text(" = " + field.DefaultSource)
+			}
+		}
+	}
+	if len(b.out) != 0 && strings.HasSuffix(b.out[len(b.out)-1].text, "(") {
+		b.text(")")
+	} else {
+		b.text("\n)")
+	}
+	return b.out
+}
+
+func typeParams(params []string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+func flattenName(name types.NodeName) string {
+	switch node := name.(type) {
+	case *types.NodeNameSingle:
+		return node.Name
+	case *types.NodeNameComposite:
+		parts := slices.Clone(node.Parts)
+		// A default prototype method is documented as the requirement.
+		if requirement, ok := types.ProtoDefaultRequirement(parts[len(parts)-1]); ok {
+			parts[len(parts)-1] = requirement
+		}
+		return strings.Join(parts, ".")
+	}
+	return ""
+}
+
+// summary returns the first paragraph of a description for index listings.
+func (d Documentation) summary() string {
+	lines := []string{}
+	for _, line := range d.description {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(lines) != 0 {
+				break
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
@@ -0,0 +1,149 @@
+package docgen
+
+import (
+	"Magma/src/types"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Example is one `@example` block wrapped in a standalone program. The
+// program imports the documenting module under its module name, hoists any
+// leading `use` lines of the example, and runs the remaining lines as the
+// body of `main() !void`.
+type Example struct {
+	// File is the documenting module and Line the source line of the
+	// example's first line.
+	File    *types.FileCtx
+	Line    uint32
+	Program string
+	origins []origin
+}
+
+// origin maps a line of the synthesized program back to the doc comment.
+// Wrapper lines have no origin line.
+type origin struct {
+	line  uint32
+	shift int
+}
+
+// Examples returns the examples of every module Generate would document, by
+// module and source line. Modules named main cannot be imported and are
+// skipped.
+func Examples(state *types.SharedState, rootPath string) []*Example {
+	g := &generator{state: state, byPath: map[string]*module{}}
+	g.collect(rootPath)
+	examples := []*Example{}
+	for path, m := range g.byPath {
+		if m.file.ModuleName == "main" {
+			continue
+		}
+		specifier := filepath.ToSlash(path)
+		if g.inStandardLibrary(path) {
+			relative, _ := filepath.Rel(state.StdRoot, strings.TrimSuffix(path, filepath.Ext(path)))
+			specifier = "std:" + filepath.ToSlash(relative)
+		}
+		byLine, moduleDoc := ParseSource(string(m.file.Content))
+		docs := []Documentation{moduleDoc}
+		for _, doc := range byLine {
+			docs = append(docs, doc)
+		}
+		source := strings.Split(strings.ReplaceAll(string(m.file.Content), "\r\n", "\n"), "\n")
+		for _, doc := range docs {
+			for i, text := range doc.examples {
+				examples = append(examples, newExample(m.file, specifier, doc.exampleLines[i], text, source))
+			}
+		}
+	}
+	sort.Slice(examples, func(i, j int) bool {
+		if examples[i].File.FilePath != examples[j].File.FilePath {
+			return examples[i].File.FilePath < examples[j].File.FilePath
+		}
+		return examples[i].Line < examples[j].Line
+	})
+	return examples
+}
+
+func newExample(file *types.FileCtx, specifier string, line uint32, text string, source []string) *Example {
+	e := &Example{File: file, Line: line}
+	var program strings.Builder
+	emit := func(code string, from uint32, indent string) {
+		program.WriteString(indent + code + "\n")
+		o := origin{line: from}
+		if from != 0 && int(from) <= len(source) {
+			// Columns are counted in runes past the comment prefix.
+			sourceLine := source[from-1]
+			prefix := max(strings.Index(sourceLine, code), 0)
+			o.shift = utf8.RuneCountInString(sourceLine[:prefix]) - utf8.RuneCountInString(indent)
+		}
+		e.origins = append(e.origins, o)
+	}
+	emit("mod main", 0, "")
+	emit(`use "`+specifier+`" `+file.ModuleName, 0, "")
+	lines := strings.Split(text, "\n")
+	body := 0
+	for body < len(lines) && strings.HasPrefix(lines[body], "use ") {
+		emit(lines[body], line+uint32(body), "")
+		body++
+	}
+	emit("main() !void:", 0, "")
+	for i := body; i < len(lines); i++ {
+		emit(lines[i], line+uint32(i), "    ")
+	}
+	emit("..", 0, "")
+	e.Program = program.String()
+	return e
+}
+
+// Locate moves a diagnostic reported in the example's program, written to
+// programPath, onto the doc comment it came from. Wrapper lines are reported
+// at the example's first line; diagnostics in other files are unchanged.
+// Positions which the compiler spelled into the text of a message are moved
+// the same way.
+func (e *Example) Locate(d *types.Diagnostic, programPath string) {
+	if d.FilePath != programPath {
+		return
+	}
+	d.Ctx, d.FilePath = e.File, e.File.FilePath
+	d.Token.Pos, d.Token.End = e.position(d.Token.Pos), e.position(d.Token.End)
+	d.End = e.position(d.End)
+	d.Message, d.ShortDesc, d.Additional = e.locateText(d.Message), e.locateText(d.ShortDesc), e.locateText(d.Additional)
+	for _, events := range [][]types.DiagnosticRelated{d.Related, d.Trace} {
+		for i := range events {
+			if events[i].FilePath == "" || events[i].FilePath == programPath {
+				events[i].FilePath = e.File.FilePath
+				events[i].Token.Pos, events[i].Token.End = e.position(events[i].Token.Pos), e.position(events[i].Token.End)
+				events[i].Message = e.locateText(events[i].Message)
+			}
+		}
+	}
+}
+
+// textPosition matches the "line L, column C" form in which diagnostics
+// mention a second position of the same file.
+var textPosition = regexp.MustCompile(`line (\d+), column (\d+)`)
+
+func (e *Example) locateText(text string) string {
+	return textPosition.ReplaceAllStringFunc(text, func(match string) string {
+		parts := textPosition.FindStringSubmatch(match)
+		line, _ := strconv.ParseUint(parts[1], 10, 32)
+		column, _ := strconv.ParseUint(parts[2], 10, 32)
+		pos := e.position(types.FilePos{Line: uint32(line), Col: uint32(column)})
+		return fmt.Sprintf("line %d, column %d", pos.Line, pos.Col)
+	})
+}
+
+func (e *Example) position(pos types.FilePos) types.FilePos {
+	if pos.Line == 0 {
+		return pos
+	}
+	if int(pos.Line) > len(e.origins) || e.origins[pos.Line-1].line == 0 {
+		return types.FilePos{Line: e.Line, Col: 1}
+	}
+	o := e.origins[pos.Line-1]
+	return types.FilePos{Line: o.line, Col: uint32(max(int(pos.Col)+o.shift, 1))}
+}
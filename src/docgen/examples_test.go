@@ -0,0 +1,58 @@
+package docgen
+
+import (
+	"Magma/src/types"
+	"strings"
+	"testing"
+)
+
+func TestExamplesWrapBlocksInMainPrograms(t *testing.T) {
+	source := `mod shapes
+# Rectangle helpers.
+# @example
+#   use "std:io" io
+#   io.print("shapes")
+
+# Returns the area.
+# @example
+#   size := shapes.area(2, 3)
+pub area(w u64, h u64) u64:
+    ret w * h
+..
+`
+	file := &types.FileCtx{FilePath: "/project/shapes.mg", ModuleName: "shapes", Content: []byte(source), GlNode: &types.NodeGlobal{}}
+	state := &types.SharedState{Files: map[string]*types.FileCtx{file.FilePath: file}}
+	examples := Examples(state, file.FilePath)
+	if len(examples) != 2 {
+		t.Fatalf("found %d examples, want 2", len(examples))
+	}
+	module, area := examples[0], examples[1]
+	if module.Line != 4 || area.Line != 9 {
+		t.Fatalf("example lines = %d, %d, want 4, 9", module.Line, area.Line)
+	}
+	want := "mod main\nuse \"/project/shapes.mg\" shapes\nuse \"std:io\" io\nmain() !void:\n    io.print(\"shapes\")\n..\n"
+	if module.Program != want {
+		t.Fatalf("program = %q, want %q", module.Program, want)
+	}
+	if !strings.Contains(area.Program, "main() !void:\n    size := shapes.area(2, 3)\n..") {
+		t.Fatalf("program does not wrap the example in main:\n%s", area.Program)
+	}
+
+	// The `#   ` comment prefix is as wide as the body indentation, so
+	// `shapes` is at column 13 of both lines.
+	diagnostic := &types.Diagnostic{FilePath: "/tmp/example.mg", Token: types.Token{Pos: types.FilePos{Line: 4, Col: 13}}}
+	area.Locate(diagnostic, "/tmp/example.mg")
+	if diagnostic.Ctx != file || diagnostic.Token.Pos.Line != 9 || diagnostic.Token.Pos.Col != 13 {
+		t.Fatalf("located at %s:%d:%d, want %s:9:13", diagnostic.FilePath, diagnostic.Token.Pos.Line, diagnostic.Token.Pos.Col, file.FilePath)
+	}
+	wrapper := &types.Diagnostic{FilePath: "/tmp/example.mg", Token: types.Token{Pos: types.FilePos{Line: 5, Col: 1}}}
+	area.Locate(wrapper, "/tmp/example.mg")
+	if wrapper.Token.Pos.Line != 9 {
+		t.Fatalf("wrapper diagnostic located at line %d, want the example's first line", wrapper.Token.Pos.Line)
+	}
+	mentioned := &types.Diagnostic{FilePath: "/tmp/example.mg", Message: "generic member call at line 4, column 13: failed", Related: []types.DiagnosticRelated{{Message: "previous move at line 5, column 1"}}}
+	area.Locate(mentioned, "/tmp/example.mg")
+	if mentioned.Message != "generic member call at line 9, column 13: failed" || mentioned.Related[0].Message != "previous move at line 9, column 1" {
+		t.Fatalf("message positions = %q, %q, want lines of the doc comment", mentioned.Message, mentioned.Related[0].Message)
+	}
+}
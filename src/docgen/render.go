@@ -0,0 +1,286 @@
+package docgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+func (m *module) markdown() string {
+	parts := []string{"# `" + m.title + "`"}
+	if text := m.doc.Markdown(); text != "" {
+		parts = append(parts, text)
+	}
+	if len(m.sections) == 0 {
+		parts = append(parts, "This module has no public declarations.")
+	}
+	for _, s := range m.sections {
+		parts = append(parts, "## "+s.title)
+		for _, item := range s.entries {
+			parts = append(parts, item.markdown("###")...)
+			for _, member := range item.members {
+				parts = append(parts, member.markdown("####")...)
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+func (e *entry) markdown(heading string) []string {
+	parts := []string{heading + " `" + e.name + "`", "```magma\n" + e.signature.String() + "\n```"}
+	if text := e.doc.Markdown(); text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+func (g *generator) markdownIndex() string {
+	lines := []string{"# API reference", ""}
+	for _, m := range g.modules {
+		line := fmt.Sprintf("- [`%s`](%s.md)", m.title, m.page)
+		if summary := m.doc.summary(); summary != "" {
+			line += " — " + summary
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+const stylesheet = `body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 0 auto; padding: 1rem 2rem; line-height: 1.5; }
+code, pre { font-family: ui-monospace, monospace; }
+pre { background: #f4f4f4; padding: 0.75rem; overflow-x: auto; }
+blockquote { border-left: 4px solid #ccc; margin: 0; padding-left: 1rem; }
+section.member { margin-left: 1.5rem; }
+nav ul { columns: 2; }
+#search { width: 100%; font-size: 1rem; padding: 0.4rem; }`
+
+// searchScript filters the entries of search-index.js by name on the index
+// page.
+const searchScript = `const input = document.getElementById("search");
+const results = document.getElementById("results");
+input.addEventListener("input", () => {
+  const query = input.value.trim().toLowerCase();
+  results.replaceChildren();
+  if (query === "") {
+    return;
+  }
+  for (const item of searchIndex) {
+    if (!item.name.toLowerCase().includes(query)) {
+      continue;
+    }
+    const link = document.createElement("a");
+    link.href = item.url;
+    link.textContent = item.name;
+    const li = document.createElement("li");
+    li.append(link, " — " + item.kind + " in " + item.module);
+    if (item.summary !== "") {
+      li.append(": " + item.summary);
+    }
+    results.append(li);
+  }
+});`
+
+func htmlPage(title, body string) string {
+	return "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<title>" + html.EscapeString(title) +
+		"</title>\n<style>\n" + stylesheet + "\n</style>\n</head>\n<body>\n" + body + "</body>\n</html>\n"
+}
+
+func (g *generator) htmlIndex() string {
+	var body strings.Builder
+	body.WriteString("<h1>API reference</h1>\n")
+	body.WriteString("<input id=\"search\" type=\"search\" placeholder=\"Search declarations\" autocomplete=\"off\">\n<ul id=\"results\"></ul>\n")
+	body.WriteString("<h2>Modules</h2>\n<ul>\n")
+	for _, m := range g.modules {
+		fmt.Fprintf(&body, "<li><a href=\"%s.html\"><code>%s</code></a>", m.page, html.EscapeString(m.title))
+		if summary := m.doc.summary(); summary != "" {
+			body.WriteString(" — " + inlineHTML(summary))
+		}
+		body.WriteString("</li>\n")
+	}
+	body.WriteString("</ul>\n<script src=\"search-index.js\"></script>\n<script>\n" + searchScript + "\n</script>\n")
+	return htmlPage("API reference", body.String())
+}
+
+func (g *generator) htmlModule(m *module) string {
+	anchors := map[string]bool{}
+	for _, s := range m.sections {
+		for _, item := range s.entries {
+			anchors[item.name] = true
+			for _, member := range item.members {
+				anchors[member.name] = true
+			}
+		}
+	}
+	see := func(symbol string) string {
+		if anchors[symbol] {
+			return "#" + symbol
+		}
+		return ""
+	}
+	var body strings.Builder
+	body.WriteString("<p><a href=\"index.html\">API reference</a></p>\n")
+	fmt.Fprintf(&body, "<h1><code>%s</code></h1>\n", html.EscapeString(m.title))
+	body.WriteString(m.doc.html(see))
+	if len(m.sections) == 0 {
+		body.WriteString("<p>This module has no public declarations.</p>\n")
+	} else {
+		body.WriteString("<nav>\n<ul>\n")
+		for _, s := range m.sections {
+			for _, item := range s.entries {
+				fmt.Fprintf(&body, "<li><a href=\"#%s\">%s</a></li>\n", item.name, item.name)
+			}
+		}
+		body.WriteString("</ul>\n</nav>\n")
+	}
+	for _, s := range m.sections {
+		fmt.Fprintf(&body, "<h2>%s</h2>\n", s.title)
+		for _, item := range s.entries {
+			body.WriteString(item.html("h3", "declaration", see))
+			for _, member := range item.members {
+				body.WriteString(member.html("h4", "member", see))
+			}
+		}
+	}
+	return htmlPage(m.title, body.String())
+}
+
+func (e *entry) html(heading, class string, see func(string) string) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "<section class=\"%s\" id=\"%s\">\n<%s><a href=\"#%s\"><code>%s</code></a></%s>\n<pre><code>", class, e.name, heading, e.name, e.name, heading)
+	for _, part := range e.signature {
+		if part.target != "" {
+			fmt.Fprintf(&out, "<a href=\"%s\">%s</a>", part.target, html.EscapeString(part.text))
+		} else {
+			out.WriteString(html.EscapeString(part.text))
+		}
+	}
+	out.WriteString("</code></pre>\n" + e.doc.html(see) + "</section>\n")
+	return out.String()
+}
+
+type searchItem struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Module  string `json:"module"`
+	URL     string `json:"url"`
+	Summary string `json:"summary"`
+}
+
+// searchIndex is loaded with a script element rather than fetched, so the
+// site also works when opened from the file system.
+func (g *generator) searchIndex() string {
+	items := []searchItem{}
+	for _, m := range g.modules {
+		items = append(items, searchItem{Name: m.title, Kind: "module", Module: m.title, URL: m.page + ".html", Summary: m.doc.summary()})
+		for _, s := range m.sections {
+			for _, item := range s.entries {
+				items = append(items, searchItem{Name: item.name, Kind: item.kind, Module: m.title, URL: m.page + ".html#" + item.name, Summary: item.doc.summary()})
+				for _, member := range item.members {
+					items = append(items, searchItem{Name: member.name, Kind: member.kind, Module: m.title, URL: m.page + ".html#" + member.name, Summary: member.doc.summary()})
+				}
+			}
+		}
+	}
+	data, _ := json.MarshalIndent(items, "", "  ")
+	return "const searchIndex = " + string(data) + ";\n"
+}
+
+// html renders the documentation like Markdown, with `@see` symbols linked
+// when see resolves them.
+func (d Documentation) html(see func(string) string) string {
+	var out strings.Builder
+	for _, paragraph := range strings.Split(strings.TrimSpace(strings.Join(d.description, "\n")), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			out.WriteString("<p>" + inlineHTML(paragraph) + "</p>\n")
+		}
+	}
+	for _, text := range d.deprecated {
+		out.WriteString(htmlCallout("Deprecated", text))
+	}
+	for _, text := range d.warnings {
+		out.WriteString(htmlCallout("⚠ Warning", text))
+	}
+	for _, text := range d.safety {
+		out.WriteString(htmlCallout("Safety", text))
+	}
+	for _, text := range d.notes {
+		out.WriteString(htmlCallout("Note", text))
+	}
+	if len(d.params) != 0 {
+		out.WriteString("<p><strong>Parameters</strong></p>\n<ul>\n")
+		for _, param := range d.params {
+			out.WriteString("<li><code>" + html.EscapeString(param.name) + "</code>")
+			if param.text != "" {
+				out.WriteString(" — " + inlineHTML(param.text))
+			}
+			out.WriteString("</li>\n")
+		}
+		out.WriteString("</ul>\n")
+	}
+	if d.returns != "" {
+		out.WriteString("<p><strong>Returns:</strong> " + inlineHTML(d.returns) + "</p>\n")
+	}
+	out.WriteString(htmlList("Throws", d.throws, false))
+	out.WriteString(htmlList("Ownership", d.ownership, false))
+	out.WriteString(htmlList("Must call", d.mustCall, true))
+	out.WriteString(htmlList("Complexity", d.complexity, false))
+	out.WriteString(htmlList("Platforms", d.platforms, false))
+	for _, example := range d.examples {
+		out.WriteString("<p><strong>Example</strong></p>\n<pre><code class=\"language-magma\">" + html.EscapeString(example) + "</code></pre>\n")
+	}
+	if len(d.see) != 0 {
+		links := make([]string, 0, len(d.see))
+		for _, symbol := range d.see {
+			text := "<code>" + html.EscapeString(symbol) + "</code>"
+			if target := see(symbol); target != "" {
+				text = "<a href=\"" + target + "\">" + text + "</a>"
+			}
+			links = append(links, text)
+		}
+		out.WriteString("<p><strong>See also:</strong> " + strings.Join(links, ", ") + "</p>\n")
+	}
+	return out.String()
+}
+
+func htmlCallout(title, text string) string {
+	return "<blockquote><p><strong>" + title + ":</strong> " + strings.ReplaceAll(inlineHTML(text), "\n", "<br>\n") + "</p></blockquote>\n"
+}
+
+func htmlList(title string, values []string, codeValues bool) string {
+	if len(values) == 0 {
+		return ""
+	}
+	render := func(value string) string {
+		if codeValues {
+			return "<code>" + html.EscapeString(value) + "</code>"
+		}
+		return inlineHTML(value)
+	}
+	if len(values) == 1 {
+		return "<p><strong>" + title + ":</strong> " + render(values[0]) + "</p>\n"
+	}
+	out := "<p><strong>" + title + "</strong></p>\n<ul>\n"
+	for _, value := range values {
+		out += "<li>" + render(value) + "</li>\n"
+	}
+	return out + "</ul>\n"
+}
+
+// inlineHTML escapes text and renders its `code` spans.
+func inlineHTML(text string) string {
+	parts := strings.Split(text, "`")
+	var out strings.Builder
+	for i, part := range parts {
+		switch {
+		case i%2 == 0:
+			out.WriteString(html.EscapeString(part))
+		case i == len(parts)-1:
+			// An unmatched backtick is literal text.
+			out.WriteString("`" + html.EscapeString(part))
+		default:
+			out.WriteString("<code>" + html.EscapeString(part) + "</code>")
+		}
+	}
+	return out.String()
+}
@@ -2,6 +2,10 @@
 ; Use="miscellaneous utilities for bootstrapping magma"
 
 declare void @llvm.memset.p0i8.i64(ptr, i8, i64, i32, i1)
+declare half @llvm.trunc.f16(half)
+declare float @llvm.trunc.f32(float)
+declare double @llvm.trunc.f64(double)
+declare fp128 @llvm.trunc.f128(fp128)
 declare i64 @strlen(ptr nocapture readonly) nounwind
 declare i32 @printf(ptr, ...)
 
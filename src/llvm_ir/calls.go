@@ -344,6 +344,17 @@ func irExprCallFuncMember(ctx *IrCtx, fnCall *t.NodeExprCall, topLevel bool) (Ss
 			ownerSsa = allocSsa
 		}
 	}
+	callee := fnCall.AssociatedFnDef
+	if target := fnCall.Devirtualized; target != nil {
+		method := callee.ProtoDispatch
+		if target != method.Default {
+			implAddr, impl := irSsaLocal(ctx), irSsaLocal(ctx)
+			irWritef(ctx, "  %s = getelementptr inbounds %%struct.%s.%s, ptr %s, i32 0, i32 0\n", implAddr.Repr, method.Proto.Module, method.Proto.Name, ownerSsa.Repr)
+			irWritef(ctx, "  %s = load ptr, ptr %s\n", impl.Repr, implAddr.Repr)
+			ownerSsa = impl
+		}
+		callee = target
+	}
 	argsSsa = slices.Insert(argsSsa, 0, ownerSsa)
 
 	ssa := irSsaLocal(ctx)
@@ -366,10 +377,10 @@ func irExprCallFuncMember(ctx *IrCtx, fnCall *t.NodeExprCall, topLevel bool) (Ss
 	irWrite(ctx, " @")
 
 	//irWritef(ctx, "%s.", fnCall.MemberOwnerModule)
-	if fnCall.AssociatedFnDef.NoAliasName != "" {
+	if callee.NoAliasName != "" {
 		return SsaName{}, fmt.Errorf("cannot call aliased or external function as member function, something went terribly wrong.")
 	} else {
-		irWrite(ctx, fnCall.AssociatedFnDef.AbsName)
+		irWrite(ctx, callee.AbsName)
 	}
 
 	/*
@@ -417,7 +428,9 @@ func irExprFuncCall(ctx *IrCtx, fnCall *t.NodeExprCall, keepError bool, topLevel
 	var ssa = SsaName{}
 	var e error
 
-	if fnCall.IsFuncPointer {
+	if fnCall.AssociatedFnDef != nil && fnCall.AssociatedFnDef.Intrinsic != t.IntrinsicNone {
+		ssa, e = irIntrinsicCall(ctx, fnCall)
+	} else if fnCall.IsFuncPointer {
 		ssa, e = irExprCallFuncPtr(ctx, fnCall, topLevel)
 	} else if fnCall.IsMemberFunc {
 		ssa, e = irExprCallFuncMember(ctx, fnCall, topLevel)
@@ -11,6 +11,7 @@ import (
 	magmatarget "Magma/src/target"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -244,6 +245,103 @@ main() void:
 	}
 }
 
+func TestPrototypeDefaultMethodFillsOmittedSlot(t *testing.T) {
+	ir, err := compileSource(t, `mod main
+proto Counter(
+    count() u64
+    twice() u64
+)
+Counter.twice() u64:
+    ret this.count() * 2
+..
+Fixed impl Counter(value u64)
+Fixed.count() u64:
+    ret this.value
+..
+main() void:
+    fixed := Fixed(value=3)
+    counter := fixed.proto[Counter]()
+    counter.twice()
+..
+`)
+	if err != nil {
+		t.Fatalf("compile default prototype method: %v", err)
+	}
+	// Module symbols carry a hash suffix, matched by `\w*`.
+	for _, want := range []string{
+		`\{ ptr @main\w*\.Fixed\.count, ptr @main\w*\.Fixed\.__proto\.main\w*\.Counter\.twice \}`,
+		`define internal i64 @main\w*\.Fixed\.__proto\.main\w*\.Counter\.twice\(`,
+		`store ptr @main\w*\.Fixed\.__proto\.main\w*\.Counter, ptr %proto\.vtable\.addr`,
+		`call i64 @main\w*\.Counter\.__default_twice\(`,
+	} {
+		if !regexp.MustCompile(want).MatchString(ir) {
+			t.Fatalf("default method IR does not match %q:\n%s", want, ir)
+		}
+	}
+}
+
+func TestPrototypeExtendsInheritsRequirementsAndUpcasts(t *testing.T) {
+	ir, err := compileSource(t, `mod main
+proto Reader(read() u64)
+proto Writer(write(value u64) void)
+proto Duplex extends Reader, Writer(flush() void)
+Duplex.flush() void:
+    this.write(this.read())
+..
+Pipe impl Duplex(value u64)
+Pipe.read() u64:
+    ret this.value
+..
+Pipe.write(value u64) void:
+    this.value = value
+..
+main() void:
+    pipe := Pipe(value=1)
+    duplex := pipe.proto[Duplex]()
+    duplex.flush()
+    writer Writer = duplex.proto()
+    writer.write(duplex.read())
+..
+`)
+	if err != nil {
+		t.Fatalf("compile extending prototype: %v", err)
+	}
+	for _, want := range []string{
+		`@main\w*\.Pipe\.__proto\.main\w*\.Duplex = private constant %struct\.main\w*\.__proto_Duplex_vtable \{ ptr @main\w*\.Pipe\.__proto\.main\w*\.Duplex\.flush, ptr @main\w*\.Pipe\.__proto\.main\w*\.Reader, ptr @main\w*\.Pipe\.__proto\.main\w*\.Writer \}`,
+		`@main\w*\.Pipe\.__proto\.main\w*\.Writer = private constant`,
+		`define internal i64 @main\w*\.Duplex\.read\(`,
+		`getelementptr inbounds %struct\.main\w*\.__proto_Duplex_vtable, ptr %\S+, i32 0, i32 2`,
+	} {
+		if !regexp.MustCompile(want).MatchString(ir) {
+			t.Fatalf("extending prototype IR does not match %q:\n%s", want, ir)
+		}
+	}
+}
+
+func TestPrototypeExtendsDiagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "not a prototype", source: "Plain(value u64)\nproto Derived extends Plain()\n", want: "Plain' is not a prototype"},
+		{name: "cycle", source: "proto First extends Second()\nproto Second extends First()\n", want: "which extends it"},
+		{name: "repeated", source: "proto Base(run() void)\nproto Derived extends Base, Base()\n", want: "is extended more than once"},
+		{name: "ambiguous", source: "proto Left(run() void)\nproto Right(run() u64)\nproto Both extends Left, Right()\n", want: "inherits method 'run' from both 'Left' and 'Right'"},
+		{name: "redeclared", source: "proto Base(run() void)\nproto Derived extends Base(run() u64)\n", want: "returns 'u64', expected 'void'"},
+		{name: "default signature", source: "proto Base(run() void)\nBase.run(value u64) void:\n..\n", want: "expected 0 argument(s), got 1"},
+		{name: "missing", source: "proto Base(run() void)\nproto Derived extends Base()\nBroken impl Derived(value u64)\n", want: "missing method 'run'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := compileSource(t, "mod main\n"+test.source+"main() void:\n..\n")
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
 func TestReturnTypeProvidesShallowInferenceExpectation(t *testing.T) {
 	_, err := compileSource(t, `mod main
 proto Value(read() u64)
@@ -442,6 +540,86 @@ widen(a i16, b i64, c f32, d f64) f64:
 	}
 }
 
+func TestNumericCastsLowerRangeChecks(t *testing.T) {
+	ir, err := compileSource(t, `mod main
+
+convert(value i64, ratio f64) !u8:
+    wrapped := value as u8
+    clamped := ratio as saturating i32
+    signed := ratio as i32
+    ret try value as checked u8
+..
+`)
+	if err != nil {
+		t.Fatalf("compile numeric casts: %v", err)
+	}
+	for _, want := range []string{
+		"trunc i64",
+		"call double @llvm.trunc.f64",
+		"fcmp oge double",
+		"select i1",
+		"i32 2147483647",
+		"fptosi double",
+		"icmp sgt i64",
+		"i32 5, 1",
+		"@magma.error.push",
+	} {
+		if !strings.Contains(ir, want) {
+			t.Fatalf("numeric cast lowering is missing %q:\n%s", want, ir)
+		}
+	}
+}
+
+func TestRichLiteralsLowerToExactConstants(t *testing.T) {
+	ir, err := compileSource(t, `mod main
+
+literals() f64:
+    narrow f32 = 1.5e-3
+    tiny f16 = 0.1
+    wide f128 = 3.5
+    byte u8 = 'A'
+    mask u16 = 0b1111_0000
+    ret 2
+..
+`)
+	if err != nil {
+		t.Fatalf("compile literals: %v", err)
+	}
+	for _, want := range []string{
+		"store float 0x3F589374C0000000",
+		"store half 0x3FB9980000000000",
+		"store fp128 0xL00000000000000004000C00000000000",
+		"store i8 65",
+		"store i16 u0xF0",
+		"store double 0x4000000000000000",
+	} {
+		if !strings.Contains(ir, want) {
+			t.Fatalf("literal lowering is missing %q:\n%s", want, ir)
+		}
+	}
+}
+
+func TestInferredFractionalLiteralsAreF64(t *testing.T) {
+	ir, err := compileSource(t, `mod main
+
+literals() f64:
+    small := 1.5e-3
+    half := 0.5
+    large := 2E3
+    mask := 0x1E
+    ret small + half + large + mask
+..
+`)
+	if err != nil {
+		t.Fatalf("compile literals: %v", err)
+	}
+	for _, want := range []string{"store double 1.5e-3", "store double 0.5", "store double 2.0E3", "store i64 u0x1E"} {
+		if !strings.Contains(ir, want) {
+			t.Fatalf("inferred literal lowering is missing %q:\n%s", want, ir)
+		}
+	}
+}
+
 func TestRuntimeArrayExpressionLowersToStackBackedSlice(t *testing.T) {
 	ir, err := compileSource(t, `mod main
 
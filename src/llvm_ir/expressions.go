@@ -32,9 +32,12 @@ func irExprLitStr(ctx *IrCtx, litStr *t.NodeExprLit) (SsaName, error) {
 	return litSsa, nil
 }
 
-func irExprLitNum(ctx *IrCtx, litNum *t.NodeExprLit) (SsaName, error) {
+func irExprLitNum(ctx *IrCtx, litNum *t.NodeExprLit, expectedType *t.NodeType) (SsaName, error) {
 	ssa := ssaName(litNum.Value)
 	ssa.IsLiteral = true
+	if irType := numericIrType(expectedType); isFloatIrType(irType) {
+		ssa.Repr = floatLiteralConst(litNum.Value, irType)
+	}
 	return ssa, nil
 }
 
@@ -52,8 +55,8 @@ func irExprLit(ctx *IrCtx, lit *t.NodeExprLit, expectedType *t.NodeType) (SsaNam
 	switch lit.LitType {
 	case t.TokLitStr:
 		return irExprLitStr(ctx, lit)
-	case t.TokLitNum:
-		return irExprLitNum(ctx, lit)
+	case t.TokLitNum, t.TokLitChar:
+		return irExprLitNum(ctx, lit, expectedType)
 	case t.TokLitBool:
 		return irExprLitBool(ctx, lit)
 	case t.TokLitNone:
@@ -870,6 +873,8 @@ func irExpression(ctx *IrCtx, expectedType *t.NodeType, expr t.NodeExpr, topLeve
 		return irExprAddrof(ctx, ne)
 	case *t.NodeExprMove:
 		return irExpression(ctx, expectedType, ne.Expr, topLevel)
+	case *t.NodeExprCast:
+		return irExprCast(ctx, ne)
 	case *t.NodeExprName:
 		return irExprName(ctx, ne)
 	case *t.NodeExprMemberAccess:
@@ -468,6 +468,10 @@ func irFunDefAliased(ctx *IrCtx, fnDefNode *t.NodeFuncDef) error {
 }
 
 func irFuncDef(ctx *IrCtx, fnDefNode *t.NodeFuncDef) error {
+	if fnDefNode.Intrinsic != t.IntrinsicNone {
+		// Calls to an intrinsic are expanded in place.
+		return nil
+	}
 	if fnDefNode.NoAliasName != "" {
 		// func declared elsewhere, just emit declaration
 		if err := irFunDefAliased(ctx, fnDefNode); err != nil {
@@ -700,7 +704,15 @@ func irProtoDispatchFunc(ctx *IrCtx, fn *t.NodeFuncDef) error {
 	irWrite(ctx, "  %proto.impl = load ptr, ptr %proto.impl.addr\n")
 	irWritef(ctx, "  %%proto.vtable.addr = getelementptr inbounds %%struct.%s.%s, ptr %%this, i32 0, i32 1\n", method.Proto.Module, method.Proto.Name)
 	irWrite(ctx, "  %proto.vtable = load ptr, ptr %proto.vtable.addr\n")
-	irWritef(ctx, "  %%proto.slot.addr = getelementptr inbounds %%struct.%s.%s, ptr %%proto.vtable, i32 0, i32 %d\n", method.Proto.Module, method.Proto.VtableName, method.Slot)
+	vtable := "%proto.vtable"
+	for i := 0; method.Origin != nil; i++ {
+		// An inherited requirement is in the vtable of the base it comes from.
+		irWritef(ctx, "  %%proto.base%d.addr = getelementptr inbounds %%struct.%s.%s, ptr %s, i32 0, i32 %d\n", i, method.Proto.Module, method.Proto.VtableName, vtable, len(method.Proto.Methods)+method.Base)
+		irWritef(ctx, "  %%proto.base%d = load ptr, ptr %%proto.base%d.addr\n", i, i)
+		vtable = fmt.Sprintf("%%proto.base%d", i)
+		method = method.Origin
+	}
+	irWritef(ctx, "  %%proto.slot.addr = getelementptr inbounds %%struct.%s.%s, ptr %s, i32 0, i32 %d\n", method.Proto.Module, method.Proto.VtableName, vtable, method.Slot)
 	irWrite(ctx, "  %proto.fn = load ptr, ptr %proto.slot.addr\n  ")
 	returnsValue := !(isVoidType(fn.ReturnType) && !fn.ReturnType.Throws)
 	if returnsValue {
@@ -738,6 +750,62 @@ func irProtoDispatchFunc(ctx *IrCtx, fn *t.NodeFuncDef) error {
 	return nil
 }
 
+// irProtoDefaultAdapter emits the vtable entry for a requirement which an
+// implementation leaves to the prototype's default body. The default body
+// takes a view, so the adapter builds one from the implementation pointer and
+// the vtable it belongs to.
+func irProtoDefaultAdapter(ctx *IrCtx, symbol string, vtable string, method *t.ProtoMethod) error {
+	fn := method.Default
+	irWrite(ctx, "define internal ")
+	if err := irThrowingType(ctx, fn.ReturnType); err != nil {
+		return err
+	}
+	irWritef(ctx, " @%s", symbol)
+	if err := irArgsList(ctx, &fn.Class.ArgsNode, true, fn.ContextABI); err != nil {
+		return err
+	}
+	irWrite(ctx, " {\n")
+	irWritef(ctx, "  %%proto.view = alloca %%struct.%s.%s\n", method.Proto.Module, method.Proto.Name)
+	irWritef(ctx, "  %%proto.impl.addr = getelementptr inbounds %%struct.%s.%s, ptr %%proto.view, i32 0, i32 0\n", method.Proto.Module, method.Proto.Name)
+	irWrite(ctx, "  store ptr %this, ptr %proto.impl.addr\n")
+	irWritef(ctx, "  %%proto.vtable.addr = getelementptr inbounds %%struct.%s.%s, ptr %%proto.view, i32 0, i32 1\n", method.Proto.Module, method.Proto.Name)
+	irWritef(ctx, "  store ptr @%s, ptr %%proto.vtable.addr\n  ", vtable)
+	returnsValue := !(isVoidType(fn.ReturnType) && !fn.ReturnType.Throws)
+	if returnsValue {
+		irWrite(ctx, "%proto.result = ")
+	}
+	irWrite(ctx, "call ")
+	if err := irThrowingType(ctx, fn.ReturnType); err != nil {
+		return err
+	}
+	irWritef(ctx, " @%s(", fn.AbsName)
+	if fn.ContextABI == t.ContextABIContextful {
+		irWrite(ctx, "ptr %.ctx.in, ")
+	}
+	irWrite(ctx, "ptr %proto.view")
+	for i, arg := range fn.Class.ArgsNode.Args {
+		if i == 0 {
+			continue
+		}
+		irWrite(ctx, ", ")
+		if err := irType(ctx, arg.TypeNode); err != nil {
+			return err
+		}
+		irWritef(ctx, " %%%s", arg.Name)
+	}
+	irWrite(ctx, ")\n  ret ")
+	if returnsValue {
+		if err := irThrowingType(ctx, fn.ReturnType); err != nil {
+			return err
+		}
+		irWrite(ctx, " %proto.result\n")
+	} else {
+		irWrite(ctx, "void\n")
+	}
+	irWrite(ctx, "}\n")
+	return nil
+}
+
 func irExportWrapper(ctx *IrCtx, fn *t.NodeFuncDef) error {
 	return irCABIExportWrapper(ctx, fn)
 }
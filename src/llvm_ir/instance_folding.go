@@ -0,0 +1,240 @@
+package llvmir
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	t "Magma/src/types"
+)
+
+// specializationMarker appears in the symbol of every generic instantiation
+// and of the adapters lowered for it.
+const specializationMarker = "__g__"
+
+var (
+	irTypeDefinition = regexp.MustCompile(`^(%[-A-Za-z$._0-9]+) = type (.*)$`)
+	irIdentifier     = regexp.MustCompile(`[%@][-A-Za-z$._0-9]+`)
+	irLabel          = regexp.MustCompile(`^([-A-Za-z$._0-9]+):`)
+)
+
+// irFunction is one `define` in the joined module: lines[start] is its
+// header and lines[end] its closing brace.
+type irFunction struct {
+	symbol     string
+	start, end int
+}
+
+// foldIdenticalInstances replaces generic instantiations whose lowered
+// definitions are identical by a single definition. Instantiations over
+// pointers to different element types, for example, usually lower to the same
+// code. Definitions must have the same signature to fold; within their bodies,
+// local names and distinct struct types with the same layout may differ.
+// Folding repeats until no definition changes, because folding callees can
+// make their callers identical.
+//
+// The IR size of every instantiation in shared.Instantiations is recorded
+// before folding, and the survivor of each folded one afterwards.
+func foldIdenticalInstances(shared *t.SharedState, ir []byte) []byte {
+	lines := strings.Split(string(ir), "\n")
+	types := map[string]string{}
+	for _, line := range lines {
+		if match := irTypeDefinition.FindStringSubmatch(line); match != nil {
+			types[match[1]] = match[2]
+		}
+	}
+	functions := irFunctions(lines)
+
+	sizes := map[string]int{}
+	for _, function := range functions {
+		for _, line := range lines[function.start : function.end+1] {
+			sizes[function.symbol] += len(line) + 1
+		}
+	}
+	for _, line := range lines {
+		if match := irTypeDefinition.FindStringSubmatch(line); match != nil {
+			sizes[strings.TrimPrefix(match[1], "%struct.")] = len(line) + 1
+		}
+	}
+
+	layouts := &typeLayouts{types: types, keys: map[string]string{}, ids: map[string]int{}}
+	removed := map[int]bool{}
+	folded := map[string]string{}
+	for {
+		survivors := map[string]string{}
+		replaced := map[string]string{}
+		for i, function := range functions {
+			if removed[i] || !strings.Contains(function.symbol, specializationMarker) || !strings.HasPrefix(lines[function.start], "define internal ") {
+				continue
+			}
+			key := normalizedDefinition(lines[function.start:function.end+1], function.symbol, layouts)
+			survivor, seen := survivors[key]
+			if !seen || function.symbol < survivor {
+				if seen {
+					replaced[survivor] = function.symbol
+				}
+				survivors[key] = function.symbol
+				continue
+			}
+			replaced[function.symbol] = survivor
+		}
+		if len(replaced) == 0 {
+			break
+		}
+		// Chains inside one round point at a symbol which has itself been
+		// replaced; resolve them to the final survivor.
+		for symbol := range replaced {
+			target := replaced[symbol]
+			for replaced[target] != "" {
+				target = replaced[target]
+			}
+			replaced[symbol] = target
+		}
+		for i, function := range functions {
+			if replaced[function.symbol] != "" {
+				removed[i] = true
+			}
+		}
+		for i := range lines {
+			lines[i] = irIdentifier.ReplaceAllStringFunc(lines[i], func(name string) string {
+				if target := replaced[strings.TrimPrefix(name, "@")]; name[0] == '@' && target != "" {
+					return "@" + target
+				}
+				return name
+			})
+		}
+		for symbol, target := range replaced {
+			folded[symbol] = target
+		}
+	}
+	for symbol := range folded {
+		target := folded[symbol]
+		for folded[target] != "" {
+			target = folded[target]
+		}
+		folded[symbol] = target
+	}
+
+	for _, record := range shared.Instantiations {
+		record.IrBytes = sizes[record.Symbol]
+		record.FoldedInto = folded[record.Symbol]
+	}
+	if len(removed) == 0 {
+		return ir
+	}
+	out := bytes.Buffer{}
+	out.Grow(len(ir))
+	skip := map[int]int{}
+	for i := range removed {
+		skip[functions[i].start] = functions[i].end
+	}
+	for i := 0; i < len(lines); i++ {
+		if end, ok := skip[i]; ok {
+			i = end
+			continue
+		}
+		out.WriteString(lines[i])
+		if i != len(lines)-1 {
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
+
+func irFunctions(lines []string) []irFunction {
+	var functions []irFunction
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "define ") {
+			continue
+		}
+		open := strings.Index(lines[i], "(")
+		at := strings.LastIndex(lines[i][:max(open, 0)], "@")
+		if open < 0 || at < 0 {
+			continue
+		}
+		end := i
+		for end < len(lines) && lines[end] != "}" {
+			end++
+		}
+		if end == len(lines) {
+			break
+		}
+		functions = append(functions, irFunction{symbol: lines[i][at+1 : open], start: i, end: end})
+		i = end
+	}
+	return functions
+}
+
+// normalizedDefinition renders a definition so that two definitions compare
+// equal exactly when one can replace the other: the function's own symbol
+// and its local names are numbered by first use, comments are dropped, and,
+// outside the signature, struct types are replaced by their layout.
+func normalizedDefinition(lines []string, symbol string, layouts *typeLayouts) string {
+	locals := map[string]string{}
+	local := func(name string) string {
+		if renamed, ok := locals[name]; ok {
+			return renamed
+		}
+		renamed := "%" + strconv.Itoa(len(locals))
+		locals[name] = renamed
+		return renamed
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), ";") {
+			continue
+		}
+		if match := irLabel.FindStringSubmatch(line); match != nil && i != 0 {
+			line = local("%" + match[1])[1:] + line[len(match[1]):]
+		}
+		line = irIdentifier.ReplaceAllStringFunc(line, func(name string) string {
+			switch {
+			case name == "@"+symbol:
+				return "@"
+			case name[0] == '@':
+				return name
+			case layouts.types[name] != "":
+				if i == 0 {
+					return name
+				}
+				return layouts.key(name)
+			default:
+				return local(name)
+			}
+		})
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// typeLayouts identifies named IR types by their structure, so struct types
+// which are distinct only by name share a key.
+type typeLayouts struct {
+	types map[string]string
+	keys  map[string]string
+	ids   map[string]int
+}
+
+func (l *typeLayouts) key(name string) string {
+	if key, ok := l.keys[name]; ok {
+		return key
+	}
+	// A recursive type keeps its own name rather than expanding forever.
+	l.keys[name] = name
+	layout := irIdentifier.ReplaceAllStringFunc(l.types[name], func(inner string) string {
+		if inner[0] == '%' && l.types[inner] != "" {
+			return l.key(inner)
+		}
+		return inner
+	})
+	id, ok := l.ids[layout]
+	if !ok {
+		id = len(l.ids)
+		l.ids[layout] = id
+	}
+	key := "%layout." + strconv.Itoa(id)
+	l.keys[name] = key
+	return key
+}
@@ -0,0 +1,92 @@
+package llvmir
+
+import (
+	"strings"
+	"testing"
+
+	t "Magma/src/types"
+)
+
+const foldingIr = `%struct.m.Box__g__A = type { ptr, i64 }
+%struct.m.Box__g__B = type { ptr, i64 }
+
+define internal i64 @m.size__g__A(ptr %.ctx.in, ptr %b) {
+entry:
+  ; call m.size
+  %.12 = getelementptr %struct.m.Box__g__A, ptr %b, i32 0, i32 1
+  %.13 = load i64, ptr %.12
+  ret i64 %.13
+}
+
+define internal i64 @m.size__g__B(ptr %.ctx.in, ptr %b) {
+entry:
+  %.40 = getelementptr %struct.m.Box__g__B, ptr %b, i32 0, i32 1
+  %.41 = load i64, ptr %.40
+  ret i64 %.41
+}
+
+define internal i64 @m.twice__g__A(ptr %.ctx.in, ptr %b) {
+entry:
+  %.1 = call i64 @m.size__g__A(ptr %.ctx.in, ptr %b)
+  %.2 = add i64 %.1, %.1
+  ret i64 %.2
+}
+
+define internal i64 @m.twice__g__B(ptr %.ctx.in, ptr %b) {
+entry:
+  %.7 = call i64 @m.size__g__B(ptr %.ctx.in, ptr %b)
+  %.8 = add i64 %.7, %.7
+  ret i64 %.8
+}
+
+define internal %struct.m.Box__g__A @m.make__g__A(ptr %.ctx.in) {
+entry:
+  ret %struct.m.Box__g__A zeroinitializer
+}
+
+define internal %struct.m.Box__g__B @m.make__g__B(ptr %.ctx.in) {
+entry:
+  ret %struct.m.Box__g__B zeroinitializer
+}
+
+define i64 @m.main(ptr %.ctx.in, ptr %a, ptr %b) {
+entry:
+  %.1 = call i64 @m.twice__g__A(ptr %.ctx.in, ptr %a)
+  %.2 = call i64 @m.twice__g__B(ptr %.ctx.in, ptr %b)
+  ret i64 %.2
+}
+`
+
+func TestFoldIdenticalInstancesFollowsFoldedCallees(test *testing.T) {
+	records := []*t.Instantiation{
+		{Symbol: "m.size__g__A"}, {Symbol: "m.size__g__B"},
+		{Symbol: "m.twice__g__A"}, {Symbol: "m.twice__g__B"},
+		{Symbol: "m.make__g__A"}, {Symbol: "m.make__g__B"},
+		{Symbol: "m.Box__g__A"},
+	}
+	shared := &t.SharedState{Instantiations: records}
+	ir := string(foldIdenticalInstances(shared, []byte(foldingIr)))
+
+	// The size instances differ only by struct names with the same layout,
+	// and the twice instances become identical once they call the same size.
+	if records[1].FoldedInto != "m.size__g__A" || records[3].FoldedInto != "m.twice__g__A" {
+		test.Errorf("folded into %q and %q", records[1].FoldedInto, records[3].FoldedInto)
+	}
+	if records[0].FoldedInto != "" || records[2].FoldedInto != "" {
+		test.Errorf("kept instances folded into %q and %q", records[0].FoldedInto, records[2].FoldedInto)
+	}
+	if records[5].FoldedInto != "" {
+		test.Errorf("make instances with different result types folded into %q", records[5].FoldedInto)
+	}
+	if records[0].IrBytes == 0 || records[6].IrBytes == 0 {
+		test.Errorf("sizes were not recorded: %d, %d", records[0].IrBytes, records[6].IrBytes)
+	}
+	for _, gone := range []string{"@m.size__g__B", "@m.twice__g__B"} {
+		if strings.Contains(ir, gone) {
+			test.Errorf("folded symbol %s is still in the IR:\n%s", gone, ir)
+		}
+	}
+	if !strings.Contains(ir, "%.2 = call i64 @m.twice__g__A(ptr %.ctx.in, ptr %b)") {
+		test.Errorf("caller was not redirected to the kept instance:\n%s", ir)
+	}
+}
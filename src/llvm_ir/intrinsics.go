@@ -0,0 +1,115 @@
+package llvmir
+
+import (
+	magmatypes "Magma/src/magma_types"
+	t "Magma/src/types"
+	"fmt"
+)
+
+var atomicRmwOperations = map[t.IntrinsicKind]string{
+	t.IntrinsicAtomicExchange: "xchg",
+	t.IntrinsicAtomicFetchAdd: "add",
+	t.IntrinsicAtomicFetchSub: "sub",
+	t.IntrinsicAtomicFetchAnd: "and",
+	t.IntrinsicAtomicFetchOr:  "or",
+	t.IntrinsicAtomicFetchXor: "xor",
+}
+
+// irIntrinsicCall lowers a call to a compiler-known function in place. Atomic
+// operations access their place with its natural alignment. A bool is held in
+// memory as a byte, so it is operated on as i8 and truncated back.
+func irIntrinsicCall(ctx *IrCtx, fnCall *t.NodeExprCall) (SsaName, error) {
+	fn := fnCall.AssociatedFnDef
+	if len(fnCall.Orderings) != t.AtomicOrderingCount(fn.Intrinsic) {
+		return SsaName{}, fmt.Errorf("cannot lower intrinsic %q: orderings were not checked", t.IntrinsicName(fn.Intrinsic))
+	}
+	irWritef(ctx, "  ; intrinsic %s\n", t.IntrinsicName(fn.Intrinsic))
+	if fn.Intrinsic == t.IntrinsicAtomicFence {
+		irWritef(ctx, "  fence %s\n", fnCall.Orderings[0])
+		return SsaName{}, nil
+	}
+
+	params := fn.Class.ArgsNode.Args
+	placeType, ok := params[0].TypeNode.KindNode.(*t.NodeTypePointer)
+	if !ok {
+		return SsaName{}, fmt.Errorf("cannot lower intrinsic %q: its place is not a pointer", t.IntrinsicName(fn.Intrinsic))
+	}
+	valueType := &t.NodeType{KindNode: placeType.Kind}
+	memType, align, e := atomicIrType(ctx, valueType)
+	if e != nil {
+		return SsaName{}, e
+	}
+	operands := make([]SsaName, len(fnCall.Args)-len(fnCall.Orderings))
+	for i := range operands {
+		operand, e := irExpression(ctx, params[i].TypeNode, fnCall.Args[i], false)
+		if e != nil {
+			return SsaName{}, e
+		}
+		if operand, e = irCoerceNumeric(ctx, params[i].TypeNode, fnCall.Args[i], operand); e != nil {
+			return SsaName{}, e
+		}
+		if isBoolType(params[i].TypeNode) {
+			widened := irSsaLocal(ctx)
+			irWritef(ctx, "  %s = zext i1 %s to i8\n", widened.Repr, operand.Repr)
+			operand = widened
+		}
+		operands[i] = operand
+	}
+	place := operands[0]
+
+	var result SsaName
+	switch fn.Intrinsic {
+	case t.IntrinsicAtomicLoad:
+		result = irSsaLocal(ctx)
+		irWritef(ctx, "  %s = load atomic %s, ptr %s %s, align %d\n", result.Repr, memType, place.Repr, fnCall.Orderings[0], align)
+	case t.IntrinsicAtomicStore:
+		irWritef(ctx, "  store atomic %s %s, ptr %s %s, align %d\n", memType, operands[1].Repr, place.Repr, fnCall.Orderings[0], align)
+		return SsaName{}, nil
+	case t.IntrinsicAtomicCompareExchange, t.IntrinsicAtomicCompareExchangeWeak:
+		// The value found at place is written back to expected whether or not
+		// the exchange happened; on success it equals what was there.
+		expected, pair, found, exchanged := irSsaLocal(ctx), irSsaLocal(ctx), irSsaLocal(ctx), irSsaLocal(ctx)
+		weak := ""
+		if fn.Intrinsic == t.IntrinsicAtomicCompareExchangeWeak {
+			weak = "weak "
+		}
+		irWritef(ctx, "  %s = load %s, ptr %s, align %d\n", expected.Repr, memType, operands[1].Repr, align)
+		irWritef(ctx, "  %s = cmpxchg %sptr %s, %s %s, %s %s %s %s, align %d\n", pair.Repr, weak, place.Repr, memType, expected.Repr, memType, operands[2].Repr, fnCall.Orderings[0], fnCall.Orderings[1], align)
+		irWritef(ctx, "  %s = extractvalue { %s, i1 } %s, 0\n", found.Repr, memType, pair.Repr)
+		irWritef(ctx, "  store %s %s, ptr %s, align %d\n", memType, found.Repr, operands[1].Repr, align)
+		irWritef(ctx, "  %s = extractvalue { %s, i1 } %s, 1\n", exchanged.Repr, memType, pair.Repr)
+		return exchanged, nil
+	default:
+		operation, ok := atomicRmwOperations[fn.Intrinsic]
+		if !ok {
+			return SsaName{}, fmt.Errorf("cannot lower unknown intrinsic %d", fn.Intrinsic)
+		}
+		result = irSsaLocal(ctx)
+		irWritef(ctx, "  %s = atomicrmw %s ptr %s, %s %s %s, align %d\n", result.Repr, operation, place.Repr, memType, operands[1].Repr, fnCall.Orderings[0], align)
+	}
+	if isBoolType(valueType) {
+		narrowed := irSsaLocal(ctx)
+		irWritef(ctx, "  %s = trunc i8 %s to i1\n", narrowed.Repr, result.Repr)
+		result = narrowed
+	}
+	return result, nil
+}
+
+// atomicIrType returns the LLVM type through which an atomic intrinsic
+// accesses a value of valueType, and its alignment in bytes.
+func atomicIrType(ctx *IrCtx, valueType *t.NodeType) (string, int, error) {
+	switch {
+	case isBoolType(valueType):
+		return "i8", 1, nil
+	case isPointerType(valueType):
+		return "ptr", ctx.Shared.Target.PointerBits / 8, nil
+	}
+	if named, ok := valueType.KindNode.(*t.NodeTypeNamed); ok {
+		if single, ok := named.NameNode.(*t.NodeNameSingle); ok {
+			if number, ok := magmatypes.NumberTypes[single.Name]; ok && !number.IsFloat {
+				return magmatypes.BasicTypes[single.Name], number.ByteSize / 8, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("cannot lower atomic access to value of type %s", t.DisplayType(valueType))
+}
@@ -0,0 +1,50 @@
+package llvmir_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAtomicIntrinsicsLowerWithOrderingAndAlignment(t *testing.T) {
+	ir, err := compileSource(t, `mod main
+
+use "std:atomic" atomic
+
+main() void:
+    count u64 = 0
+    small u16 = 0
+    ready := false
+    expected u64 = 0
+    value := atomic.load(addrof count, atomic.ACQUIRE)
+    atomic.store(addrof small, 2, atomic.RELEASE)
+    old := atomic.fetchSub(addrof small, 1, atomic.RELAXED)
+    flag := atomic.exchange(addrof ready, true, atomic.ACQ_REL)
+    swapped := atomic.compareExchangeWeak(addrof count, addrof expected, value + 1, atomic.SEQ_CST, atomic.RELAXED)
+    atomic.fence(atomic.SEQ_CST)
+..
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"load atomic i64, ptr %",
+		" acquire, align 8",
+		"store atomic i16 2, ptr %",
+		" release, align 2",
+		"atomicrmw sub ptr %",
+		"i16 1 monotonic, align 2",
+		"atomicrmw xchg ptr %",
+		"acq_rel, align 1",
+		"cmpxchg weak ptr %",
+		"seq_cst monotonic, align 8",
+		"fence seq_cst",
+	} {
+		if !strings.Contains(ir, want) {
+			t.Fatalf("atomic IR is missing %q:\n%s", want, ir)
+		}
+	}
+	if regexp.MustCompile(`(?m)^define .* @atomic_\w+\.(load|fence)\(`).MatchString(ir) {
+		t.Fatalf("intrinsic was emitted as a function:\n%s", ir)
+	}
+}
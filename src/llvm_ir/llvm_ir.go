@@ -31,14 +31,28 @@ func irProtoVtables(ctx *IrCtx, gl *t.NodeGlobal, reachable map[string]bool) err
 			}
 			irWriteGlf(ctx, "@%s = private constant %%struct.%s.%s { ", t.ProtoVtableSymbol(implementation, proto), proto.Module, proto.VtableName)
 			for i, method := range proto.Methods {
-				concrete := implementation.Funcs[method.Name]
-				if concrete == nil {
+				if i != 0 {
+					irWriteGl(ctx, ", ")
+				}
+				if concrete := implementation.Funcs[method.Name]; concrete != nil {
+					irWriteGlf(ctx, "ptr @%s", concrete.AbsName)
+					continue
+				}
+				if method.Default == nil {
 					return fmt.Errorf("missing resolved prototype method %s.%s", implementation.Name, method.Name)
 				}
-				if i != 0 {
+				adapter := t.ProtoVtableSymbol(implementation, proto) + "." + method.Name
+				if err := irProtoDefaultAdapter(ctx, adapter, t.ProtoVtableSymbol(implementation, proto), method); err != nil {
+					return err
+				}
+				irWriteGlf(ctx, "ptr @%s", adapter)
+			}
+			// Base vtables follow the slots, in `extends` order.
+			for i, base := range proto.Extends {
+				if i != 0 || len(proto.Methods) != 0 {
 					irWriteGl(ctx, ", ")
 				}
-				irWriteGlf(ctx, "ptr @%s", concrete.AbsName)
+				irWriteGlf(ctx, "ptr @%s", t.ProtoVtableSymbol(implementation, base.Proto))
 			}
 			irWriteGl(ctx, " }\n")
 		}
@@ -317,5 +331,5 @@ func irWriteProgram(shared *t.SharedState, pruneFunctions bool) ([]byte, error)
 		}
 		irStrings = append(irStrings, r.S)
 	}
-	return bytes.Join(irStrings, []byte("\n")), nil
+	return foldIdenticalInstances(shared, bytes.Join(irStrings, []byte("\n"))), nil
 }
@@ -1,8 +1,13 @@
 package llvmir
 
 import (
+	magmatypes "Magma/src/magma_types"
 	t "Magma/src/types"
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
 )
 
 func irExtendFlt(ctx *IrCtx, valSsa SsaName, prevType *t.NodeType, newType *t.NodeType) (SsaName, error) {
@@ -134,13 +139,14 @@ func irIntToFloat(ctx *IrCtx, valSsa SsaName, numType *t.NodeType, toType *t.Nod
 }
 
 func irFloatToInt(ctx *IrCtx, valSsa SsaName, numType *t.NodeType, toType *t.NodeType) (SsaName, error) {
-	numDesc := getNumDesc(numType)
+	// The integer target, not the floating-point source, selects signedness.
+	toDesc := getNumDesc(toType)
 
 	// here target is guaranteed to be integer type
 	outSsa := irSsaLocal(ctx)
 	irWritef(ctx, "  %s = ", outSsa.Repr)
 
-	if numDesc.IsSigned {
+	if toDesc.IsSigned {
 		irWrite(ctx, "fptosi ")
 	} else {
 		irWrite(ctx, "fptoui ")
@@ -165,6 +171,9 @@ func irFloatToInt(ctx *IrCtx, valSsa SsaName, numType *t.NodeType, toType *t.Nod
 func irPromoteSingleToNum(ctx *IrCtx, expectedType *t.NodeType, ssa SsaName, fromType *t.NodeType) (SsaName, error) {
 	if ssa.IsLiteral {
 		fromType = expectedType
+		if irType := numericIrType(expectedType); isFloatIrType(irType) {
+			ssa.Repr = floatLiteralConst(ssa.Repr, irType)
+		}
 	}
 
 	fromNum := getNumDesc(fromType)
@@ -893,3 +902,288 @@ func irExprBinary(ctx *IrCtx, expectedType *t.NodeType, binaryExpr *t.NodeExprBi
 	}
 	return SsaName{}, fmt.Errorf("unsupported binary expression")
 }
+
+// irExprCast lowers an explicit numeric cast. Plain and wrapping casts use the
+// implicit conversion sequence. Saturating and checked casts additionally
+// compare the operand with the integer target's range before converting.
+func irExprCast(ctx *IrCtx, cast *t.NodeExprCast) (SsaName, error) {
+	fromType := cast.Expr.GetInferredType()
+	value, e := irExpression(ctx, fromType, cast.Expr, false)
+	if e != nil {
+		return SsaName{}, e
+	}
+	from, to := getNumDesc(fromType), getNumDesc(cast.InfType)
+	fromIr, toIr := numericIrType(fromType), numericIrType(cast.InfType)
+	if fromIr == "" || toIr == "" {
+		return SsaName{}, fmt.Errorf("cast at line %d, column %d has no numeric representation", cast.Tk.Pos.Line, cast.Tk.Pos.Col)
+	}
+	if value.IsLiteral {
+		// A literal has no width of its own; give it the operand's type so that
+		// `300 as u8` wraps like any other i64 value instead of emitting `i8 300`.
+		materialized := irSsaLocal(ctx)
+		if from.IsFloat {
+			irWritef(ctx, "  %s = fadd %s %s, %s\n", materialized.Repr, fromIr, floatConst(0, fromIr), value.Repr)
+		} else {
+			irWritef(ctx, "  %s = add %s 0, %s\n", materialized.Repr, fromIr, value.Repr)
+		}
+		value = materialized
+	}
+	converted, e := irPromoteSingleToNum(ctx, cast.InfType, value, fromType)
+	if e != nil {
+		return SsaName{}, e
+	}
+	if cast.Mode == t.CastPlain || cast.Mode == t.CastWrapping || to.IsFloat {
+		return converted, nil
+	}
+
+	minimum, maximum := integerBounds(to)
+	var below, above, unordered string
+	if from.IsFloat {
+		below, above, unordered = irFloatRangeChecks(ctx, value, fromIr, to)
+	} else {
+		below, above = irIntRangeChecks(ctx, value, fromIr, from, to)
+	}
+
+	if cast.Mode == t.CastSaturating {
+		result := converted.Repr
+		for _, clamp := range []struct{ cond, value string }{
+			{above, integerConst(maximum, to.ByteSize)},
+			{below, integerConst(minimum, to.ByteSize)},
+			{unordered, "0"},
+		} {
+			if clamp.cond == "" {
+				continue
+			}
+			selected := irSsaLocal(ctx)
+			irWritef(ctx, "  %s = select i1 %s, %s %s, %s %s\n", selected.Repr, clamp.cond, toIr, clamp.value, toIr, result)
+			result = selected.Repr
+		}
+		return ssaName(result), nil
+	}
+
+	failed := "false"
+	for _, cond := range []string{below, above, unordered} {
+		if cond == "" {
+			continue
+		}
+		if failed == "false" {
+			failed = cond
+			continue
+		}
+		combined := irSsaLocal(ctx)
+		irWritef(ctx, "  %s = or i1 %s, %s\n", combined.Repr, failed, cond)
+		failed = combined.Repr
+	}
+	if e := irCastFailure(ctx, cast, failed); e != nil {
+		return SsaName{}, e
+	}
+	return converted, nil
+}
+
+// irIntRangeChecks compares an integer with the bounds of an integer target.
+// A bound the source type cannot exceed yields no comparison.
+func irIntRangeChecks(ctx *IrCtx, value SsaName, fromIr string, from, to magmatypes.NumberType) (string, string) {
+	fromMin, fromMax := integerBounds(from)
+	toMin, toMax := integerBounds(to)
+	less, greater := "ult", "ugt"
+	if from.IsSigned {
+		less, greater = "slt", "sgt"
+	}
+	below, above := "", ""
+	if toMin.Cmp(fromMin) > 0 {
+		cmp := irSsaLocal(ctx)
+		irWritef(ctx, "  %s = icmp %s %s %s, %s\n", cmp.Repr, less, fromIr, value.Repr, integerConst(toMin, from.ByteSize))
+		below = cmp.Repr
+	}
+	if toMax.Cmp(fromMax) < 0 {
+		cmp := irSsaLocal(ctx)
+		irWritef(ctx, "  %s = icmp %s %s %s, %s\n", cmp.Repr, greater, fromIr, value.Repr, integerConst(toMax, from.ByteSize))
+		above = cmp.Repr
+	}
+	return below, above
+}
+
+// irFloatRangeChecks compares a floating-point value, truncated toward zero,
+// with the powers of two bounding an integer target, and reports NaN.
+func irFloatRangeChecks(ctx *IrCtx, value SsaName, fromIr string, to magmatypes.NumberType) (string, string, string) {
+	truncated, below, above, unordered := irSsaLocal(ctx), irSsaLocal(ctx), irSsaLocal(ctx), irSsaLocal(ctx)
+	irWritef(ctx, "  %s = call %s @llvm.trunc.%s(%s %s)\n", truncated.Repr, fromIr, floatIntrinsicSuffix(fromIr), fromIr, value.Repr)
+	lower, upper := 0.0, math.Ldexp(1, to.ByteSize)
+	if to.IsSigned {
+		lower, upper = -math.Ldexp(1, to.ByteSize-1), math.Ldexp(1, to.ByteSize-1)
+	}
+	irWritef(ctx, "  %s = fcmp olt %s %s, %s\n", below.Repr, fromIr, truncated.Repr, floatConst(lower, fromIr))
+	irWritef(ctx, "  %s = fcmp oge %s %s, %s\n", above.Repr, fromIr, truncated.Repr, floatConst(upper, fromIr))
+	irWritef(ctx, "  %s = fcmp uno %s %s, %s\n", unordered.Repr, fromIr, value.Repr, floatConst(0, fromIr))
+	return below.Repr, above.Repr, unordered.Repr
+}
+
+// irCastFailure routes a failed checked cast to the enclosing try or
+// destructuring assignment as an ERR_WOULD_OVERFLOW error.
+func irCastFailure(ctx *IrCtx, cast *t.NodeExprCast, failed string) error {
+	message := fmt.Sprintf("value does not fit in '%s'", flattenType(cast.InfType))
+	messageSsa := irCStringGlobal(ctx, message)
+	withMessage, withCode, overflow := irSsaLocal(ctx), irSsaLocal(ctx), irSsaLocal(ctx)
+	irWritef(ctx, "  %s = insertvalue %%type.error zeroinitializer, ptr %s, 0\n", withMessage.Repr, messageSsa.Repr)
+	irWritef(ctx, "  %s = insertvalue %%type.error %s, i32 5, 1\n", withCode.Repr, withMessage.Repr)
+	irWritef(ctx, "  %s = insertvalue %%type.error %s, i16 %d, 3\n", overflow.Repr, withCode.Repr, len(message))
+	errSsa := irSsaLocal(ctx)
+	irWritef(ctx, "  %s = select i1 %s, %%type.error %s, %%type.error zeroinitializer\n", errSsa.Repr, failed, overflow.Repr)
+
+	if cast.ErrorMode == 1 && ctx.ErrorMode == 1 {
+		return irThrowSsa(ctx, errSsa, ctx.CurrFunc, cast.Tk.Pos)
+	}
+	if cast.ErrorMode != 2 || ctx.ErrorMode != 2 {
+		return fmt.Errorf("checked cast at line %d, column %d has no enclosing error handler", cast.Tk.Pos.Line, cast.Tk.Pos.Col)
+	}
+	failureStore, successLabel := irSsaName(ctx), irSsaName(ctx)
+	irWritef(ctx, "  br i1 %s, label %%%s, label %%%s, !prof !9000\n", failed, failureStore.Repr, successLabel.Repr)
+	irWritef(ctx, "%s:\n", failureStore.Repr)
+	irWritef(ctx, "  store %%type.error %s, ptr %s\n", errSsa.Repr, ctx.CapturedErrorSlot.Repr)
+	irWritef(ctx, "  br label %%%s\n", ctx.ErrorFailureLabel.Repr)
+	irWritef(ctx, "%s:\n", successLabel.Repr)
+	return nil
+}
+
+func numericIrType(node *t.NodeType) string {
+	if node == nil {
+		return ""
+	}
+	if named, ok := node.KindNode.(*t.NodeTypeNamed); ok {
+		if single, ok := named.NameNode.(*t.NodeNameSingle); ok {
+			if _, numeric := magmatypes.NumberTypes[single.Name]; numeric {
+				return magmatypes.BasicTypes[single.Name]
+			}
+		}
+	}
+	return ""
+}
+
+func integerBounds(desc magmatypes.NumberType) (*big.Int, *big.Int) {
+	if desc.IsSigned {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(desc.ByteSize-1))
+		return new(big.Int).Neg(limit), limit.Sub(limit, big.NewInt(1))
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(desc.ByteSize))
+	return big.NewInt(0), limit.Sub(limit, big.NewInt(1))
+}
+
+// integerConst prints value in two's complement for an integer of the given
+// width, so unsigned maxima are emitted as -1 rather than overflowing literals.
+func integerConst(value *big.Int, bits int) string {
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	if value.Cmp(limit) >= 0 {
+		return new(big.Int).Sub(value, new(big.Int).Lsh(limit, 1)).String()
+	}
+	return value.String()
+}
+
+// floatConst prints a bound which is exact in the target format, or an
+// infinity when the format's finite range cannot reach it.
+func floatConst(value float64, irType string) string {
+	if irType == "fp128" {
+		return fp128Const(big.NewFloat(value))
+	}
+	if value == 0 {
+		return "0.0"
+	}
+	maxExponent := map[string]int{"half": 15, "float": 127}[irType]
+	if maxExponent != 0 && math.Abs(value) >= math.Ldexp(1, maxExponent+1) {
+		if value < 0 {
+			return "0xFFF0000000000000"
+		}
+		return "0x7FF0000000000000"
+	}
+	text := strconv.FormatFloat(value, 'e', -1, 64)
+	if !strings.Contains(text, ".") {
+		text = strings.Replace(text, "e", ".0e", 1)
+	}
+	return text
+}
+
+func floatIntrinsicSuffix(irType string) string {
+	switch irType {
+	case "half":
+		return "f16"
+	case "float":
+		return "f32"
+	case "fp128":
+		return "f128"
+	default:
+		return "f64"
+	}
+}
+
+func isFloatIrType(irType string) bool {
+	return irType == "half" || irType == "float" || irType == "double" || irType == "fp128"
+}
+
+// floatLiteralConst spells a numeric literal as an LLVM constant of a
+// floating-point type. LLVM rejects decimal constants which are inexact in
+// half or float, integer spellings, and decimal fp128, so those are rounded
+// here and written in hexadecimal. Decimal doubles are kept as written.
+func floatLiteralConst(repr string, irType string) string {
+	if irType == "double" && strings.Contains(repr, ".") && !strings.HasPrefix(repr, "0x") {
+		return repr
+	}
+	value := new(big.Float).SetPrec(256)
+	switch {
+	case strings.HasPrefix(repr, "u0x"):
+		integer, ok := new(big.Int).SetString(repr[3:], 16)
+		if !ok {
+			return repr
+		}
+		value.SetInt(integer)
+	case strings.HasPrefix(repr, "0x") && len(repr) == 18:
+		bits, err := strconv.ParseUint(repr[2:], 16, 64)
+		if err != nil {
+			return repr
+		}
+		value.SetFloat64(math.Float64frombits(bits))
+	default:
+		if _, ok := value.SetString(repr); !ok {
+			return repr
+		}
+	}
+	var rounded float64
+	switch irType {
+	case "fp128":
+		return fp128Const(value)
+	case "half":
+		rounded, _ = new(big.Float).SetPrec(11).Set(value).Float64()
+		if math.Abs(rounded) > 65504 {
+			rounded = math.Copysign(math.Inf(1), rounded)
+		}
+	case "float":
+		narrow, _ := value.Float32()
+		rounded = float64(narrow)
+	default:
+		rounded, _ = value.Float64()
+	}
+	return fmt.Sprintf("0x%016X", math.Float64bits(rounded))
+}
+
+// fp128Const writes value, rounded to quadruple precision, as 0xL followed by
+// the low and then the high 64 bits of its IEEE encoding.
+func fp128Const(value *big.Float) string {
+	if value.Sign() == 0 {
+		return fmt.Sprintf("0xL%016X%016X", uint64(0), uint64(0))
+	}
+	quad := new(big.Float).SetPrec(113).Set(value)
+	mantissa := new(big.Float)
+	exponent := quad.MantExp(mantissa) - 1 + 16383
+	bits := new(big.Int)
+	if exponent >= 0x7FFF {
+		bits.Lsh(big.NewInt(0x7FFF), 112)
+	} else if exponent > 0 {
+		significand, _ := mantissa.Abs(mantissa).SetMantExp(mantissa, 113).Int(nil)
+		bits.Sub(significand, new(big.Int).Lsh(big.NewInt(1), 112))
+		bits.Or(bits, new(big.Int).Lsh(big.NewInt(int64(exponent)), 112))
+	}
+	if value.Sign() < 0 {
+		bits.SetBit(bits, 127, 1)
+	}
+	low := new(big.Int).And(bits, new(big.Int).SetUint64(math.MaxUint64))
+	high := new(big.Int).Rsh(bits, 64)
+	return fmt.Sprintf("0xL%016X%016X", low.Uint64(), high.Uint64())
+}
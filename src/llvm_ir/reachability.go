@@ -154,6 +154,28 @@ func (w *reachabilityWalker) function(fn *t.NodeFuncDef) {
 	}
 }
 
+// protoVtable marks the vtable of implementation for proto, the methods in
+// its slots, and the vtables of the bases it points to.
+func (w *reachabilityWalker) protoVtable(implementation *t.StructDef, proto *t.ProtoDef) {
+	symbol := t.ProtoVtableSymbol(implementation, proto)
+	if w.reachableProtoTables[symbol] {
+		return
+	}
+	w.reachableProtoTables[symbol] = true
+	for _, method := range proto.Methods {
+		if concrete := implementation.Funcs[method.Name]; concrete != nil {
+			w.enqueue(concrete)
+		} else {
+			w.enqueue(method.Default)
+		}
+	}
+	for _, base := range proto.Extends {
+		if base.Proto != nil {
+			w.protoVtable(implementation, base.Proto)
+		}
+	}
+}
+
 func (w *reachabilityWalker) nodeType(node *t.NodeType) {
 	if node == nil {
 		return
@@ -249,6 +271,7 @@ func (w *reachabilityWalker) expression(expression t.NodeExpr) {
 		}
 	case *t.NodeExprCall:
 		w.enqueue(node.AssociatedFnDef)
+		w.enqueue(node.Devirtualized)
 		w.expression(node.Callee)
 		w.expression(node.MemberOwnerExpr)
 		for _, argument := range node.Args {
@@ -261,11 +284,9 @@ func (w *reachabilityWalker) expression(expression t.NodeExpr) {
 		}
 	case *t.NodeExprProtoView:
 		w.expression(node.Target)
-		if node.Implementation != nil && node.Implementation.Owner != nil && node.Implementation.Proto != nil {
-			w.reachableProtoTables[t.ProtoVtableSymbol(node.Implementation.Owner, node.Implementation.Proto)] = true
-			for _, method := range node.Implementation.Proto.Methods {
-				w.enqueue(node.Implementation.Owner.Funcs[method.Name])
-			}
+		// An upcast reuses the vtables reached by the view it starts from.
+		if len(node.Upcast) == 0 && node.Implementation != nil && node.Implementation.Owner != nil && node.Implementation.Proto != nil {
+			w.protoVtable(node.Implementation.Owner, node.Implementation.Proto)
 		}
 	case *t.NodeExprSubscript:
 		w.expression(node.Target)
@@ -290,6 +311,8 @@ func (w *reachabilityWalker) expression(expression t.NodeExpr) {
 		w.expression(node.Expr)
 	case *t.NodeExprMove:
 		w.expression(node.Expr)
+	case *t.NodeExprCast:
+		w.expression(node.Expr)
 	case *t.NodeExprDestructureAssign:
 		w.expression(&node.ValueDef)
 		w.expression(&node.ErrDef)
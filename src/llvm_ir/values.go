@@ -4,8 +4,6 @@ import (
 	t "Magma/src/types"
 	"fmt"
 	"slices"
-	"strconv"
-	"strings"
 )
 
 func irGlVarDef(ctx *IrCtx, vd *t.NodeExprVarDef) error {
@@ -38,7 +36,14 @@ func irConstValue(ctx *IrCtx, expected *t.NodeType, expr t.NodeExpr) error {
 	switch n := expr.(type) {
 	case *t.NodeExprLit:
 		switch n.LitType {
-		case t.TokLitNum, t.TokLitBool:
+		case t.TokLitNum, t.TokLitChar:
+			if irType := numericIrType(expected); isFloatIrType(irType) {
+				irWrite(ctx, floatLiteralConst(n.Value, irType))
+				return nil
+			}
+			irWrite(ctx, n.Value)
+			return nil
+		case t.TokLitBool:
 			irWrite(ctx, n.Value)
 			return nil
 		case t.TokLitStr:
@@ -81,7 +86,7 @@ func irConstValue(ctx *IrCtx, expected *t.NodeType, expr t.NodeExpr) error {
 		irWritef(ctx, "@%s", varDef.AbsName)
 		return nil
 	case *t.NodeExprStructInit:
-		fields := slices.Clone(n.Fields)
+		fields := append(slices.Clone(n.Fields), n.Defaults...)
 		slices.SortFunc(fields, func(a, b t.NodeStructFieldInit) int { return a.FieldIndex - b.FieldIndex })
 		irWrite(ctx, "{ ")
 		for i, field := range fields {
@@ -128,6 +133,9 @@ func irPrepareConstStrings(ctx *IrCtx, expr t.NodeExpr, seen map[t.NodeExpr]bool
 		for _, field := range n.Fields {
 			irPrepareConstStrings(ctx, field.Expression, seen)
 		}
+		for _, field := range n.Defaults {
+			irPrepareConstStrings(ctx, field.Expression, seen)
+		}
 	case *t.NodeExprArray:
 		for _, entry := range n.Entries {
 			irPrepareConstStrings(ctx, entry.Value, seen)
@@ -138,16 +146,7 @@ func irPrepareConstStrings(ctx *IrCtx, expr t.NodeExpr, seen map[t.NodeExpr]bool
 func irConstUint(expr t.NodeExpr) (uint64, bool) {
 	switch n := expr.(type) {
 	case *t.NodeExprLit:
-		if n.LitType != t.TokLitNum {
-			return 0, false
-		}
-		repr := strings.ReplaceAll(n.Value, "_", "")
-		base := 10
-		if strings.HasPrefix(repr, "0x") || strings.HasPrefix(repr, "0X") || strings.HasPrefix(repr, "0b") || strings.HasPrefix(repr, "0B") || strings.HasPrefix(repr, "0o") || strings.HasPrefix(repr, "0O") {
-			base = 0
-		}
-		value, err := strconv.ParseUint(repr, base, 64)
-		return value, err == nil
+		return n.UintValue()
 	case *t.NodeExprName:
 		variable, ok := n.AssociatedNode.(*t.NodeExprVarDef)
 		if !ok || !variable.IsConst || variable.Initializer == nil {
@@ -319,9 +318,11 @@ func irVarDefAssign(ctx *IrCtx, vda *t.NodeExprVarDefAssign) (SsaName, error) {
 	return allocSsa, nil
 }
 
+// irExprStructInit inserts the given fields, then the declared defaults of
+// the omitted ones, into a zeroed value.
 func irExprStructInit(ctx *IrCtx, init *t.NodeExprStructInit) (SsaName, error) {
 	current := SsaName{Repr: "zeroinitializer", IsLiteral: true}
-	for _, field := range init.Fields {
+	for _, field := range append(slices.Clone(init.Fields), init.Defaults...) {
 		value, e := irExpression(ctx, field.FieldType, field.Expression, false)
 		if e != nil {
 			return SsaName{}, e
@@ -363,6 +364,10 @@ func irExprProtoView(ctx *IrCtx, view *t.NodeExprProtoView) (SsaName, error) {
 	if err != nil {
 		return SsaName{}, err
 	}
+	vtable := "@" + t.ProtoVtableSymbol(view.Implementation.Owner, view.Implementation.Proto)
+	if len(view.Upcast) > 0 {
+		target, vtable = irProtoUpcast(ctx, target, view.Upcast)
+	}
 	current := SsaName{Repr: "zeroinitializer", IsLiteral: true}
 	withImpl := irSsaLocal(ctx)
 	irWritef(ctx, "  %s = insertvalue ", withImpl.Repr)
@@ -375,6 +380,27 @@ func irExprProtoView(ctx *IrCtx, view *t.NodeExprProtoView) (SsaName, error) {
 	if err := irType(ctx, view.ProtoType); err != nil {
 		return SsaName{}, err
 	}
-	irWritef(ctx, " %s, ptr @%s, 1\n", withImpl.Repr, t.ProtoVtableSymbol(view.Implementation.Owner, view.Implementation.Proto))
+	irWritef(ctx, " %s, ptr %s, 1\n", withImpl.Repr, vtable)
 	return withTable, nil
 }
+
+// irProtoUpcast loads the implementation and the base vtable of the view at
+// target. Each relation in path selects the next base through the vtable
+// pointer which follows the requirement slots of the extending prototype.
+func irProtoUpcast(ctx *IrCtx, target SsaName, path []*t.ProtoImpl) (SsaName, string) {
+	derived := path[0].Owner.Proto
+	implAddr, impl := irSsaLocal(ctx), irSsaLocal(ctx)
+	irWritef(ctx, "  %s = getelementptr inbounds %%struct.%s.%s, ptr %s, i32 0, i32 0\n", implAddr.Repr, derived.Module, derived.Name, target.Repr)
+	irWritef(ctx, "  %s = load ptr, ptr %s\n", impl.Repr, implAddr.Repr)
+	tableAddr, table := irSsaLocal(ctx), irSsaLocal(ctx)
+	irWritef(ctx, "  %s = getelementptr inbounds %%struct.%s.%s, ptr %s, i32 0, i32 1\n", tableAddr.Repr, derived.Module, derived.Name, target.Repr)
+	irWritef(ctx, "  %s = load ptr, ptr %s\n", table.Repr, tableAddr.Repr)
+	for _, relation := range path {
+		proto := relation.Owner.Proto
+		baseAddr, base := irSsaLocal(ctx), irSsaLocal(ctx)
+		irWritef(ctx, "  %s = getelementptr inbounds %%struct.%s.%s, ptr %s, i32 0, i32 %d\n", baseAddr.Repr, proto.Module, proto.VtableName, table.Repr, len(proto.Methods)+slices.Index(proto.Extends, relation))
+		irWritef(ctx, "  %s = load ptr, ptr %s\n", base.Repr, baseAddr.Repr)
+		table = base
+	}
+	return impl, table.Repr
+}
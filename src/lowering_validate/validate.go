@@ -636,6 +636,9 @@ func expressionValid(file *t.FileCtx, expression t.NodeExpr) error {
 		if err := typeValid(file, node.InfType, "try expression result"); err != nil {
 			return err
 		}
+		if cast, ok := node.Call.(*t.NodeExprCast); ok && cast.Mode == t.CastChecked {
+			return expressionValid(file, cast)
+		}
 		call, ok := node.Call.(*t.NodeExprCall)
 		if !ok {
 			return invalid(file, &node.Tk, "try expression does not contain a function call")
@@ -726,6 +729,14 @@ func expressionValid(file *t.FileCtx, expression t.NodeExpr) error {
 			return err
 		}
 		return expressionValid(file, node.Expr)
+	case *t.NodeExprCast:
+		if err := typeValid(file, node.InfType, "cast expression result"); err != nil {
+			return err
+		}
+		if node.Mode == t.CastChecked && node.ErrorMode == 0 {
+			return invalid(file, &node.Tk, "checked cast has no enclosing error handler")
+		}
+		return expressionValid(file, node.Expr)
 	case *t.NodeExprStructInit:
 		if err := typeValid(file, node.Type, "struct initializer result"); err != nil {
 			return err
@@ -741,6 +752,20 @@ func expressionValid(file *t.FileCtx, expression t.NodeExpr) error {
 				return err
 			}
 		}
+		for _, field := range node.Defaults {
+			if field.FieldIndex < 0 || field.Name == "" {
+				return invalid(file, &node.Tk, "struct initializer contains incomplete default field metadata")
+			}
+			if err := typeValid(file, field.FieldType, "struct initializer default field"); err != nil {
+				return err
+			}
+			if field.Expression == nil {
+				return invalid(file, &node.Tk, "struct initializer default field has no value")
+			}
+			if err := expressionValid(file, field.Expression); err != nil {
+				return err
+			}
+		}
 	case *t.NodeExprProtoView:
 		if node.Implementation == nil || node.Implementation.Owner == nil || node.Implementation.Proto == nil {
 			return invalid(file, &node.Tk, "prototype view has no resolved implementation")
@@ -0,0 +1,248 @@
+package lsp
+
+import (
+	"Magma/src/types"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+type callHierarchyIncomingCall struct {
+	From       hierarchyItem   `json:"from"`
+	FromRanges []rangePosition `json:"fromRanges"`
+}
+
+type callHierarchyOutgoingCall struct {
+	To         hierarchyItem   `json:"to"`
+	FromRanges []rangePosition `json:"fromRanges"`
+}
+
+// callSite is one call from caller to callee, both keyed like the docIndex
+// functions, located by the callee's name at the call.
+type callSite struct {
+	caller string
+	callee string
+	uri    string
+	at     rangePosition
+}
+
+func (s *server) handlePrepareCallHierarchy(msg message) error {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Position position `json:"position"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	d := s.documents[p.TextDocument.URI]
+	if d == nil {
+		return s.respond(msg.ID, nil)
+	}
+	if d.result == nil {
+		d.result = s.analyzeDocument(d)
+	}
+	key, ok := d.result.callableAt(p.Position)
+	if !ok {
+		return s.respond(msg.ID, nil)
+	}
+	item, ok := d.result.callHierarchyItem(d.URI, key)
+	if !ok {
+		return s.respond(msg.ID, nil)
+	}
+	return s.respond(msg.ID, []hierarchyItem{item})
+}
+
+// handleCallHierarchy answers callHierarchy/incomingCalls and
+// callHierarchy/outgoingCalls from the analysis of the document which
+// prepared the item. Incoming call ranges are in the caller; outgoing ones in
+// the item itself.
+func (s *server) handleCallHierarchy(msg message, incoming bool) error {
+	var p struct {
+		Item hierarchyItem `json:"item"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	d := s.documents[p.Item.Data.Document]
+	if d == nil {
+		if incoming {
+			return s.respond(msg.ID, []callHierarchyIncomingCall{})
+		}
+		return s.respond(msg.ID, []callHierarchyOutgoingCall{})
+	}
+	if d.result == nil {
+		d.result = s.analyzeDocument(d)
+	}
+	key := p.Item.Data.Module + "\x00" + p.Item.Data.Name
+	ranges := map[string][]rangePosition{}
+	for _, site := range d.result.callSites() {
+		if incoming && site.callee == key {
+			ranges[site.caller] = append(ranges[site.caller], site.at)
+		} else if !incoming && site.caller == key {
+			ranges[site.callee] = append(ranges[site.callee], site.at)
+		}
+	}
+	incomingCalls := []callHierarchyIncomingCall{}
+	outgoingCalls := []callHierarchyOutgoingCall{}
+	for _, related := range sortedKeys(ranges) {
+		item, ok := d.result.callHierarchyItem(d.URI, related)
+		if !ok {
+			continue
+		}
+		sites := ranges[related]
+		sort.Slice(sites, func(i, j int) bool {
+			if sites[i].Start.Line != sites[j].Start.Line {
+				return sites[i].Start.Line < sites[j].Start.Line
+			}
+			return sites[i].Start.Character < sites[j].Start.Character
+		})
+		if incoming {
+			incomingCalls = append(incomingCalls, callHierarchyIncomingCall{From: item, FromRanges: sites})
+		} else {
+			outgoingCalls = append(outgoingCalls, callHierarchyOutgoingCall{To: item, FromRanges: sites})
+		}
+	}
+	if incoming {
+		return s.respond(msg.ID, incomingCalls)
+	}
+	return s.respond(msg.ID, outgoingCalls)
+}
+
+// callSites lists the calls made by every checked function of the loaded
+// units. A generic instance and the calls made in and to it are attributed to
+// its template, and a call dispatched through a prototype targets the
+// requirement. Calls the compiler synthesizes borrow their tokens and are
+// recognized by a name mismatch.
+func (a *analysis) callSites() []callSite {
+	sites := []callSite{}
+	if a == nil || a.shared == nil {
+		return sites
+	}
+	modules := a.functionModules()
+	seen := map[callSite]bool{}
+	for _, file := range a.shared.Files {
+		if file == nil || file.GlNode == nil {
+			continue
+		}
+		for _, declaration := range file.GlNode.Declarations {
+			function, ok := declaration.(*types.NodeFuncDef)
+			if !ok || function.Derived {
+				continue
+			}
+			caller := file.PackageName + "\x00" + flattenName(function.Class.NameNode)
+			walkAST(&function.Body, func(value any) bool {
+				call, ok := value.(*types.NodeExprCall)
+				if !ok || call.AssociatedFnDef == nil || call.IsFuncPointer || call.Tk.Pos.Line == 0 {
+					return true
+				}
+				callee, ok := calleeKey(call.AssociatedFnDef, modules)
+				if !ok || call.Tk.Repr != lastPart(callee[strings.IndexByte(callee, 0)+1:]) {
+					return true
+				}
+				site := callSite{caller: caller, callee: callee, uri: fileURI(file.FilePath), at: spanRange(call.Tk.Span())}
+				if !seen[site] {
+					seen[site] = true
+					sites = append(sites, site)
+				}
+				return true
+			})
+		}
+	}
+	return sites
+}
+
+// functionModules maps each declared function, instances included, to the
+// module declaring it.
+func (a *analysis) functionModules() map[*types.NodeFuncDef]string {
+	modules := map[*types.NodeFuncDef]string{}
+	for _, file := range a.shared.Files {
+		if file == nil || file.GlNode == nil {
+			continue
+		}
+		for _, function := range file.GlNode.FuncDefs {
+			modules[function] = file.PackageName
+		}
+		for _, declaration := range file.GlNode.Declarations {
+			if function, ok := declaration.(*types.NodeFuncDef); ok {
+				modules[function] = file.PackageName
+			}
+		}
+	}
+	return modules
+}
+
+func calleeKey(function *types.NodeFuncDef, modules map[*types.NodeFuncDef]string) (string, bool) {
+	if method := function.ProtoDispatch; method != nil && method.Proto != nil {
+		return method.Proto.Module + "\x00" + sourceName(method.Proto.Name) + "." + method.Name, true
+	}
+	module, ok := modules[function]
+	return module + "\x00" + flattenName(function.Class.NameNode), ok
+}
+
+// callableAt resolves the function under pos: the callee of a call, a
+// function declaration, or a prototype requirement.
+func (a *analysis) callableAt(pos position) (string, bool) {
+	if a == nil || a.file == nil || a.file.GlNode == nil || a.shared == nil {
+		return "", false
+	}
+	modules := a.functionModules()
+	key := ""
+	walkAST(a.file.GlNode, func(value any) bool {
+		if call, ok := value.(*types.NodeExprCall); ok && call.AssociatedFnDef != nil && !call.IsFuncPointer && callNameAt(call.Callee, pos) {
+			key, _ = calleeKey(call.AssociatedFnDef, modules)
+		}
+		return key == ""
+	})
+	if key != "" {
+		return key, true
+	}
+	if a.docs != nil {
+		for name, function := range a.docs.functions {
+			if token, ok := declarationNameToken(function.Class.NameNode); ok && strings.HasPrefix(name, a.file.PackageName+"\x00") && tokenAt(token, pos) {
+				return name, true
+			}
+		}
+	}
+	for _, proto := range a.file.GlNode.ProtoDefs {
+		for _, method := range proto.Methods {
+			if tokenAt(method.Tk, pos) {
+				return proto.Module + "\x00" + sourceName(proto.Name) + "." + method.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// callHierarchyItem locates a function through its source declaration, which
+// generic templates keep, or a prototype requirement through its prototype.
+func (a *analysis) callHierarchyItem(document, key string) (hierarchyItem, bool) {
+	module, name, _ := strings.Cut(key, "\x00")
+	file := a.moduleFile(module)
+	if file == nil {
+		return hierarchyItem{}, false
+	}
+	var function *types.NodeFuncDef
+	if a.docs != nil {
+		function = a.docs.functions[key]
+	}
+	var token types.Token
+	found := false
+	if function != nil {
+		token, found = declarationNameToken(function.Class.NameNode)
+	} else if owner, method, ok := strings.Cut(name, "."); ok && file.GlNode != nil {
+		if proto := file.GlNode.ProtoDefs[owner]; proto != nil && proto.MethodMap[method] != nil {
+			token, found = proto.MethodMap[method].Tk, true
+		}
+	}
+	if !found || token.Pos.Line == 0 {
+		return hierarchyItem{}, false
+	}
+	kind := 12 // SymbolKind.Function
+	if strings.Contains(name, ".") {
+		kind = 6 // SymbolKind.Method
+	}
+	target := tokenLocation(file.FilePath, token)
+	return hierarchyItem{Name: name, Kind: kind, Detail: file.ModuleName, URI: target.URI, Range: target.Range, SelectionRange: target.Range, Data: hierarchyData{Document: document, Module: module, Name: name}}, true
+}
@@ -0,0 +1,144 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const callHierarchySource = "mod main\n" +
+	"use \"std:errors\" errors\n" +
+	"proto Reader(read() u64)\n" +
+	"File impl Reader(value u64)\n" +
+	"File.read() u64:\n" +
+	"    ret this.value\n" +
+	"..\n" +
+	"risky() !u64:\n" +
+	"    ret 1\n" +
+	"..\n" +
+	"twice() !u64:\n" +
+	"    first := try risky()\n" +
+	"    ret first + try risky()\n" +
+	"..\n" +
+	"pick[T](left T, right T) !T:\n" +
+	"    checked := try risky()\n" +
+	"    ret left\n" +
+	"..\n" +
+	"use_reader(r Reader) u64:\n" +
+	"    ret r.read()\n" +
+	"..\n" +
+	"main() void:\n" +
+	"    f := File(value=2)\n" +
+	"    n := use_reader(f.proto[Reader]())\n" +
+	"    m := f.read()\n" +
+	"    a, e1 := pick[u64](1, 2)\n" +
+	"    b, e2 := pick[i32](1, 2)\n" +
+	"    e := errors.ok()\n" +
+	"..\n"
+
+func callHierarchyServer(t *testing.T) (*server, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "calls.mg")
+	if err := os.WriteFile(path, []byte(callHierarchySource), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file:///" + filepath.ToSlash(path)
+	s := &server{stdRoot: testStdRoot(), documents: map[string]*document{uri: {URI: uri, Text: callHierarchySource}}}
+	if result := analyze(uri, callHierarchySource, s.stdRoot); result.err != nil {
+		t.Fatal(result.err)
+	}
+	return s, uri
+}
+
+func callHierarchyRequest(t *testing.T, s *server, method string, params any, result any) {
+	t.Helper()
+	var output bytes.Buffer
+	s.out = &output
+	payload, _ := json.Marshal(params)
+	if err := s.handle(message{ID: json.RawMessage("1"), Method: method, Params: payload}); err != nil {
+		t.Fatal(err)
+	}
+	response := struct {
+		Result any `json:"result"`
+	}{result}
+	body := output.String()
+	if err := json.Unmarshal([]byte(body[strings.Index(body, "{"):]), &response); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// callSummary renders each related function with the lines of its call
+// ranges.
+func callSummary(name string, ranges []rangePosition) string {
+	lines := []string{}
+	for _, at := range ranges {
+		lines = append(lines, fmt.Sprint(at.Start.Line))
+	}
+	return name + "@" + strings.Join(lines, ",")
+}
+
+func TestCallHierarchyIncomingAndOutgoingCalls(t *testing.T) {
+	s, uri := callHierarchyServer(t)
+	prepare := func(at position) hierarchyItem {
+		t.Helper()
+		var items []hierarchyItem
+		callHierarchyRequest(t, s, "textDocument/prepareCallHierarchy", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": at}, &items)
+		if len(items) != 1 {
+			t.Fatalf("prepare at %v = %#v", at, items)
+		}
+		return items[0]
+	}
+	incoming := func(item hierarchyItem) string {
+		t.Helper()
+		var calls []callHierarchyIncomingCall
+		callHierarchyRequest(t, s, "callHierarchy/incomingCalls", map[string]any{"item": item}, &calls)
+		summary := []string{}
+		for _, call := range calls {
+			summary = append(summary, callSummary(call.From.Name, call.FromRanges))
+		}
+		return strings.Join(summary, " ")
+	}
+	outgoing := func(item hierarchyItem) string {
+		t.Helper()
+		var calls []callHierarchyOutgoingCall
+		callHierarchyRequest(t, s, "callHierarchy/outgoingCalls", map[string]any{"item": item}, &calls)
+		summary := []string{}
+		for _, call := range calls {
+			summary = append(summary, callSummary(call.To.Name, call.FromRanges))
+		}
+		return strings.Join(summary, " ")
+	}
+
+	risky := prepare(position{Line: 7, Character: 1})
+	if risky.Name != "risky" || risky.Kind != 12 || risky.Range.Start.Line != 7 {
+		t.Fatalf("prepared item = %#v", risky)
+	}
+	// Both instances of pick share the template's call, which is listed once.
+	if got := incoming(risky); got != "pick@15 twice@11,12" {
+		t.Fatalf("incoming calls = %q", got)
+	}
+	if got := incoming(prepare(position{Line: 25, Character: 15})); got != "main@25,26" {
+		t.Fatalf("incoming calls of the generic template = %q", got)
+	}
+	if got := outgoing(prepare(position{Line: 18, Character: 1})); got != "Reader.read@19" {
+		t.Fatalf("outgoing calls through a prototype = %q", got)
+	}
+	main := prepare(position{Line: 21, Character: 1})
+	got := outgoing(main)
+	for _, want := range []string{"File.read@24", "ok@27", "pick@25,26", "use_reader@23"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("outgoing calls of main = %q, missing %q", got, want)
+		}
+	}
+	var calls []callHierarchyOutgoingCall
+	callHierarchyRequest(t, s, "callHierarchy/outgoingCalls", map[string]any{"item": main}, &calls)
+	for _, call := range calls {
+		if call.To.Name == "ok" && (call.To.URI == uri || !strings.HasSuffix(call.To.URI, "errors.mg")) {
+			t.Fatalf("imported callee = %#v", call.To)
+		}
+	}
+}
@@ -9,6 +9,19 @@ import (
 	"unicode"
 )
 
+// Completion item kinds, as numbered by the LSP CompletionItemKind.
+const (
+	completionMethod   = 2
+	completionFunction = 3
+	completionField    = 5
+	completionVariable = 6
+	completionModule   = 9
+	completionKeyword  = 14
+	completionFolder   = 19
+	completionConstant = 21
+	completionStruct   = 22
+)
+
 type completionItem struct {
 	Label            string              `json:"label"`
 	Kind             int                 `json:"kind,omitempty"`
@@ -109,25 +122,39 @@ func complete(uri, source string, pos position, stdRoot string) []completionItem
 			return result.docs.moduleCompletions(module, context.prefix)
 		}
 	}
+	module, owner, ok := result.selectorOwner(receiverParts, pos.Line+1)
+	if !ok {
+		return []completionItem{}
+	}
+	if owner == "" {
+		return result.docs.moduleCompletions(module, context.prefix)
+	}
+	return result.docs.memberCompletions(module, owner, context.prefix)
+}
+
+// selectorOwner resolves a receiver written as a selector chain, such as
+// `items.first().value` or `alias.module`, to the module and owner type whose
+// members follow it. The owner is "" when the chain names a module.
+func (a *analysis) selectorOwner(parts []selectorPart, line uint32) (string, string, bool) {
 	var receiverType *types.NodeType
 	partIndex := 1
-	module := result.importedPackage(receiverParts[0].name)
+	module := a.importedPackage(parts[0].name)
 	owner := ""
 	if module == "" {
-		if receiverParts[0].call {
-			receiverType = result.docs.functionReturns[result.file.PackageName+"\x00"+receiverParts[0].name]
+		if parts[0].call {
+			receiverType = a.docs.functionReturns[a.file.PackageName+"\x00"+parts[0].name]
 		} else {
-			receiverType = result.docs.completionTypeAt(result.file.PackageName, receiverParts[0].name, pos.Line+1)
+			receiverType = a.docs.completionTypeAt(a.file.PackageName, parts[0].name, line)
 			if receiverType == nil {
-				receiverType = findValueType(result.file.GlNode, receiverParts[0].name)
+				receiverType = findValueType(a.file.GlNode, parts[0].name)
 			}
 		}
-		module, owner = completionType(result, receiverType)
+		module, owner = completionType(a, receiverType)
 	}
-	for ; partIndex < len(receiverParts); partIndex++ {
-		part := receiverParts[partIndex]
+	for ; partIndex < len(parts); partIndex++ {
+		part := parts[partIndex]
 		if owner == "" && !part.call {
-			if target := result.docs.publicModuleAlias(module, part.name); target != "" {
+			if target := a.docs.publicModuleAlias(module, part.name); target != "" {
 				module = target
 				continue
 			}
@@ -137,19 +164,16 @@ func complete(uri, source string, pos position, stdRoot string) []completionItem
 			if owner != "" {
 				key = module + "\x00" + owner + "." + part.name
 			}
-			receiverType = result.docs.functionReturns[key]
+			receiverType = a.docs.functionReturns[key]
 		} else {
 			if owner == "" {
-				return []completionItem{}
+				return "", "", false
 			}
-			receiverType = result.docs.memberTypes[module+"\x00"+owner+"."+part.name]
+			receiverType = a.docs.memberTypes[module+"\x00"+owner+"."+part.name]
 		}
-		module, owner = completionType(result, receiverType)
+		module, owner = completionType(a, receiverType)
 	}
-	if owner == "" {
-		return result.docs.moduleCompletions(module, context.prefix)
-	}
-	return result.docs.memberCompletions(module, owner, context.prefix)
+	return module, owner, true
 }
 
 type usePathCompletionContext struct {
@@ -241,7 +265,7 @@ func usePathCompletions(uri string, context usePathCompletionContext, stdRoot st
 		if strings.HasPrefix(name, ".") {
 			continue
 		}
-		kind := 19 // CompletionItemKind.Folder
+		kind := completionFolder
 		if entry.IsDir() {
 			name += "/"
 		} else {
@@ -249,7 +273,7 @@ func usePathCompletions(uri string, context usePathCompletionContext, stdRoot st
 				continue
 			}
 			name = strings.TrimSuffix(name, filepath.Ext(name))
-			kind = 9 // CompletionItemKind.Module
+			kind = completionModule
 		}
 		if !strings.HasPrefix(name, prefix) {
 			continue
@@ -266,7 +290,7 @@ func usePathCompletions(uri string, context usePathCompletionContext, stdRoot st
 	if context.specifier == "" {
 		items = append([]completionItem{{
 			Label:      "std:",
-			Kind:       19,
+			Kind:       completionFolder,
 			Detail:     "standard library",
 			FilterText: "std:",
 			SortText:   "0:std:",
@@ -292,7 +316,8 @@ func sanitizeOtherSelectors(source string, activeLine int) string {
 				clean[i] = ' '
 			}
 		}
-		if lineNumber != activeLine {
+		// Doc comments routinely end with a full stop; they are not selectors.
+		if lineNumber != activeLine && !strings.HasPrefix(strings.TrimSpace(lineText), "#") {
 			trimmed := strings.TrimSpace(string(clean[lineStart:lineEnd]))
 			if trimmed == "if" || trimmed == "loop" || trimmed == "elif" {
 				for i := lineStart; i < lineEnd; i++ {
@@ -406,7 +431,7 @@ func (a *analysis) expressionCompletions(prefix string, line uint32) []completio
 		{"unsafe", "localize an unverifiable operation", "unsafe:\n    ${0}\n.."},
 	} {
 		if strings.HasPrefix(keyword.label, prefix) {
-			item := completionItem{Label: keyword.label, Kind: 14, Detail: keyword.detail, InsertText: keyword.insert}
+			item := completionItem{Label: keyword.label, Kind: completionKeyword, Detail: keyword.detail, InsertText: keyword.insert}
 			if strings.Contains(keyword.insert, "${") {
 				item.InsertTextFormat = 2
 			}
@@ -423,13 +448,13 @@ func (a *analysis) expressionCompletions(prefix string, line uint32) []completio
 			continue
 		}
 		detail := binding.name + " " + formatType(binding.valueType)
-		items[binding.name] = completionItem{Label: binding.name, Kind: 6, Detail: detail, Documentation: markdownContent(code(detail))}
+		items[binding.name] = completionItem{Label: binding.name, Kind: completionVariable, Detail: detail, Documentation: markdownContent(code(detail))}
 	}
 	for alias, module := range a.importedPackages() {
 		if strings.HasPrefix(alias, "__") || !strings.HasPrefix(alias, prefix) {
 			continue
 		}
-		items[alias] = completionItem{Label: alias, Kind: 9, Detail: "module " + alias, Documentation: markdownContent(a.docs.modules[module])}
+		items[alias] = completionItem{Label: alias, Kind: completionModule, Detail: "module " + alias, Documentation: markdownContent(a.docs.modules[module])}
 	}
 	result := make([]completionItem, 0, len(items))
 	for _, item := range items {
@@ -661,7 +686,7 @@ func (d *docIndex) moduleCompletions(module, prefix string) []completionItem {
 		if seen[alias] || !strings.HasPrefix(alias, prefix) {
 			continue
 		}
-		items = append(items, completionItem{Label: alias, Kind: 9, Detail: "module " + alias})
+		items = append(items, completionItem{Label: alias, Kind: completionModule, Detail: "module " + alias})
 	}
 	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
 	return items
@@ -693,12 +718,12 @@ func (d *docIndex) completions(keyPrefix, forbiddenDotPrefix, typedPrefix string
 			continue
 		}
 		seen[name] = true
-		kind := 3
+		kind := completionFunction
 		if declaredKind := d.completionKinds[key]; declaredKind != 0 {
 			kind = declaredKind
 		}
 		if forbiddenDotPrefix != "" && !strings.Contains(firstCodeLine(hover), "(") {
-			kind = 5
+			kind = completionField
 		}
 		label := name
 		filterText := ""
@@ -707,7 +732,7 @@ func (d *docIndex) completions(keyPrefix, forbiddenDotPrefix, typedPrefix string
 			label = "~" + name
 			filterText = name
 			insertText = name
-		} else if name == "proto" && kind == 2 {
+		} else if name == "proto" && kind == completionMethod {
 			filterText = name
 			insertText = "proto()"
 		}
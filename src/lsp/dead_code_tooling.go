@@ -0,0 +1,79 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diagnosticTags marks dead-code findings as unnecessary, so editors fade the
+// code rather than underlining it.
+func diagnosticTags(code string) []int {
+	switch code {
+	case "unused-function", "unused-global", "unused-import", "unused-variable", "unused-parameter", "unreachable-code":
+		return []int{1}
+	}
+	return nil
+}
+
+// deadCodeActions offers the quick fixes for one dead-code diagnostic. Edits are
+// line-based: declarations and statements occupy whole lines, and blocks end at
+// a `..` line indented less than their statements.
+func deadCodeActions(uri, source string, diag diagnostic) []codeAction {
+	lines := strings.SplitAfter(source, "\n")
+	line := diag.Range.Start.Line
+	if int(line) >= len(lines) {
+		return nil
+	}
+	action := func(title string, edit textEdit) codeAction {
+		return codeAction{Title: title, Kind: "quickfix", Diagnostics: []diagnostic{diag}, Edit: workspaceEdit{Changes: map[string][]textEdit{uri: {edit}}}}
+	}
+	removeLines := func(end uint32) textEdit {
+		return textEdit{Range: rangePosition{Start: position{Line: line}, End: position{Line: end}}}
+	}
+	allow := action(fmt.Sprintf("Allow `%s` here", diag.Code), textEdit{Range: rangePosition{Start: position{Line: line}, End: position{Line: line}}, NewText: fmt.Sprintf("@allow(%q)\n", diag.Code)})
+	prefix := action("Prefix the name with `_`", textEdit{Range: rangePosition{Start: diag.Range.Start, End: diag.Range.Start}, NewText: "_"})
+	switch diag.Code {
+	case "unused-import", "unused-global":
+		return []codeAction{action("Remove unused declaration", removeLines(line+1)), allow}
+	case "unused-function":
+		return []codeAction{action("Remove unused function", removeLines(functionEnd(lines, line))), allow}
+	case "unused-variable":
+		// An initializer containing a call may be needed for its effect.
+		if strings.Contains(lines[line], "(") {
+			return []codeAction{prefix}
+		}
+		return []codeAction{action("Remove unused variable", removeLines(line+1)), prefix}
+	case "unused-parameter":
+		return []codeAction{prefix}
+	case "unreachable-code":
+		return []codeAction{action("Remove unreachable code", removeLines(blockEnd(lines, line)))}
+	}
+	return nil
+}
+
+// functionEnd returns the line after the `..` which closes a top-level
+// function declared on start.
+func functionEnd(lines []string, start uint32) uint32 {
+	for index := start + 1; int(index) < len(lines); index++ {
+		if strings.TrimRight(lines[index], " \t\r\n") == ".." {
+			return index + 1
+		}
+	}
+	return start + 1
+}
+
+// blockEnd returns the first line after start which is not blank and is
+// indented less than start: the `..` which closes the enclosing block.
+func blockEnd(lines []string, start uint32) uint32 {
+	limit := indentation(lines[start])
+	for index := start + 1; int(index) < len(lines); index++ {
+		if strings.TrimSpace(lines[index]) != "" && indentation(lines[index]) < limit {
+			return index
+		}
+	}
+	return uint32(len(lines))
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
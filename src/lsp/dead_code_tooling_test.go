@@ -0,0 +1,81 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeadCodeDiagnosticsAreTaggedUnnecessary(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, "dead.mg")
+	source := "mod main\nhelper() void:\n..\nmain() void:\n    ret\n    value := 1\n..\n"
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	result := analyze(uri, source, testStdRoot())
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	codes := map[string]bool{}
+	for _, diag := range diagnosticsForFile(result.err, result.warnings, path) {
+		if len(diag.Tags) != 1 || diag.Tags[0] != 1 {
+			t.Fatalf("dead-code diagnostic is not tagged unnecessary: %#v", diag)
+		}
+		codes[diag.Code] = true
+	}
+	if !codes["unused-function"] || !codes["unreachable-code"] {
+		t.Fatalf("dead-code codes = %v", codes)
+	}
+}
+
+func TestDeadCodeQuickFixes(t *testing.T) {
+	source := "mod main\nuse \"std:io\" io\nhelper(value u64) void:\n    ret\n    other := 1\n    other = 2\n..\n"
+	uri := "file:///tmp/dead_actions.mg"
+	diagnostics := []diagnostic{
+		{Code: "unused-import", Range: rangePosition{Start: position{Line: 1, Character: 13}}},
+		{Code: "unused-function", Range: rangePosition{Start: position{Line: 2}}},
+		{Code: "unused-parameter", Range: rangePosition{Start: position{Line: 2, Character: 7}}},
+		{Code: "unreachable-code", Range: rangePosition{Start: position{Line: 4, Character: 4}}},
+	}
+	var output bytes.Buffer
+	s := &server{out: &output, documents: map[string]*document{uri: {URI: uri, Text: source}}}
+	params, _ := json.Marshal(map[string]any{"textDocument": map[string]any{"uri": uri}, "context": map[string]any{"diagnostics": diagnostics}})
+	if err := s.handleCodeAction(message{ID: json.RawMessage("1"), Params: params}); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Result []codeAction `json:"result"`
+	}
+	body := output.String()
+	if err := json.Unmarshal([]byte(body[strings.Index(body, "{"):]), &response); err != nil {
+		t.Fatal(err)
+	}
+	ranges := map[string]rangePosition{}
+	for _, action := range response.Result {
+		edits := action.Edit.Changes[uri]
+		if len(edits) != 1 {
+			t.Fatalf("action %q edits = %#v", action.Title, edits)
+		}
+		ranges[action.Title] = edits[0].Range
+		if action.Title == "Allow `unused-import` here" && edits[0].NewText != "@allow(\"unused-import\")\n" {
+			t.Fatalf("allow edit = %q", edits[0].NewText)
+		}
+	}
+	want := map[string]rangePosition{
+		"Remove unused declaration": {Start: position{Line: 1}, End: position{Line: 2}},
+		"Remove unused function":    {Start: position{Line: 2}, End: position{Line: 7}},
+		"Prefix the name with `_`":  {Start: position{Line: 2, Character: 7}, End: position{Line: 2, Character: 7}},
+		"Remove unreachable code":   {Start: position{Line: 4}, End: position{Line: 6}},
+	}
+	for title, expected := range want {
+		if got, ok := ranges[title]; !ok || got != expected {
+			t.Errorf("%s range = %#v (present %v), want %#v", title, got, ok, expected)
+		}
+	}
+}
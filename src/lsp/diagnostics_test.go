@@ -61,6 +61,24 @@ func TestDiagnosticsIncludeCodeAndRelatedLocation(t *testing.T) {
 	}
 }
 
+func TestDiagnosticsPublishSourceSpans(t *testing.T) {
+	path := `C:\project\main.mg`
+	warnings := []types.Diagnostic{{
+		Severity: types.SeverityWarning, FilePath: path, Message: "bad cast",
+		Token: types.Token{Repr: "as", Pos: types.FilePos{Line: 2, Col: 9}, End: types.FilePos{Line: 2, Col: 11}},
+		End:   types.FilePos{Line: 2, Col: 23},
+		Related: []types.DiagnosticRelated{{FilePath: path, Message: "declared here",
+			Token: types.Token{Repr: "é", Pos: types.FilePos{Line: 1, Col: 5}, End: types.FilePos{Line: 1, Col: 8}}}},
+	}}
+	got := diagnosticsForFile(nil, warnings, path)
+	if len(got) != 1 || got[0].Range != (rangePosition{Start: position{Line: 1, Character: 8}, End: position{Line: 1, Character: 22}}) {
+		t.Fatalf("diagnostic range = %#v", got)
+	}
+	if related := got[0].RelatedInformation[0].Location.Range; related.End != (position{Line: 0, Character: 7}) {
+		t.Fatalf("related range = %#v, want the token's recorded end", related)
+	}
+}
+
 func TestDidOpenPublishesCompilerDiagnostics(t *testing.T) {
 	directory := t.TempDir()
 	path := filepath.Join(directory, "broken.mg")
@@ -113,3 +131,26 @@ func TestDidOpenPublishesCompilerWarnings(t *testing.T) {
 		t.Fatalf("compiler warning was not published: %s", got)
 	}
 }
+
+func TestSyntaxErrorsAreAllReportedAlongsideSemanticAnalysis(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, "main.mg")
+	source := "mod main\nfirst() void:\n    x := 1 +\n..\nsecond() void:\n    if true > :\n    ..\n    missing()\n..\n"
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result := analyze((&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(), source, testStdRoot())
+	got := diagnosticsForFile(result.err, result.warnings, path)
+	lines := []uint32{}
+	for _, diagnostic := range got {
+		if diagnostic.Severity == 1 {
+			lines = append(lines, diagnostic.Range.Start.Line)
+		}
+	}
+	if len(lines) != 3 || lines[0] != 2 || lines[1] != 5 || lines[2] != 7 {
+		t.Fatalf("error lines = %v, want syntax errors on 2 and 5 and the unknown function on 7: %#v", lines, got)
+	}
+	if !strings.Contains(got[2].Message, "unknown function") {
+		t.Fatalf("semantic diagnostic = %q, want unknown function", got[2].Message)
+	}
+}
@@ -1,31 +1,13 @@
 package lsp
 
 import (
+	"Magma/src/docgen"
 	magmatypes "Magma/src/magma_types"
 	"Magma/src/types"
 	"fmt"
 	"strings"
 )
 
-type documentation struct {
-	description []string
-	params      []docParam
-	returns     string
-	warnings    []string
-	notes       []string
-	complexity  []string
-	throws      []string
-	ownership   []string
-	safety      []string
-	mustCall    []string
-	platforms   []string
-	deprecated  []string
-	see         []string
-	examples    []string
-}
-
-type docParam struct{ name, text string }
-
 type docIndex struct {
 	byNode       map[any]string
 	modules      map[string]string
@@ -45,6 +27,14 @@ type docIndex struct {
 	functionReturns       map[string]*types.NodeType
 	primitiveModules      map[string]string
 	publicModuleAliases   map[string]map[string]string
+	// functions and functionDocs keep source declarations, generic templates
+	// included, for signature help. Keys match functionReturns.
+	functions    map[string]*types.NodeFuncDef
+	functionDocs map[string]docgen.Documentation
+	// semanticBindings classifies the function name, parameter, and local
+	// tokens of source declarations, keyed like valueHovers, for the generic
+	// templates the checked tree no longer has.
+	semanticBindings map[string]semanticClass
 }
 
 // completionBinding is captured from the source AST before monomorphization.
@@ -59,12 +49,12 @@ type completionBinding struct {
 }
 
 func buildDocIndex(state *types.SharedState) *docIndex {
-	index := &docIndex{byNode: map[any]string{}, modules: map[string]string{}, symbols: map[string]string{}, hoverSymbols: map[string]string{}, hoverByName: map[string]string{}, valueHovers: map[string]string{}, completionVisible: map[string]bool{}, completionKinds: map[string]int{}, completionDestructors: map[string]bool{}, memberTypes: map[string]*types.NodeType{}, expressionSymbols: map[string]map[string]completionItem{}, functionReturns: map[string]*types.NodeType{}, primitiveModules: map[string]string{}, publicModuleAliases: map[string]map[string]string{}}
+	index := &docIndex{byNode: map[any]string{}, modules: map[string]string{}, symbols: map[string]string{}, hoverSymbols: map[string]string{}, hoverByName: map[string]string{}, valueHovers: map[string]string{}, completionVisible: map[string]bool{}, completionKinds: map[string]int{}, completionDestructors: map[string]bool{}, memberTypes: map[string]*types.NodeType{}, expressionSymbols: map[string]map[string]completionItem{}, functionReturns: map[string]*types.NodeType{}, functions: map[string]*types.NodeFuncDef{}, functionDocs: map[string]docgen.Documentation{}, semanticBindings: map[string]semanticClass{}, primitiveModules: map[string]string{}, publicModuleAliases: map[string]map[string]string{}}
 	for _, file := range state.Files {
 		if file == nil || file.GlNode == nil {
 			continue
 		}
-		byLine, module := parseDocumentation(string(file.Content))
+		byLine, module := docgen.ParseSource(string(file.Content))
 		for alias := range file.GlNode.PublicImportAlias {
 			target := file.GlNode.ImportAlias[alias]
 			if target == "" {
@@ -75,7 +65,7 @@ func buildDocIndex(state *types.SharedState) *docIndex {
 			}
 			index.publicModuleAliases[file.PackageName][alias] = target
 		}
-		if text := module.markdown(); text != "" {
+		if text := module.Markdown(); text != "" {
 			index.modules[file.PackageName] = text
 		}
 		for primitive := range file.GlNode.PrimitiveMethods {
@@ -85,47 +75,55 @@ func buildDocIndex(state *types.SharedState) *docIndex {
 			switch node := declaration.(type) {
 			case *types.NodeFuncDef:
 				name := flattenName(node.Class.NameNode)
-				docs := index.add(file, name, nameLine(node.Class.NameNode), node, byLine)
+				docs := index.add(file, name, docgen.DeclarationLine(node.Class.NameNode), node, byLine)
 				index.addHover(file.PackageName, name, joinHover(code(formatFunction(node)), docs))
 				index.completionVisible[file.PackageName+"\x00"+name] = node.IsPublic || strings.Contains(name, ".")
-				kind := 3 // CompletionItemKind.Function
+				kind := completionFunction
 				if strings.Contains(name, ".") {
-					kind = 2 // CompletionItemKind.Method
+					kind = completionMethod
 				}
 				key := file.PackageName + "\x00" + name
 				index.completionKinds[key] = kind
 				index.completionDestructors[key] = node.IsDestructor
 				index.functionReturns[file.PackageName+"\x00"+name] = node.ReturnType
+				index.functions[key] = node
+				if doc, ok := byLine[docgen.DeclarationLine(node.Class.NameNode)]; ok {
+					index.functionDocs[key] = doc
+				}
 				if !strings.Contains(name, ".") {
-					index.addExpressionSymbol(file.PackageName, completionItem{Label: name, Kind: 3, Detail: formatFunction(node), Documentation: markdownContent(index.hoverSymbols[file.PackageName+"\x00"+name])})
+					index.addExpressionSymbol(file.PackageName, completionItem{Label: name, Kind: completionFunction, Detail: formatFunction(node), Documentation: markdownContent(index.hoverSymbols[file.PackageName+"\x00"+name])})
 				}
 			case *types.NodeExprVarDef:
 				name := flattenName(node.Name)
 				detail := formatVariable(node)
-				docs := index.add(file, name, nameLine(node.Name), node, byLine)
+				docs := index.add(file, name, docgen.DeclarationLine(node.Name), node, byLine)
 				index.addHover(file.PackageName, name, joinHover(code(detail), docs))
 				index.completionVisible[file.PackageName+"\x00"+name] = node.IsPublic
-				index.completionKinds[file.PackageName+"\x00"+name] = 6
-				index.addExpressionSymbol(file.PackageName, completionItem{Label: name, Kind: 6, Detail: detail, Documentation: markdownContent(index.hoverSymbols[file.PackageName+"\x00"+name])})
+				index.completionKinds[file.PackageName+"\x00"+name] = completionVariable
+				index.addExpressionSymbol(file.PackageName, completionItem{Label: name, Kind: completionVariable, Detail: detail, Documentation: markdownContent(index.hoverSymbols[file.PackageName+"\x00"+name])})
 			case *types.NodeConstDef:
 				if node.VarDef != nil {
 					name := flattenName(node.VarDef.Name)
 					detail := formatVariable(node.VarDef)
-					docs := index.add(file, name, nameLine(node.VarDef.Name), node, byLine)
+					docs := index.add(file, name, docgen.DeclarationLine(node.VarDef.Name), node, byLine)
 					index.addHover(file.PackageName, name, joinHover(code(detail), docs))
 					index.completionVisible[file.PackageName+"\x00"+name] = node.VarDef.IsPublic
-					index.completionKinds[file.PackageName+"\x00"+name] = 21
-					index.addExpressionSymbol(file.PackageName, completionItem{Label: name, Kind: 21, Detail: detail, Documentation: markdownContent(index.hoverSymbols[file.PackageName+"\x00"+name])})
+					index.completionKinds[file.PackageName+"\x00"+name] = completionConstant
+					index.addExpressionSymbol(file.PackageName, completionItem{Label: name, Kind: completionConstant, Detail: detail, Documentation: markdownContent(index.hoverSymbols[file.PackageName+"\x00"+name])})
 				}
 			case *types.NodeStructDef:
 				name := flattenName(node.Class.NameNode)
-				text := index.add(file, name, nameLine(node.Class.NameNode), node, byLine)
+				text := index.add(file, name, docgen.DeclarationLine(node.Class.NameNode), node, byLine)
 				index.addHover(file.PackageName, name, joinHover(code("struct "+name), text))
 				index.completionVisible[file.PackageName+"\x00"+name] = node.IsPublic
-				index.completionKinds[file.PackageName+"\x00"+name] = 22 // CompletionItemKind.Struct
+				index.completionKinds[file.PackageName+"\x00"+name] = completionStruct
 				for _, field := range node.Class.ArgsNode.Args {
 					key := file.PackageName + "\x00" + name + "." + field.Name
-					index.hoverSymbols[key] = code(field.Name + " " + formatType(field.TypeNode))
+					signature := field.Name + " " + formatType(field.TypeNode)
+					if field.DefaultSource != "" {
+						signature += " = " + field.DefaultSource
+					}
+					index.hoverSymbols[key] = code(signature)
 					index.completionVisible[key] = true
 					index.memberTypes[key] = field.TypeNode
 				}
@@ -148,7 +146,7 @@ func buildDocIndex(state *types.SharedState) *docIndex {
 						}
 						index.hoverSymbols[key] = joinHover(code(detail), documentation)
 						index.completionVisible[key] = true
-						index.completionKinds[key] = 2 // CompletionItemKind.Method
+						index.completionKinds[key] = completionMethod
 					}
 				}
 			case *types.NodeTypeAlias:
@@ -170,6 +168,7 @@ func buildDocIndex(state *types.SharedState) *docIndex {
 		for _, declaration := range file.GlNode.Declarations {
 			if function, ok := declaration.(*types.NodeFuncDef); ok {
 				index.indexFunctionValueUsages(file.PackageName, file.GlNode.ImportAlias, function)
+				index.indexSemanticBindings(file.PackageName, function)
 			}
 		}
 	}
@@ -204,7 +203,7 @@ func (d *docIndex) addExpressionSymbol(module string, item completionItem) {
 // available while the source tree is intact.
 func (d *docIndex) indexFunctionValueUsages(module string, aliases map[string]string, function *types.NodeFuncDef) {
 	bindings := map[string]*types.NodeType{}
-	functionLine := nameLine(function.Class.NameNode)
+	functionLine := docgen.DeclarationLine(function.Class.NameNode)
 	for _, argument := range function.Class.ArgsNode.Args {
 		bindings[argument.Name] = argument.TypeNode
 		declarationLine := argument.Tk.Pos.Line
@@ -308,17 +307,28 @@ func (d *docIndex) inferredCompletionType(module string, aliases map[string]stri
 		}
 	case *types.NodeExprTry:
 		return d.inferredCompletionType(module, aliases, node.Call, bindings)
+	case *types.NodeExprCast:
+		return node.Type
 	case *types.NodeExprCall:
 		if node.AssociatedFnDef != nil {
 			return node.AssociatedFnDef.ReturnType
 		}
 		if callee, ok := node.Callee.(*types.NodeExprName); ok {
+			// Until monomorphization resolves it, a constructor is a call to
+			// its struct.
+			constructed := &types.NodeType{KindNode: &types.NodeTypeNamed{NameNode: callee.Name, GenericArgs: node.GenericArgs}}
 			if name, ok := callee.Name.(*types.NodeNameSingle); ok {
+				if d.completionKinds[module+"\x00"+name.Name] == completionStruct {
+					return constructed
+				}
 				return d.functionReturns[module+"\x00"+name.Name]
 			}
 			if name, ok := callee.Name.(*types.NodeNameComposite); ok && len(name.Parts) >= 2 {
 				first, member := name.Parts[0], name.Parts[len(name.Parts)-1]
 				if imported := aliases[first]; imported != "" {
+					if d.completionKinds[imported+"\x00"+member] == completionStruct {
+						return constructed
+					}
 					return d.functionReturns[imported+"\x00"+member]
 				}
 				ownerModule, owner := d.completionTypeIdentity(module, aliases, bindings[first])
@@ -489,12 +499,12 @@ func (d *docIndex) addHover(module, name, value string) {
 	}
 }
 
-func (d *docIndex) add(file *types.FileCtx, name string, line uint32, node any, byLine map[uint32]documentation) string {
+func (d *docIndex) add(file *types.FileCtx, name string, line uint32, node any, byLine map[uint32]docgen.Documentation) string {
 	doc, ok := byLine[line]
 	if !ok {
 		return ""
 	}
-	text := doc.markdown()
+	text := doc.Markdown()
 	if text == "" {
 		return ""
 	}
@@ -503,230 +513,6 @@ func (d *docIndex) add(file *types.FileCtx, name string, line uint32, node any,
 	return text
 }
 
-func parseDocumentation(source string) (map[uint32]documentation, documentation) {
-	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
-	result := map[uint32]documentation{}
-	var module documentation
-	moduleLine := -1
-	for i, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), "mod ") && strings.TrimLeft(line, " \t") == line {
-			moduleLine = i
-			break
-		}
-	}
-	for i := 0; i < len(lines); {
-		// Indented comments belong to function bodies and are never docs.
-		if !strings.HasPrefix(lines[i], "#") {
-			i++
-			continue
-		}
-		start := i
-		comments := []string{}
-		for i < len(lines) && strings.HasPrefix(lines[i], "#") {
-			text := strings.TrimPrefix(lines[i], "#")
-			comments = append(comments, strings.TrimPrefix(text, " "))
-			i++
-		}
-		doc := parseDocBlock(comments)
-		if start == moduleLine+1 {
-			module = doc
-			continue
-		}
-		if i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.TrimLeft(lines[i], " \t") == lines[i] {
-			// Source and token lines are one-based.
-			result[uint32(i+1)] = doc
-		}
-	}
-	return result, module
-}
-
-func parseDocBlock(lines []string) documentation {
-	doc := documentation{}
-	// A tag owns every following comment line until the next tag. Folding the
-	// block first keeps the individual tag parsers simple while preserving
-	// intentional line breaks (especially for examples and warnings).
-	folded := make([]string, 0, len(lines))
-	for _, line := range lines {
-		if len(folded) != 0 && strings.HasPrefix(folded[len(folded)-1], "@") && !strings.HasPrefix(line, "@") {
-			folded[len(folded)-1] += "\n" + line
-			continue
-		}
-		folded = append(folded, line)
-	}
-	lines = folded
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-		if rest, ok := strings.CutPrefix(line, "@param "); ok {
-			name, text, _ := strings.Cut(strings.TrimSpace(rest), " ")
-			doc.params = append(doc.params, docParam{name: name, text: strings.TrimSpace(text)})
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@returns "); ok {
-			doc.returns = strings.TrimSpace(rest)
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@return "); ok {
-			doc.returns = strings.TrimSpace(rest)
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@warning "); ok {
-			doc.warnings = append(doc.warnings, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@note "); ok {
-			doc.notes = append(doc.notes, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@complexity "); ok {
-			doc.complexity = append(doc.complexity, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@throws "); ok {
-			doc.throws = append(doc.throws, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@ownership "); ok {
-			doc.ownership = append(doc.ownership, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@safety "); ok {
-			doc.safety = append(doc.safety, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@mustcall "); ok {
-			doc.mustCall = append(doc.mustCall, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@platform "); ok {
-			doc.platforms = append(doc.platforms, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@deprecated "); ok {
-			doc.deprecated = append(doc.deprecated, strings.TrimSpace(rest))
-			continue
-		}
-		if rest, ok := strings.CutPrefix(line, "@see "); ok {
-			doc.see = append(doc.see, strings.TrimSpace(rest))
-			continue
-		}
-		if line == "@example" || strings.HasPrefix(line, "@example ") || strings.HasPrefix(line, "@example\n") {
-			example := strings.TrimPrefix(line, "@example")
-			exampleLines := strings.Split(strings.Trim(example, "\n"), "\n")
-			for i := range exampleLines {
-				exampleLines[i] = strings.TrimPrefix(exampleLines[i], "  ")
-			}
-			doc.examples = append(doc.examples, strings.TrimSpace(strings.Join(exampleLines, "\n")))
-			continue
-		}
-		doc.description = append(doc.description, line)
-	}
-	return doc
-}
-
-func (d documentation) markdown() string {
-	parts := []string{}
-	if text := strings.TrimSpace(strings.Join(d.description, "\n")); text != "" {
-		parts = append(parts, text)
-	}
-	for _, text := range d.deprecated {
-		parts = append(parts, blockquote("Deprecated", text))
-	}
-	for _, text := range d.warnings {
-		parts = append(parts, blockquote("⚠ Warning", text))
-	}
-	for _, text := range d.safety {
-		parts = append(parts, blockquote("Safety", text))
-	}
-	for _, text := range d.notes {
-		parts = append(parts, blockquote("Note", text))
-	}
-	if len(d.params) != 0 {
-		lines := []string{"**Parameters**"}
-		for _, param := range d.params {
-			line := fmt.Sprintf("- `%s`", param.name)
-			if param.text != "" {
-				line += " — " + param.text
-			}
-			lines = append(lines, line)
-		}
-		parts = append(parts, strings.Join(lines, "\n"))
-	}
-	if d.returns != "" {
-		parts = append(parts, "**Returns:** "+d.returns)
-	}
-	if len(d.throws) != 0 {
-		parts = append(parts, markdownList("Throws", d.throws, false))
-	}
-	if len(d.ownership) != 0 {
-		parts = append(parts, markdownList("Ownership", d.ownership, false))
-	}
-	if len(d.mustCall) != 0 {
-		parts = append(parts, markdownList("Must call", d.mustCall, true))
-	}
-	if len(d.complexity) != 0 {
-		parts = append(parts, markdownList("Complexity", d.complexity, false))
-	}
-	if len(d.platforms) != 0 {
-		parts = append(parts, markdownList("Platforms", d.platforms, false))
-	}
-	for _, example := range d.examples {
-		parts = append(parts, "**Example**\n\n```magma\n"+example+"\n```")
-	}
-	if len(d.see) != 0 {
-		quoted := make([]string, 0, len(d.see))
-		for _, symbol := range d.see {
-			quoted = append(quoted, "`"+symbol+"`")
-		}
-		parts = append(parts, "**See also:** "+strings.Join(quoted, ", "))
-	}
-	return strings.Join(parts, "\n\n")
-}
-
-func blockquote(title, text string) string {
-	lines := strings.Split(text, "\n")
-	if len(lines) == 0 {
-		return "> **" + title + ":**"
-	}
-	lines[0] = "> **" + title + ":** " + lines[0]
-	for i := 1; i < len(lines); i++ {
-		lines[i] = "> " + lines[i]
-	}
-	return strings.Join(lines, "\n")
-}
-
-func markdownList(title string, values []string, codeValues bool) string {
-	if len(values) == 1 {
-		value := values[0]
-		if codeValues {
-			value = "`" + value + "`"
-		}
-		return "**" + title + ":** " + value
-	}
-	lines := []string{"**" + title + "**"}
-	for _, value := range values {
-		if codeValues {
-			value = "`" + value + "`"
-		}
-		lines = append(lines, "- "+value)
-	}
-	return strings.Join(lines, "\n")
-}
-
-func nameLine(name types.NodeName) uint32 {
-	switch node := name.(type) {
-	case *types.NodeNameSingle:
-		return node.Tk.Pos.Line
-	case *types.NodeNameComposite:
-		if len(node.Tokens) != 0 {
-			// For member declarations the owner token may be inherited from a
-			// generic type node. The final token is the declared member itself and
-			// therefore identifies the line immediately following its docs.
-			return node.Tokens[len(node.Tokens)-1].Pos.Line
-		}
-	}
-	return 0
-}
-
 func (a *analysis) withDocs(base string, node any) string {
 	if a == nil || a.docs == nil {
 		return base
@@ -0,0 +1,253 @@
+package lsp
+
+import (
+	"Magma/src/types"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+type inlayHint struct {
+	Position     position `json:"position"`
+	Label        string   `json:"label"`
+	Kind         int      `json:"kind,omitempty"`
+	PaddingLeft  bool     `json:"paddingLeft,omitempty"`
+	PaddingRight bool     `json:"paddingRight,omitempty"`
+}
+
+func (s *server) handleInlayHint(msg message) error {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Range rangePosition `json:"range"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	d := s.documents[p.TextDocument.URI]
+	if d == nil {
+		return s.respond(msg.ID, []inlayHint{})
+	}
+	if d.result == nil {
+		d.result = s.analyzeDocument(d)
+	}
+	return s.respond(msg.ID, d.result.inlayHints(p.Range))
+}
+
+// inlayHints annotates the checked functions of the document with the types
+// of `:=` locals, the parameter names at call sites, and the ownership effect
+// of each argument: `move` where a temporary flows into a `$T` parameter and
+// `borrow` where a destructible local is lent to an unmarked one. Generic
+// templates are skipped; only their instances are checked, and an instance
+// does not describe the source its tokens point into.
+func (a *analysis) inlayHints(within rangePosition) []inlayHint {
+	hints := []inlayHint{}
+	if a == nil || a.file == nil || a.file.GlNode == nil {
+		return hints
+	}
+	tokens := map[string]int{}
+	for i, token := range a.file.Tokens {
+		tokens[tokenPositionKey(token)] = i
+	}
+	// Nodes the compiler duplicates keep their source tokens; each hint is
+	// reported once.
+	seen := map[inlayHint]bool{}
+	add := func(hint inlayHint) {
+		if seen[hint] || !rangeContains(within, hint.Position) {
+			return
+		}
+		seen[hint] = true
+		hints = append(hints, hint)
+	}
+	for _, declaration := range a.file.GlNode.Declarations {
+		function, ok := declaration.(*types.NodeFuncDef)
+		if !ok || function.Derived || len(function.Class.TypeParams) != 0 || strings.Contains(function.AbsName, "__g__") {
+			continue
+		}
+		walkAST(&function.Body, func(value any) bool {
+			switch node := value.(type) {
+			case *types.NodeExprVarDefAssign:
+				if node.Tk.KeywType == types.KwInfer && node.VarDef != nil {
+					valueType := node.VarDef.Type
+					if valueType == nil && node.AssignExpr != nil {
+						valueType = node.AssignExpr.GetInferredType()
+					}
+					if hint, ok := typeHint(node.VarDef.Name, valueType); ok {
+						add(hint)
+					}
+				}
+			case *types.NodeExprDestructureAssign:
+				// The two bindings are inferred together, so the error name
+				// followed by `:=` marks both.
+				if errName, ok := node.ErrDef.Name.(*types.NodeNameSingle); ok && a.tokenFollows(tokens, errName.Tk, types.KwInfer) {
+					for _, variable := range []*types.NodeExprVarDef{&node.ValueDef, &node.ErrDef} {
+						if hint, ok := typeHint(variable.Name, variable.Type); ok {
+							add(hint)
+						}
+					}
+				}
+			case *types.NodeExprCall:
+				for _, hint := range a.argumentHints(tokens, node) {
+					add(hint)
+				}
+			}
+			return true
+		})
+	}
+	sort.SliceStable(hints, func(i, j int) bool {
+		if hints[i].Position.Line != hints[j].Position.Line {
+			return hints[i].Position.Line < hints[j].Position.Line
+		}
+		return hints[i].Position.Character < hints[j].Position.Character
+	})
+	return hints
+}
+
+func typeHint(name types.NodeName, valueType *types.NodeType) (inlayHint, bool) {
+	single, ok := name.(*types.NodeNameSingle)
+	if !ok || single.Tk.Pos.Line == 0 || single.Name == "_" || valueType == nil {
+		return inlayHint{}, false
+	}
+	return inlayHint{Position: spanRange(single.Tk.Span()).End, Label: signatureType(valueType), Kind: 1, PaddingLeft: true}, true
+}
+
+// argumentHints labels each argument of a resolved call with its parameter
+// name and ownership effect. Arguments are located in the source tokens after
+// the call's `(`, so calls synthesized by the compiler, whose tokens are
+// borrowed from elsewhere, are recognized by a mismatch and skipped. A call
+// with named arguments already shows its parameters.
+func (a *analysis) argumentHints(tokens map[string]int, call *types.NodeExprCall) []inlayHint {
+	if call.AssociatedFnDef == nil || call.IsFuncPointer || len(call.Args) == 0 || len(call.ArgNames) != 0 {
+		return nil
+	}
+	parameters := callParameters(call.AssociatedFnDef)
+	starts := a.argumentStarts(tokens, call.Tk)
+	if len(parameters) != len(call.Args) || len(starts) != len(call.Args) {
+		return nil
+	}
+	hints := []inlayHint{}
+	for i, argument := range call.Args {
+		parameter := parameters[i]
+		at := spanRange(starts[i].Span()).Start
+		named := argument
+		move, moved := argument.(*types.NodeExprMove)
+		if moved {
+			named = move.Expr
+		}
+		if variable := argumentVariable(named); variable == nil || flattenName(variable.Name) != parameter.Name {
+			hints = append(hints, inlayHint{Position: at, Label: parameter.Name + ":", Kind: 2, PaddingRight: true})
+		}
+		if parameter.TypeNode == nil {
+			continue
+		}
+		variable := argumentVariable(argument)
+		if parameter.TypeNode.Owned && !moved && variable == nil {
+			hints = append(hints, inlayHint{Position: at, Label: "move", PaddingRight: true})
+		} else if !parameter.TypeNode.Owned && variable != nil && destructible(argumentType(argument, variable)) {
+			hints = append(hints, inlayHint{Position: at, Label: "borrow", PaddingRight: true})
+		}
+	}
+	return hints
+}
+
+// argumentStarts returns the first source token of each argument of the call
+// whose callee ends with callee.
+func (a *analysis) argumentStarts(tokens map[string]int, callee types.Token) []types.Token {
+	index, ok := tokens[tokenPositionKey(callee)]
+	if !ok || callee.Pos.Line == 0 {
+		return nil
+	}
+	source := a.file.Tokens
+	index++
+	if index < len(source) && source[index].KeywType == types.KwBrackOp {
+		index = closingToken(source, index, types.KwBrackOp, types.KwBrackCl) + 1
+	}
+	if index <= 0 || index >= len(source) || source[index].KeywType != types.KwParenOp {
+		return nil
+	}
+	starts := []types.Token{}
+	depth := 0
+	expectArgument := true
+	for index++; index < len(source); index++ {
+		token := source[index]
+		switch token.KeywType {
+		case types.KwParenOp, types.KwBrackOp:
+			depth++
+		case types.KwParenCl, types.KwBrackCl:
+			if depth == 0 {
+				return starts
+			}
+			depth--
+		case types.KwComma:
+			if depth == 0 {
+				expectArgument = true
+				continue
+			}
+		case types.KwNewline:
+			continue
+		}
+		if expectArgument {
+			starts = append(starts, token)
+			expectArgument = false
+		}
+	}
+	return nil
+}
+
+// closingToken returns the index of the token closing the one at open, or -1.
+func closingToken(source []types.Token, open int, opening, closing types.KwType) int {
+	depth := 0
+	for i := open; i < len(source); i++ {
+		switch source[i].KeywType {
+		case opening:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (a *analysis) tokenFollows(tokens map[string]int, token types.Token, keyword types.KwType) bool {
+	index, ok := tokens[tokenPositionKey(token)]
+	return ok && token.Pos.Line != 0 && index+1 < len(a.file.Tokens) && a.file.Tokens[index+1].KeywType == keyword
+}
+
+// argumentVariable returns the variable an argument names directly.
+func argumentVariable(argument types.NodeExpr) *types.NodeExprVarDef {
+	name, ok := argument.(*types.NodeExprName)
+	if !ok || len(name.MemberAccesses) != 0 {
+		return nil
+	}
+	switch node := name.AssociatedNode.(type) {
+	case *types.NodeExprVarDef:
+		return node
+	case *types.NodeExprVarDefAssign:
+		return node.VarDef
+	}
+	return nil
+}
+
+func argumentType(argument types.NodeExpr, variable *types.NodeExprVarDef) *types.NodeType {
+	if variable.Type != nil {
+		return variable.Type
+	}
+	return argument.GetInferredType()
+}
+
+func destructible(valueType *types.NodeType) bool {
+	return valueType != nil && (valueType.Destructor != nil || valueType.Owned)
+}
+
+func rangeContains(within rangePosition, at position) bool {
+	if within == (rangePosition{}) {
+		return true
+	}
+	after := at.Line > within.Start.Line || (at.Line == within.Start.Line && at.Character >= within.Start.Character)
+	before := at.Line < within.End.Line || (at.Line == within.End.Line && at.Character <= within.End.Character)
+	return after && before
+}
@@ -0,0 +1,75 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInlayHintsShowTypesParametersAndOwnership(t *testing.T) {
+	source := "mod main\n" +
+		"Res(id u64)\n" +
+		"destr Res.close() void:\n" +
+		"..\n" +
+		"make() $Res:\n" +
+		"    ret Res(id=1)\n" +
+		"..\n" +
+		"show(res Res) void:\n" +
+		"..\n" +
+		"keep(res $Res) void:\n" +
+		"    res.close()\n" +
+		"..\n" +
+		"risky() !u64:\n" +
+		"    ret 1\n" +
+		"..\n" +
+		"main() void:\n" +
+		"    r := make()\n" +
+		"    show(r)\n" +
+		"    keep(make())\n" +
+		"    value, err := risky()\n" +
+		"    res := make()\n" +
+		"    show(res)\n" +
+		"    keep(move res)\n" +
+		"    keep(move r)\n" +
+		"..\n"
+	path := filepath.Join(t.TempDir(), "hints.mg")
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result := analyze("file:///"+filepath.ToSlash(path), source, testStdRoot())
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	got := map[string]bool{}
+	for _, hint := range result.inlayHints(rangePosition{}) {
+		got[fmt.Sprintf("%d:%d %s", hint.Position.Line, hint.Position.Character, hint.Label)] = true
+	}
+	for _, want := range []string{
+		"16:5 $Res",   // r := make()
+		"17:9 res:",   // show(r)
+		"17:9 borrow", // a destructible local lent to `Res`
+		"18:9 res:",   // keep(make())
+		"18:9 move",   // a fresh owner flows into `$Res`
+		"19:9 u64",    // value, err := risky()
+		"19:14 error",
+		"23:9 res:", // keep(move r)
+	} {
+		if !got[want] {
+			t.Errorf("missing hint %q in %v", want, got)
+		}
+	}
+	for _, unwanted := range []string{
+		"21:9 res:", // show(res) already spells the parameter
+		"22:9 res:", // so does keep(move res)
+		"22:9 move", // the move is explicit
+		"22:9 borrow",
+	} {
+		if got[unwanted] {
+			t.Errorf("unexpected hint %q", unwanted)
+		}
+	}
+	if hints := result.inlayHints(rangePosition{Start: position{Line: 19}, End: position{Line: 19, Character: 40}}); len(hints) != 2 {
+		t.Fatalf("hints within line 19 = %#v", hints)
+	}
+}
@@ -0,0 +1,322 @@
+package lsp
+
+import (
+	"Magma/src/types"
+	"encoding/json"
+	"sort"
+)
+
+// hierarchyItem is a declaration in the type or call hierarchy: a struct or
+// prototype, or a function. Data carries the document whose analysis
+// resolved it, which answers the follow-up requests.
+type hierarchyItem struct {
+	Name           string        `json:"name"`
+	Kind           int           `json:"kind"`
+	Detail         string        `json:"detail,omitempty"`
+	URI            string        `json:"uri"`
+	Range          rangePosition `json:"range"`
+	SelectionRange rangePosition `json:"selectionRange"`
+	Data           hierarchyData `json:"data"`
+}
+
+type hierarchyData struct {
+	Document string `json:"document"`
+	Module   string `json:"module"`
+	Name     string `json:"name"`
+}
+
+// prototypeSymbol is the prototype relationship operand under the cursor: a
+// struct or prototype, or with method set, a prototype requirement.
+type prototypeSymbol struct {
+	module string
+	name   string
+	method string
+}
+
+func (s *server) handleImplementation(msg message) error {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Position position `json:"position"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	d := s.documents[p.TextDocument.URI]
+	if d == nil {
+		return s.respond(msg.ID, []location{})
+	}
+	if d.result == nil {
+		d.result = s.analyzeDocument(d)
+	}
+	return s.respond(msg.ID, d.result.implementations(p.Position))
+}
+
+func (s *server) handlePrepareTypeHierarchy(msg message) error {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Position position `json:"position"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	d := s.documents[p.TextDocument.URI]
+	if d == nil {
+		return s.respond(msg.ID, nil)
+	}
+	if d.result == nil {
+		d.result = s.analyzeDocument(d)
+	}
+	symbol, ok := d.result.prototypeSymbolAt(p.Position)
+	if !ok || symbol.method != "" {
+		return s.respond(msg.ID, nil)
+	}
+	item, ok := d.result.typeHierarchyItem(d.URI, d.result.typeDefinitions()[symbol.module+"\x00"+symbol.name])
+	if !ok {
+		return s.respond(msg.ID, nil)
+	}
+	return s.respond(msg.ID, []hierarchyItem{item})
+}
+
+// handleTypeHierarchy answers typeHierarchy/supertypes and
+// typeHierarchy/subtypes from the analysis of the document which prepared the
+// item.
+func (s *server) handleTypeHierarchy(msg message, supertypes bool) error {
+	var p struct {
+		Item hierarchyItem `json:"item"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	d := s.documents[p.Item.Data.Document]
+	if d == nil {
+		return s.respond(msg.ID, []hierarchyItem{})
+	}
+	if d.result == nil {
+		d.result = s.analyzeDocument(d)
+	}
+	definitions := d.result.typeDefinitions()
+	definition := definitions[p.Item.Data.Module+"\x00"+p.Item.Data.Name]
+	if definition == nil {
+		return s.respond(msg.ID, []hierarchyItem{})
+	}
+	var related []*types.StructDef
+	if supertypes {
+		related = prototypeSupertypes(definition, definitions)
+	} else {
+		related = prototypeSubtypes(definition, definitions)
+	}
+	items := []hierarchyItem{}
+	for _, relative := range related {
+		if item, ok := d.result.typeHierarchyItem(d.URI, relative); ok {
+			items = append(items, item)
+		}
+	}
+	return s.respond(msg.ID, items)
+}
+
+// implementations lists the declarations implementing the prototype under
+// pos, or for a requirement, each implementation's method. Bases count every
+// struct which implements an extending prototype. An implementation which
+// leaves a requirement to its default body has no method of its own and is
+// not listed for it.
+func (a *analysis) implementations(pos position) []location {
+	result := []location{}
+	symbol, ok := a.prototypeSymbolAt(pos)
+	if !ok {
+		return result
+	}
+	definitions := a.typeDefinitions()
+	proto := definitions[symbol.module+"\x00"+symbol.name]
+	if proto == nil || !proto.IsProto {
+		return result
+	}
+	seen := map[location]bool{}
+	for _, key := range sortedKeys(definitions) {
+		definition := definitions[key]
+		if definition.IsProto || !implements(definition, proto) {
+			continue
+		}
+		declaration := definition.Module + "\x00" + sourceName(definition.Name)
+		if symbol.method != "" {
+			method := definition.Funcs[symbol.method]
+			if method == nil {
+				continue
+			}
+			declaration = definition.Module + "\x00" + flattenName(method.Class.NameNode)
+		}
+		if target, ok := a.definitions[declaration]; ok && !seen[target] {
+			seen[target] = true
+			result = append(result, target)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].URI != result[j].URI {
+			return result[i].URI < result[j].URI
+		}
+		return result[i].Range.Start.Line < result[j].Range.Start.Line
+	})
+	return result
+}
+
+// prototypeSymbolAt resolves the struct, prototype, or requirement under pos.
+// A call is resolved semantically; a name through its module qualifier, or as
+// the member of its owner in `Owner.method`.
+func (a *analysis) prototypeSymbolAt(pos position) (prototypeSymbol, bool) {
+	if a == nil || a.file == nil || a.file.GlNode == nil || a.shared == nil {
+		return prototypeSymbol{}, false
+	}
+	var symbol prototypeSymbol
+	found := false
+	walkAST(a.file.GlNode, func(value any) bool {
+		call, ok := value.(*types.NodeExprCall)
+		if ok && call.AssociatedFnDef != nil && call.AssociatedFnDef.ProtoDispatch != nil && callNameAt(call.Callee, pos) {
+			method := call.AssociatedFnDef.ProtoDispatch
+			symbol = prototypeSymbol{module: method.Proto.Module, name: method.Proto.Name, method: method.Name}
+			found = true
+		}
+		return !found
+	})
+	if found {
+		return symbol, true
+	}
+	for _, proto := range a.file.GlNode.ProtoDefs {
+		for _, method := range proto.Methods {
+			if tokenAt(method.Tk, pos) {
+				return prototypeSymbol{module: proto.Module, name: proto.Name, method: method.Name}, true
+			}
+		}
+	}
+	for i, token := range a.file.Tokens {
+		if token.Type != types.TokName || !tokenAt(token, pos) {
+			continue
+		}
+		symbol := prototypeSymbol{module: a.file.PackageName, name: token.Repr}
+		if i >= 2 && a.file.Tokens[i-1].KeywType == types.KwDot {
+			qualifier := a.file.Tokens[i-2].Repr
+			if imported := a.importedPackage(qualifier); imported != "" {
+				symbol.module = imported
+			} else {
+				symbol.name, symbol.method = qualifier, token.Repr
+			}
+		}
+		_, ok := a.typeDefinitions()[symbol.module+"\x00"+symbol.name]
+		return symbol, ok
+	}
+	return prototypeSymbol{}, false
+}
+
+// typeDefinitions indexes the structs and prototypes of every loaded unit by
+// module and source name. A generic declaration is represented by one of its
+// instances, whose relations are resolved, when there is one.
+func (a *analysis) typeDefinitions() map[string]*types.StructDef {
+	definitions := map[string]*types.StructDef{}
+	if a == nil || a.shared == nil {
+		return definitions
+	}
+	for _, file := range a.shared.Files {
+		if file == nil || file.GlNode == nil {
+			continue
+		}
+		for _, definition := range file.GlNode.StructDefs {
+			key := definition.Module + "\x00" + sourceName(definition.Name)
+			if previous := definitions[key]; previous == nil || len(previous.TypeParams) != 0 {
+				definitions[key] = definition
+			}
+		}
+	}
+	return definitions
+}
+
+func (a *analysis) typeHierarchyItem(document string, definition *types.StructDef) (hierarchyItem, bool) {
+	if definition == nil {
+		return hierarchyItem{}, false
+	}
+	name := sourceName(definition.Name)
+	target, ok := a.definitions[definition.Module+"\x00"+name]
+	if !ok {
+		return hierarchyItem{}, false
+	}
+	kind := 23 // SymbolKind.Struct
+	if definition.IsProto {
+		kind = 11 // SymbolKind.Interface
+	}
+	detail := ""
+	if file := a.moduleFile(definition.Module); file != nil {
+		detail = file.ModuleName
+	}
+	return hierarchyItem{Name: name, Kind: kind, Detail: detail, URI: target.URI, Range: target.Range, SelectionRange: target.Range, Data: hierarchyData{Document: document, Module: definition.Module, Name: name}}, true
+}
+
+// moduleFile returns the loaded unit of a module.
+func (a *analysis) moduleFile(module string) *types.FileCtx {
+	for _, file := range a.shared.Files {
+		if file != nil && file.PackageName == module {
+			return file
+		}
+	}
+	return nil
+}
+
+// prototypeSupertypes returns the prototypes a struct names with `impl`, or a
+// prototype with `extends`.
+func prototypeSupertypes(definition *types.StructDef, definitions map[string]*types.StructDef) []*types.StructDef {
+	relations := definition.Implements
+	if definition.Proto != nil {
+		relations = append(relations[:len(relations):len(relations)], definition.Proto.Extends...)
+	}
+	result := []*types.StructDef{}
+	seen := map[*types.StructDef]bool{}
+	for _, relation := range relations {
+		if relation == nil || relation.Implied || relation.Proto == nil {
+			continue
+		}
+		if proto := definitions[relation.Proto.Module+"\x00"+sourceName(relation.Proto.Name)]; proto != nil && !seen[proto] {
+			seen[proto] = true
+			result = append(result, proto)
+		}
+	}
+	return result
+}
+
+// prototypeSubtypes returns the structs and prototypes which name the
+// prototype definition directly.
+func prototypeSubtypes(definition *types.StructDef, definitions map[string]*types.StructDef) []*types.StructDef {
+	result := []*types.StructDef{}
+	if !definition.IsProto {
+		return result
+	}
+	for _, key := range sortedKeys(definitions) {
+		candidate := definitions[key]
+		for _, supertype := range prototypeSupertypes(candidate, definitions) {
+			if supertype == definition {
+				result = append(result, candidate)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// implements reports whether definition satisfies proto, directly or through
+// a prototype extending it.
+func implements(definition, proto *types.StructDef) bool {
+	for _, relation := range definition.Implements {
+		if relation != nil && relation.Proto != nil && relation.Proto.Module == proto.Module && sourceName(relation.Proto.Name) == sourceName(proto.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,153 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const prototypeNavigationSource = "mod main\n" +
+	"proto Reader(read() u64)\n" +
+	"proto Writer(write(value u64) void)\n" +
+	"proto Duplex extends Reader, Writer(\n" +
+	"    copy() void\n" +
+	")\n" +
+	"Duplex.copy() void:\n" +
+	"    this.write(this.read())\n" +
+	"..\n" +
+	"Pipe impl Duplex(value u64)\n" +
+	"Pipe.read() u64:\n" +
+	"    ret this.value\n" +
+	"..\n" +
+	"Pipe.write(value u64) void:\n" +
+	"    this.value = value\n" +
+	"..\n" +
+	"File impl Reader(value u64)\n" +
+	"File.read() u64:\n" +
+	"    ret this.value\n" +
+	"..\n" +
+	"main() void:\n" +
+	"    file := File(value=1)\n" +
+	"    reader Reader = file.proto()\n" +
+	"    value := reader.read()\n" +
+	"..\n"
+
+func prototypeNavigationServer(t *testing.T) (*server, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "navigation.mg")
+	if err := os.WriteFile(path, []byte(prototypeNavigationSource), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file:///" + filepath.ToSlash(path)
+	s := &server{stdRoot: testStdRoot(), documents: map[string]*document{uri: {URI: uri, Text: prototypeNavigationSource}}}
+	return s, uri
+}
+
+func lines(locations []location) []uint32 {
+	result := []uint32{}
+	for _, target := range locations {
+		result = append(result, target.Range.Start.Line)
+	}
+	return result
+}
+
+func TestImplementationOfPrototypesAndRequirements(t *testing.T) {
+	s, uri := prototypeNavigationServer(t)
+	result := analyze(uri, s.documents[uri].Text, s.stdRoot)
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	tests := []struct {
+		name string
+		at   position
+		want string
+	}{
+		{"prototype", position{Line: 1, Character: 7}, "[9 16]"},
+		{"base reached through an extending prototype", position{Line: 2, Character: 7}, "[9]"},
+		{"requirement", position{Line: 1, Character: 14}, "[10 17]"},
+		{"dispatched call", position{Line: 23, Character: 20}, "[10 17]"},
+		{"requirement left to its default", position{Line: 4, Character: 5}, "[]"},
+		{"struct", position{Line: 16, Character: 1}, "[]"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprint(lines(result.implementations(test.at))); got != test.want {
+			t.Errorf("%s: implementation lines = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func typeHierarchyRequest(t *testing.T, s *server, method string, params any) []hierarchyItem {
+	t.Helper()
+	var output bytes.Buffer
+	s.out = &output
+	payload, _ := json.Marshal(params)
+	if err := s.handle(message{ID: json.RawMessage("1"), Method: method, Params: payload}); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Result []hierarchyItem `json:"result"`
+	}
+	body := output.String()
+	if err := json.Unmarshal([]byte(body[strings.Index(body, "{"):]), &response); err != nil {
+		t.Fatal(err)
+	}
+	return response.Result
+}
+
+func names(items []hierarchyItem) string {
+	result := []string{}
+	for _, item := range items {
+		result = append(result, item.Name)
+	}
+	return strings.Join(result, ",")
+}
+
+func TestTypeHierarchyOverPrototypes(t *testing.T) {
+	s, uri := prototypeNavigationServer(t)
+	prepare := func(at position) hierarchyItem {
+		items := typeHierarchyRequest(t, s, "textDocument/prepareTypeHierarchy", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": at})
+		if len(items) != 1 {
+			t.Fatalf("prepared items at %v = %#v", at, items)
+		}
+		return items[0]
+	}
+	duplex := prepare(position{Line: 3, Character: 7})
+	if duplex.Name != "Duplex" || duplex.Kind != 11 || duplex.SelectionRange.Start.Line != 3 {
+		t.Fatalf("prepared item = %#v", duplex)
+	}
+	if got := names(typeHierarchyRequest(t, s, "typeHierarchy/supertypes", map[string]any{"item": duplex})); got != "Reader,Writer" {
+		t.Fatalf("Duplex supertypes = %s", got)
+	}
+	if got := names(typeHierarchyRequest(t, s, "typeHierarchy/subtypes", map[string]any{"item": duplex})); got != "Pipe" {
+		t.Fatalf("Duplex subtypes = %s", got)
+	}
+	reader := prepare(position{Line: 22, Character: 12})
+	if got := names(typeHierarchyRequest(t, s, "typeHierarchy/subtypes", map[string]any{"item": reader})); got != "Duplex,File" {
+		t.Fatalf("Reader subtypes = %s", got)
+	}
+	file := prepare(position{Line: 16, Character: 1})
+	if got := names(typeHierarchyRequest(t, s, "typeHierarchy/supertypes", map[string]any{"item": file})); got != "Reader" || file.Kind != 23 {
+		t.Fatalf("File item %#v, supertypes = %s", file, got)
+	}
+}
+
+func TestImplementationSpansLoadedModules(t *testing.T) {
+	source := "mod main\nuse \"std:allocator\" alc\nuse \"std:arena_alloc\" arena\nuse \"std:heap\" heap\nhold(a alc.Allocator, b arena.Arena) void:\n..\n"
+	path := filepath.Join(t.TempDir(), "allocators.mg")
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result := analyze("file:///"+filepath.ToSlash(path), source, testStdRoot())
+	files := []string{}
+	for _, target := range result.implementations(position{Line: 4, Character: 13}) {
+		files = append(files, filepath.Base(target.URI))
+	}
+	got := strings.Join(files, ",")
+	if !strings.Contains(got, "arena_alloc.mg") || !strings.Contains(got, "heap_impl.mg") {
+		t.Fatalf("Allocator implementations = %s", got)
+	}
+}
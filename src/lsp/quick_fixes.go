@@ -0,0 +1,247 @@
+package lsp
+
+import (
+	"Magma/src/docgen"
+	"Magma/src/types"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	quotedName      = regexp.MustCompile(`'([^']+)'`)
+	leakedValue     = regexp.MustCompile(`^destructible value '([^']+)' is not consumed`)
+	localDefinition = regexp.MustCompile(`^(\s*)([A-Za-z_][A-Za-z0-9_]*) := `)
+	suggestedName   = regexp.MustCompile(`did you mean '([^']+)'\?`)
+)
+
+func quickFix(uri, title string, diag diagnostic, edits ...textEdit) codeAction {
+	return codeAction{Title: title, Kind: "quickfix", Diagnostics: []diagnostic{diag}, Edit: workspaceEdit{Changes: map[string][]textEdit{uri: edits}}}
+}
+
+func insertion(at position, text string) textEdit {
+	return textEdit{Range: rangePosition{Start: at, End: at}, NewText: text}
+}
+
+// suggestionAction applies the "did you mean" suggestion of a diagnostic to
+// its range. A string literal, such as an import path, keeps its quotes.
+func suggestionAction(uri, source string, diag diagnostic) (codeAction, bool) {
+	match := suggestedName.FindStringSubmatch(diag.Message)
+	if match == nil || diag.Range.Start.Line != diag.Range.End.Line {
+		return codeAction{}, false
+	}
+	lines := strings.Split(source, "\n")
+	if int(diag.Range.Start.Line) >= len(lines) {
+		return codeAction{}, false
+	}
+	text := []rune(lines[diag.Range.Start.Line])
+	replacement := match[1]
+	if start := int(diag.Range.Start.Character); start < len(text) && text[start] == '"' {
+		replacement = `"` + replacement + `"`
+	}
+	return quickFix(uri, "Change to `"+match[1]+"`", diag, textEdit{Range: diag.Range, NewText: replacement}), true
+}
+
+// missingImportActions imports a standard-library module named like the
+// unknown qualifier of the diagnostic, one action per matching module. The
+// declaration goes after the last top-level `use`, or after `mod`.
+func missingImportActions(uri, source, stdRoot string, diag diagnostic) []codeAction {
+	match := quotedName.FindStringSubmatch(diag.Message)
+	if match == nil || stdRoot == "" {
+		return nil
+	}
+	alias, _, _ := strings.Cut(match[1], ".")
+	var modules []string
+	_ = filepath.WalkDir(stdRoot, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() && path != stdRoot && (strings.HasPrefix(entry.Name(), ".") || entry.Name() == "tests") {
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() && entry.Name() == alias+".mg" {
+			if relative, err := filepath.Rel(stdRoot, path); err == nil {
+				modules = append(modules, strings.TrimSuffix(filepath.ToSlash(relative), ".mg"))
+			}
+		}
+		return nil
+	})
+	// The portable module precedes platform-specific ones of the same name.
+	sort.Slice(modules, func(i, j int) bool {
+		if depthI, depthJ := strings.Count(modules[i], "/"), strings.Count(modules[j], "/"); depthI != depthJ {
+			return depthI < depthJ
+		}
+		return modules[i] < modules[j]
+	})
+	line := uint32(0)
+	for index, text := range strings.Split(source, "\n") {
+		if strings.HasPrefix(text, "use ") || strings.HasPrefix(text, "pub use ") || strings.HasPrefix(text, "mod ") {
+			line = uint32(index) + 1
+		}
+	}
+	actions := []codeAction{}
+	for _, module := range modules {
+		declaration := "use \"std:" + module + "\" " + alias
+		actions = append(actions, quickFix(uri, "Add `"+declaration+"`", diag, insertion(position{Line: line}, declaration+"\n")))
+	}
+	return actions
+}
+
+// unhandledErrorActions propagates the error of a throwing call with `try`,
+// or when the call initializes a local, destructures it into value and error
+// bindings. The diagnostic starts at the callee's last name, so the call's
+// start is found by scanning back over its qualifier. The error binding takes
+// the first of `err`, `err2`, ... not already bound in the function.
+func (a *analysis) unhandledErrorActions(uri, source string, diag diagnostic) []codeAction {
+	lines := strings.Split(source, "\n")
+	if int(diag.Range.Start.Line) >= len(lines) {
+		return nil
+	}
+	text := []rune(lines[diag.Range.Start.Line])
+	start := int(min(diag.Range.Start.Character, uint32(len(text))))
+	for start > 0 && (text[start-1] == '.' || text[start-1] == '_' || isIdentifierRune(text[start-1])) {
+		start--
+	}
+	at := position{Line: diag.Range.Start.Line, Character: uint32(start)}
+	actions := []codeAction{quickFix(uri, "Propagate the error with `try`", diag, insertion(at, "try "))}
+	if match := localDefinition.FindStringSubmatch(string(text)); match != nil && len([]rune(match[0])) == start {
+		bound := a.localNames(diag.Range.Start.Line + 1)
+		errName := "err"
+		for suffix := 2; bound[errName]; suffix++ {
+			errName = "err" + strconv.Itoa(suffix)
+		}
+		name := position{Line: at.Line, Character: uint32(len([]rune(match[1] + match[2])))}
+		actions = append(actions, quickFix(uri, "Destructure into `"+match[2]+", "+errName+"`", diag, insertion(name, ", "+errName)))
+	}
+	return actions
+}
+
+// localNames collects the parameters and locals of the top-level function
+// declared last at or before line.
+func (a *analysis) localNames(line uint32) map[string]bool {
+	names := map[string]bool{}
+	if a == nil || a.file == nil || a.file.GlNode == nil {
+		return names
+	}
+	var function *types.NodeFuncDef
+	for _, declaration := range a.file.GlNode.Declarations {
+		if candidate, ok := declaration.(*types.NodeFuncDef); ok {
+			if declared := docgen.DeclarationLine(candidate.Class.NameNode); declared <= line && (function == nil || declared > docgen.DeclarationLine(function.Class.NameNode)) {
+				function = candidate
+			}
+		}
+	}
+	if function == nil {
+		return names
+	}
+	for _, argument := range function.Class.ArgsNode.Args {
+		names[argument.Name] = true
+	}
+	walkAST(&function.Body, func(value any) bool {
+		switch node := value.(type) {
+		case *types.NodeExprVarDef:
+			names[flattenName(node.Name)] = true
+		case *types.NodeExprDestructureAssign:
+			names[flattenName(node.ValueDef.Name)] = true
+			names[flattenName(node.ErrDef.Name)] = true
+		}
+		return true
+	})
+	return names
+}
+
+func isIdentifierRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// throwingFunctionAction marks the return type of the top-level function
+// enclosing the diagnostic with `!`. The parameter list is the first
+// parenthesized group of the header; its closing parenthesis is matched so
+// function-pointer parameters do not end it early.
+func throwingFunctionAction(uri, source string, diag diagnostic) (codeAction, bool) {
+	lines := strings.Split(source, "\n")
+	header := int(diag.Range.Start.Line)
+	for header >= 0 && (header >= len(lines) || indentation(lines[header]) != 0 || !strings.HasSuffix(strings.TrimRight(lines[header], " \t\r"), ":")) {
+		header--
+	}
+	if header < 0 {
+		return codeAction{}, false
+	}
+	text := []rune(lines[header])
+	depth, end := 0, -1
+	for index, r := range text {
+		if r == '(' {
+			depth++
+		} else if r == ')' {
+			depth--
+			if depth == 0 {
+				end = index + 1
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return codeAction{}, false
+	}
+	for end < len(text) && text[end] == ' ' {
+		end++
+	}
+	if end >= len(text) || text[end] == '!' || text[end] == ':' {
+		return codeAction{}, false
+	}
+	return quickFix(uri, "Mark the function as throwing", diag, insertion(position{Line: uint32(header), Character: uint32(end)}, "!")), true
+}
+
+// cleanupActions defers a destructor call for a destructible local which
+// leaks on some exit path, one action per destructor of its struct. The defer
+// follows the declaration, or for a parameter, opens the function body.
+func (a *analysis) cleanupActions(uri, source string, diag diagnostic) []codeAction {
+	match := leakedValue.FindStringSubmatch(diag.Message)
+	if match == nil || a == nil || a.file == nil || a.file.GlNode == nil {
+		return nil
+	}
+	var variable *types.NodeExprVarDef
+	walkAST(a.file.GlNode, func(value any) bool {
+		if definition, ok := value.(*types.NodeExprVarDef); ok && flattenName(definition.Name) == match[1] {
+			if token, ok := declarationNameToken(definition.Name); ok && tokenAt(token, diag.Range.Start) {
+				variable = definition
+			}
+		}
+		return variable == nil
+	})
+	if variable == nil || variable.Type == nil {
+		return nil
+	}
+	definition := a.structDefinition(variable.Type)
+	if definition == nil {
+		return nil
+	}
+	destructors := definition.Destructors
+	if len(destructors) == 0 && definition.Destructor != nil {
+		destructors = []*types.NodeFuncDef{definition.Destructor}
+	}
+	lines := strings.Split(source, "\n")
+	line := diag.Range.Start.Line + 1
+	if int(line) > len(lines) {
+		return nil
+	}
+	indent := lines[line-1][:indentation(lines[line-1])]
+	if variable.Storage == types.VariableStorageArgument && int(line) < len(lines) {
+		indent = lines[line][:indentation(lines[line])]
+	}
+	actions := []codeAction{}
+	seen := map[string]bool{}
+	for _, destructor := range destructors {
+		name := lastPart(flattenName(destructor.Class.NameNode))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		statement := "defer " + match[1] + "." + name + "()"
+		actions = append(actions, quickFix(uri, "Insert `"+statement+"`", diag, insertion(position{Line: line}, indent+statement+"\n")))
+	}
+	return actions
+}
@@ -0,0 +1,145 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// quickFixes analyzes source and returns the actions offered for its
+// diagnostics with code, keyed by title.
+func quickFixes(t *testing.T, source, code string) (map[string]codeAction, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fix.mg")
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file:///" + filepath.ToSlash(path)
+	result := analyze(uri, source, testStdRoot())
+	diagnostics := []diagnostic{}
+	for _, diag := range diagnosticsForFile(result.err, result.warnings, path) {
+		if diag.Code == code {
+			diagnostics = append(diagnostics, diag)
+		}
+	}
+	if len(diagnostics) == 0 {
+		t.Fatalf("no %s diagnostic in %v / %v", code, result.err, result.warnings)
+	}
+	var output bytes.Buffer
+	s := &server{out: &output, stdRoot: testStdRoot(), documents: map[string]*document{uri: {URI: uri, Text: source}}}
+	params, _ := json.Marshal(map[string]any{"textDocument": map[string]any{"uri": uri}, "context": map[string]any{"diagnostics": diagnostics}})
+	if err := s.handleCodeAction(message{ID: json.RawMessage("1"), Params: params}); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Result []codeAction `json:"result"`
+	}
+	body := output.String()
+	if err := json.Unmarshal([]byte(body[strings.Index(body, "{"):]), &response); err != nil {
+		t.Fatal(err)
+	}
+	actions := map[string]codeAction{}
+	for _, action := range response.Result {
+		actions[action.Title] = action
+	}
+	return actions, uri
+}
+
+// applyQuickFix applies the single-line edits of an action and checks that
+// the result compiles.
+func applyQuickFix(t *testing.T, source, uri string, action codeAction) string {
+	t.Helper()
+	lines := strings.SplitAfter(source, "\n")
+	for _, edit := range action.Edit.Changes[uri] {
+		if edit.Range.Start.Line != edit.Range.End.Line {
+			t.Fatalf("%q spans lines %#v", action.Title, edit.Range)
+		}
+		line := lines[edit.Range.Start.Line]
+		lines[edit.Range.Start.Line] = line[:edit.Range.Start.Character] + edit.NewText + line[edit.Range.End.Character:]
+	}
+	fixed := strings.Join(lines, "")
+	path := filepath.Join(t.TempDir(), "fixed.mg")
+	if err := os.WriteFile(path, []byte(fixed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if result := analyze("file:///"+filepath.ToSlash(path), fixed, testStdRoot()); result.err != nil {
+		t.Fatalf("%q produced\n%s\nwhich fails: %v", action.Title, fixed, result.err)
+	}
+	return fixed
+}
+
+func TestMissingImportQuickFix(t *testing.T) {
+	source := "mod main\nmain() void:\n    e := errors.ok()\n..\n"
+	actions, uri := quickFixes(t, source, "missing-import")
+	action, ok := actions["Add `use \"std:errors\" errors`"]
+	if !ok {
+		t.Fatalf("actions = %v", actions)
+	}
+	if fixed := applyQuickFix(t, source, uri, action); !strings.HasPrefix(fixed, "mod main\nuse \"std:errors\" errors\n") {
+		t.Fatalf("import inserted at %q", fixed)
+	}
+}
+
+func TestUnhandledErrorQuickFixes(t *testing.T) {
+	source := "mod main\nuse \"std:errors\" errors\nrisky() !u64:\n    ret 1\n..\nload() !u64:\n    n := risky()\n    ret n\n..\n"
+	actions, uri := quickFixes(t, source, "unhandled-error")
+	if fixed := applyQuickFix(t, source, uri, actions["Propagate the error with `try`"]); !strings.Contains(fixed, "    n := try risky()\n") {
+		t.Fatalf("try inserted in %q", fixed)
+	}
+	if fixed := applyQuickFix(t, source, uri, actions["Destructure into `n, err`"]); !strings.Contains(fixed, "    n, err := risky()\n") {
+		t.Fatalf("destructured into %q", fixed)
+	}
+
+	// An error binding already in scope is not redeclared.
+	source = "mod main\nuse \"std:errors\" errors\nrisky() !u64:\n    ret 1\n..\nload(err2 u64) !u64:\n    first, err := risky()\n    n := risky()\n    ret first + n + err2\n..\n"
+	actions, uri = quickFixes(t, source, "unhandled-error")
+	if fixed := applyQuickFix(t, source, uri, actions["Destructure into `n, err3`"]); !strings.Contains(fixed, "    n, err3 := risky()\n") {
+		t.Fatalf("destructured with %v into %q", actions, fixed)
+	}
+}
+
+func TestThrowingFunctionQuickFix(t *testing.T) {
+	source := "mod main\nrisky() !u64:\n    ret 1\n..\nload(f (u64) u64) u64:\n    ret try risky()\n..\n"
+	actions, uri := quickFixes(t, source, "non-throwing-function")
+	if fixed := applyQuickFix(t, source, uri, actions["Mark the function as throwing"]); !strings.Contains(fixed, "load(f (u64) u64) !u64:\n") {
+		t.Fatalf("marked %q", fixed)
+	}
+}
+
+func TestCleanupQuickFixChoosesDestructor(t *testing.T) {
+	source := "mod main\nRes(id u64)\ndestr Res.close() void:\n..\ndestr Res.discard() void:\n..\nmain() void:\n    r := Res(id=1)\n..\n"
+	actions, uri := quickFixes(t, source, "cleanup")
+	for _, title := range []string{"Insert `defer r.close()`", "Insert `defer r.discard()`"} {
+		action, ok := actions[title]
+		if !ok {
+			t.Fatalf("missing %q in %v", title, actions)
+		}
+		if fixed := applyQuickFix(t, source, uri, action); !strings.Contains(fixed, "    r := Res(id=1)\n    defer r.") {
+			t.Fatalf("defer inserted in %q", fixed)
+		}
+	}
+}
+
+func TestDidYouMeanQuickFixes(t *testing.T) {
+	for _, test := range []struct {
+		source, code, title string
+	}{
+		{"mod main\nPoint(name u64)\nmain() void:\n    p := Point(name=1)\n    n := p.nmae\n..\n", "", "Change to `name`"},
+		{"mod main\nPoint(name u64)\nmain() void:\n    p := Point(nmae=1)\n..\n", "", "Change to `name`"},
+		{"mod main\nmain() void:\n    count := 1\n    n := coutn + 1\n..\n", "", "Change to `count`"},
+		{"mod main\nuse \"std:errors\" errors\nmain() void:\n    e := errors.okk()\n..\n", "", "Change to `ok`"},
+		{"mod main\nuse \"std:errors\" errors\nmain() void:\n    e := erors.ok()\n..\n", "missing-import", "Change to `errors`"},
+		{"mod main\nuse \"std:erors\" errors\nmain() void:\n    e := errors.ok()\n..\n", "", "Change to `std:errors`"},
+	} {
+		actions, uri := quickFixes(t, test.source, test.code)
+		action, ok := actions[test.title]
+		if !ok {
+			t.Errorf("%q: actions = %v", test.source, actions)
+			continue
+		}
+		applyQuickFix(t, test.source, uri, action)
+	}
+}
@@ -4,64 +4,8 @@ import (
 	"encoding/json"
 	"regexp"
 	"strings"
-	"unicode/utf8"
 )
 
-type semanticTokens struct {
-	Data []uint32 `json:"data"`
-}
-
-// handleSemanticTokens highlights memory-safety syntax. `move` is contextual,
-// so an ordinary function call named move is deliberately not classified as a
-// keyword.
-func (s *server) handleSemanticTokens(msg message) error {
-	var p struct {
-		TextDocument struct {
-			URI string `json:"uri"`
-		} `json:"textDocument"`
-	}
-	if err := json.Unmarshal(msg.Params, &p); err != nil {
-		return err
-	}
-	d := s.documents[p.TextDocument.URI]
-	if d == nil {
-		return s.respond(msg.ID, semanticTokens{Data: []uint32{}})
-	}
-	words := regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*|\S`).FindAllStringIndex(d.Text, -1)
-	line, lineByte, lastLine, lastChar := uint32(0), 0, uint32(0), uint32(0)
-	data := []uint32{}
-	for i, span := range words {
-		for lineByte < span[0] {
-			n := strings.IndexByte(d.Text[lineByte:span[0]], '\n')
-			if n < 0 {
-				break
-			}
-			line++
-			lineByte += n + 1
-		}
-		word := d.Text[span[0]:span[1]]
-		keyword := word == "bounded" || word == "unsafe"
-		if word == "move" {
-			next := ""
-			if i+1 < len(words) {
-				next = d.Text[words[i+1][0]:words[i+1][1]]
-			}
-			keyword = next != "(" && next != "." && next != "=" && next != ":="
-		}
-		if !keyword {
-			continue
-		}
-		character := uint32(utf8.RuneCountInString(d.Text[lineByte:span[0]]))
-		deltaLine, deltaChar := line-lastLine, character
-		if deltaLine == 0 {
-			deltaChar = character - lastChar
-		}
-		data = append(data, deltaLine, deltaChar, uint32(utf8.RuneCountInString(word)), 0, 0)
-		lastLine, lastChar = line, character
-	}
-	return s.respond(msg.ID, semanticTokens{Data: data})
-}
-
 type codeAction struct {
 	Title       string        `json:"title"`
 	Kind        string        `json:"kind"`
@@ -91,6 +35,11 @@ func (s *server) handleCodeAction(msg message) error {
 	d := s.documents[p.TextDocument.URI]
 	actions := []codeAction{}
 	for _, diag := range p.Context.Diagnostics {
+		if d != nil {
+			if action, ok := suggestionAction(p.TextDocument.URI, d.Text, diag); ok {
+				actions = append(actions, action)
+			}
+		}
 		switch diag.Code {
 		case "missing-move":
 			actions = append(actions, codeAction{Title: "Insert `move`", Kind: "quickfix", Diagnostics: []diagnostic{diag}, Edit: workspaceEdit{Changes: map[string][]textEdit{p.TextDocument.URI: {{Range: rangePosition{Start: diag.Range.Start, End: diag.Range.Start}, NewText: "move "}}}}})
@@ -100,6 +49,34 @@ func (s *server) handleCodeAction(msg message) error {
 					actions = append(actions, codeAction{Title: "Wrap statement in `bounded` proof", Kind: "quickfix", Diagnostics: []diagnostic{diag}, Edit: workspaceEdit{Changes: map[string][]textEdit{p.TextDocument.URI: {edit}}}})
 				}
 			}
+		case "missing-import":
+			if d != nil {
+				actions = append(actions, missingImportActions(p.TextDocument.URI, d.Text, s.stdRootFor(d.URI), diag)...)
+			}
+		case "unhandled-error":
+			if d != nil {
+				if d.result == nil {
+					d.result = s.analyzeDocument(d)
+				}
+				actions = append(actions, d.result.unhandledErrorActions(p.TextDocument.URI, d.Text, diag)...)
+			}
+		case "non-throwing-function":
+			if d != nil {
+				if action, ok := throwingFunctionAction(p.TextDocument.URI, d.Text, diag); ok {
+					actions = append(actions, action)
+				}
+			}
+		case "cleanup":
+			if d != nil {
+				if d.result == nil {
+					d.result = s.analyzeDocument(d)
+				}
+				actions = append(actions, d.result.cleanupActions(p.TextDocument.URI, d.Text, diag)...)
+			}
+		default:
+			if d != nil {
+				actions = append(actions, deadCodeActions(p.TextDocument.URI, d.Text, diag)...)
+			}
 		}
 	}
 	return s.respond(msg.ID, actions)
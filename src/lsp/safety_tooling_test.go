@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -64,8 +65,8 @@ func TestWarningPolicyChangesOnlySafetySeverityForUnsavedBuffer(t *testing.T) {
 	}
 	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
 	source := "mod main\nResource(value str)\ndestr Resource.close() void:\n..\nmakeResource() $Resource:\n    ret Resource(value=\"x\")\n..\nconsume(value $Resource) void:\n    value.close()\n..\nmain() void:\n    value $Resource = makeResource()\n    consume(value)\n..\n"
-	fatal := analyzePolicy(uri, source, testStdRoot(), false)
-	warn := analyzePolicy(uri, source, testStdRoot(), true)
+	fatal := analyzePolicy(uri, source, testStdRoot(), false, nil, io.Discard)
+	warn := analyzePolicy(uri, source, testStdRoot(), true, nil, io.Discard)
 	if fatal.err == nil || warn.err != nil {
 		t.Fatalf("fatal err=%v warning err=%v", fatal.err, warn.err)
 	}
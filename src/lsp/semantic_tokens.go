@@ -0,0 +1,564 @@
+package lsp
+
+import (
+	"Magma/src/types"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// semanticTokenTypes and semanticTokenModifiers form the legend advertised by
+// initialize. A token's type indexes the first; its modifiers are a bit set
+// over the second.
+var (
+	semanticTokenTypes     = []string{"keyword", "namespace", "struct", "interface", "type", "function", "method", "parameter", "variable", "typeParameter"}
+	semanticTokenModifiers = []string{"declaration", "readonly", "global", "threadLocal", "destructor", "owned", "borrowed"}
+)
+
+const (
+	semanticKeyword uint32 = iota
+	semanticNamespace
+	semanticStruct
+	semanticInterface
+	semanticType
+	semanticFunction
+	semanticMethod
+	semanticParameter
+	semanticVariable
+	semanticTypeParameter
+)
+
+const (
+	modifierDeclaration uint32 = 1 << iota
+	modifierReadonly
+	modifierGlobal
+	modifierThreadLocal
+	modifierDestructor
+	modifierOwned
+	modifierBorrowed
+)
+
+type semanticTokens struct {
+	ResultID string   `json:"resultId,omitempty"`
+	Data     []uint32 `json:"data"`
+}
+
+type semanticTokensDelta struct {
+	ResultID string               `json:"resultId"`
+	Edits    []semanticTokensEdit `json:"edits"`
+}
+
+type semanticTokensEdit struct {
+	Start       uint32   `json:"start"`
+	DeleteCount uint32   `json:"deleteCount"`
+	Data        []uint32 `json:"data"`
+}
+
+type semanticClass struct {
+	tokenType uint32
+	modifiers uint32
+}
+
+type semanticToken struct {
+	line, character, length uint32
+	class                   semanticClass
+}
+
+// handleSemanticTokens answers full, full/delta, and range requests. Full and
+// delta responses are numbered; a delta against the previous number is the
+// single edit which turns the data reported then into the current data, and
+// any other delta request gets the full data.
+func (s *server) handleSemanticTokens(msg message) error {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		PreviousResultID string        `json:"previousResultId"`
+		Range            rangePosition `json:"range"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	d := s.documents[p.TextDocument.URI]
+	if d == nil {
+		return s.respond(msg.ID, semanticTokens{Data: []uint32{}})
+	}
+	if d.result == nil {
+		d.result = s.analyzeDocument(d)
+	}
+	tokens := d.result.semanticTokens(d.Text)
+	if msg.Method == "textDocument/semanticTokens/range" {
+		return s.respond(msg.ID, semanticTokens{Data: encodeSemanticTokens(tokens, p.Range)})
+	}
+	previous, previousID := d.semanticTokens, d.semanticResultID
+	s.semanticResults++
+	d.semanticTokens, d.semanticResultID = encodeSemanticTokens(tokens, rangePosition{}), strconv.Itoa(s.semanticResults)
+	if msg.Method == "textDocument/semanticTokens/full/delta" && previousID != "" && p.PreviousResultID == previousID {
+		return s.respond(msg.ID, semanticTokensDelta{ResultID: d.semanticResultID, Edits: semanticTokenEdits(previous, d.semanticTokens)})
+	}
+	return s.respond(msg.ID, semanticTokens{ResultID: d.semanticResultID, Data: d.semanticTokens})
+}
+
+func encodeSemanticTokens(tokens []semanticToken, within rangePosition) []uint32 {
+	data := []uint32{}
+	lastLine, lastCharacter := uint32(0), uint32(0)
+	for _, token := range tokens {
+		if !rangeContains(within, position{Line: token.line, Character: token.character}) {
+			continue
+		}
+		deltaLine, deltaCharacter := token.line-lastLine, token.character
+		if deltaLine == 0 {
+			deltaCharacter = token.character - lastCharacter
+		}
+		data = append(data, deltaLine, deltaCharacter, token.length, token.class.tokenType, token.class.modifiers)
+		lastLine, lastCharacter = token.line, token.character
+	}
+	return data
+}
+
+// semanticTokenEdits returns the edit replacing what differs between the
+// common prefix and suffix of previous and current.
+func semanticTokenEdits(previous, current []uint32) []semanticTokensEdit {
+	prefix := 0
+	for prefix < len(previous) && prefix < len(current) && previous[prefix] == current[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(previous)-prefix && suffix < len(current)-prefix && previous[len(previous)-1-suffix] == current[len(current)-1-suffix] {
+		suffix++
+	}
+	if prefix == len(previous) && prefix == len(current) {
+		return []semanticTokensEdit{}
+	}
+	return []semanticTokensEdit{{Start: uint32(prefix), DeleteCount: uint32(len(previous) - prefix - suffix), Data: current[prefix : len(current)-suffix]}}
+}
+
+// semanticTokens classifies the names of the analyzed document and the
+// memory-safety keywords of its text, in source order. The keywords are found
+// in the text so they are highlighted even when the document does not parse.
+func (a *analysis) semanticTokens(source string) []semanticToken {
+	tokens := semanticKeywords(source)
+	if a != nil && a.file != nil {
+		classes := a.semanticClasses()
+		for _, token := range a.file.Tokens {
+			class, ok := classes[tokenPositionKey(token)]
+			if !ok || token.Type != types.TokName || token.Pos.Line == 0 {
+				continue
+			}
+			span := spanRange(token.Span())
+			tokens = append(tokens, semanticToken{line: span.Start.Line, character: span.Start.Character, length: span.End.Character - span.Start.Character, class: class})
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if tokens[i].line != tokens[j].line {
+			return tokens[i].line < tokens[j].line
+		}
+		return tokens[i].character < tokens[j].character
+	})
+	return tokens
+}
+
+// semanticKeywords finds `bounded`, `unsafe`, and the contextual `move`. An
+// ordinary function call named move is deliberately not a keyword.
+func semanticKeywords(source string) []semanticToken {
+	words := regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*|\S`).FindAllStringIndex(source, -1)
+	line, lineByte := uint32(0), 0
+	tokens := []semanticToken{}
+	for i, span := range words {
+		for lineByte < span[0] {
+			n := strings.IndexByte(source[lineByte:span[0]], '\n')
+			if n < 0 {
+				break
+			}
+			line++
+			lineByte += n + 1
+		}
+		word := source[span[0]:span[1]]
+		keyword := word == "bounded" || word == "unsafe"
+		if word == "move" {
+			next := ""
+			if i+1 < len(words) {
+				next = source[words[i+1][0]:words[i+1][1]]
+			}
+			keyword = next != "(" && next != "." && next != "=" && next != ":="
+		}
+		if keyword {
+			character := uint32(utf8.RuneCountInString(source[lineByte:span[0]]))
+			tokens = append(tokens, semanticToken{line: line, character: character, length: uint32(utf8.RuneCountInString(word)), class: semanticClass{tokenType: semanticKeyword}})
+		}
+	}
+	return tokens
+}
+
+// semanticClasses classifies name tokens by position. The checked tree
+// resolves the names of non-generic code and of generic instances, which keep
+// their template's tokens. Bindings recorded before monomorphization cover
+// templates without an instance, and type parameters, types, and module
+// qualifiers are recognized in the token stream.
+func (a *analysis) semanticClasses() map[string]semanticClass {
+	classes := map[string]semanticClass{}
+	classify := func(token types.Token, class semanticClass) {
+		key := tokenPositionKey(token)
+		if _, ok := classes[key]; !ok && token.Pos.Line != 0 {
+			classes[key] = class
+		}
+	}
+	if a.file.GlNode != nil {
+		for _, declaration := range a.file.GlNode.Declarations {
+			// Derived members are synthesized and borrow their tokens.
+			if function, ok := declaration.(*types.NodeFuncDef); ok && function.Derived {
+				continue
+			}
+			walkAST(declaration, func(value any) bool {
+				switch node := value.(type) {
+				case *types.NodeFuncDef:
+					if token, ok := declarationNameToken(node.Class.NameNode); ok && token.Repr == lastPart(flattenName(node.Class.NameNode)) {
+						class := functionClass(node)
+						class.modifiers |= modifierDeclaration
+						classify(token, class)
+					}
+					for _, argument := range node.Class.ArgsNode.Args {
+						if argument.Tk.Repr == argument.Name {
+							classify(argument.Tk, semanticClass{tokenType: semanticParameter, modifiers: modifierDeclaration | a.bindingOwnership(argument.TypeNode, true)})
+						}
+					}
+				case *types.NodeExprVarDef:
+					if single, ok := node.Name.(*types.NodeNameSingle); ok && single.Tk.Repr == sourceName(single.Name) {
+						class := a.variableClass(node)
+						class.modifiers |= modifierDeclaration
+						classify(single.Tk, class)
+					}
+				case *types.NodeExprName:
+					a.classifyName(node, classify)
+				case *types.NodeExprCall:
+					if function := node.AssociatedFnDef; function != nil && !node.IsFuncPointer && node.Tk.Repr == lastPart(flattenName(function.Class.NameNode)) {
+						classify(node.Tk, functionClass(function))
+					}
+				}
+				return true
+			})
+		}
+	}
+	definitions := a.typeDefinitions()
+	parameters := genericParameters(a.file.Tokens)
+	for i, token := range a.file.Tokens {
+		if token.Type != types.TokName {
+			continue
+		}
+		if class, ok := parameters[i]; ok {
+			classify(token, class)
+		} else if class, ok := a.docs.semanticBinding(a.file.PackageName, token); ok {
+			classify(token, class)
+		} else if class, ok := a.tokenClass(i, definitions); ok {
+			classify(token, class)
+		}
+	}
+	return classes
+}
+
+// classifyName classifies the variable a resolved name starts with, or the
+// function it ends with. A global of another module is spelled after its
+// module qualifier.
+func (a *analysis) classifyName(node *types.NodeExprName, classify func(types.Token, semanticClass)) {
+	var tokens []types.Token
+	var parts []string
+	switch name := node.Name.(type) {
+	case *types.NodeNameSingle:
+		tokens, parts = []types.Token{name.Tk}, []string{name.Name}
+	case *types.NodeNameComposite:
+		tokens, parts = name.Tokens, name.Parts
+	}
+	if len(tokens) == 0 || len(tokens) != len(parts) {
+		return
+	}
+	switch target := node.AssociatedNode.(type) {
+	case *types.NodeExprVarDef:
+		index := 0
+		if len(parts) > 1 && target.IsGlobal && a.importedPackage(parts[0]) != "" {
+			index = 1
+		}
+		if tokens[index].Repr == sourceName(parts[index]) {
+			classify(tokens[index], a.variableClass(target))
+		}
+	case *types.NodeFuncDef:
+		last := tokens[len(tokens)-1]
+		if last.Repr == lastPart(flattenName(target.Class.NameNode)) {
+			classify(last, functionClass(target))
+		}
+	}
+}
+
+// tokenClass recognizes a name in the token stream: the module name, an
+// import alias, or a struct, prototype, type alias, or function of the current module or of the
+// module qualifying it.
+func (a *analysis) tokenClass(index int, definitions map[string]*types.StructDef) (semanticClass, bool) {
+	source := a.file.Tokens
+	token := source[index]
+	module := a.file.PackageName
+	if index >= 2 && source[index-1].KeywType == types.KwDot {
+		module = a.importedPackage(source[index-2].Repr)
+		if module == "" || (index >= 3 && source[index-3].KeywType == types.KwDot) {
+			return semanticClass{}, false
+		}
+	} else if index >= 1 && source[index-1].KeywType == types.KwModule {
+		return semanticClass{tokenType: semanticNamespace, modifiers: modifierDeclaration}, true
+	} else if a.importedPackage(token.Repr) != "" {
+		if index+1 < len(source) && source[index+1].KeywType == types.KwDot {
+			return semanticClass{tokenType: semanticNamespace}, true
+		}
+		if index >= 2 && source[index-1].Type == types.TokLitStr && source[index-2].KeywType == types.KwUse {
+			return semanticClass{tokenType: semanticNamespace, modifiers: modifierDeclaration}, true
+		}
+	}
+	declaration := uint32(0)
+	if module == a.file.PackageName && declaresName(source, index) {
+		declaration = modifierDeclaration
+	}
+	if definition := definitions[module+"\x00"+token.Repr]; definition != nil {
+		if definition.IsProto {
+			return semanticClass{tokenType: semanticInterface, modifiers: declaration}, true
+		}
+		return semanticClass{tokenType: semanticStruct, modifiers: declaration}, true
+	}
+	if a.shared != nil {
+		for _, file := range a.shared.Files {
+			if file == nil || file.PackageName != module || file.GlNode == nil || file.GlNode.TypeAliases[token.Repr] == nil {
+				continue
+			}
+			alias := file.GlNode.TypeAliases[token.Repr]
+			if alias.Tk.Pos == token.Pos && module == a.file.PackageName {
+				declaration = modifierDeclaration
+			}
+			return semanticClass{tokenType: semanticType, modifiers: declaration}, true
+		}
+	}
+	if a.docs != nil {
+		if function := a.docs.functions[module+"\x00"+token.Repr]; function != nil {
+			return functionClass(function), true
+		}
+	}
+	return semanticClass{}, false
+}
+
+// declaresName reports whether the name at index is the one a top-level
+// struct or prototype declaration introduces: the first name of an unindented
+// line, after `pub` or `proto`, which is not the owner of a member.
+func declaresName(source []types.Token, index int) bool {
+	start := index
+	for start > 0 && (source[start-1].KeywType == types.KwPublic || (source[start-1].Type == types.TokName && source[start-1].Repr == "proto")) {
+		start--
+	}
+	if source[start].Pos.Col != 1 || (start != 0 && source[start-1].KeywType != types.KwNewline) {
+		return false
+	}
+	next := index + 1
+	if next < len(source) && source[next].KeywType == types.KwBrackOp {
+		next = closingToken(source, next, types.KwBrackOp, types.KwBrackCl) + 1
+	}
+	return next <= 0 || next >= len(source) || source[next].KeywType != types.KwDot
+}
+
+// genericParameters classifies the type parameters of each top-level generic
+// declaration, `name[T]` or `Owner[T].name[U]`, through the end of its body.
+// The parameters are found in the tokens so that templates without an
+// instance, which the checked tree no longer has, are covered too.
+func genericParameters(source []types.Token) map[int]semanticClass {
+	classes := map[int]semanticClass{}
+	for start := range source {
+		if source[start].Pos.Col != 1 || (start != 0 && source[start-1].KeywType != types.KwNewline) {
+			continue
+		}
+		index := start
+		for index < len(source) && (source[index].KeywType == types.KwPublic || source[index].KeywType == types.KwDestructor || source[index].KeywType == types.KwNoCtx || (source[index].Repr == "proto" && source[index].Type == types.TokName)) {
+			index++
+		}
+		names := map[string]bool{}
+		for index < len(source) && source[index].Type == types.TokName {
+			open := index + 1
+			_, end := source[index].Span()
+			if open < len(source) && source[open].KeywType == types.KwBrackOp && source[open].Pos.Line == end.Line && source[open].Pos.Col == end.Col {
+				closing := closingToken(source, open, types.KwBrackOp, types.KwBrackCl)
+				if closing < 0 {
+					break
+				}
+				for i := open + 1; i < closing; i++ {
+					if source[i].Type == types.TokName {
+						names[source[i].Repr] = true
+						classes[i] = semanticClass{tokenType: semanticTypeParameter, modifiers: modifierDeclaration}
+					} else if source[i].KeywType != types.KwComma {
+						delete(classes, i)
+						names = nil
+						break
+					}
+				}
+				index = closing + 1
+			} else {
+				index++
+			}
+			if names == nil || index >= len(source) || source[index].KeywType != types.KwDot {
+				break
+			}
+			index++
+		}
+		if len(names) == 0 {
+			continue
+		}
+		for i := index; i < len(source) && !declarationEnds(source, i); i++ {
+			if source[i].Type == types.TokName && names[source[i].Repr] && source[i-1].KeywType != types.KwDot {
+				classes[i] = semanticClass{tokenType: semanticTypeParameter}
+			}
+		}
+	}
+	return classes
+}
+
+// declarationEnds reports whether the token at index closes a top-level
+// declaration: the `..` ending a body, or the end of a header line which
+// does not open one.
+func declarationEnds(source []types.Token, index int) bool {
+	token := source[index]
+	if token.KeywType == types.KwDots && token.Pos.Col == 1 {
+		return true
+	}
+	if token.KeywType != types.KwNewline || index == 0 || source[index-1].KeywType == types.KwColon {
+		return false
+	}
+	for i := index + 1; i < len(source); i++ {
+		if source[i].KeywType != types.KwNewline {
+			return source[i].Pos.Col == 1
+		}
+	}
+	return true
+}
+
+func functionClass(function *types.NodeFuncDef) semanticClass {
+	class := semanticClass{tokenType: semanticFunction}
+	if function.IsMember || function.ProtoDispatch != nil || strings.Contains(flattenName(function.Class.NameNode), ".") {
+		class.tokenType = semanticMethod
+	}
+	if function.IsDestructor {
+		class.modifiers |= modifierDestructor
+	}
+	return class
+}
+
+// variableClass classifies a parameter, the receiver included, a global,
+// which is thread-local unless it is a constant, or a local.
+func (a *analysis) variableClass(variable *types.NodeExprVarDef) semanticClass {
+	switch {
+	case variable.Storage == types.VariableStorageArgument || flattenName(variable.Name) == "this":
+		return semanticClass{tokenType: semanticParameter, modifiers: a.bindingOwnership(variable.Type, true)}
+	case variable.IsGlobal && variable.IsConst:
+		return semanticClass{tokenType: semanticVariable, modifiers: modifierGlobal | modifierReadonly}
+	case variable.IsGlobal:
+		return semanticClass{tokenType: semanticVariable, modifiers: modifierGlobal | modifierThreadLocal}
+	}
+	class := semanticClass{tokenType: semanticVariable, modifiers: a.bindingOwnership(variable.Type, false)}
+	if variable.IsConst {
+		class.modifiers |= modifierReadonly
+	}
+	return class
+}
+
+// bindingOwnership marks a binding which owns a destructible value: a `$T`
+// parameter or a local. An unmarked parameter of such a type borrows it.
+func (a *analysis) bindingOwnership(valueType *types.NodeType, parameter bool) uint32 {
+	switch {
+	case valueType == nil || !(destructible(valueType) || a.hasDestructor(valueType)):
+		return 0
+	case valueType.Owned || !parameter:
+		return modifierOwned
+	}
+	return modifierBorrowed
+}
+
+// hasDestructor reports whether a resolved struct type declares a
+// destructor. Linking records it on the type only for qualified names.
+func (a *analysis) hasDestructor(valueType *types.NodeType) bool {
+	definition := a.structDefinition(valueType)
+	return definition != nil && definition.Destructor != nil
+}
+
+// structDefinition returns the struct a resolved type names.
+func (a *analysis) structDefinition(valueType *types.NodeType) *types.StructDef {
+	absolute, ok := valueType.KindNode.(*types.NodeTypeAbsolute)
+	if !ok || a.shared == nil {
+		return nil
+	}
+	module, name, _ := strings.Cut(absolute.AbsoluteName, ".")
+	if file := a.moduleFile(module); file != nil && file.GlNode != nil {
+		return file.GlNode.StructDefs[name]
+	}
+	return nil
+}
+
+// indexSemanticBindings records the name, parameters, and locals of a
+// function, and the names referring to them, before monomorphization prunes
+// generic templates. Types are unresolved at this point, so only a spelled `$`
+// marks an owning binding.
+func (d *docIndex) indexSemanticBindings(module string, function *types.NodeFuncDef) {
+	if token, ok := declarationNameToken(function.Class.NameNode); ok && token.Pos.Line != 0 && token.Repr == lastPart(flattenName(function.Class.NameNode)) {
+		class := functionClass(function)
+		class.modifiers |= modifierDeclaration
+		d.semanticBindings[scopedTokenPositionKey(module, token)] = class
+	}
+	bindings := map[string]semanticClass{}
+	declare := func(token types.Token, name string, class semanticClass) {
+		if token.Pos.Line == 0 || token.Repr != name {
+			return
+		}
+		bindings[name] = class
+		class.modifiers |= modifierDeclaration
+		d.semanticBindings[scopedTokenPositionKey(module, token)] = class
+	}
+	for _, argument := range function.Class.ArgsNode.Args {
+		class := semanticClass{tokenType: semanticParameter}
+		if argument.TypeNode != nil && argument.TypeNode.Owned {
+			class.modifiers = modifierOwned
+		}
+		declare(argument.Tk, argument.Name, class)
+	}
+	walkAST(&function.Body, func(value any) bool {
+		switch node := value.(type) {
+		case *types.NodeExprVarDef:
+			if single, ok := node.Name.(*types.NodeNameSingle); ok {
+				class := semanticClass{tokenType: semanticVariable}
+				if node.IsConst {
+					class.modifiers = modifierReadonly
+				}
+				declare(single.Tk, single.Name, class)
+			}
+		case *types.NodeExprName:
+			var token types.Token
+			var name string
+			switch n := node.Name.(type) {
+			case *types.NodeNameSingle:
+				token, name = n.Tk, n.Name
+			case *types.NodeNameComposite:
+				if len(n.Tokens) == 0 || len(n.Parts) == 0 {
+					return true
+				}
+				token, name = n.Tokens[0], n.Parts[0]
+			}
+			if class, ok := bindings[name]; ok && token.Pos.Line != 0 && token.Repr == name {
+				d.semanticBindings[scopedTokenPositionKey(module, token)] = class
+			}
+		}
+		return true
+	})
+}
+
+func (d *docIndex) semanticBinding(module string, token types.Token) (semanticClass, bool) {
+	if d == nil {
+		return semanticClass{}, false
+	}
+	class, ok := d.semanticBindings[scopedTokenPositionKey(module, token)]
+	return class, ok
+}
+
+func lastPart(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
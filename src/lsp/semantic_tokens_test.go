@@ -0,0 +1,161 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const semanticSource = "mod main\n" +
+	"use \"std:errors\" errors\n" +
+	"alias Id = u64\n" +
+	"const LIMIT u64 = 3\n" +
+	"counter u64\n" +
+	"proto Reader(read() u64)\n" +
+	"Res impl Reader(id Id)\n" +
+	"destr Res.close() void:\n" +
+	"..\n" +
+	"Res.read() u64:\n" +
+	"    ret this.id\n" +
+	"..\n" +
+	"pick[T](left T, right T) T:\n" +
+	"    x := left\n" +
+	"    ret x\n" +
+	"..\n" +
+	"keep(res $Res, other Res) u64:\n" +
+	"    res.close()\n" +
+	"    ret other.read() + LIMIT + counter\n" +
+	"..\n" +
+	"main() void:\n" +
+	"    r := Res(id=1)\n" +
+	"    e := errors.ok()\n" +
+	"..\n"
+
+func semanticServer(t *testing.T) (*server, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "semantic.mg")
+	if err := os.WriteFile(path, []byte(semanticSource), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file:///" + filepath.ToSlash(path)
+	return &server{stdRoot: testStdRoot(), documents: map[string]*document{uri: {URI: uri, Text: semanticSource}}}, uri
+}
+
+func TestSemanticTokensClassifyNames(t *testing.T) {
+	s, uri := semanticServer(t)
+	result := analyze(uri, semanticSource, s.stdRoot)
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	lines := strings.Split(semanticSource, "\n")
+	got := map[string]bool{}
+	for _, token := range result.semanticTokens(semanticSource) {
+		text := string([]rune(lines[token.line])[token.character : token.character+token.length])
+		modifiers := []string{}
+		for i, modifier := range semanticTokenModifiers {
+			if token.class.modifiers&(1<<i) != 0 {
+				modifiers = append(modifiers, modifier)
+			}
+		}
+		got[fmt.Sprintf("%d %s %s %s", token.line, text, semanticTokenTypes[token.class.tokenType], strings.Join(modifiers, ","))] = true
+	}
+	for _, want := range []string{
+		"0 main namespace declaration",
+		"1 errors namespace declaration",
+		"2 Id type declaration",
+		"3 LIMIT variable declaration,readonly,global",
+		"4 counter variable declaration,global,threadLocal",
+		"5 Reader interface declaration",
+		"6 Res struct declaration",
+		"6 Reader interface ",
+		"6 Id type ",
+		"7 Res struct ",
+		"7 close method declaration,destructor",
+		"9 read method declaration",
+		"10 this parameter ",
+		"12 pick function declaration",
+		"12 T typeParameter declaration",
+		"12 left parameter declaration",
+		"12 T typeParameter ",
+		"13 x variable declaration",
+		"13 left parameter ",
+		"16 res parameter declaration,owned",
+		"16 other parameter declaration,borrowed",
+		"17 res parameter owned",
+		"17 close method destructor",
+		"18 read method ",
+		"18 LIMIT variable readonly,global",
+		"18 counter variable global,threadLocal",
+		"21 r variable declaration,owned",
+		"22 errors namespace ",
+		"22 ok function ",
+	} {
+		if !got[want] {
+			t.Errorf("missing token %q in %v", want, got)
+		}
+	}
+}
+
+func semanticTokensRequest(t *testing.T, s *server, method string, params map[string]any) map[string]json.RawMessage {
+	t.Helper()
+	var output bytes.Buffer
+	s.out = &output
+	payload, _ := json.Marshal(params)
+	if err := s.handle(message{ID: json.RawMessage("1"), Method: method, Params: payload}); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Result map[string]json.RawMessage `json:"result"`
+	}
+	body := output.String()
+	if err := json.Unmarshal([]byte(body[strings.Index(body, "{"):]), &response); err != nil {
+		t.Fatal(err)
+	}
+	return response.Result
+}
+
+func TestSemanticTokensDeltaAndRange(t *testing.T) {
+	s, uri := semanticServer(t)
+	document := map[string]any{"uri": uri}
+	full := semanticTokensRequest(t, s, "textDocument/semanticTokens/full", map[string]any{"textDocument": document})
+	var data []uint32
+	if err := json.Unmarshal(full["data"], &data); err != nil || len(data) == 0 || len(data)%5 != 0 {
+		t.Fatalf("full response = %s", full["data"])
+	}
+	var resultID string
+	_ = json.Unmarshal(full["resultId"], &resultID)
+
+	// Renaming a local changes only the tokens of its two lines.
+	d := s.documents[uri]
+	d.Text = strings.Replace(d.Text, "    x := left\n    ret x\n", "    y := left\n    ret y\n", 1)
+	d.result = nil
+	delta := semanticTokensRequest(t, s, "textDocument/semanticTokens/full/delta", map[string]any{"textDocument": document, "previousResultId": resultID})
+	var edits []semanticTokensEdit
+	if err := json.Unmarshal(delta["edits"], &edits); err != nil {
+		t.Fatalf("delta response = %v", delta)
+	}
+	if len(edits) != 0 {
+		t.Fatalf("renaming to a name of the same length produced edits %#v", edits)
+	}
+	_ = json.Unmarshal(delta["resultId"], &resultID)
+
+	d.Text = strings.Replace(d.Text, "    y := left\n", "    y := right\n", 1)
+	d.result = nil
+	delta = semanticTokensRequest(t, s, "textDocument/semanticTokens/full/delta", map[string]any{"textDocument": document, "previousResultId": resultID})
+	if err := json.Unmarshal(delta["edits"], &edits); err != nil || len(edits) != 1 || edits[0].DeleteCount != 1 || fmt.Sprint(edits[0].Data) != "[5]" {
+		t.Fatalf("delta edits = %s", delta["edits"])
+	}
+	stale := semanticTokensRequest(t, s, "textDocument/semanticTokens/full/delta", map[string]any{"textDocument": document, "previousResultId": "stale"})
+	if _, ok := stale["data"]; !ok {
+		t.Fatalf("delta against an unknown result = %v, want full data", stale)
+	}
+
+	within := semanticTokensRequest(t, s, "textDocument/semanticTokens/range", map[string]any{"textDocument": document, "range": rangePosition{Start: position{Line: 21}, End: position{Line: 21, Character: 20}}})
+	if err := json.Unmarshal(within["data"], &data); err != nil || fmt.Sprint(data) != "[21 4 1 8 33 0 5 3 2 0]" {
+		t.Fatalf("range data = %s", within["data"])
+	}
+}
@@ -33,6 +33,10 @@ type document struct {
 	URI, Text string
 	Version   int
 	result    *analysis
+	// semanticTokens is the data last reported under semanticResultID, which
+	// full/delta requests are answered against.
+	semanticTokens   []uint32
+	semanticResultID string
 }
 type server struct {
 	in             *bufio.Reader
@@ -40,6 +44,19 @@ type server struct {
 	stdRoot        string
 	documents      map[string]*document
 	safetyWarnings bool
+	// semanticResults numbers semantic token responses.
+	semanticResults int
+	// folders are the workspace folders, innermost first; folderStdRoots are
+	// the standard libraries configured for them by URI.
+	folders        []workspaceFolder
+	folderStdRoots map[string]string
+	// importers is the reverse-import graph of every unit loaded by an
+	// analysis, keyed by imported path; imports holds its forward edges.
+	importers map[string]map[string]bool
+	imports   map[string][]string
+	// watchFiles is set when the client accepts a dynamically registered
+	// watcher for Magma sources.
+	watchFiles bool
 }
 type analysis struct {
 	file        *types.FileCtx
@@ -47,6 +64,8 @@ type analysis struct {
 	warnings    []types.Diagnostic
 	docs        *docIndex
 	definitions map[string]location
+	// shared holds every unit loaded for the analysis, imports included.
+	shared *types.SharedState
 }
 
 type rangePosition struct {
@@ -64,6 +83,7 @@ type diagnostic struct {
 	Source             string              `json:"source"`
 	Message            string              `json:"message"`
 	Code               string              `json:"code,omitempty"`
+	Tags               []int               `json:"tags,omitempty"`
 	RelatedInformation []diagnosticRelated `json:"relatedInformation,omitempty"`
 }
 
@@ -85,17 +105,6 @@ func diagnosticsForFile(err error, warnings []types.Diagnostic, path string) []d
 		if item.FilePath == "" || filepath.Clean(item.FilePath) != cleanPath {
 			continue
 		}
-		line, column := item.Token.Pos.Line, item.Token.Pos.Col
-		if line > 0 {
-			line--
-		}
-		if column > 0 {
-			column--
-		}
-		end := column + uint32(utf8.RuneCountInString(item.Token.Repr))
-		if end == column {
-			end++
-		}
 		severity := 1
 		if item.Severity == types.SeverityWarning {
 			severity = 2
@@ -106,8 +115,8 @@ func diagnosticsForFile(err error, warnings []types.Diagnostic, path string) []d
 		}
 		message = strings.Join(strings.Fields(message), " ")
 		result = append(result, diagnostic{
-			Range:    rangePosition{Start: position{Line: line, Character: column}, End: position{Line: line, Character: end}},
-			Severity: severity, Source: "magma", Message: message, Code: item.Code,
+			Range:    spanRange(item.Span()),
+			Severity: severity, Source: "magma", Message: message, Code: item.Code, Tags: diagnosticTags(item.Code),
 			RelatedInformation: relatedDiagnostics(item.Related),
 		})
 	}
@@ -190,8 +199,18 @@ func (s *server) handle(msg message) error {
 	case "initialize":
 		var p struct {
 			InitializationOptions struct {
-				SafetyWarnings bool `json:"safetyWarnings"`
+				SafetyWarnings bool              `json:"safetyWarnings"`
+				StdRoots       map[string]string `json:"stdRoots"`
 			} `json:"initializationOptions"`
+			RootURI          string                  `json:"rootUri"`
+			WorkspaceFolders []workspaceFolderParams `json:"workspaceFolders"`
+			Capabilities     struct {
+				Workspace struct {
+					DidChangeWatchedFiles struct {
+						DynamicRegistration bool `json:"dynamicRegistration"`
+					} `json:"didChangeWatchedFiles"`
+				} `json:"workspace"`
+			} `json:"capabilities"`
 		}
 		if len(msg.Params) != 0 {
 			_ = json.Unmarshal(msg.Params, &p)
@@ -199,11 +218,30 @@ func (s *server) handle(msg message) error {
 				s.safetyWarnings = true
 			}
 		}
-		return s.respond(msg.ID, map[string]any{"capabilities": map[string]any{"textDocumentSync": 1, "hoverProvider": true, "definitionProvider": true, "completionProvider": map[string]any{"triggerCharacters": []string{".", "\"", "/", ":"}}, "codeActionProvider": true, "semanticTokensProvider": map[string]any{"legend": map[string]any{"tokenTypes": []string{"keyword"}, "tokenModifiers": []string{}}, "full": true}}})
+		s.folderStdRoots = p.InitializationOptions.StdRoots
+		if len(p.WorkspaceFolders) == 0 && p.RootURI != "" {
+			p.WorkspaceFolders = []workspaceFolderParams{{URI: p.RootURI}}
+		}
+		for _, folder := range p.WorkspaceFolders {
+			s.addWorkspaceFolder(folder.URI)
+		}
+		s.watchFiles = p.Capabilities.Workspace.DidChangeWatchedFiles.DynamicRegistration
+		return s.respond(msg.ID, map[string]any{"capabilities": map[string]any{"workspace": map[string]any{"workspaceFolders": map[string]any{"supported": true, "changeNotifications": true}}, "textDocumentSync": 1, "hoverProvider": true, "definitionProvider": true, "completionProvider": map[string]any{"triggerCharacters": []string{".", "\"", "/", ":"}}, "signatureHelpProvider": map[string]any{"triggerCharacters": []string{"(", ","}}, "inlayHintProvider": true, "implementationProvider": true, "typeHierarchyProvider": true, "callHierarchyProvider": true, "codeActionProvider": true, "semanticTokensProvider": map[string]any{"legend": map[string]any{"tokenTypes": semanticTokenTypes, "tokenModifiers": semanticTokenModifiers}, "range": true, "full": map[string]any{"delta": true}}}})
 	case "shutdown":
 		return s.respond(msg.ID, nil)
-	case "initialized", "$/cancelRequest", "textDocument/didSave":
+	case "initialized":
+		if !s.watchFiles {
+			return nil
+		}
+		return s.write(map[string]any{"jsonrpc": "2.0", "id": "watch-sources", "method": "client/registerCapability", "params": map[string]any{"registrations": []map[string]any{{"id": "watch-sources", "method": "workspace/didChangeWatchedFiles", "registerOptions": map[string]any{"watchers": []map[string]any{{"globPattern": "**/*.mg"}}}}}}})
+	case "", "$/cancelRequest", "textDocument/didSave":
+		// A message without a method is a client's response to a request of
+		// the server.
 		return nil
+	case "workspace/didChangeWatchedFiles":
+		return s.handleDidChangeWatchedFiles(msg)
+	case "workspace/didChangeWorkspaceFolders":
+		return s.handleDidChangeWorkspaceFolders(msg)
 	case "workspace/didChangeConfiguration":
 		var p struct {
 			Settings struct {
@@ -214,13 +252,7 @@ func (s *server) handle(msg message) error {
 			return err
 		}
 		s.safetyWarnings = p.Settings.SafetyWarnings
-		for uri, d := range s.documents {
-			d.result = nil
-			if err := s.publishDiagnostics(uri); err != nil {
-				return err
-			}
-		}
-		return nil
+		return s.republishAll()
 	case "textDocument/didOpen":
 		var p struct {
 			TextDocument struct {
@@ -233,7 +265,7 @@ func (s *server) handle(msg message) error {
 			return err
 		}
 		s.documents[p.TextDocument.URI] = &document{URI: p.TextDocument.URI, Text: p.TextDocument.Text, Version: p.TextDocument.Version}
-		return s.publishDiagnostics(p.TextDocument.URI)
+		return s.publishChanged(p.TextDocument.URI)
 	case "textDocument/didChange":
 		var p struct {
 			TextDocument struct {
@@ -251,7 +283,7 @@ func (s *server) handle(msg message) error {
 			d.Text = p.ContentChanges[len(p.ContentChanges)-1].Text
 			d.Version = p.TextDocument.Version
 			d.result = nil
-			return s.publishDiagnostics(p.TextDocument.URI)
+			return s.publishChanged(p.TextDocument.URI)
 		}
 	case "textDocument/didClose":
 		var p struct {
@@ -263,7 +295,13 @@ func (s *server) handle(msg message) error {
 			return err
 		}
 		delete(s.documents, p.TextDocument.URI)
-		return s.write(map[string]any{"jsonrpc": "2.0", "method": "textDocument/publishDiagnostics", "params": map[string]any{"uri": p.TextDocument.URI, "diagnostics": []diagnostic{}}})
+		if err := s.write(map[string]any{"jsonrpc": "2.0", "method": "textDocument/publishDiagnostics", "params": map[string]any{"uri": p.TextDocument.URI, "diagnostics": []diagnostic{}}}); err != nil {
+			return err
+		}
+		// Importers now read the file from disk instead of the buffer.
+		if path, ok := documentPath(p.TextDocument.URI); ok {
+			return s.republishDependents(path)
+		}
 	case "textDocument/hover":
 		var p struct {
 			TextDocument struct {
@@ -279,7 +317,7 @@ func (s *server) handle(msg message) error {
 			return s.respond(msg.ID, nil)
 		}
 		if d.result == nil {
-			d.result = analyze(d.URI, d.Text, s.stdRoot)
+			d.result = s.analyzeDocument(d)
 		}
 		value := d.result.hover(p.Position)
 		if value == "" {
@@ -301,7 +339,7 @@ func (s *server) handle(msg message) error {
 			return s.respond(msg.ID, nil)
 		}
 		if d.result == nil {
-			d.result = analyze(d.URI, d.Text, s.stdRoot)
+			d.result = s.analyzeDocument(d)
 		}
 		definition, ok := d.result.definition(p.Position)
 		if !ok {
@@ -322,8 +360,26 @@ func (s *server) handle(msg message) error {
 		if d == nil {
 			return s.respond(msg.ID, completionList{IsIncomplete: true, Items: []completionItem{}})
 		}
-		return s.respond(msg.ID, completionList{IsIncomplete: true, Items: complete(d.URI, d.Text, p.Position, s.stdRoot)})
-	case "textDocument/semanticTokens/full":
+		return s.respond(msg.ID, completionList{IsIncomplete: true, Items: complete(d.URI, d.Text, p.Position, s.stdRootFor(d.URI))})
+	case "textDocument/signatureHelp":
+		return s.handleSignatureHelp(msg)
+	case "textDocument/inlayHint":
+		return s.handleInlayHint(msg)
+	case "textDocument/implementation":
+		return s.handleImplementation(msg)
+	case "textDocument/prepareTypeHierarchy":
+		return s.handlePrepareTypeHierarchy(msg)
+	case "typeHierarchy/supertypes":
+		return s.handleTypeHierarchy(msg, true)
+	case "typeHierarchy/subtypes":
+		return s.handleTypeHierarchy(msg, false)
+	case "textDocument/prepareCallHierarchy":
+		return s.handlePrepareCallHierarchy(msg)
+	case "callHierarchy/incomingCalls":
+		return s.handleCallHierarchy(msg, true)
+	case "callHierarchy/outgoingCalls":
+		return s.handleCallHierarchy(msg, false)
+	case "textDocument/semanticTokens/full", "textDocument/semanticTokens/full/delta", "textDocument/semanticTokens/range":
 		return s.handleSemanticTokens(msg)
 	case "textDocument/codeAction":
 		return s.handleCodeAction(msg)
@@ -340,7 +396,7 @@ func (s *server) publishDiagnostics(uri string) error {
 	if d == nil {
 		return nil
 	}
-	d.result = analyzePolicy(d.URI, d.Text, s.stdRoot, s.safetyWarnings)
+	d.result = s.analyzeDocument(d)
 	path, err := uriPath(uri)
 	if err != nil {
 		return err
@@ -360,6 +416,18 @@ func (s *server) publishDiagnostics(uri string) error {
 	})
 }
 
+// publishChanged publishes a document whose text changed, then every open
+// document importing it.
+func (s *server) publishChanged(uri string) error {
+	if err := s.publishDiagnostics(uri); err != nil {
+		return err
+	}
+	if path, ok := documentPath(uri); ok {
+		return s.republishDependents(path)
+	}
+	return nil
+}
+
 func (s *server) respond(id json.RawMessage, result any) error {
 	return s.write(map[string]any{"jsonrpc": "2.0", "id": id, "result": result})
 }
@@ -376,18 +444,20 @@ func (s *server) write(v any) error {
 }
 
 func analyze(rawURI, source, stdRoot string) *analysis {
-	return analyzePolicy(rawURI, source, stdRoot, false)
-}
-
-func analyzePolicy(rawURI, source, stdRoot string, safetyWarnings bool) *analysis {
-	return analyzeWithRecoveryPolicy(rawURI, source, stdRoot, true, safetyWarnings)
+	return analyzePolicy(rawURI, source, stdRoot, false, nil, os.Stderr)
 }
 
-func analyzeWithRecovery(rawURI, source, stdRoot string, recoverSyntax bool) *analysis {
-	return analyzeWithRecoveryPolicy(rawURI, source, stdRoot, recoverSyntax, false)
+// analyzeQuiet analyzes a speculative rewrite of a buffer, such as one with
+// an unfinished call blanked out. Such rewrites routinely fail to resolve
+// names, so their failures are not logged.
+func analyzeQuiet(rawURI, source, stdRoot string) *analysis {
+	return analyzePolicy(rawURI, source, stdRoot, false, nil, io.Discard)
 }
 
-func analyzeWithRecoveryPolicy(rawURI, source, stdRoot string, recoverSyntax, safetyWarnings bool) *analysis {
+// analyzePolicy analyzes source as the file of rawURI. overrides supply the
+// text of other files by absolute path, such as unsaved editor buffers.
+// Analysis failures are logged to log.
+func analyzePolicy(rawURI, source, stdRoot string, safetyWarnings bool, overrides map[string][]byte, log io.Writer) *analysis {
 	path, err := uriPath(rawURI)
 	if err != nil {
 		return &analysis{err: err}
@@ -400,36 +470,30 @@ func analyzeWithRecoveryPolicy(rawURI, source, stdRoot string, recoverSyntax, sa
 	if err != nil {
 		return &analysis{err: err}
 	}
+	for overridden, text := range overrides {
+		state.SourceOverrides[overridden] = text
+	}
 	state.SourceOverrides[path] = []byte(source)
 	parsed, err := compilerpipeline.Parse(state, path)
 	file := state.Files[path]
 	docs := buildDocIndex(state)
 	definitions := buildDefinitionIndex(state)
-	if recoverSyntax && err != nil {
-		for _, syntaxError := range comp_err.Diagnostics(err) {
-			if syntaxError.Ctx != nil && filepath.Clean(syntaxError.Ctx.FilePath) == path {
-				if recovered, ok := blankSourceLine(source, syntaxError.Token.Pos.Line); ok {
-					result := analyzeWithRecoveryPolicy(rawURI, recovered, stdRoot, false, safetyWarnings)
-					result.err = err
-					return result
-				}
-			}
-		}
-	}
 	if file == nil || file.GlNode == nil {
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "magma-lsp: analysis failed for %s: %v\n", path, err)
+			fmt.Fprintf(log, "magma-lsp: analysis failed for %s: %v\n", path, err)
 		}
-		return &analysis{file: file, err: err, docs: docs, definitions: definitions}
+		return &analysis{file: file, shared: state, err: err, docs: docs, definitions: definitions}
 	}
-	// The parser returns the portion of the global tree completed before a
-	// syntax error. Keep that tree useful for editor features: a half-written
-	// expression later in the buffer must not disable hover for imports and
-	// declarations that were parsed successfully.
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "magma-lsp: partial analysis for %s: %v\n", path, err)
-		return &analysis{file: file, err: err, docs: docs, definitions: definitions}
+	// The parser recovers from syntax errors, replacing each failed statement
+	// or declaration with an error node. Keep the rest of the buffer useful for
+	// editor features: a half-written expression must not disable hover or
+	// semantic diagnostics for declarations that parsed successfully. Only a
+	// unit without any tree, such as a missing import, stops analysis here.
+	if err != nil && !allUnitsParsed(state) {
+		fmt.Fprintf(log, "magma-lsp: partial analysis for %s: %v\n", path, err)
+		return &analysis{file: file, shared: state, err: err, docs: docs, definitions: definitions}
 	}
+	syntaxErr := err
 	specialized, err := compilerpipeline.Specialize(parsed)
 	if err == nil {
 		var linked compilerpipeline.LinkedProgram
@@ -438,6 +502,7 @@ func analyzeWithRecoveryPolicy(rawURI, source, stdRoot string, recoverSyntax, sa
 			var typed compilerpipeline.TypedProgram
 			typed, err = compilerpipeline.CheckTypes(linked)
 			if err == nil {
+				compilerpipeline.CheckDeadCode(typed)
 				var validated compilerpipeline.ValidatedProgram
 				validated, err = compilerpipeline.ValidateLowering(typed)
 				if err == nil {
@@ -450,9 +515,25 @@ func analyzeWithRecoveryPolicy(rawURI, source, stdRoot string, recoverSyntax, sa
 	// function locals with call/try types resolved by semantic analysis.
 	docs.refreshCompletionBindings(file.PackageName, file.GlNode)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "magma-lsp: semantic analysis failed for %s: %v\n", path, err)
+		fmt.Fprintf(log, "magma-lsp: semantic analysis failed for %s: %v\n", path, err)
 	}
-	return &analysis{file: file, err: err, warnings: state.Warnings, docs: docs, definitions: definitions}
+	return &analysis{file: file, shared: state, err: comp_err.Join(syntaxErr, err), warnings: state.Warnings, docs: docs, definitions: definitions}
+}
+
+// allUnitsParsed reports whether every compilation unit, and every unit it
+// imports, produced a syntax tree.
+func allUnitsParsed(state *types.SharedState) bool {
+	for _, file := range state.Files {
+		if file == nil || file.GlNode == nil {
+			return false
+		}
+		for _, imported := range file.Imports {
+			if unit := state.Files[imported]; unit == nil || unit.GlNode == nil {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 func buildDefinitionIndex(state *types.SharedState) map[string]location {
@@ -477,6 +558,13 @@ func buildDefinitionIndex(state *types.SharedState) map[string]location {
 			}
 			index[file.PackageName+"\x00"+sourceName(name)] = tokenLocation(file.FilePath, token)
 		}
+		for _, declaration := range file.GlNode.Declarations {
+			if structure, ok := declaration.(*types.NodeStructDef); ok {
+				if token, ok := declarationNameToken(structure.Class.NameNode); ok {
+					index[file.PackageName+"\x00"+flattenName(structure.Class.NameNode)] = tokenLocation(file.FilePath, token)
+				}
+			}
+		}
 	}
 	return index
 }
@@ -494,10 +582,16 @@ func declarationNameToken(name types.NodeName) (types.Token, bool) {
 }
 
 func tokenLocation(path string, token types.Token) location {
-	start := position{Line: token.Pos.Line - 1, Character: token.Pos.Col - 1}
-	end := start
-	end.Character += uint32(utf8.RuneCountInString(token.Repr))
-	return location{URI: fileURI(path), Range: rangePosition{Start: start, End: end}}
+	return location{URI: fileURI(path), Range: spanRange(token.Span())}
+}
+
+// spanRange converts an exclusive compiler span, with 1-based lines and
+// columns, to a zero-based protocol range.
+func spanRange(start, end types.FilePos) rangePosition {
+	toPosition := func(pos types.FilePos) position {
+		return position{Line: max(pos.Line, 1) - 1, Character: max(pos.Col, 1) - 1}
+	}
+	return rangePosition{Start: toPosition(start), End: toPosition(end)}
 }
 
 func fileURI(path string) string {
@@ -569,31 +663,6 @@ func (a *analysis) resolvedMemberDefinition(pos position) (location, bool) {
 	return result, found
 }
 
-// blankSourceLine preserves every token position outside the invalid line so
-// hover can use the rest of an editor buffer while an expression is unfinished.
-func blankSourceLine(source string, line uint32) (string, bool) {
-	if line == 0 {
-		return source, false
-	}
-	lines := strings.SplitAfter(source, "\n")
-	index := int(line - 1)
-	if index >= len(lines) {
-		return source, false
-	}
-	ending := ""
-	body := lines[index]
-	if strings.HasSuffix(body, "\n") {
-		ending = "\n"
-		body = strings.TrimSuffix(body, "\n")
-	}
-	if strings.HasSuffix(body, "\r") {
-		ending = "\r" + ending
-		body = strings.TrimSuffix(body, "\r")
-	}
-	lines[index] = strings.Repeat(" ", len(body)) + ending
-	return strings.Join(lines, ""), true
-}
-
 func uriPath(raw string) (string, error) {
 	u, err := url.Parse(raw)
 	if err != nil {
@@ -948,7 +1017,11 @@ func formatFunction(fn *types.NodeFuncDef) string {
 		if fn.IsMember && i == 0 && a.Name == "this" {
 			continue
 		}
-		args = append(args, a.Name+" "+formatType(a.TypeNode))
+		arg := a.Name + " " + formatType(a.TypeNode)
+		if a.DefaultSource != "" {
+			arg += " = " + a.DefaultSource
+		}
+		args = append(args, arg)
 	}
 	name := flattenName(fn.Class.NameNode)
 	if fn.DisplayName != "" {
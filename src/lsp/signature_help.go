@@ -0,0 +1,314 @@
+package lsp
+
+import (
+	"Magma/src/docgen"
+	"Magma/src/types"
+	"encoding/json"
+	"strings"
+	"unicode/utf16"
+)
+
+type signatureHelp struct {
+	Signatures      []signatureInformation `json:"signatures"`
+	ActiveSignature uint32                 `json:"activeSignature"`
+	ActiveParameter uint32                 `json:"activeParameter"`
+}
+
+type signatureInformation struct {
+	Label         string                 `json:"label"`
+	Documentation map[string]any         `json:"documentation,omitempty"`
+	Parameters    []parameterInformation `json:"parameters"`
+}
+
+// parameterInformation labels a parameter by its UTF-16 offsets within the
+// signature label, so clients highlight it even when two share a spelling.
+type parameterInformation struct {
+	Label         [2]uint32      `json:"label"`
+	Documentation map[string]any `json:"documentation,omitempty"`
+}
+
+// signatureContext is the innermost call enclosing the cursor.
+type signatureContext struct {
+	callee string
+	active int
+	// firstLine and lastLine bound the lines of the unfinished call, which
+	// are blanked before analysis.
+	firstLine int
+	lastLine  int
+}
+
+type signatureFrame struct {
+	open   int
+	line   int
+	kind   byte
+	commas int
+}
+
+func (s *server) handleSignatureHelp(msg message) error {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Position position `json:"position"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	d := s.documents[p.TextDocument.URI]
+	if d == nil {
+		return s.respond(msg.ID, nil)
+	}
+	help, ok := signatureHelpAt(d.URI, d.Text, p.Position, s.stdRootFor(d.URI))
+	if !ok {
+		return s.respond(msg.ID, nil)
+	}
+	return s.respond(msg.ID, help)
+}
+
+func signatureHelpAt(uri, source string, pos position, stdRoot string) (signatureHelp, bool) {
+	context, ok := signatureCallAt(source, pos)
+	if !ok {
+		return signatureHelp{}, false
+	}
+	parts, ok := selectorParts(context.callee)
+	if !ok {
+		return signatureHelp{}, false
+	}
+	// The unfinished call is not valid Magma yet. Blanking its lines, while
+	// keeping their breaks, leaves the rest of the buffer analyzable without
+	// moving the declarations that receiver resolution looks up by line.
+	lines := strings.SplitAfter(sanitizeOtherSelectors(source, int(pos.Line)), "\n")
+	for i := context.firstLine; i <= context.lastLine && i < len(lines); i++ {
+		lines[i] = lines[i][len(strings.TrimRight(lines[i], "\r\n")):]
+	}
+	result := analyzeQuiet(uri, strings.Join(lines, ""), stdRoot)
+	if result == nil || result.file == nil || result.docs == nil {
+		return signatureHelp{}, false
+	}
+	key, ok := result.signatureKey(parts, pos.Line+1)
+	if !ok {
+		return signatureHelp{}, false
+	}
+	function := result.docs.functions[key]
+	if function == nil {
+		return signatureHelp{}, false
+	}
+	signature := formatSignature(function, result.docs.functionDocs[key])
+	return signatureHelp{Signatures: []signatureInformation{signature}, ActiveParameter: uint32(context.active)}, true
+}
+
+// signatureKey resolves a callee selector to its docIndex function key. Calls
+// qualified by a type name, such as `Thing.create()`, have no receiver value
+// and fall back to the owner's members in the current module.
+func (a *analysis) signatureKey(parts []selectorPart, line uint32) (string, bool) {
+	name := parts[len(parts)-1].name
+	if len(parts) == 1 {
+		return a.file.PackageName + "\x00" + name, true
+	}
+	module, owner, ok := a.selectorOwner(parts[:len(parts)-1], line)
+	if ok && module != "" {
+		if owner != "" {
+			return module + "\x00" + owner + "." + name, true
+		}
+		return module + "\x00" + name, true
+	}
+	if len(parts) == 2 && !parts[0].call {
+		return a.file.PackageName + "\x00" + parts[0].name + "." + name, true
+	}
+	return "", false
+}
+
+// formatSignature renders a declaration in source syntax, keeping the `$`
+// ownership and `!` throwing markers which formatFunction spells out for
+// hovers.
+func formatSignature(function *types.NodeFuncDef, doc docgen.Documentation) signatureInformation {
+	label := flattenName(function.Class.NameNode)
+	if len(function.Class.TypeParams) != 0 {
+		label += "[" + strings.Join(function.Class.TypeParams, ", ") + "]"
+	}
+	label += "("
+	parameters := []parameterInformation{}
+	for i, argument := range callParameters(function) {
+		if i > 0 {
+			label += ", "
+		}
+		start := utf16Length(label)
+		label += argument.Name + " " + signatureType(argument.TypeNode)
+		if argument.DefaultSource != "" {
+			label += " = " + argument.DefaultSource
+		}
+		parameters = append(parameters, parameterInformation{
+			Label:         [2]uint32{start, utf16Length(label)},
+			Documentation: markdownContent(doc.Param(argument.Name)),
+		})
+	}
+	label += ") " + signatureType(function.ReturnType)
+	return signatureInformation{Label: label, Documentation: markdownContent(doc.Markdown()), Parameters: parameters}
+}
+
+// callParameters returns the parameters a call site supplies, without the
+// receiver which semantic analysis inserts into member declarations.
+func callParameters(function *types.NodeFuncDef) []types.NodeArg {
+	arguments := function.Class.ArgsNode.Args
+	if function.IsMember && len(arguments) != 0 && arguments[0].Name == "this" {
+		return arguments[1:]
+	}
+	return arguments
+}
+
+// signatureType renders a type as written in a signature: `$T` for an
+// ownership-transfer position and `!T` for a throwing result.
+func signatureType(node *types.NodeType) string {
+	if node == nil {
+		return "?"
+	}
+	text := types.DisplayType(&types.NodeType{KindNode: node.KindNode})
+	if node.Owned {
+		text = "$" + text
+	}
+	if node.Throws {
+		text = "!" + text
+	}
+	return text
+}
+
+func utf16Length(value string) uint32 {
+	return uint32(len(utf16.Encode([]rune(value))))
+}
+
+// signatureCallAt scans the statement containing pos, from its enclosing
+// top-level line, for the innermost open call. Strings and comments are
+// skipped, and the commas directly inside that call select the active
+// parameter. A parenthesized group without a callee defers to the call
+// around it, as does the parameter list of a declaration.
+func signatureCallAt(source string, pos position) (signatureContext, bool) {
+	lines := strings.SplitAfter(source, "\n")
+	if int(pos.Line) >= len(lines) {
+		return signatureContext{}, false
+	}
+	current := []rune(strings.TrimRight(lines[pos.Line], "\r\n"))
+	if int(pos.Character) > len(current) {
+		return signatureContext{}, false
+	}
+	start := int(pos.Line)
+	for ; start > 0; start-- {
+		text := strings.TrimRight(lines[start], "\r\n")
+		trimmed := strings.TrimSpace(text)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") && indentation(text) == 0 {
+			break
+		}
+	}
+	text := strings.Join(lines[start:pos.Line], "") + string(current[:pos.Character])
+	stack := []signatureFrame{}
+	line := start
+	var quote rune
+	escaped := false
+	comment := false
+	for i, r := range text {
+		if r == '\n' {
+			line++
+			comment = false
+			quote = 0
+			continue
+		}
+		if comment {
+			continue
+		}
+		if quote != 0 {
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'':
+			quote = r
+		case '#':
+			comment = true
+		case '(', '[':
+			stack = append(stack, signatureFrame{open: i, line: line, kind: byte(r)})
+		case ')', ']':
+			if len(stack) != 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			if len(stack) != 0 {
+				stack[len(stack)-1].commas++
+			}
+		}
+	}
+	if quote != 0 || comment || len(stack) == 0 {
+		return signatureContext{}, false
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		frame := stack[i]
+		if frame.kind != '(' {
+			continue
+		}
+		callee, calleeStart := signatureCallee(text[:frame.open])
+		if callee == "" {
+			continue
+		}
+		// `name(`, `pub name(`, or `Owner.name(` at the start of an
+		// unindented line opens a declaration's parameter list, not a call.
+		lineStart := strings.LastIndexByte(text[:calleeStart], '\n') + 1
+		declaration := indentation(text[lineStart:]) == 0
+		for _, word := range strings.Fields(text[lineStart:calleeStart]) {
+			declaration = declaration && identifier(word)
+		}
+		if declaration {
+			continue
+		}
+		return signatureContext{callee: callee, active: frame.commas, firstLine: stack[0].line, lastLine: int(pos.Line)}, true
+	}
+	return signatureContext{}, false
+}
+
+// signatureCallee returns the selector directly before a call's `(`, without
+// explicit generic arguments, and the byte offset where it starts.
+func signatureCallee(before string) (string, int) {
+	end := len(before)
+	if strings.HasSuffix(before, "]") {
+		depth := 0
+		for end > 0 {
+			r, size := lastRune(before[:end])
+			end -= size
+			if r == ']' {
+				depth++
+			} else if r == '[' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if depth != 0 {
+			return "", 0
+		}
+	}
+	start := end
+	depth := 0
+	for start > 0 {
+		r, size := lastRune(before[:start])
+		if r == ')' || r == ']' {
+			depth++
+		} else if r == '(' || r == '[' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		} else if depth == 0 && !isIdentRune(r) && r != '.' {
+			break
+		}
+		start -= size
+	}
+	callee := before[start:end]
+	if _, ok := selectorParts(callee); !ok || strings.HasPrefix(callee, ".") {
+		return "", 0
+	}
+	return callee, start
+}
@@ -0,0 +1,104 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const signatureSource = "mod main\n" +
+	"\n" +
+	"# Adds two numbers.\n" +
+	"# @param a the first term\n" +
+	"# @param b the second term\n" +
+	"add(a i32, b i32) i32:\n" +
+	"    ret a + b\n" +
+	"..\n" +
+	"Res(id u64)\n" +
+	"destr Res.close() void:\n" +
+	"..\n" +
+	"Res.adopt(other $Res, count u64) !u64:\n" +
+	"    other.close()\n" +
+	"    ret count\n" +
+	"..\n" +
+	"pick[T](left T, right T) T:\n" +
+	"    ret left\n" +
+	"..\n" +
+	"main() void:\n" +
+	"    r := Res(id=1)\n" +
+	"    %s\n" +
+	"..\n"
+
+func signatureHelpFor(t *testing.T, line string) (signatureHelp, bool) {
+	t.Helper()
+	source := fmt.Sprintf(signatureSource, line)
+	path := filepath.Join(t.TempDir(), "signature.mg")
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return signatureHelpAt("file:///"+filepath.ToSlash(path), source, position{Line: 20, Character: uint32(4 + len([]rune(line)))}, testStdRoot())
+}
+
+func TestSignatureHelpTracksActiveParameterThroughNestedCalls(t *testing.T) {
+	tests := []struct {
+		line   string
+		label  string
+		active uint32
+	}{
+		{"add(", "add(a i32, b i32) i32", 0},
+		{"add(1, ", "add(a i32, b i32) i32", 1},
+		{"add(add(1, ", "add(a i32, b i32) i32", 1},
+		{"add(add(1, 2), ", "add(a i32, b i32) i32", 1},
+		{"add((1 + 2), ", "add(a i32, b i32) i32", 1},
+		{"add(\"(,\", ", "add(a i32, b i32) i32", 1},
+		{"pick[u64](1, ", "pick[T](left T, right T) T", 1},
+		{"r.adopt(", "Res.adopt(other $Res, count u64) !u64", 0},
+	}
+	for _, test := range tests {
+		help, ok := signatureHelpFor(t, test.line)
+		if !ok || len(help.Signatures) != 1 {
+			t.Fatalf("%q: signature help = %#v, %v", test.line, help, ok)
+		}
+		if got := help.Signatures[0].Label; got != test.label || help.ActiveParameter != test.active {
+			t.Fatalf("%q: signature = %q, active %d; want %q, active %d", test.line, got, help.ActiveParameter, test.label, test.active)
+		}
+	}
+}
+
+func TestSignatureHelpParameterLabelsAndDocumentation(t *testing.T) {
+	help, ok := signatureHelpFor(t, "add(1, ")
+	if !ok {
+		t.Fatal("no signature help")
+	}
+	signature := help.Signatures[0]
+	if len(signature.Parameters) != 2 {
+		t.Fatalf("parameters = %#v", signature.Parameters)
+	}
+	for i, want := range []string{"a i32", "b i32"} {
+		span := signature.Parameters[i].Label
+		if got := signature.Label[span[0]:span[1]]; got != want {
+			t.Fatalf("parameter %d label = %q, want %q", i, got, want)
+		}
+	}
+	if got := signature.Parameters[1].Documentation["value"]; got != "the second term" {
+		t.Fatalf("parameter documentation = %#v", got)
+	}
+	if got, _ := signature.Documentation["value"].(string); !strings.HasPrefix(got, "Adds two numbers.") {
+		t.Fatalf("signature documentation = %q", got)
+	}
+}
+
+func TestSignatureHelpIgnoresDeclarationsAndClosedCalls(t *testing.T) {
+	if context, ok := signatureCallAt("mod main\nadd(a i32, ", position{Line: 1, Character: 11}); ok {
+		t.Fatalf("declaration parameter list resolved as call %#v", context)
+	}
+	if context, ok := signatureCallAt("mod main\nmain() void:\n    add(1, 2) ", position{Line: 2, Character: 14}); ok {
+		t.Fatalf("closed call resolved as %#v", context)
+	}
+	context, ok := signatureCallAt("mod main\nmain() void:\n    x := items.first().add(1,\n        2, ", position{Line: 3, Character: 11})
+	if !ok || context.callee != "items.first().add" || context.active != 2 || context.firstLine != 2 || context.lastLine != 3 {
+		t.Fatalf("multi-line call = %#v, %v", context, ok)
+	}
+}
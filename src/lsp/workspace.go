@@ -0,0 +1,201 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// workspaceFolder is an open workspace root and the standard library its
+// documents compile against.
+type workspaceFolder struct {
+	uri     string
+	path    string
+	stdRoot string
+}
+
+type workspaceFolderParams struct {
+	URI string `json:"uri"`
+}
+
+// addWorkspaceFolder resolves the standard library of a folder: an explicit
+// initializationOptions.stdRoots entry, then a `std` directory holding
+// core.mg at the folder root, then the server's own root.
+func (s *server) addWorkspaceFolder(uri string) {
+	path, ok := documentPath(uri)
+	if !ok {
+		return
+	}
+	stdRoot := s.stdRoot
+	if configured := s.folderStdRoots[uri]; configured != "" {
+		stdRoot = configured
+	} else if info, err := os.Stat(filepath.Join(path, "std", "core.mg")); err == nil && !info.IsDir() {
+		stdRoot = filepath.Join(path, "std")
+	}
+	s.removeWorkspaceFolder(uri)
+	s.folders = append(s.folders, workspaceFolder{uri: uri, path: path, stdRoot: stdRoot})
+	// Nested folders are matched before the folders containing them.
+	sort.Slice(s.folders, func(i, j int) bool { return len(s.folders[i].path) > len(s.folders[j].path) })
+}
+
+func (s *server) removeWorkspaceFolder(uri string) {
+	kept := s.folders[:0]
+	for _, folder := range s.folders {
+		if folder.uri != uri {
+			kept = append(kept, folder)
+		}
+	}
+	s.folders = kept
+}
+
+// stdRootFor returns the standard library of the innermost workspace folder
+// containing a document.
+func (s *server) stdRootFor(uri string) string {
+	path, ok := documentPath(uri)
+	if !ok {
+		return s.stdRoot
+	}
+	for _, folder := range s.folders {
+		if relative, err := filepath.Rel(folder.path, path); err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			return folder.stdRoot
+		}
+	}
+	return s.stdRoot
+}
+
+func (s *server) handleDidChangeWorkspaceFolders(msg message) error {
+	var p struct {
+		Event struct {
+			Added   []workspaceFolderParams `json:"added"`
+			Removed []workspaceFolderParams `json:"removed"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	for _, folder := range p.Event.Removed {
+		s.removeWorkspaceFolder(folder.URI)
+	}
+	for _, folder := range p.Event.Added {
+		s.addWorkspaceFolder(folder.URI)
+	}
+	return s.republishAll()
+}
+
+// handleDidChangeWatchedFiles re-analyzes the open documents importing a file
+// changed on disk. An open document is read from its buffer rather than the
+// disk, so its own changes are already accounted for.
+func (s *server) handleDidChangeWatchedFiles(msg message) error {
+	var p struct {
+		Changes []struct {
+			URI string `json:"uri"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return err
+	}
+	changed := []string{}
+	for _, change := range p.Changes {
+		if s.documents[change.URI] != nil {
+			continue
+		}
+		if path, ok := documentPath(change.URI); ok {
+			changed = append(changed, path)
+		}
+	}
+	return s.republishDependents(changed...)
+}
+
+// analyzeDocument analyzes an open document under the server's safety policy
+// and the standard library of its workspace folder. Every other open buffer
+// stands in for its file on disk, and the imports loaded on the way update
+// the reverse-import graph.
+func (s *server) analyzeDocument(d *document) *analysis {
+	overrides := map[string][]byte{}
+	for uri, other := range s.documents {
+		if path, ok := documentPath(uri); ok && other != d {
+			overrides[path] = []byte(other.Text)
+		}
+	}
+	result := analyzePolicy(d.URI, d.Text, s.stdRootFor(d.URI), s.safetyWarnings, overrides, os.Stderr)
+	if result.shared != nil {
+		for path, file := range result.shared.Files {
+			if file != nil {
+				s.recordImports(path, file.Imports)
+			}
+		}
+	}
+	return result
+}
+
+// recordImports replaces the edges of a unit in the reverse-import graph.
+func (s *server) recordImports(path string, imports []string) {
+	if s.importers == nil {
+		s.importers = map[string]map[string]bool{}
+		s.imports = map[string][]string{}
+	}
+	for _, previous := range s.imports[path] {
+		delete(s.importers[previous], path)
+	}
+	s.imports[path] = imports
+	for _, imported := range imports {
+		if s.importers[imported] == nil {
+			s.importers[imported] = map[string]bool{}
+		}
+		s.importers[imported][path] = true
+	}
+}
+
+// republishDependents re-analyzes and publishes the open documents which
+// import any of paths, directly or transitively, in URI order.
+func (s *server) republishDependents(paths ...string) error {
+	seen := map[string]bool{}
+	pending := append([]string(nil), paths...)
+	for len(pending) != 0 {
+		path := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for importer := range s.importers[path] {
+			if !seen[importer] {
+				seen[importer] = true
+				pending = append(pending, importer)
+			}
+		}
+	}
+	for _, path := range paths {
+		delete(seen, path)
+	}
+	dependents := []string{}
+	for uri := range s.documents {
+		if path, ok := documentPath(uri); ok && seen[path] {
+			dependents = append(dependents, uri)
+		}
+	}
+	sort.Strings(dependents)
+	for _, uri := range dependents {
+		if err := s.publishDiagnostics(uri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) republishAll() error {
+	for _, uri := range sortedKeys(s.documents) {
+		if err := s.publishDiagnostics(uri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// documentPath returns the cleaned absolute path of a file URI.
+func documentPath(uri string) (string, bool) {
+	path, err := uriPath(uri)
+	if err != nil {
+		return "", false
+	}
+	path, err = filepath.Abs(path)
+	return path, err == nil
+}
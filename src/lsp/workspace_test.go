@@ -0,0 +1,139 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// publishedDiagnostics decodes the publishDiagnostics notifications written
+// to output, keyed by URI; the last publication of a URI wins.
+func publishedDiagnostics(t *testing.T, output *bytes.Buffer) map[string][]diagnostic {
+	t.Helper()
+	published := map[string][]diagnostic{}
+	for _, frame := range strings.Split(output.String(), "Content-Length: ")[1:] {
+		var notification struct {
+			Method string `json:"method"`
+			Params struct {
+				URI         string       `json:"uri"`
+				Diagnostics []diagnostic `json:"diagnostics"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(frame[strings.Index(frame, "{"):]), &notification); err != nil {
+			t.Fatal(err)
+		}
+		if notification.Method == "textDocument/publishDiagnostics" {
+			published[notification.Params.URI] = notification.Params.Diagnostics
+		}
+	}
+	output.Reset()
+	return published
+}
+
+func notify(t *testing.T, s *server, method string, params any) {
+	t.Helper()
+	payload, _ := json.Marshal(params)
+	if err := s.handle(message{Method: method, Params: payload}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportersAreRepublishedWhenADependencyChanges(t *testing.T) {
+	directory := t.TempDir()
+	library := "mod lib\npub answer() u64:\n    ret 42\n..\n"
+	application := "mod app\nuse \"lib.mg\" lib\nmain() void:\n    n := lib.answer()\n..\n"
+	libraryPath, applicationPath := filepath.Join(directory, "lib.mg"), filepath.Join(directory, "app.mg")
+	for path, source := range map[string]string{libraryPath: library, applicationPath: application} {
+		if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	libraryURI := (&url.URL{Scheme: "file", Path: filepath.ToSlash(libraryPath)}).String()
+	applicationURI := (&url.URL{Scheme: "file", Path: filepath.ToSlash(applicationPath)}).String()
+	var output bytes.Buffer
+	s := &server{out: &output, stdRoot: testStdRoot(), documents: map[string]*document{}}
+	errorsOf := func(diagnostics []diagnostic) string {
+		messages := []string{}
+		for _, diag := range diagnostics {
+			if diag.Severity == 1 {
+				messages = append(messages, diag.Message)
+			}
+		}
+		return strings.Join(messages, "; ")
+	}
+
+	notify(t, s, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": applicationURI, "text": application, "version": 1}})
+	notify(t, s, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": libraryURI, "text": library, "version": 1}})
+	if published := publishedDiagnostics(t, &output); errorsOf(published[applicationURI]) != "" {
+		t.Fatalf("initial diagnostics = %v", published)
+	}
+
+	// The unsaved library buffer is what the importer compiles against.
+	renamed := strings.Replace(library, "answer", "reply", 1)
+	notify(t, s, "textDocument/didChange", map[string]any{"textDocument": map[string]any{"uri": libraryURI, "version": 2}, "contentChanges": []map[string]any{{"text": renamed}}})
+	published := publishedDiagnostics(t, &output)
+	if got, ok := published[applicationURI]; !ok || !strings.Contains(errorsOf(got), "unknown function 'lib.answer'") {
+		t.Fatalf("importer after a dependency edit = %v (published %v)", got, ok)
+	}
+
+	// Closing the buffer falls back to the file on disk, which still declares
+	// the function.
+	notify(t, s, "textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": libraryURI}})
+	if published := publishedDiagnostics(t, &output); errorsOf(published[applicationURI]) != "" || len(published[libraryURI]) != 0 {
+		t.Fatalf("diagnostics after closing the dependency = %v", published)
+	}
+
+	if err := os.WriteFile(libraryPath, []byte(renamed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notify(t, s, "workspace/didChangeWatchedFiles", map[string]any{"changes": []map[string]any{{"uri": libraryURI, "type": 2}}})
+	if got := publishedDiagnostics(t, &output)[applicationURI]; !strings.Contains(errorsOf(got), "unknown function 'lib.answer'") {
+		t.Fatalf("importer after a change on disk = %v", got)
+	}
+	notify(t, s, "workspace/didChangeWatchedFiles", map[string]any{"changes": []map[string]any{{"uri": fileURI(filepath.Join(directory, "unrelated.mg")), "type": 1}}})
+	if published := publishedDiagnostics(t, &output); len(published) != 0 {
+		t.Fatalf("an unrelated file change republished %v", published)
+	}
+}
+
+func TestWorkspaceFoldersHaveTheirOwnStandardLibrary(t *testing.T) {
+	bundled, configured, plain := t.TempDir(), t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(bundled, "std"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bundled, "std", "core.mg"), []byte("mod core\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(plain, "nested")
+	var output bytes.Buffer
+	s := &server{out: &output, stdRoot: testStdRoot(), documents: map[string]*document{}}
+	initialize, _ := json.Marshal(map[string]any{
+		"workspaceFolders":      []map[string]any{{"uri": fileURI(bundled)}, {"uri": fileURI(configured)}},
+		"initializationOptions": map[string]any{"stdRoots": map[string]any{fileURI(configured): "/opt/magma/std"}},
+	})
+	if err := s.handle(message{ID: json.RawMessage("1"), Method: "initialize", Params: initialize}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), `"workspaceFolders":{"changeNotifications":true,"supported":true}`) {
+		t.Fatalf("capabilities = %s", output.String())
+	}
+	notify(t, s, "workspace/didChangeWorkspaceFolders", map[string]any{"event": map[string]any{"added": []map[string]any{{"uri": fileURI(nested)}, {"uri": fileURI(plain)}}, "removed": []map[string]any{}}})
+	for path, want := range map[string]string{
+		filepath.Join(bundled, "main.mg"):    filepath.Join(bundled, "std"),
+		filepath.Join(configured, "main.mg"): "/opt/magma/std",
+		filepath.Join(nested, "main.mg"):     s.stdRoot,
+		filepath.Join(t.TempDir(), "x.mg"):   s.stdRoot,
+	} {
+		if got := s.stdRootFor(fileURI(path)); got != want {
+			t.Errorf("std root of %s = %q, want %q", path, got, want)
+		}
+	}
+	notify(t, s, "workspace/didChangeWorkspaceFolders", map[string]any{"event": map[string]any{"added": []map[string]any{}, "removed": []map[string]any{{"uri": fileURI(bundled)}}}})
+	if got := s.stdRootFor(fileURI(filepath.Join(bundled, "main.mg"))); got != s.stdRoot {
+		t.Fatalf("std root after removing the folder = %q", got)
+	}
+}
@@ -61,6 +61,37 @@ func ResolveImport(specifier, importedFromAbs, stdRoot string) (string, error) {
 	return "", fmt.Errorf("standard library module '%s' does not exist under '%s'", name, stdRoot)
 }
 
+// SiblingModules lists the modules in the directory an import specifier
+// names, spelled like the specifier's last element, after the prefix which
+// precedes that element. It serves suggestions for unresolved imports.
+func SiblingModules(specifier, importedFromAbs, stdRoot string) (string, []string) {
+	prefix, directory := "", filepath.Dir(importedFromAbs)
+	name := specifier
+	if strings.HasPrefix(specifier, "std:") {
+		prefix, directory, name = "std:", stdRoot, strings.TrimPrefix(specifier, "std:")
+	}
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		prefix += name[:slash+1]
+		directory = filepath.Join(directory, filepath.FromSlash(name[:slash]))
+	}
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return prefix, nil
+	}
+	modules := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".mg" {
+			continue
+		}
+		if filepath.Ext(specifier) == ".mg" {
+			modules = append(modules, entry.Name())
+		} else {
+			modules = append(modules, strings.TrimSuffix(entry.Name(), ".mg"))
+		}
+	}
+	return prefix, modules
+}
+
 func withOptionalMagmaExtension(path string) []string {
 	paths := []string{path}
 	if filepath.Ext(path) == "" {
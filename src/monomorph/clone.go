@@ -103,6 +103,7 @@ func cloneExpr(in t.NodeExpr) t.NodeExpr {
 			Tk:          n.Tk,
 			Callee:      cloneExpr(n.Callee),
 			Args:        args,
+			ArgNames:    append([]t.Token(nil), n.ArgNames...),
 			GenericArgs: typeArgs,
 			InfType:     cloneType(n.InfType),
 		}
@@ -178,6 +179,8 @@ func cloneExpr(in t.NodeExpr) t.NodeExpr {
 		return &t.NodeExprAddrof{Tk: n.Tk, Expr: cloneExpr(n.Expr), InfType: cloneType(n.InfType)}
 	case *t.NodeExprMove:
 		return &t.NodeExprMove{Tk: n.Tk, Expr: cloneExpr(n.Expr), InfType: cloneType(n.InfType)}
+	case *t.NodeExprCast:
+		return &t.NodeExprCast{Tk: n.Tk, Expr: cloneExpr(n.Expr), Type: cloneType(n.Type), Mode: n.Mode, ErrorMode: n.ErrorMode, InfType: cloneType(n.InfType)}
 	case *t.NodeExprDestructureAssign:
 		return &t.NodeExprDestructureAssign{
 			ValueDef: *cloneExpr(&n.ValueDef).(*t.NodeExprVarDef),
@@ -240,6 +243,8 @@ func cloneStmt(in t.NodeStatement) t.NodeStatement {
 		return &t.NodeStmtUnsafe{Tk: n.Tk, Body: cloneBody(&n.Body)}
 	case *t.NodeLlvm:
 		return &t.NodeLlvm{Tk: n.Tk, Text: n.Text}
+	case *t.NodeError:
+		return &t.NodeError{Tk: n.Tk}
 	case *t.NodeStmtDefer:
 		return &t.NodeStmtDefer{
 			Expression: cloneExpr(n.Expression),
@@ -290,6 +295,8 @@ func cloneFuncDef(in *t.NodeFuncDef) *t.NodeFuncDef {
 		ErrorPredicate:          in.ErrorPredicate,
 		ProtoDispatch:           in.ProtoDispatch,
 		NeedsNativeContextThunk: in.NeedsNativeContextThunk,
+		Derived:                 in.Derived,
+		Intrinsic:               in.Intrinsic,
 	}
 	if in.ImplicitContext != nil {
 		out.ImplicitContext = &t.NodeExprVarDef{
@@ -299,9 +306,11 @@ func cloneFuncDef(in *t.NodeFuncDef) *t.NodeFuncDef {
 	}
 	for i, a := range in.Class.ArgsNode.Args {
 		out.Class.ArgsNode.Args[i] = t.NodeArg{
-			Tk:       a.Tk,
-			Name:     a.Name,
-			TypeNode: cloneType(a.TypeNode),
+			Tk:            a.Tk,
+			Name:          a.Name,
+			TypeNode:      cloneType(a.TypeNode),
+			Default:       cloneExpr(a.Default),
+			DefaultSource: a.DefaultSource,
 		}
 	}
 	return out
@@ -323,9 +332,11 @@ func cloneStructDef(in *t.NodeStructDef) *t.NodeStructDef {
 	}
 	for i, a := range in.Class.ArgsNode.Args {
 		out.Class.ArgsNode.Args[i] = t.NodeArg{
-			Tk:       a.Tk,
-			Name:     a.Name,
-			TypeNode: cloneType(a.TypeNode),
+			Tk:            a.Tk,
+			Name:          a.Name,
+			TypeNode:      cloneType(a.TypeNode),
+			Default:       cloneExpr(a.Default),
+			DefaultSource: a.DefaultSource,
 		}
 	}
 	return out
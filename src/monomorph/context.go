@@ -83,6 +83,8 @@ func expressionToken(expr t.NodeExpr) *t.Token {
 		return &n.Tk
 	case *t.NodeExprMove:
 		return &n.Tk
+	case *t.NodeExprCast:
+		return expressionToken(n.Expr)
 	case *t.NodeExprDestructureAssign:
 		return expressionToken(n.Call)
 	}
@@ -114,6 +116,10 @@ type monoCtx struct {
 	funcInstances      map[string]string
 	memberInstances    map[string]string
 	structDisplayNames map[string]string
+	// structOrigins maps the absolute name of each struct instance to the
+	// template and arguments it was created from, so inference can see through
+	// specialized types.
+	structOrigins map[string]structOrigin
 
 	queuedStruct map[*t.NodeStructDef]bool
 	queuedFunc   map[*t.NodeFuncDef]bool
@@ -122,6 +128,49 @@ type monoCtx struct {
 	structQueue []structWorkItem
 	funcQueue   []*t.NodeFuncDef
 	varQueue    []*t.NodeExprVarDef
+
+	// requester names the declaration being rewritten and site the type or
+	// call currently requesting an instantiation, for the shared
+	// instantiation records.
+	requester, requesterName string
+	siteGl                   *t.NodeGlobal
+	siteTk                   *t.Token
+}
+
+// requestSite marks tk in gl as the source of the instantiations which follow.
+func (m *monoCtx) requestSite(gl *t.NodeGlobal, tk *t.Token) {
+	m.siteGl, m.siteTk = gl, tk
+}
+
+// requestedBy attributes the instantiations made by run to fn, as when a
+// caller needs the result type of fn before fn itself has been rewritten.
+func (m *monoCtx) requestedBy(module string, fn *t.NodeFuncDef, run func()) {
+	requester, requesterName := m.requester, m.requesterName
+	m.requester, m.requesterName = fn.AbsName, m.declarationName(module, fn.Class.NameNode, fn.DisplayName)
+	run()
+	m.requester, m.requesterName = requester, requesterName
+}
+
+// recordInstantiation adds a newly specialized declaration to the shared
+// instantiation records.
+func (m *monoCtx) recordInstantiation(kind, name, symbol string) {
+	if m.shared == nil {
+		return
+	}
+	record := &t.Instantiation{Kind: kind, Name: name, Symbol: symbol, Requester: m.requester, RequesterName: m.requesterName}
+	if m.siteTk != nil {
+		record.Pos = m.siteTk.Pos
+		if fileCtx := m.fileCtxForGlobal(m.siteGl); fileCtx != nil {
+			record.File = fileCtx.FilePath
+		}
+	}
+	m.shared.Instantiations = append(m.shared.Instantiations, record)
+}
+
+type structOrigin struct {
+	module   string
+	baseName string
+	args     []*t.NodeType
 }
 
 type structWorkItem struct {
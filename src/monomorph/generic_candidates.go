@@ -1,11 +1,16 @@
 package monomorph
 
-import t "Magma/src/types"
+import (
+	magmatypes "Magma/src/magma_types"
+	t "Magma/src/types"
+)
 
-// resolveGenericCandidateExpressions resolves the only expression grammar
-// ambiguity that needs cross-module knowledge: `name[type]` can be either a
-// specialized generic function value or an ordinary subscript. Parsing keeps
-// both interpretations; this pass runs after all modules and templates exist.
+// resolveGenericCandidateExpressions resolves the expression grammar
+// ambiguities that need cross-module knowledge. `name[type]` can be either a
+// specialized generic function value or an ordinary subscript, and
+// `name(field=value)` or `name()` either calls a function or constructs a
+// struct. Parsing keeps both interpretations; this pass runs after all modules
+// and templates exist.
 func (m *monoCtx) resolveGenericCandidateExpressions() {
 	seenFunctions := map[*t.NodeFuncDef]bool{}
 	for module, gl := range m.modules {
@@ -95,6 +100,9 @@ func (m *monoCtx) resolveCandidateExpr(module string, gl *t.NodeGlobal, expr t.N
 		for i := range node.Args {
 			node.Args[i] = m.resolveCandidateExpr(module, gl, node.Args[i])
 		}
+		if init := m.constructorCandidate(module, gl, node); init != nil {
+			return init
+		}
 	case *t.NodeExprStructInit:
 		for i := range node.Fields {
 			node.Fields[i].Expression = m.resolveCandidateExpr(module, gl, node.Fields[i].Expression)
@@ -128,6 +136,8 @@ func (m *monoCtx) resolveCandidateExpr(module string, gl *t.NodeGlobal, expr t.N
 		node.Expr = m.resolveCandidateExpr(module, gl, node.Expr)
 	case *t.NodeExprMove:
 		node.Expr = m.resolveCandidateExpr(module, gl, node.Expr)
+	case *t.NodeExprCast:
+		node.Expr = m.resolveCandidateExpr(module, gl, node.Expr)
 	case *t.NodeExprDestructureAssign:
 		if call, ok := m.resolveCandidateExpr(module, gl, node.Call).(*t.NodeExprCall); ok {
 			node.Call = call
@@ -144,3 +154,38 @@ func (m *monoCtx) isGenericFunctionName(module string, gl *t.NodeGlobal, name t.
 	_, found := m.funcTemplates[makeTemplateKey(targetModule, baseName)]
 	return found
 }
+
+// constructorCandidate returns the struct constructor a call parsed from a
+// `name=value` or empty argument list stands for, or nil when the callee does
+// not name a type. A type which is not a struct is left for linking to reject.
+func (m *monoCtx) constructorCandidate(module string, gl *t.NodeGlobal, call *t.NodeExprCall) *t.NodeExprStructInit {
+	name, ok := call.Callee.(*t.NodeExprName)
+	if !ok || len(call.ArgNames) != len(call.Args) {
+		return nil
+	}
+	for _, argName := range call.ArgNames {
+		if argName.Repr == "" {
+			return nil
+		}
+	}
+	targetModule, baseName, err := resolveQualifiedName(m.modules, module, gl, name.Name)
+	target := m.modules[targetModule]
+	if err != nil || target == nil || target.FuncDefs[baseName] != nil {
+		return nil
+	}
+	_, isStruct := target.StructDefs[baseName]
+	_, isAlias := target.TypeAliases[baseName]
+	_, isBasic := magmatypes.BasicTypes[baseName]
+	if !isStruct && !isAlias && !isBasic {
+		return nil
+	}
+	fields := make([]t.NodeStructFieldInit, len(call.Args))
+	for i, arg := range call.Args {
+		fields[i] = t.NodeStructFieldInit{Tk: call.ArgNames[i], Name: call.ArgNames[i].Repr, Expression: arg, FieldIndex: -1}
+	}
+	return &t.NodeExprStructInit{
+		Tk:     name.Tk,
+		Type:   &t.NodeType{KindNode: &t.NodeTypeNamed{NameNode: name.Name, GenericArgs: call.GenericArgs}},
+		Fields: fields,
+	}
+}
@@ -2,13 +2,17 @@ package monomorph
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
+	"Magma/src/comp_err"
 	t "Magma/src/types"
 )
 
-// inferGenericExpr performs intentionally shallow inference before the regular
-// checker runs. It only uses a surrounding expected type and expression types
-// which are already explicit in the lexical environment.
+// inferGenericExpr infers the type arguments of generic calls before the
+// regular checker runs. It unifies the template's parameter types with the
+// argument types already known in the lexical environment and with a
+// surrounding expected type.
 func (m *monoCtx) inferGenericExpr(module string, gl *t.NodeGlobal, expr t.NodeExpr, expected *t.NodeType, env map[string]*t.NodeType) error {
 	if expr == nil {
 		return nil
@@ -36,33 +40,71 @@ func (m *monoCtx) inferGenericExpr(module string, gl *t.NodeGlobal, expr t.NodeE
 		if len(n.GenericArgs) != 0 {
 			return nil
 		}
-		template, err := m.genericCallTemplate(module, gl, n, env)
+		templateModule, template, err := m.genericCallTemplate(module, gl, n, env)
 		if err != nil || template == nil {
 			return err
 		}
-		bindings := map[string]*t.NodeType{}
+		u := &typeUnifier{m: m, module: templateModule, params: template.Class.TypeParams, bindings: map[string]*t.NodeType{}, sources: map[string]string{}}
 		params := template.Class.ArgsNode.Args
 		if _, member := template.Class.NameNode.(*t.NodeNameComposite); member && len(params) > 0 && params[0].Name == "this" {
 			params = params[1:]
 		}
-		for i := 0; i < len(params) && i < len(n.Args); i++ {
-			actual := m.shallowExprType(module, gl, n.Args[i], env)
-			if actual != nil {
-				if err := inferTypeBindings(params[i].TypeNode, actual, template.Class.TypeParams, bindings); err != nil {
-					return fmt.Errorf("cannot infer generic call at line %d: %w", n.Tk.Pos.Line, err)
+		// Typed arguments and the expectation bind first. A literal converts
+		// to any compatible type, so it only decides a parameter which nothing
+		// else constrains.
+		// A literal default decides its parameter like a literal argument.
+		arguments := make([]t.NodeExpr, len(params))
+		for i, arg := range n.Args {
+			index := i
+			if i < len(n.ArgNames) && n.ArgNames[i].Repr != "" {
+				index = slices.IndexFunc(params, func(param t.NodeArg) bool { return param.Name == n.ArgNames[i].Repr })
+			}
+			if index >= 0 && index < len(params) {
+				arguments[index] = arg
+			}
+		}
+		var literals []int
+		for i, arg := range arguments {
+			if arg == nil {
+				if literalType(params[i].Default) != nil {
+					literals = append(literals, i)
 				}
+				continue
+			}
+			if literalType(arg) != nil {
+				literals = append(literals, i)
+				continue
+			}
+			actual := m.shallowExprType(module, gl, arg, env)
+			if actual == nil {
+				continue
+			}
+			_ = m.rewriteType(module, gl, actual)
+			if err := u.unify(params[i].TypeNode, actual, fmt.Sprintf("argument %d", i+1)); err != nil {
+				return m.inferenceError(gl, expressionToken(arg), n, err)
 			}
 		}
 		if expected != nil {
-			if err := inferTypeBindings(template.ReturnType, expected, template.Class.TypeParams, bindings); err != nil {
-				return fmt.Errorf("cannot infer generic call at line %d: %w", n.Tk.Pos.Line, err)
+			if err := u.unify(template.ReturnType, expected, "the expected result type"); err != nil {
+				return m.inferenceError(gl, &n.Tk, n, err)
+			}
+		}
+		for _, i := range literals {
+			literal := arguments[i]
+			if literal == nil {
+				literal = params[i].Default
+			}
+			if param := u.param(params[i].TypeNode); param != "" && u.bindings[param] == nil {
+				u.bind(param, literalType(literal), fmt.Sprintf("argument %d", i+1))
 			}
 		}
 		inferred := make([]*t.NodeType, len(template.Class.TypeParams))
 		for i, param := range template.Class.TypeParams {
-			inferred[i] = bindings[param]
+			inferred[i] = u.bindings[param]
+		}
+		for i, param := range template.Class.TypeParams {
 			if inferred[i] == nil {
-				return fmt.Errorf("cannot infer generic type parameter '%s' for call at line %d; provide explicit type arguments", param, n.Tk.Pos.Line)
+				return m.inferenceError(gl, &n.Tk, n, &undeterminedParam{param: param, inferred: inferred, params: template.Class.TypeParams})
 			}
 		}
 		n.GenericArgs = inferred
@@ -70,26 +112,80 @@ func (m *monoCtx) inferGenericExpr(module string, gl *t.NodeGlobal, expr t.NodeE
 	return nil
 }
 
-func (m *monoCtx) genericCallTemplate(module string, gl *t.NodeGlobal, call *t.NodeExprCall, env map[string]*t.NodeType) (*t.NodeFuncDef, error) {
+// inferenceError places an inference failure on tk, naming the callee.
+func (m *monoCtx) inferenceError(gl *t.NodeGlobal, tk *t.Token, call *t.NodeExprCall, err error) error {
+	callee := "call"
+	if name, ok := call.Callee.(*t.NodeExprName); ok {
+		callee = flattenName(name.Name)
+	}
+	var message, hint string
+	switch failure := err.(type) {
+	case *inferenceConflict:
+		message = fmt.Sprintf("type parameter '%s' of '%s' is inferred as '%s' from %s but as '%s' from %s",
+			failure.param, callee, m.displayType(failure.prior), failure.priorSource, m.displayType(failure.actual), failure.source)
+		hint = "make the types agree, or write the type arguments explicitly"
+	case *undeterminedParam:
+		args := make([]string, len(failure.params))
+		for i, param := range failure.params {
+			args[i] = param
+			if failure.inferred[i] != nil {
+				args[i] = m.displayType(failure.inferred[i])
+			}
+		}
+		message = fmt.Sprintf("cannot infer generic type parameter '%s' of '%s'", failure.param, callee)
+		hint = fmt.Sprintf("no argument or expected result type determines '%s'; write the type arguments explicitly: %s[%s](...)", failure.param, callee, strings.Join(args, ", "))
+	default:
+		return err
+	}
+	fileCtx := m.fileCtxForGlobal(gl)
+	if fileCtx == nil {
+		return fmt.Errorf("%s", message)
+	}
+	return comp_err.CompilationErrorToken(fileCtx, tk, message, hint)
+}
+
+// inferenceConflict reports a type parameter bound to two different types.
+type inferenceConflict struct {
+	param               string
+	prior, actual       *t.NodeType
+	priorSource, source string
+}
+
+func (e *inferenceConflict) Error() string {
+	return fmt.Sprintf("type parameter '%s' is constrained to both '%s' and '%s'", e.param, CanonicalTypeSignature(e.prior), CanonicalTypeSignature(e.actual))
+}
+
+// undeterminedParam reports a type parameter nothing at the call site binds.
+type undeterminedParam struct {
+	param    string
+	inferred []*t.NodeType
+	params   []string
+}
+
+func (e *undeterminedParam) Error() string {
+	return fmt.Sprintf("cannot infer generic type parameter '%s'; provide explicit type arguments", e.param)
+}
+
+func (m *monoCtx) genericCallTemplate(module string, gl *t.NodeGlobal, call *t.NodeExprCall, env map[string]*t.NodeType) (string, *t.NodeFuncDef, error) {
 	name, ok := call.Callee.(*t.NodeExprName)
 	if !ok {
-		return nil, nil
+		return "", nil, nil
 	}
 	if composite, ok := name.Name.(*t.NodeNameComposite); ok && len(composite.Parts) >= 2 {
 		if _, imported := gl.ImportAlias[composite.Parts[0]]; !imported {
 			member := composite.Parts[len(composite.Parts)-1]
 			_, ownerModule, ownerName, err := m.inferOwnerTypeFromCallee(module, gl, composite.Parts[:len(composite.Parts)-1], env)
 			if err != nil {
-				return nil, nil // ordinary unresolved calls are diagnosed by the checker
+				return "", nil, nil // ordinary unresolved calls are diagnosed by the checker
 			}
-			return m.memberTemplates[makeMemberTemplateKey(ownerModule, ownerName, member)], nil
+			return ownerModule, m.memberTemplates[makeMemberTemplateKey(ownerModule, ownerName, member)], nil
 		}
 	}
 	targetModule, function, err := resolveQualifiedName(m.modules, module, gl, name.Name)
 	if err != nil {
-		return nil, nil
+		return "", nil, nil
 	}
-	return m.funcTemplates[makeTemplateKey(targetModule, function)], nil
+	return targetModule, m.funcTemplates[makeTemplateKey(targetModule, function)], nil
 }
 
 func (m *monoCtx) shallowExprType(module string, gl *t.NodeGlobal, expr t.NodeExpr, env map[string]*t.NodeType) *t.NodeType {
@@ -122,6 +218,20 @@ func (m *monoCtx) shallowExprType(module string, gl *t.NodeGlobal, expr t.NodeEx
 				}
 			}
 		}
+		// A function named as a value has its function pointer type.
+		if targetModule, function, err := resolveQualifiedName(m.modules, module, gl, n.Name); err == nil && len(n.GenericArgs) == 0 {
+			if target := m.modules[targetModule]; target != nil {
+				if definition := target.FuncDefs[function]; definition != nil && len(definition.Class.TypeParams) == 0 {
+					value := &t.NodeTypeFunc{RetType: cloneType(definition.ReturnType), ContextABI: definition.ContextABI}
+					for _, arg := range definition.Class.ArgsNode.Args {
+						value.Args = append(value.Args, cloneType(arg.TypeNode))
+					}
+					result := &t.NodeType{KindNode: value}
+					_ = m.rewriteType(targetModule, target, result)
+					return result
+				}
+			}
+		}
 	case *t.NodeExprMemberAccess:
 		ownerType := m.shallowExprType(module, gl, n.Target, env)
 		if ownerType == nil {
@@ -140,6 +250,25 @@ func (m *monoCtx) shallowExprType(module string, gl *t.NodeGlobal, expr t.NodeEx
 			_ = m.rewriteType(ownerModule, ownerGlobal, result)
 		}
 		return result
+	case *t.NodeExprLit:
+		if result := literalType(n); result != nil {
+			return result
+		}
+	case *t.NodeExprArray:
+		if n.ElemType != nil {
+			return &t.NodeType{KindNode: &t.NodeTypeSlice{ElemKind: cloneType(n.ElemType).KindNode}}
+		}
+	case *t.NodeExprSubscript:
+		target := m.shallowExprType(module, gl, n.Target, env)
+		if target == nil {
+			return nil
+		}
+		switch container := target.KindNode.(type) {
+		case *t.NodeTypeSlice:
+			return &t.NodeType{KindNode: container.ElemKind}
+		case *t.NodeTypePointer:
+			return &t.NodeType{KindNode: container.Kind}
+		}
 	case *t.NodeExprStructInit:
 		return cloneType(n.Type)
 	case *t.NodeExprProtoView:
@@ -150,6 +279,8 @@ func (m *monoCtx) shallowExprType(module string, gl *t.NodeGlobal, expr t.NodeEx
 		}
 	case *t.NodeExprMove:
 		return m.shallowExprType(module, gl, n.Expr, env)
+	case *t.NodeExprCast:
+		return cloneType(n.Type)
 	case *t.NodeExprTry:
 		return m.shallowExprType(module, gl, n.Call, env)
 	case *t.NodeExprCall:
@@ -168,36 +299,86 @@ func (m *monoCtx) shallowExprType(module string, gl *t.NodeGlobal, expr t.NodeEx
 	return nil
 }
 
-func inferTypeBindings(pattern, actual *t.NodeType, params []string, bindings map[string]*t.NodeType) error {
+// typeUnifier binds the type parameters of one generic template by matching
+// the template's parameter types against the types at a call site. Pattern
+// names resolve in the template's module.
+type typeUnifier struct {
+	m        *monoCtx
+	module   string
+	params   []string
+	bindings map[string]*t.NodeType
+	// sources describe where each binding came from, for diagnostics.
+	sources map[string]string
+}
+
+// param returns the type parameter pattern names, or "" when pattern is any
+// other type.
+func (u *typeUnifier) param(pattern *t.NodeType) string {
+	named, ok := pattern.KindNode.(*t.NodeTypeNamed)
+	if !ok || len(named.GenericArgs) != 0 {
+		return ""
+	}
+	if single, ok := named.NameNode.(*t.NodeNameSingle); ok && containsString(u.params, single.Name) {
+		return single.Name
+	}
+	return ""
+}
+
+func (u *typeUnifier) bind(param string, actual *t.NodeType, source string) error {
+	value := inferenceValueType(actual)
+	if prior := u.bindings[param]; prior != nil {
+		if CanonicalTypeSignature(prior) != CanonicalTypeSignature(value) {
+			return &inferenceConflict{param: param, prior: prior, actual: value, priorSource: u.sources[param], source: source}
+		}
+		return nil
+	}
+	u.bindings[param] = value
+	u.sources[param] = source
+	return nil
+}
+
+// unify walks pattern and actual in parallel, binding every type parameter it
+// reaches. Shapes which do not match bind nothing; the checker reports them as
+// ordinary type mismatches once the call is specialized.
+func (u *typeUnifier) unify(pattern, actual *t.NodeType, source string) error {
 	if pattern == nil || actual == nil {
 		return nil
 	}
-	if named, ok := pattern.KindNode.(*t.NodeTypeNamed); ok && len(named.GenericArgs) == 0 {
-		if single, ok := named.NameNode.(*t.NodeNameSingle); ok && containsString(params, single.Name) {
-			if prior := bindings[single.Name]; prior != nil && CanonicalTypeSignature(prior) != CanonicalTypeSignature(actual) {
-				return fmt.Errorf("type parameter '%s' is constrained to both '%s' and '%s'", single.Name, CanonicalTypeSignature(prior), CanonicalTypeSignature(actual))
-			}
-			bindings[single.Name] = inferenceValueType(actual)
-			return nil
+	if param := u.param(pattern); param != "" {
+		if pattern.Owned && actual.Owned {
+			// `$T` already states the ownership; T is the value type.
+			actual = cloneType(actual)
+			actual.Owned = false
 		}
+		return u.bind(param, actual, source)
 	}
 	switch p := pattern.KindNode.(type) {
 	case *t.NodeTypePointer:
 		if a, ok := actual.KindNode.(*t.NodeTypePointer); ok {
-			return inferTypeBindings(&t.NodeType{KindNode: p.Kind}, &t.NodeType{KindNode: a.Kind}, params, bindings)
+			return u.unify(&t.NodeType{KindNode: p.Kind}, &t.NodeType{KindNode: a.Kind}, source)
 		}
 	case *t.NodeTypeRfc:
 		if a, ok := actual.KindNode.(*t.NodeTypeRfc); ok {
-			return inferTypeBindings(&t.NodeType{KindNode: p.Kind}, &t.NodeType{KindNode: a.Kind}, params, bindings)
+			return u.unify(&t.NodeType{KindNode: p.Kind}, &t.NodeType{KindNode: a.Kind}, source)
 		}
 	case *t.NodeTypeSlice:
 		if a, ok := actual.KindNode.(*t.NodeTypeSlice); ok {
-			return inferTypeBindings(&t.NodeType{KindNode: p.ElemKind}, &t.NodeType{KindNode: a.ElemKind}, params, bindings)
+			return u.unify(&t.NodeType{KindNode: p.ElemKind}, &t.NodeType{KindNode: a.ElemKind}, source)
+		}
+	case *t.NodeTypeFunc:
+		if a, ok := actual.KindNode.(*t.NodeTypeFunc); ok && len(p.Args) == len(a.Args) {
+			for i := range p.Args {
+				if err := u.unify(p.Args[i], a.Args[i], source); err != nil {
+					return err
+				}
+			}
+			return u.unify(p.RetType, a.RetType, source)
 		}
 	case *t.NodeTypeNamed:
-		if a, ok := actual.KindNode.(*t.NodeTypeNamed); ok && len(p.GenericArgs) == len(a.GenericArgs) {
+		args := u.structArgs(p, actual)
+		if len(args) == len(p.GenericArgs) {
 			for i := range p.GenericArgs {
-				if err := inferTypeBindings(p.GenericArgs[i], a.GenericArgs[i], params, bindings); err != nil {
+				if err := u.unify(p.GenericArgs[i], args[i], source); err != nil {
 					return err
 				}
 			}
@@ -206,6 +387,63 @@ func inferTypeBindings(pattern, actual *t.NodeType, params []string, bindings ma
 	return nil
 }
 
+// structArgs returns the type arguments of actual when it is an instance of
+// the generic struct pattern names. Specialized types are traced back to the
+// arguments they were instantiated with.
+func (u *typeUnifier) structArgs(pattern *t.NodeTypeNamed, actual *t.NodeType) []*t.NodeType {
+	if len(pattern.GenericArgs) == 0 {
+		return nil
+	}
+	switch a := actual.KindNode.(type) {
+	case *t.NodeTypeNamed:
+		return a.GenericArgs
+	case *t.NodeTypeAbsolute:
+		origin, ok := u.m.structOrigins[a.AbsoluteName]
+		if !ok {
+			return nil
+		}
+		targetModule, baseName, err := resolveQualifiedName(u.m.modules, u.module, u.m.modules[u.module], pattern.NameNode)
+		if err != nil || targetModule != origin.module || baseName != origin.baseName {
+			return nil
+		}
+		return origin.args
+	}
+	return nil
+}
+
+// literalType returns the type a literal argument has when nothing else
+// constrains it, or nil when expr is not a literal. Numbers with a fraction or
+// exponent default to f64, other numbers to i64.
+func literalType(expr t.NodeExpr) *t.NodeType {
+	if unary, ok := expr.(*t.NodeExprUnary); ok && unary.Operator == t.KwMinus {
+		expr = unary.Operand
+	}
+	lit, ok := expr.(*t.NodeExprLit)
+	if !ok {
+		return nil
+	}
+	name := ""
+	switch lit.LitType {
+	case t.TokLitNum:
+		name = "i64"
+		if lit.IsFloat() {
+			name = "f64"
+		}
+	case t.TokLitChar:
+		name = "u8"
+		if value, _ := lit.UintValue(); value > 0x7F {
+			name = "u32"
+		}
+	case t.TokLitStr:
+		name = "str"
+	case t.TokLitBool:
+		name = "bool"
+	default:
+		return nil
+	}
+	return &t.NodeType{KindNode: &t.NodeTypeNamed{NameNode: &t.NodeNameSingle{Name: name}}}
+}
+
 // inferenceValueType removes contextual function effects from a type copied
 // into an expression or generic argument. Throws belongs to the enclosing
 // function signature; it is not part of the value produced on a successful
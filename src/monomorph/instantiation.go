@@ -40,6 +40,10 @@ func (m *monoCtx) instantiateStruct(module string, baseName string, args []*t.No
 	m.structInstances[instanceKey] = specName
 	structDisplayName := m.genericDisplayName(sourceModuleName(module)+"."+baseName, args)
 	m.structDisplayNames[module+"."+specName] = structDisplayName
+	if m.structOrigins != nil {
+		m.structOrigins[module+"."+specName] = structOrigin{module: module, baseName: baseName, args: args}
+	}
+	m.recordInstantiation("struct", structDisplayName, module+"."+specName)
 
 	gl := m.modules[module]
 
@@ -66,7 +70,9 @@ func (m *monoCtx) instantiateStruct(module string, baseName string, args []*t.No
 		FieldNb:    map[string]int{},
 		Fields:     map[string]*t.NodeType{},
 		FieldOrder: []string{},
+		Defaults:   map[string]t.NodeExpr{},
 		Funcs:      map[string]*t.NodeFuncDef{},
+		Derives:    origDef.Derives,
 	}
 	for _, relation := range origDef.Implements {
 		stDef.Implements = append(stDef.Implements, &t.ProtoImpl{Type: substituteType(cloneType(relation.Type), subst), Tk: relation.Tk})
@@ -75,6 +81,9 @@ func (m *monoCtx) instantiateStruct(module string, baseName string, args []*t.No
 		stDef.FieldNb[fld.Name] = i
 		stDef.Fields[fld.Name] = cloneType(fld.TypeNode)
 		stDef.FieldOrder = append(stDef.FieldOrder, fld.Name)
+		if fld.Default != nil {
+			stDef.Defaults[fld.Name] = fld.Default
+		}
 	}
 
 	for memberName, fnTpl := range origDef.Funcs {
@@ -110,6 +119,7 @@ func (m *monoCtx) instantiateStruct(module string, baseName string, args []*t.No
 		}
 		specFn.AbsName = module + "." + flattenName(specFn.Class.NameNode)
 		specFn.DisplayName = unqualifiedDisplayName(structDisplayName) + "." + memberName
+		m.recordInstantiation("method", structDisplayName+"."+memberName, specFn.AbsName)
 
 		key := specName + "." + memberName
 		gl.FuncDefs[key] = specFn
@@ -170,6 +180,7 @@ func (m *monoCtx) instantiateFunc(module string, baseName string, args []*t.Node
 	}
 
 	specFn.AbsName = module + "." + specName
+	m.recordInstantiation("function", sourceModuleName(module)+"."+specFn.DisplayName, specFn.AbsName)
 	gl.FuncDefs[specName] = specFn
 	gl.Declarations = append(gl.Declarations, specFn)
 	m.queueFunc(specFn)
@@ -226,6 +237,7 @@ func (m *monoCtx) instantiateMemberFunc(module string, ownerName string, memberN
 	}
 
 	specFn.AbsName = module + "." + flattenName(specFn.Class.NameNode)
+	m.recordInstantiation("method", sourceModuleName(module)+"."+specFn.DisplayName, specFn.AbsName)
 	gl.FuncDefs[ownerName+"."+specMemberName] = specFn
 	gl.Declarations = append(gl.Declarations, specFn)
 	m.queueFunc(specFn)
@@ -20,6 +20,7 @@ func Run(shared *t.SharedState) error {
 		funcInstances:      map[string]string{},
 		memberInstances:    map[string]string{},
 		structDisplayNames: map[string]string{},
+		structOrigins:      map[string]structOrigin{},
 		queuedStruct:       map[*t.NodeStructDef]bool{},
 		queuedFunc:         map[*t.NodeFuncDef]bool{},
 		queuedVar:          map[*t.NodeExprVarDef]bool{},
@@ -85,6 +86,10 @@ func Run(shared *t.SharedState) error {
 			if gl == nil {
 				continue
 			}
+			ctx.requester, ctx.requesterName = st.AbsName, ctx.declarationName(module, st.Class.NameNode, "")
+			if display, ok := ctx.structDisplayNames[st.AbsName]; ok {
+				ctx.requesterName = display
+			}
 			for _, fld := range st.Class.ArgsNode.Args {
 				if e := ctx.rewriteType(module, gl, fld.TypeNode); e != nil {
 					return ctx.sourceError(gl, &fld.Tk, e)
@@ -102,6 +107,7 @@ func Run(shared *t.SharedState) error {
 				continue
 			}
 
+			ctx.requester, ctx.requesterName = fn.AbsName, ctx.declarationName(module, fn.Class.NameNode, fn.DisplayName)
 			for _, a := range fn.Class.ArgsNode.Args {
 				if e := ctx.rewriteType(module, gl, a.TypeNode); e != nil {
 					return ctx.sourceError(gl, &a.Tk, e)
@@ -137,6 +143,7 @@ func Run(shared *t.SharedState) error {
 			}
 		}
 
+		ctx.requester, ctx.requesterName = "", ""
 		for len(ctx.varQueue) > 0 {
 			v := ctx.varQueue[0]
 			ctx.varQueue = ctx.varQueue[1:]
@@ -111,6 +111,15 @@ func (m *monoCtx) genericDisplayName(base string, args []*t.NodeType) string {
 	return base + "[" + strings.Join(displayArgs, ", ") + "]"
 }
 
+// declarationName returns the module-qualified source name of a declaration,
+// preferring the display name given to specializations.
+func (m *monoCtx) declarationName(module string, name t.NodeName, display string) string {
+	if display == "" {
+		display = flattenName(name)
+	}
+	return sourceModuleName(module) + "." + display
+}
+
 func unqualifiedDisplayName(name string) string {
 	if i := strings.Index(name, "."); i >= 0 {
 		return name[i+1:]
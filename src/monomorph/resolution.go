@@ -228,7 +228,9 @@ func (m *monoCtx) shallowCallReturnType(module string, gl *t.NodeGlobal, call *t
 					if owner := ownerGlobal.StructDefs[ownerName]; owner != nil {
 						if definition := owner.Funcs[memberName]; definition != nil {
 							result := cloneType(definition.ReturnType)
-							_ = m.rewriteType(ownerModule, ownerGlobal, result)
+							m.requestedBy(ownerModule, definition, func() {
+								_ = m.rewriteType(ownerModule, ownerGlobal, result)
+							})
 							return result
 						}
 					}
@@ -246,6 +248,8 @@ func (m *monoCtx) shallowCallReturnType(module string, gl *t.NodeGlobal, call *t
 		return nil
 	}
 	result := cloneType(target.FuncDefs[functionName].ReturnType)
-	_ = m.rewriteType(targetModule, target, result)
+	m.requestedBy(targetModule, target.FuncDefs[functionName], func() {
+		_ = m.rewriteType(targetModule, target, result)
+	})
 	return result
 }
@@ -48,6 +48,7 @@ func (m *monoCtx) rewriteType(module string, gl *t.NodeGlobal, tp *t.NodeType) e
 			}
 		}
 
+		m.requestSite(gl, nameToken(n.NameNode))
 		specName, e := m.instantiateStruct(targetModule, baseName, n.GenericArgs)
 		if e != nil {
 			return m.genericInstantiationError(gl, nameToken(n.NameNode), e)
@@ -113,6 +114,7 @@ func (m *monoCtx) rewriteExpr(module string, gl *t.NodeGlobal, expr t.NodeExpr,
 				return fmt.Errorf("function '%s' is private and cannot be used from another module", flattenName(n.Name))
 			}
 		}
+		m.requestSite(gl, &n.Tk)
 		specName, e := m.instantiateFunc(targetModule, baseName, n.GenericArgs)
 		if e != nil {
 			return m.genericInstantiationError(gl, &n.Tk, e)
@@ -189,6 +191,7 @@ func (m *monoCtx) rewriteExpr(module string, gl *t.NodeGlobal, expr t.NodeExpr,
 						return fmt.Errorf("generic member call at line %d, column %d: %w", n.Tk.Pos.Line, n.Tk.Pos.Col, e)
 					}
 
+					m.requestSite(gl, &n.Tk)
 					specMemberName, e := m.instantiateMemberFunc(ownerModule, ownerSpecName, memberName, n.GenericArgs)
 					if e != nil {
 						return m.genericInstantiationError(gl, &n.Tk, e)
@@ -211,6 +214,7 @@ func (m *monoCtx) rewriteExpr(module string, gl *t.NodeGlobal, expr t.NodeExpr,
 					return fmt.Errorf("function '%s' is private and cannot be used from another module", flattenName(nameExpr.Name))
 				}
 			}
+			m.requestSite(gl, &n.Tk)
 			specName, e := m.instantiateFunc(targetModule, baseName, n.GenericArgs)
 			if e != nil {
 				return m.genericInstantiationError(gl, &n.Tk, e)
@@ -227,6 +231,7 @@ func (m *monoCtx) rewriteExpr(module string, gl *t.NodeGlobal, expr t.NodeExpr,
 			if e != nil {
 				return m.genericInstantiationError(gl, &n.Tk, e)
 			}
+			m.requestSite(gl, &n.Tk)
 			specName, e := m.instantiateFunc(targetModule, baseName, n.GenericArgs)
 			if e != nil {
 				return m.genericInstantiationError(gl, &n.Tk, e)
@@ -297,6 +302,11 @@ func (m *monoCtx) rewriteExpr(module string, gl *t.NodeGlobal, expr t.NodeExpr,
 			return e
 		}
 		return m.rewriteExpr(module, gl, n.Expr, env)
+	case *t.NodeExprCast:
+		if e := m.rewriteType(module, gl, n.Type); e != nil {
+			return e
+		}
+		return m.rewriteExpr(module, gl, n.Expr, env)
 	case *t.NodeExprDestructureAssign:
 		if e := m.rewriteType(module, gl, n.ValueDef.Type); e != nil {
 			return e
@@ -123,6 +123,10 @@ func substituteExpr(expr t.NodeExpr, subst map[string]*t.NodeType) {
 	case *t.NodeExprMove:
 		n.InfType = substituteType(n.InfType, subst)
 		substituteExpr(n.Expr, subst)
+	case *t.NodeExprCast:
+		n.Type = substituteType(n.Type, subst)
+		n.InfType = substituteType(n.InfType, subst)
+		substituteExpr(n.Expr, subst)
 	case *t.NodeExprDestructureAssign:
 		n.ValueDef.Type = substituteType(n.ValueDef.Type, subst)
 		n.ErrDef.Type = substituteType(n.ErrDef.Type, subst)
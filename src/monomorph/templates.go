@@ -37,10 +37,14 @@ func syncStructDefFields(gl *t.NodeGlobal, st *t.NodeStructDef) {
 		sd.Fields = map[string]*t.NodeType{}
 	}
 	sd.FieldOrder = sd.FieldOrder[:0]
+	sd.Defaults = map[string]t.NodeExpr{}
 
 	for _, fld := range st.Class.ArgsNode.Args {
 		sd.Fields[fld.Name] = cloneType(fld.TypeNode)
 		sd.FieldOrder = append(sd.FieldOrder, fld.Name)
+		if fld.Default != nil {
+			sd.Defaults[fld.Name] = cloneExpr(fld.Default)
+		}
 	}
 }
 
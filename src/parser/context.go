@@ -32,9 +32,24 @@ type ParseCtx struct {
 	NextExportName  string
 	NextExportABI   string
 	NextNoRetain    bool
+	// NextAllow holds the warning codes of pending `@allow` directives, which
+	// apply to the next declaration starting after NextAllowLine.
+	NextAllow     []string
+	NextAllowLine uint32
+	// NextDerive holds the operations of pending `@derive` directives, which
+	// must apply to a struct declaration.
+	NextDerive []t.Derive
+	// NextIntrinsic is the kind named by a pending `@intrinsic` directive,
+	// which must apply to a function declaration.
+	NextIntrinsic   t.IntrinsicKind
+	NextIntrinsicTk t.Token
 
 	PruneNext  bool
 	ModuleSeen bool
+
+	// Errors collects every syntax error from which the parser recovered, in
+	// the order they were found.
+	Errors []error
 }
 
 type parsedName struct {
@@ -233,11 +248,16 @@ func parseUseDecl(ctx *ParseCtx, tk t.Token, prune bool) error {
 
 	absPath, err := makeabs.ResolveImport(path.Repr, ctx.Fctx.FilePath, ctx.Shared.StdRoot)
 	if err != nil {
+		additional := ""
+		prefix, modules := makeabs.SiblingModules(path.Repr, ctx.Fctx.FilePath, ctx.Shared.StdRoot)
+		if closest, ok := comp_err.ClosestName(strings.TrimPrefix(path.Repr, prefix), modules); ok {
+			additional = comp_err.DidYouMean(prefix + closest)
+		}
 		return comp_err.CompilationErrorToken(
 			ctx.Fctx,
 			&path,
 			fmt.Sprintf("syntax error: failed to get full path from '%s' (%s)", path.Repr, err.Error()),
-			"",
+			additional,
 		)
 	}
 
@@ -49,6 +49,7 @@ func parseStructDef(ctx *ParseCtx, tk t.Token, gncls t.NodeGenericClass) (*t.Nod
 		Funcs:      map[string]*t.NodeFuncDef{},
 		FieldNb:    map[string]int{},
 		FieldOrder: []string{},
+		Defaults:   map[string]t.NodeExpr{},
 	}
 
 	for i, arg := range gncls.ArgsNode.Args {
@@ -60,6 +61,9 @@ func parseStructDef(ctx *ParseCtx, tk t.Token, gncls t.NodeGenericClass) (*t.Nod
 				"field names within a struct must be unique",
 			)
 		}
+		if arg.Default != nil {
+			structMap.Defaults[arg.Name] = arg.Default
+		}
 		structMap.Fields[arg.Name] = arg.TypeNode
 		structMap.FieldNb[arg.Name] = i
 		structMap.FieldOrder = append(structMap.FieldOrder, arg.Name)
@@ -110,8 +114,27 @@ func parseProtoDef(ctx *ParseCtx, protoTk t.Token) (t.NodeGlobalDecl, error) {
 	if _, exists := ctx.GlobalNode.TypeAliases[name.Name]; exists {
 		return nil, comp_err.CompilationErrorToken(ctx.Fctx, &name.Tk, fmt.Sprintf("type '%s' is already declared as an alias", name.Name), "")
 	}
-	impls := []*t.ProtoImpl{}
+	extends := []*t.ProtoImpl{}
 	open, err := peek(ctx)
+	if err == nil && open.Type == t.TokName && open.Repr == "extends" {
+		consume(ctx)
+		for {
+			current, currentErr := peek(ctx)
+			if currentErr != nil {
+				return nil, currentErr
+			}
+			baseType, typeErr := parseType(ctx, current, false)
+			if typeErr != nil {
+				return nil, typeErr
+			}
+			extends = append(extends, &t.ProtoImpl{Type: baseType, Tk: current})
+			if open, err = peek(ctx); err != nil || open.KeywType != t.KwComma {
+				break
+			}
+			consume(ctx)
+		}
+	}
+	impls := []*t.ProtoImpl{}
 	if err == nil && open.Type == t.TokName && open.Repr == "impl" {
 		consume(ctx)
 		for {
@@ -134,7 +157,7 @@ func parseProtoDef(ctx *ParseCtx, protoTk t.Token) (t.NodeGlobalDecl, error) {
 		return nil, comp_err.CompilationErrorToken(ctx.Fctx, &name.Tk, "prototype declaration requires a method list", "expected: `proto Name(method(args) Return)`")
 	}
 	consume(ctx)
-	proto := &t.ProtoDef{Module: ctx.Fctx.PackageName, Name: name.Name, IsPublic: slices.Contains(modifiers, MdPublic), TypeParams: decl.TypeParams, MethodMap: map[string]*t.ProtoMethod{}, VtableName: "__proto_" + name.Name + "_vtable"}
+	proto := &t.ProtoDef{Module: ctx.Fctx.PackageName, Name: name.Name, IsPublic: slices.Contains(modifiers, MdPublic), TypeParams: decl.TypeParams, MethodMap: map[string]*t.ProtoMethod{}, VtableName: "__proto_" + name.Name + "_vtable", Extends: extends}
 	for {
 		tk, e := peek(ctx)
 		if e != nil {
@@ -168,6 +191,11 @@ func parseProtoDef(ctx *ParseCtx, protoTk t.Token) (t.NodeGlobalDecl, error) {
 		if e != nil {
 			return nil, e
 		}
+		for _, arg := range args.Args {
+			if arg.Default != nil {
+				return nil, comp_err.CompilationErrorToken(ctx.Fctx, &arg.Tk, fmt.Sprintf("prototype requirement parameter '%s' cannot declare a default value", arg.Name), "calls through a prototype view pass every argument")
+			}
+		}
 		retTk, e := peek(ctx)
 		if e != nil {
 			return nil, e
@@ -193,6 +221,9 @@ func parseProtoDef(ctx *ParseCtx, protoTk t.Token) (t.NodeGlobalDecl, error) {
 		}
 		vtArgs = append(vtArgs, t.NodeArg{Name: method.Name, Tk: method.Tk, TypeNode: &t.NodeType{KindNode: &t.NodeTypeFunc{Args: fnArgs, RetType: method.Ret, ContextABI: method.ContextABI}}})
 	}
+	for i, base := range proto.Extends {
+		vtArgs = append(vtArgs, t.NodeArg{Name: fmt.Sprintf("__base%d", i), Tk: base.Tk, TypeNode: syntheticNamed("ptr")})
+	}
 	vtName := &t.NodeNameSingle{Name: proto.VtableName, Tk: name.Tk}
 	vtClass := t.NodeGenericClass{NameNode: vtName, ArgsNode: t.NodeArgList{Args: vtArgs}}
 	vtNode, e := parseStructDef(ctx, name.Tk, vtClass)
@@ -322,11 +353,18 @@ func parseFuncDef(ctx *ParseCtx, nameTk t.Token, after t.Token, gncls t.NodeGene
 		IsExternal:   alias != "",
 		NoRetain:     ctx.NextNoRetain,
 		ContextABI:   t.ContextABIContextful,
+		Intrinsic:    ctx.NextIntrinsic,
 	}
 	if alias != "" {
 		fnDef.ContextABI = t.ContextABIContextless
 	}
 	ctx.NextNoRetain = false
+	if ctx.NextIntrinsic != t.IntrinsicNone {
+		ctx.NextIntrinsic = t.IntrinsicNone
+		if isMemberFunc || alias != "" || ctx.NextExportName != "" {
+			return nil, comp_err.CompilationErrorToken(ctx.Fctx, &nameTk, "syntax error: 'intrinsic' applies only to a top-level function definition", "declare the intrinsic as a plain function with an empty body")
+		}
+	}
 	if ctx.NextExportName != "" {
 		if alias != "" {
 			return nil, comp_err.CompilationErrorToken(ctx.Fctx, &nameTk, "syntax error: 'export_name' cannot be applied to an external declaration", "apply it to a function definition")
@@ -370,6 +408,9 @@ func parseFuncDef(ctx *ParseCtx, nameTk t.Token, after t.Token, gncls t.NodeGene
 		if e != nil {
 			return nil, e
 		}
+		if fnDef.Intrinsic != t.IntrinsicNone && len(bodyNode.Statements) != 0 {
+			return nil, comp_err.CompilationErrorToken(ctx.Fctx, &nameTk, fmt.Sprintf("syntax error: intrinsic '%s' cannot have a body", fnNameSimple), "the compiler provides the implementation; end the declaration with an empty body")
+		}
 		fnDef.Body = bodyNode
 	}
 
@@ -497,6 +538,22 @@ func parseFuncDef(ctx *ParseCtx, nameTk t.Token, after t.Token, gncls t.NodeGene
 			},
 		})
 
+		if isStruct && ownerStruct.IsProto && ownerStruct.Proto.MethodMap[memberName] != nil {
+			// A member named like a requirement is the requirement's default
+			// body. It is declared under its own name, beside the dispatcher.
+			requirement := ownerStruct.Proto.MethodMap[memberName]
+			if len(fnDef.Class.TypeParams) != 0 {
+				return nil, comp_err.CompilationErrorToken(ctx.Fctx, &nameTk, fmt.Sprintf("default body of '%s.%s' cannot declare generic parameters", ownerName, memberName), "prototype requirements are not generic")
+			}
+			if requirement.Default != nil {
+				return nil, comp_err.CompilationErrorToken(ctx.Fctx, &nameTk, fmt.Sprintf("prototype method '%s.%s' already has a default body", ownerName, memberName), "")
+			}
+			requirement.Default = fnDef
+			memberName = t.ProtoDefaultName(memberName)
+			complexName.Parts[1] = memberName
+			fnNameSimple = ownerName + "." + memberName
+			fnDef.AbsName = ctx.Fctx.PackageName + "." + fnNameSimple
+		}
 		if isStruct {
 			ownerStruct.Funcs[memberName] = fnDef
 		} else {
@@ -664,7 +721,9 @@ func parseGlobalDeclFromName(ctx *ParseCtx, tk t.Token) (t.NodeGlobalDecl, error
 			def := ctx.GlobalNode.StructDefs[declName.NameNode.(*t.NodeNameSingle).Name]
 			def.IsPublic = st.IsPublic
 			def.Implements = impls
+			def.Derives = ctx.NextDerive
 		}
+		ctx.NextDerive = nil
 		return st, nil
 	}
 
@@ -722,7 +781,9 @@ func parseGlobalDeclFromName(ctx *ParseCtx, tk t.Token) (t.NodeGlobalDecl, error
 				st.IsPublic = slices.Contains(modifiers, MdPublic)
 				name := st.Class.NameNode.(*t.NodeNameSingle).Name
 				ctx.GlobalNode.StructDefs[name].IsPublic = st.IsPublic
+				ctx.GlobalNode.StructDefs[name].Derives = ctx.NextDerive
 			}
+			ctx.NextDerive = nil
 			return st, e
 		}
 
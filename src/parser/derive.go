@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"Magma/src/comp_err"
+	"Magma/src/makeabs"
+	"Magma/src/tokenizer"
+	t "Magma/src/types"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// derivedMethods maps each operation accepted by `@derive` to the member it
+// synthesizes.
+var derivedMethods = map[string]string{
+	"eq":     "eq",
+	"hash":   "hash",
+	"format": "format",
+	"json":   "writeJson",
+}
+
+// deriveMembers synthesizes the members requested by the `@derive` directives
+// of the file. It runs once the whole file is parsed, so that hand-written
+// members are known, and before specialization, so that the members of a
+// generic struct are instantiated like any other.
+func deriveMembers(ctx *ParseCtx) {
+	for _, decl := range slices.Clone(ctx.GlobalNode.Declarations) {
+		st, ok := decl.(*t.NodeStructDef)
+		if !ok {
+			continue
+		}
+		def := ctx.GlobalNode.StructDefs[flattenName(st.Class.NameNode)]
+		if def == nil || len(def.Derives) == 0 {
+			continue
+		}
+		if err := importDeriveSupport(ctx); err != nil {
+			ctx.Errors = append(ctx.Errors, comp_err.CompilationErrorToken(ctx.Fctx, &def.Derives[0].Tk, err.Error(), ""))
+			return
+		}
+		for _, derive := range def.Derives {
+			if existing := def.Funcs[derive.Method]; existing != nil && !existing.Derived {
+				ctx.Errors = append(ctx.Errors, comp_err.CompilationErrorToken(ctx.Fctx, &derive.Tk,
+					fmt.Sprintf("cannot derive '%s': struct '%s' already declares member '%s'", derive.Name, def.Name, derive.Method),
+					fmt.Sprintf("remove \"%s\" from `@derive` or remove `%s.%s`", derive.Name, def.Name, derive.Method)))
+				continue
+			}
+			if err := parseDerived(ctx, derive, derivedSource(def, derive)); err != nil {
+				ctx.Errors = append(ctx.Errors, err)
+			}
+		}
+	}
+}
+
+// importDeriveSupport imports std:derive under t.DeriveImport, as a `use`
+// declaration would.
+func importDeriveSupport(ctx *ParseCtx) error {
+	if _, ok := ctx.Fctx.ImportAlias[t.DeriveImport]; ok {
+		return nil
+	}
+	absPath, err := makeabs.ResolveImport("std:derive", ctx.Fctx.FilePath, ctx.Shared.StdRoot)
+	if err != nil {
+		return fmt.Errorf("failed to import std:derive (%s)", err.Error())
+	}
+	if !slices.Contains(ctx.Fctx.Imports, absPath) {
+		ctx.Fctx.Imports = append(ctx.Fctx.Imports, absPath)
+	}
+	ctx.Fctx.ImportAlias[t.DeriveImport] = absPath
+
+	c := ctx.Shared.PipelineFunc(ctx.Shared, absPath, t.DeriveImport, ctx.Fctx.FilePath, ctx.GlobalNode)
+	ctx.Shared.PipeChansM.Lock()
+	ctx.Shared.PipeChans = append(ctx.Shared.PipeChans, c)
+	ctx.Shared.PipeChansM.Unlock()
+	return nil
+}
+
+// parseDerived parses the source of a derived member as a declaration of the
+// file. Every token is placed on the operation's name in the directive, which
+// is where diagnostics about the member are reported.
+func parseDerived(ctx *ParseCtx, derive t.Derive, source string) error {
+	tokens, err := tokenizer.Tokenize(ctx.Fctx, []byte(source))
+	if err != nil {
+		return err
+	}
+	for i := range tokens {
+		tokens[i].Pos, tokens[i].End = derive.Tk.Pos, derive.Tk.End
+	}
+	sub := &ParseCtx{Shared: ctx.Shared, GlobalNode: ctx.GlobalNode, Fctx: ctx.Fctx, Toks: tokens, ModuleSeen: true}
+	for {
+		tk, e := peek(sub)
+		if e != nil {
+			return nil
+		}
+		if tk.KeywType == t.KwNewline {
+			consume(sub)
+			continue
+		}
+		decl, e := parseGlobalDecl(sub, tk)
+		if e != nil {
+			return e
+		}
+		if fn, ok := decl.(*t.NodeFuncDef); ok {
+			fn.Derived = true
+			ctx.GlobalNode.Declarations = append(ctx.GlobalNode.Declarations, fn)
+		}
+	}
+}
+
+// derivedSource writes the member derive synthesizes on def. Each member calls
+// the member of the same name on every field, in declaration order, and only
+// borrows the fields.
+func derivedSource(def *t.StructDef, derive t.Derive) string {
+	owner := def.Name
+	if len(def.TypeParams) > 0 {
+		owner += "[" + strings.Join(def.TypeParams, ", ") + "]"
+	}
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+	switch derive.Name {
+	case "eq":
+		other := "other"
+		if len(def.FieldOrder) == 0 {
+			other = "_other"
+		}
+		line("%s.eq(%s %s*) bool:", owner, other, owner)
+		for _, field := range def.FieldOrder {
+			line("    if this.%s.eq(addrof other.%s) == false:", field, field)
+			line("        ret false")
+			line("    ..")
+		}
+		line("    ret true")
+	case "hash":
+		line("%s.hash() u64:", owner)
+		line("    h u64 = %s.SEED", t.DeriveImport)
+		for _, field := range def.FieldOrder {
+			line("    h = %s.mix(h, this.%s.hash())", t.DeriveImport, field)
+		}
+		line("    ret h")
+	case "format":
+		line("%s.format(f %s.fmt.Format*) void:", owner, t.DeriveImport)
+		prefix, closing := def.Name+"(", ")"
+		for _, field := range def.FieldOrder {
+			line("    *f = f.str(%q)", prefix+field+"=")
+			line("    this.%s.format(f)", field)
+			prefix = ", "
+		}
+		if len(def.FieldOrder) == 0 {
+			closing = def.Name + "()"
+		}
+		line("    *f = f.str(%q)", closing)
+	case "json":
+		line("%s.writeJson(w %s.writer.Writer, precision u64) !void:", owner, t.DeriveImport)
+		prefix, closing := "{", "}"
+		for _, field := range def.FieldOrder {
+			line("    try w.writeAll(%q)", prefix+`"`+field+`":`)
+			line("    try this.%s.writeJson(w, precision)", field)
+			prefix = ","
+		}
+		if len(def.FieldOrder) == 0 {
+			closing = "{}"
+		}
+		line("    try w.writeAll(%q)", closing)
+	}
+	line("..")
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"Magma/src/comp_err"
 	t "Magma/src/types"
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -147,12 +148,59 @@ func parseCompilerDirective(ctx *ParseCtx, tk t.Token) error {
 		}
 		ctx.NextNoRetain = true
 		return nil
+	case "allow":
+		if len(dirArgs) == 0 {
+			return comp_err.CompilationErrorToken(ctx.Fctx, &tk, "syntax error: directive 'allow' takes one or more warning codes", "expected: `@allow(\"<code>\", ...)`, ex: `@allow(\"unused-function\")`")
+		}
+		for _, arg := range dirArgs {
+			if arg.Type != t.TokLitStr || arg.Repr == "" {
+				return comp_err.CompilationErrorToken(ctx.Fctx, &arg, "syntax error: warning codes in 'allow' must be non-empty strings", "expected: `@allow(\"<code>\", ...)`")
+			}
+			ctx.NextAllow = append(ctx.NextAllow, arg.Repr)
+		}
+		if ctx.NextAllowLine == 0 {
+			ctx.NextAllowLine = tk.Pos.Line
+		}
+		return nil
+	case "derive":
+		if len(dirArgs) == 0 {
+			return comp_err.CompilationErrorToken(ctx.Fctx, &tk, "syntax error: directive 'derive' takes one or more operation names", "expected: `@derive(\"eq\", \"hash\", \"format\", \"json\")`")
+		}
+		for _, arg := range dirArgs {
+			method, ok := derivedMethods[arg.Repr]
+			if arg.Type != t.TokLitStr || !ok {
+				return comp_err.CompilationErrorToken(ctx.Fctx, &arg, fmt.Sprintf("syntax error: cannot derive '%s'", arg.Repr), "derivable operations: \"eq\", \"hash\", \"format\", \"json\"")
+			}
+			if slices.ContainsFunc(ctx.NextDerive, func(d t.Derive) bool { return d.Name == arg.Repr }) {
+				return comp_err.CompilationErrorToken(ctx.Fctx, &arg, fmt.Sprintf("syntax error: '%s' is derived more than once", arg.Repr), "name each operation once")
+			}
+			ctx.NextDerive = append(ctx.NextDerive, t.Derive{Name: arg.Repr, Method: method, Tk: arg})
+		}
+		return nil
+	case "intrinsic":
+		if len(dirArgs) != 1 || dirArgs[0].Type != t.TokLitStr {
+			return comp_err.CompilationErrorToken(ctx.Fctx, &tk, "syntax error: directive 'intrinsic' takes one intrinsic name", "expected: `@intrinsic(\"<name>\")`, ex: `@intrinsic(\"atomic.load\")`")
+		}
+		kind, ok := t.Intrinsics[dirArgs[0].Repr]
+		if !ok {
+			names := make([]string, 0, len(t.Intrinsics))
+			for name := range t.Intrinsics {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return comp_err.CompilationErrorToken(ctx.Fctx, &dirArgs[0], fmt.Sprintf("syntax error: unknown intrinsic '%s'", dirArgs[0].Repr), comp_err.Suggestion(dirArgs[0].Repr, names))
+		}
+		if ctx.NextIntrinsic != t.IntrinsicNone {
+			return comp_err.CompilationErrorToken(ctx.Fctx, &tk, "syntax error: duplicate 'intrinsic' directive", "a declaration provides a single intrinsic")
+		}
+		ctx.NextIntrinsic, ctx.NextIntrinsicTk = kind, dirArgs[0]
+		return nil
 	default:
 		return comp_err.CompilationErrorToken(
 			ctx.Fctx,
 			&next,
 			"syntax error: invalid compiler directive name",
-			"expected: `@platform(...)`, `@export_name(...)`, or `@no_retain`",
+			"expected: `@platform(...)`, `@export_name(...)`, `@no_retain`, `@allow(...)`, `@derive(...)`, or `@intrinsic(...)`",
 		)
 	}
 }
@@ -233,7 +233,7 @@ func parseSimplePrimaryExpr(ctx *ParseCtx, tk t.Token) (t.NodeExpr, error) {
 		return &t.NodeExprLit{Tk: tk, Value: "null", LitType: t.TokLitNone}, nil
 	}
 
-	if tk.Type == t.TokLitNum || tk.Type == t.TokLitStr {
+	if tk.Type == t.TokLitNum || tk.Type == t.TokLitStr || tk.Type == t.TokLitChar {
 		consume(ctx)
 		return &t.NodeExprLit{Tk: tk, Value: tk.Repr, LitType: tk.Type}, nil
 	}
@@ -274,6 +274,8 @@ func expressionToken(expr t.NodeExpr, fallback t.Token) t.Token {
 func parsePostfixCallExpr(ctx *ParseCtx, tk t.Token, calleeExpr t.NodeExpr, genericArgs []*t.NodeType) (*t.NodeExprCall, error) {
 	consume(ctx)
 	argExprs := []t.NodeExpr{}
+	argNames := []t.Token{}
+	named := false
 
 	maybeCl, e := peek(ctx)
 	if e != nil {
@@ -288,11 +290,27 @@ func parsePostfixCallExpr(ctx *ParseCtx, tk t.Token, calleeExpr t.NodeExpr, gene
 			if e != nil {
 				return nil, e
 			}
+			name := t.Token{}
+			if eq, e := peekNth(ctx, 1); e == nil && nextExpr.Type == t.TokName && eq.KeywType == t.KwEqual {
+				name, named = nextExpr, true
+				consume(ctx) // name
+				consume(ctx) // '='
+				if nextExpr, e = peek(ctx); e != nil {
+					return nil, e
+				}
+			} else if named {
+				return nil, comp_err.CompilationErrorToken(
+					ctx.Fctx, &nextExpr,
+					"syntax error: positional argument after a named argument",
+					"pass positional arguments first, then `name=value` arguments",
+				)
+			}
 			parsedExpr, e := parseExpression(ctx, nextExpr, 0)
 			if e != nil {
 				return nil, e
 			}
 			argExprs = append(argExprs, parsedExpr)
+			argNames = append(argNames, name)
 
 			afterExpr, e := peek(ctx)
 			if e != nil {
@@ -323,12 +341,16 @@ func parsePostfixCallExpr(ctx *ParseCtx, tk t.Token, calleeExpr t.NodeExpr, gene
 		}
 	}
 
-	return &t.NodeExprCall{
+	call := &t.NodeExprCall{
 		Tk:          expressionToken(calleeExpr, tk),
 		Callee:      calleeExpr,
 		Args:        argExprs,
 		GenericArgs: genericArgs,
-	}, nil
+	}
+	if named {
+		call.ArgNames = argNames
+	}
+	return call, nil
 }
 
 func isStructInitList(ctx *ParseCtx) bool {
@@ -355,45 +377,45 @@ func consumeNewlines(ctx *ParseCtx) {
 	}
 }
 
-func parsePostfixStructInit(ctx *ParseCtx, tk t.Token, calleeExpr t.NodeExpr, genericArgs []*t.NodeType) (*t.NodeExprStructInit, error) {
-	nameExpr, ok := calleeExpr.(*t.NodeExprName)
-	if !ok {
-		return nil, comp_err.CompilationErrorToken(ctx.Fctx, &tk, "struct constructor requires a type name", "")
-	}
+// parseNamedArguments parses a parenthesized list of `name=value` arguments,
+// which may span lines.
+func parseNamedArguments(ctx *ParseCtx) ([]t.NodeExpr, []t.Token, error) {
 	consume(ctx) // '('
 	consumeNewlines(ctx)
-	fields := []t.NodeStructFieldInit{}
+	args := []t.NodeExpr{}
+	names := []t.Token{}
 	for {
-		fieldTk, e := peek(ctx)
+		nameTk, e := peek(ctx)
 		if e != nil {
-			return nil, e
+			return nil, nil, e
 		}
-		if fieldTk.KeywType == t.KwParenCl {
+		if nameTk.KeywType == t.KwParenCl {
 			consume(ctx)
 			break
 		}
-		if fieldTk.Type != t.TokName {
-			return nil, comp_err.CompilationErrorToken(ctx.Fctx, &fieldTk, "struct constructor fields must be named", "expected: `field=expression`")
+		if nameTk.Type != t.TokName {
+			return nil, nil, comp_err.CompilationErrorToken(ctx.Fctx, &nameTk, "argument must be named", "expected: `name=expression`")
 		}
 		consume(ctx)
 		eq, e := peek(ctx)
 		if e != nil || eq.KeywType != t.KwEqual {
-			return nil, comp_err.CompilationErrorToken(ctx.Fctx, &fieldTk, "struct constructor field is missing '='", "expected: `field=expression`")
+			return nil, nil, comp_err.CompilationErrorToken(ctx.Fctx, &nameTk, "named argument is missing '='", "expected: `name=expression`")
 		}
 		consume(ctx)
 		first, e := peek(ctx)
 		if e != nil {
-			return nil, e
+			return nil, nil, e
 		}
 		value, e := parseExpression(ctx, first, 0)
 		if e != nil {
-			return nil, e
+			return nil, nil, e
 		}
-		fields = append(fields, t.NodeStructFieldInit{Tk: fieldTk, Name: fieldTk.Repr, Expression: value, FieldIndex: -1})
+		args = append(args, value)
+		names = append(names, nameTk)
 
 		after, e := peek(ctx)
 		if e != nil {
-			return nil, e
+			return nil, nil, e
 		}
 		if after.KeywType == t.KwParenCl {
 			consume(ctx)
@@ -404,25 +426,20 @@ func parsePostfixStructInit(ctx *ParseCtx, tk t.Token, calleeExpr t.NodeExpr, ge
 			continue
 		}
 		if after.KeywType != t.KwComma {
-			return nil, comp_err.CompilationErrorToken(ctx.Fctx, &after, "unexpected token in struct constructor", "expected ',', newline, or ')'")
+			return nil, nil, comp_err.CompilationErrorToken(ctx.Fctx, &after, "unexpected token in named argument list", "expected ',', newline, or ')'")
 		}
 		consume(ctx)
 		consumeNewlines(ctx)
 		after, e = peek(ctx)
 		if e != nil {
-			return nil, e
+			return nil, nil, e
 		}
 		if after.KeywType == t.KwParenCl {
 			consume(ctx)
 			break
 		}
 	}
-
-	return &t.NodeExprStructInit{
-		Tk:     tk,
-		Type:   &t.NodeType{KindNode: &t.NodeTypeNamed{NameNode: nameExpr.Name, GenericArgs: genericArgs}},
-		Fields: fields,
-	}, nil
+	return args, names, nil
 }
 
 func tryParseGenericCallTypeArgs(ctx *ParseCtx) ([]*t.NodeType, bool) {
@@ -457,37 +474,18 @@ func isKnownGenericFunction(ctx *ParseCtx, expr *t.NodeExprName) bool {
 	return ok && len(fn.Class.TypeParams) > 0
 }
 
-func isKnownFunction(ctx *ParseCtx, expr t.NodeExpr) bool {
-	nameExpr, ok := expr.(*t.NodeExprName)
-	if !ok {
-		return false
-	}
-	name, ok := nameExpr.Name.(*t.NodeNameSingle)
-	if !ok {
-		return false
-	}
-	_, ok = ctx.GlobalNode.FuncDefs[name.Name]
-	return ok
-}
-
+// parsePostfixNamedCall parses a `name=value` argument list as a call. The
+// list constructs a struct instead when the callee names one, which is only
+// known once every module is parsed; monomorphization resolves it then.
 func parsePostfixNamedCall(ctx *ParseCtx, tk t.Token, calleeExpr t.NodeExpr, genericArgs []*t.NodeType) (*t.NodeExprCall, error) {
-	init, err := parsePostfixStructInit(ctx, tk, calleeExpr, genericArgs)
+	start := ctx.TokIdx
+	args, names, err := parseNamedArguments(ctx)
 	if err != nil {
-		return nil, err
-	}
-	args := make([]t.NodeExpr, len(init.Fields))
-	for index, field := range init.Fields {
-		args[index] = field.Expression
-	}
-	if len(init.Fields) > 0 {
-		return nil, comp_err.CompilationErrorToken(
-			ctx.Fctx,
-			&init.Fields[0].Tk,
-			"named arguments are not supported in function calls",
-			"use positional arguments instead",
-		)
+		// The call parser explains a positional argument after the names.
+		ctx.TokIdx = start
+		return parsePostfixCallExpr(ctx, tk, calleeExpr, genericArgs)
 	}
-	return &t.NodeExprCall{Tk: expressionToken(calleeExpr, tk), Callee: calleeExpr, Args: args, GenericArgs: genericArgs}, nil
+	return &t.NodeExprCall{Tk: expressionToken(calleeExpr, tk), Callee: calleeExpr, Args: args, ArgNames: names, GenericArgs: genericArgs}, nil
 }
 
 func parsePostfixSubscriptExpr(ctx *ParseCtx, tk t.Token, targetExpr t.NodeExpr) (*t.NodeExprSubscript, error) {
@@ -659,10 +657,8 @@ func parsePostfixExpr(ctx *ParseCtx, tk t.Token, baseExpr t.NodeExpr) (t.NodeExp
 				}
 			}
 
-			if isStructInitList(ctx) && isKnownFunction(ctx, expr) {
+			if isStructInitList(ctx) {
 				expr, e = parsePostfixNamedCall(ctx, tk, expr, nil)
-			} else if isStructInitList(ctx) {
-				expr, e = parsePostfixStructInit(ctx, tk, expr, nil)
 			} else {
 				expr, e = parsePostfixCallExpr(ctx, tk, expr, nil)
 			}
@@ -701,10 +697,8 @@ func parsePostfixExpr(ctx *ParseCtx, tk t.Token, baseExpr t.NodeExpr) (t.NodeExp
 			if nameExpr, ok := expr.(*t.NodeExprName); ok {
 				typeArgs, isGenericCall := tryParseGenericCallTypeArgs(ctx)
 				if isGenericCall {
-					if isStructInitList(ctx) && isKnownFunction(ctx, nameExpr) {
+					if isStructInitList(ctx) {
 						expr, e = parsePostfixNamedCall(ctx, tk, expr, typeArgs)
-					} else if isStructInitList(ctx) {
-						expr, e = parsePostfixStructInit(ctx, tk, expr, typeArgs)
 					} else {
 						expr, e = parsePostfixCallExpr(ctx, tk, expr, typeArgs)
 					}
@@ -785,6 +779,9 @@ func parsePostfixExpr(ctx *ParseCtx, tk t.Token, baseExpr t.NodeExpr) (t.NodeExp
 				if next.Type != t.TokName && next.KeywType != t.KwInfer && next.KeywType != t.KwDollar {
 					break
 				}
+				if isCastOperator(ctx, next, 0) {
+					break
+				}
 
 				if next.Type == t.TokName || next.KeywType == t.KwDollar {
 					typeNd, e := parseType(ctx, next, false)
@@ -955,6 +952,59 @@ func getBinaryPrecedence(tk t.Token) int {
 	}
 }
 
+// castPrecedence binds `as` more tightly than every binary operator and at the
+// same level as the operand of try, so `try value as checked u8` attempts the
+// cast rather than the value.
+const castPrecedence = 60
+
+// isCastOperator reports whether the token at offset is a contextual `as`
+// followed by a cast mode or a type name. `as` remains usable as an
+// ordinary name, including as a type name in `value as = ...`.
+func isCastOperator(ctx *ParseCtx, tk t.Token, offset int) bool {
+	if tk.Type != t.TokName || tk.Repr != "as" {
+		return false
+	}
+	next, e := peekNth(ctx, offset+1)
+	return e == nil && next.Type == t.TokName
+}
+
+func parseCast(ctx *ParseCtx, asTk t.Token, operand t.NodeExpr) (t.NodeExpr, error) {
+	consume(ctx) // as
+	cast := &t.NodeExprCast{Tk: asTk, Expr: operand}
+	typeTk, e := peek(ctx)
+	if e != nil {
+		return nil, e
+	}
+	if typeTk.Type == t.TokName {
+		for mode, repr := range t.CastModeToRepr {
+			if repr == "" || typeTk.Repr != repr {
+				continue
+			}
+			// A type named like a mode stays a plain cast: `x as checked`.
+			if after, afterErr := peekNth(ctx, 1); afterErr == nil && after.Type == t.TokName {
+				cast.Mode = t.CastMode(mode)
+				consume(ctx)
+				typeTk, e = peek(ctx)
+				if e != nil {
+					return nil, e
+				}
+			}
+			break
+		}
+	}
+	if typeTk.Type != t.TokName {
+		return nil, comp_err.CompilationErrorToken(ctx.Fctx, &typeTk, fmt.Sprintf("syntax error: expected a numeric type after '%s' but got '%s'", cast.Tk.Repr, typeTk.Repr), "expected: `value as u32` or `value as checked u32`")
+	}
+	// Casts target numeric types only, so no pointer or slice suffix is parsed:
+	// `size as u64 * 2` multiplies the converted value.
+	name, e := parseName(ctx, typeTk, true)
+	if e != nil {
+		return nil, e
+	}
+	cast.Type = &t.NodeType{KindNode: &t.NodeTypeNamed{NameNode: name}}
+	return cast, nil
+}
+
 func parseDestructureAssignAfterComma(ctx *ParseCtx, commaTk t.Token, left t.NodeExpr) (t.NodeExpr, bool, error) {
 	if commaTk.KeywType != t.KwComma {
 		return nil, false, nil
@@ -1158,6 +1208,17 @@ func parseExpression(ctx *ParseCtx, tk t.Token, minPrecedence int) (t.NodeExpr,
 			break
 		}
 
+		if isCastOperator(ctx, opTk, 0) {
+			if castPrecedence < minPrecedence {
+				break
+			}
+			left, e = parseCast(ctx, opTk, left)
+			if e != nil {
+				return nil, e
+			}
+			continue
+		}
+
 		precedence := getBinaryPrecedence(opTk)
 		if precedence == 0 || precedence < minPrecedence {
 			break
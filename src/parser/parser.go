@@ -6,7 +6,7 @@ import (
 	"errors"
 )
 
-func parseGlobal(ctx *ParseCtx) (*t.NodeGlobal, error) {
+func parseGlobal(ctx *ParseCtx) *t.NodeGlobal {
 	n := &t.NodeGlobal{
 		StructDefs:           map[string]*t.StructDef{},
 		ProtoDefs:            map[string]*t.ProtoDef{},
@@ -24,10 +24,7 @@ func parseGlobal(ctx *ParseCtx) (*t.NodeGlobal, error) {
 	for {
 		tk, e := peek(ctx)
 		if e != nil {
-			if errors.Is(e, errOutOfBounds) {
-				return n, nil
-			}
-			return nil, e
+			return n
 		}
 
 		if tk.KeywType == t.KwNewline {
@@ -35,9 +32,28 @@ func parseGlobal(ctx *ParseCtx) (*t.NodeGlobal, error) {
 			continue
 		}
 
+		start := ctx.TokIdx
 		glDecl, e := parseGlobalDecl(ctx, tk)
 		if e != nil {
-			return nil, e
+			if errors.Is(e, errOutOfBounds) {
+				ctx.Errors = append(ctx.Errors, prematureEnd(ctx))
+				return n
+			}
+			n.Declarations = append(n.Declarations, recoverDeclaration(ctx, start, e))
+			continue
+		}
+		if len(ctx.NextAllow) > 0 && declarationStart(tk) {
+			end, _ := peekNth(ctx, -1)
+			ctx.Fctx.Allowances = append(ctx.Fctx.Allowances, t.WarningAllowance{Codes: ctx.NextAllow, Line: ctx.NextAllowLine, EndLine: end.Pos.Line})
+			ctx.NextAllow, ctx.NextAllowLine = nil, 0
+		}
+		if len(ctx.NextDerive) > 0 && declarationStart(tk) {
+			ctx.Errors = append(ctx.Errors, comp_err.CompilationErrorToken(ctx.Fctx, &ctx.NextDerive[0].Tk, "syntax error: directive 'derive' applies only to struct declarations", "place `@derive(...)` directly above a struct"))
+			ctx.NextDerive = nil
+		}
+		if ctx.NextIntrinsic != t.IntrinsicNone && declarationStart(tk) {
+			ctx.Errors = append(ctx.Errors, comp_err.CompilationErrorToken(ctx.Fctx, &ctx.NextIntrinsicTk, "syntax error: directive 'intrinsic' applies only to function declarations", "place `@intrinsic(...)` directly above a top-level function"))
+			ctx.NextIntrinsic = t.IntrinsicNone
 		}
 
 		// this is sketch af
@@ -48,6 +64,122 @@ func parseGlobal(ctx *ParseCtx) (*t.NodeGlobal, error) {
 	}
 }
 
+// declarationStart reports whether a global item beginning with tk completes a
+// declaration, rather than being a directive or modifier applied to the next one.
+func declarationStart(tk t.Token) bool {
+	switch tk.KeywType {
+	case t.KwAt, t.KwPublic, t.KwDestructor, t.KwNoCtx:
+		return false
+	}
+	return true
+}
+
+// globalItemStart reports whether tk can begin a global item: a name, or a
+// declaration keyword, modifier, or directive.
+func globalItemStart(tk t.Token) bool {
+	if tk.Type == t.TokName {
+		return true
+	}
+	switch tk.KeywType {
+	case t.KwPublic, t.KwDestructor, t.KwNoCtx, t.KwConst, t.KwAlias, t.KwAt,
+		t.KwModule, t.KwUse, t.KwLink, t.KwBundle, t.KwLlvm, t.KwExtern:
+		return true
+	}
+	return false
+}
+
+func prematureEnd(ctx *ParseCtx) error {
+	var last t.Token
+	if len(ctx.Toks) > 0 {
+		last = ctx.Toks[len(ctx.Toks)-1]
+	}
+	return comp_err.CompilationErrorToken(
+		ctx.Fctx, &last,
+		"syntax error: reached end of file prematurely",
+		"",
+	)
+}
+
+// skipLine resynchronizes after a syntax error in the item which began at
+// token start. It skips the rest of the current line, together with every
+// block the skipped tokens open, and stops before the newline or before a
+// '..', 'elif' or 'else' which closes the enclosing block.
+func skipLine(ctx *ParseCtx, start int) {
+	depth := 0
+	for {
+		tk, e := peek(ctx)
+		if e != nil {
+			return
+		}
+		// The first token is always skipped, so that recovery makes progress
+		// even when the item failed before consuming anything.
+		closes := ctx.TokIdx > start && depth == 0
+		switch tk.KeywType {
+		case t.KwNewline:
+			if closes {
+				return
+			}
+		case t.KwColon:
+			depth++
+		case t.KwDots, t.KwElif, t.KwElse:
+			if closes {
+				return
+			}
+			// 'elif' and 'else' close a branch; their ':' opens the next one.
+			depth = max(depth-1, 0)
+		}
+		consume(ctx)
+	}
+}
+
+// recoverStatement records the syntax error of a statement beginning at token
+// start and skips past it, so the enclosing body can continue with its next
+// line. Running out of tokens cannot be recovered from.
+func recoverStatement(ctx *ParseCtx, start int, err error) (t.NodeStatement, error) {
+	if errors.Is(err, errOutOfBounds) {
+		return nil, err
+	}
+	ctx.Errors = append(ctx.Errors, err)
+	node := &t.NodeError{Tk: ctx.Toks[start]}
+	skipLine(ctx, start)
+	return node, nil
+}
+
+// recoverDeclaration records the syntax error of a global item beginning at
+// token start, then skips ahead to the next line which starts a global item in
+// the first column. Modifiers and directives pending for the failed item are
+// discarded.
+func recoverDeclaration(ctx *ParseCtx, start int, err error) t.NodeGlobalDecl {
+	ctx.Errors = append(ctx.Errors, err)
+	ctx.NextModifiers = nil
+	ctx.NextExportName, ctx.NextExportABI = "", ""
+	ctx.NextNoRetain, ctx.PruneNext = false, false
+	ctx.NextAllow, ctx.NextAllowLine = nil, 0
+	ctx.NextDerive = nil
+	ctx.NextIntrinsic = t.IntrinsicNone
+	node := &t.NodeError{Tk: ctx.Toks[start]}
+
+	skipLine(ctx, start)
+	for {
+		tk, e := peek(ctx)
+		if e != nil {
+			return node
+		}
+		if tk.KeywType == t.KwNewline {
+			consume(ctx)
+			continue
+		}
+		if tk.Pos.Col == 1 && globalItemStart(tk) {
+			return node
+		}
+		skipLine(ctx, ctx.TokIdx)
+	}
+}
+
+// Parse builds the global node of a file. Syntax errors do not stop parsing:
+// each failed statement or declaration is replaced by a NodeError and parsing
+// resumes after it, so the returned tree is never nil and the error joins every
+// syntax error in the file.
 func Parse(shared *t.SharedState, fCtx *t.FileCtx) (*t.NodeGlobal, error) {
 	ctx := &ParseCtx{
 		Shared: shared,
@@ -55,21 +187,7 @@ func Parse(shared *t.SharedState, fCtx *t.FileCtx) (*t.NodeGlobal, error) {
 		Toks:   fCtx.Tokens,
 	}
 
-	glNd, e := parseGlobal(ctx)
-	if e != nil {
-		if errors.Is(e, errOutOfBounds) {
-			var last t.Token
-			if len(ctx.Toks) > 0 {
-				last, _ = peekNth(ctx, -1)
-			}
-
-			return glNd, comp_err.CompilationErrorToken(
-				ctx.Fctx, &last,
-				"syntax error: reached end of file prematurely",
-				"",
-			)
-		}
-		return glNd, e
-	}
-	return glNd, nil
+	glNd := parseGlobal(ctx)
+	deriveMembers(ctx)
+	return glNd, comp_err.Join(ctx.Errors...)
 }
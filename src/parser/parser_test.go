@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"Magma/src/comp_err"
 	"Magma/src/tokenizer"
 	mt "Magma/src/types"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -257,6 +260,98 @@ main() void:
 	}
 }
 
+func TestNamedArgumentsAndDefaults(t *testing.T) {
+	global, err := parseTestSource(t, `mod main
+Point(x u64)
+main() void:
+    n := spawn(1, maxWorkers=8)
+    m := later(count=2)
+    p := Point(x=1)
+..
+spawn(queue u64, minWorkers u64 = (1 + 1) * 2, maxWorkers u64 = -1) u64:
+    ret queue
+..
+later(count u64) u64:
+    ret count
+..
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := global.FuncDefs["spawn"].Class.ArgsNode.Args
+	if args[0].Default != nil || args[1].DefaultSource != "(1 + 1) * 2" || args[2].DefaultSource != "-1" {
+		t.Fatalf("defaults = %q, %q, %q", args[0].DefaultSource, args[1].DefaultSource, args[2].DefaultSource)
+	}
+	statements := global.FuncDefs["main"].Body.Statements
+	call := statements[0].(*mt.NodeStmtExpr).Expression.(*mt.NodeExprVarDefAssign).AssignExpr.(*mt.NodeExprCall)
+	if len(call.ArgNames) != 2 || call.ArgNames[0].Repr != "" || call.ArgNames[1].Repr != "maxWorkers" {
+		t.Fatalf("argument names = %#v", call.ArgNames)
+	}
+	// A call and a constructor which name every argument parse alike; what
+	// the callee declares decides between them once every module is parsed.
+	for _, statement := range statements[1:] {
+		named, ok := statement.(*mt.NodeStmtExpr).Expression.(*mt.NodeExprVarDefAssign).AssignExpr.(*mt.NodeExprCall)
+		if !ok || len(named.ArgNames) != 1 {
+			t.Fatalf("named argument list parsed as %#v", statement.(*mt.NodeStmtExpr).Expression.(*mt.NodeExprVarDefAssign).AssignExpr)
+		}
+	}
+
+	for source, want := range map[string]string{
+		"mod main\nf(a u64, b u64 = a) void:\n..\n":                       "default value of 'b' must be a constant expression",
+		"mod main\nP(a u64, b u64 = a)\n":                                 "default value of 'b' must be a constant expression",
+		"mod main\nf(a u64) void:\n..\nmain() void:\n    f(a=1, 2)\n..\n": "positional argument after a named argument",
+	} {
+		if _, err := parseTestSource(t, source); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error = %v, want %q", source, err, want)
+		}
+	}
+}
+
+func TestStructFieldDefaults(t *testing.T) {
+	global, err := parseTestSource(t, `mod main
+Config(retries u64 = 3, verbose bool)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := global.StructDefs["Config"].Defaults["retries"]; def == nil {
+		t.Fatal("default of 'retries' was not recorded")
+	}
+	if _, ok := global.StructDefs["Config"].Defaults["verbose"]; ok {
+		t.Fatal("field without a default recorded one")
+	}
+}
+
+func TestCastIsContextualAndBindsTighterThanBinaryOperators(t *testing.T) {
+	global, err := parseTestSource(t, `mod main
+main() !void:
+    as u64 = 1
+    doubled := as as u32 * 2
+    narrow := try doubled as checked u8
+    checked := as as checked
+..
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statements := global.FuncDefs["main"].Body.Statements
+	if def, ok := statements[0].(*mt.NodeStmtExpr).Expression.(*mt.NodeExprVarDefAssign); !ok || def.VarDef.Type == nil {
+		t.Fatalf("`as` variable declaration = %#v", statements[0])
+	}
+	product := statements[1].(*mt.NodeStmtExpr).Expression.(*mt.NodeExprVarDefAssign).AssignExpr.(*mt.NodeExprBinary)
+	if cast, ok := product.Left.(*mt.NodeExprCast); !ok || cast.Mode != mt.CastPlain {
+		t.Fatalf("product left = %#v, want plain cast", product.Left)
+	}
+	attempt := statements[2].(*mt.NodeStmtExpr).Expression.(*mt.NodeExprVarDefAssign).AssignExpr.(*mt.NodeExprTry)
+	if cast, ok := attempt.Call.(*mt.NodeExprCast); !ok || cast.Mode != mt.CastChecked {
+		t.Fatalf("try operand = %#v, want checked cast", attempt.Call)
+	}
+	named := statements[3].(*mt.NodeStmtExpr).Expression.(*mt.NodeExprVarDefAssign).AssignExpr.(*mt.NodeExprCast)
+	if named.Mode != mt.CastPlain || named.Type.KindNode.(*mt.NodeTypeNamed).NameNode.(*mt.NodeNameSingle).Name != "checked" {
+		t.Fatalf("cast to a type named like a mode = %#v", named)
+	}
+}
+
 func TestForLoopSyntaxErrors(t *testing.T) {
 	tests := map[string]struct {
 		body string
@@ -282,3 +377,172 @@ func TestParseCharacterizesPrematureEOF(t *testing.T) {
 		t.Fatalf("error = %v, want premature EOF diagnostic", err)
 	}
 }
+
+func TestParseRecoversAndReportsEverySyntaxError(t *testing.T) {
+	global, err := parseTestSource(t, `mod main
+broken(a i32 b i32) i32:
+    ret a
+..
+main() void:
+    x := 1 +
+    if x > :
+        y := 2
+    ..
+    z := 3
+    else:
+        w := 4
+    ..
+..
+after() void:
+..
+`)
+	diagnostics := comp_err.Diagnostics(err)
+	lines := []uint32{}
+	for _, diagnostic := range diagnostics {
+		lines = append(lines, diagnostic.Token.Pos.Line)
+	}
+	if !slices.Equal(lines, []uint32{2, 6, 7, 11}) {
+		t.Fatalf("syntax error lines = %v, want [2 6 7 11]; err = %v", lines, err)
+	}
+	if len(global.Declarations) != 3 {
+		t.Fatalf("declarations = %d, want 3", len(global.Declarations))
+	}
+	if node, ok := global.Declarations[0].(*mt.NodeError); !ok || node.Tk.Repr != "broken" {
+		t.Fatalf("first declaration = %#v, want error node at 'broken'", global.Declarations[0])
+	}
+	main, ok := global.FuncDefs["main"]
+	if !ok {
+		t.Fatal("main function missing")
+	}
+	kinds := []string{}
+	for _, statement := range main.Body.Statements {
+		if _, isError := statement.(*mt.NodeError); isError {
+			kinds = append(kinds, "error")
+		} else {
+			kinds = append(kinds, "ok")
+		}
+	}
+	if got := strings.Join(kinds, " "); got != "error error ok error" {
+		t.Fatalf("main statements = %s, want error error ok error", got)
+	}
+	if _, ok := global.FuncDefs["after"]; !ok {
+		t.Fatal("declaration after the syntax errors was not parsed")
+	}
+}
+
+func TestAllowDirectiveCoversFollowingDeclaration(t *testing.T) {
+	source := "mod main\n@allow(\"unused-function\", \"unused-variable\")\npub helper() void:\n    value := 1\n..\nother() void:\n..\n"
+	fctx := &mt.FileCtx{FilePath: "test.mg", Content: []byte(source), ImportAlias: map[string]string{}}
+	tokens, err := tokenizer.Tokenize(fctx, fctx.Content)
+	if err != nil {
+		t.Fatalf("tokenize: %v", err)
+	}
+	fctx.Tokens = tokens
+	if _, err := Parse(&mt.SharedState{ExportedSymbols: map[string]string{}}, fctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(fctx.Allowances) != 1 {
+		t.Fatalf("allowances = %#v", fctx.Allowances)
+	}
+	allowance := fctx.Allowances[0]
+	if allowance.Line != 2 || allowance.EndLine != 5 || len(allowance.Codes) != 2 {
+		t.Fatalf("allowance = %#v, want lines 2 through 5 with two codes", allowance)
+	}
+	if !fctx.Allows("unused-variable", 4) || fctx.Allows("unused-variable", 6) || fctx.Allows("unused-import", 3) {
+		t.Fatalf("allowance applied outside its declaration or codes")
+	}
+}
+
+func TestAllowDirectiveRequiresStringCodes(t *testing.T) {
+	for _, directive := range []string{"@allow", "@allow(1)"} {
+		_, err := parseTestSource(t, "mod main\n"+directive+"\nmain() void:\n..\n")
+		if err == nil || !strings.Contains(err.Error(), "allow") {
+			t.Fatalf("%s error = %v", directive, err)
+		}
+	}
+}
+
+func TestDeriveDirectiveRejectsInvalidUses(t *testing.T) {
+	for directive, want := range map[string]string{
+		"@derive()\nPoint(x i32)":                   "takes one or more operation names",
+		"@derive(\"compare\")\nPoint(x i32)":        "cannot derive 'compare'",
+		"@derive(1)\nPoint(x i32)":                  "cannot derive '1'",
+		"@derive(\"eq\", \"eq\")\nPoint(x i32)":     "'eq' is derived more than once",
+		"@derive(\"eq\")\nhelper() void:\n..":       "applies only to struct declarations",
+		"@derive(\"hash\")\nLimit u64 = 1\nX(v u8)": "applies only to struct declarations",
+	} {
+		_, err := parseTestSource(t, "mod main\n"+directive+"\nmain() void:\n..\n")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q error = %v, want %q", directive, err, want)
+		}
+	}
+}
+
+func TestIntrinsicDirectiveRejectsInvalidUses(t *testing.T) {
+	for directive, want := range map[string]string{
+		"@intrinsic\nload() void:\n..":                                            "takes one intrinsic name",
+		"@intrinsic(\"atomic.lod\")\nload() void:\n..":                            "unknown intrinsic 'atomic.lod'",
+		"@intrinsic(\"atomic.load\")\n@intrinsic(\"atomic.load\")\nf() void:\n..": "duplicate 'intrinsic' directive",
+		"@intrinsic(\"atomic.fence\")\nPoint(x i32)":                              "applies only to function declarations",
+		"@intrinsic(\"atomic.fence\")\nfence() void:\n    ret\n..":                "intrinsic 'fence' cannot have a body",
+	} {
+		_, err := parseTestSource(t, "mod main\n"+directive+"\nmain() void:\n..\n")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q error = %v, want %q", directive, err, want)
+		}
+	}
+}
+
+// parseDeriveSource parses source against the repository's standard library,
+// recording the imports the parser starts instead of running them.
+func parseDeriveSource(tt *testing.T, source string) (*mt.NodeGlobal, []string, error) {
+	tt.Helper()
+	stdRoot, err := filepath.Abs(filepath.Join("..", "..", "std"))
+	if err != nil {
+		tt.Fatal(err)
+	}
+	fctx := &mt.FileCtx{FilePath: filepath.Join(tt.TempDir(), "test.mg"), Content: []byte(source), ImportAlias: map[string]string{}}
+	tokens, err := tokenizer.Tokenize(fctx, fctx.Content)
+	if err != nil {
+		tt.Fatalf("tokenize: %v", err)
+	}
+	fctx.Tokens = tokens
+	imported := []string{}
+	shared := &mt.SharedState{ExportedSymbols: map[string]string{}, StdRoot: stdRoot}
+	shared.PipelineFunc = func(_ *mt.SharedState, path, alias, _ string, _ *mt.NodeGlobal) <-chan error {
+		imported = append(imported, alias+"="+filepath.Base(path))
+		done := make(chan error)
+		close(done)
+		return done
+	}
+	global, err := Parse(shared, fctx)
+	return global, imported, err
+}
+
+func TestDeriveSynthesizesMembersAndImportsSupport(t *testing.T) {
+	global, imported, err := parseDeriveSource(t, "mod main\n@derive(\"eq\", \"json\")\nPoint[T](x T, y T)\nPoint[T].hash() u64:\n    ret 0\n..\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(imported, []string{mt.DeriveImport + "=derive.mg"}) {
+		t.Fatalf("imports = %v, want std:derive once", imported)
+	}
+	def := global.StructDefs["Point"]
+	for _, method := range []string{"eq", "writeJson"} {
+		fn := def.Funcs[method]
+		if fn == nil || !fn.Derived {
+			t.Fatalf("member %s = %#v, want derived", method, fn)
+		}
+		if line := fn.Class.NameNode.(*mt.NodeNameComposite).Tokens[0].Pos.Line; line != 2 {
+			t.Fatalf("member %s reported on line %d, want the directive's line 2", method, line)
+		}
+	}
+	if def.Funcs["hash"].Derived {
+		t.Fatal("hand-written member marked derived")
+	}
+
+	_, _, err = parseDeriveSource(t, "mod main\n@derive(\"hash\")\nPoint(x i32)\nPoint.hash() u64:\n    ret 0\n..\n")
+	if err == nil || !strings.Contains(err.Error(), "already declares member 'hash'") {
+		t.Fatalf("conflicting member error = %v", err)
+	}
+}
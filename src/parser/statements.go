@@ -189,9 +189,13 @@ func parseBody(ctx *ParseCtx, tk t.Token) (t.NodeBody, error) {
 			return n, nil
 		}
 
+		start := ctx.TokIdx
 		stmtNode, e := parseStatement(ctx, tk)
 		if e != nil {
-			return t.NodeBody{}, e
+			stmtNode, e = recoverStatement(ctx, start, e)
+			if e != nil {
+				return t.NodeBody{}, e
+			}
 		}
 		n.Statements = append(n.Statements, stmtNode)
 	}
@@ -227,17 +231,23 @@ func parseDeferBody(ctx *ParseCtx, tk t.Token) (t.NodeBody, error) {
 		}
 
 		if tk.KeywType == t.KwDefer || tk.KeywType == t.KwOnError {
-			return n, comp_err.CompilationErrorToken(
+			nested, _ := recoverStatement(ctx, ctx.TokIdx, comp_err.CompilationErrorToken(
 				ctx.Fctx,
 				&tk,
 				"syntax error: cannot nest deferred statements",
 				"",
-			)
+			))
+			n.Statements = append(n.Statements, nested)
+			continue
 		}
 
+		start := ctx.TokIdx
 		stmtNode, e := parseStatement(ctx, tk)
 		if e != nil {
-			return t.NodeBody{}, e
+			stmtNode, e = recoverStatement(ctx, start, e)
+			if e != nil {
+				return t.NodeBody{}, e
+			}
 		}
 		n.Statements = append(n.Statements, stmtNode)
 	}
@@ -290,9 +300,13 @@ func parseIfBody(ctx *ParseCtx, tk t.Token, ifStmt *t.NodeStmtIf) (t.NodeBody, e
 			return n, nil
 		}
 
+		start := ctx.TokIdx
 		stmtNode, e := parseStatement(ctx, tk)
 		if e != nil {
-			return t.NodeBody{}, e
+			stmtNode, e = recoverStatement(ctx, start, e)
+			if e != nil {
+				return t.NodeBody{}, e
+			}
 		}
 		n.Statements = append(n.Statements, stmtNode)
 	}
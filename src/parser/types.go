@@ -208,11 +208,53 @@ func parseArgument(ctx *ParseCtx) (t.NodeArg, error) {
 		return t.NodeArg{}, e
 	}
 
-	return t.NodeArg{
+	arg := t.NodeArg{
 		Tk:       name,
 		Name:     name.Repr,
 		TypeNode: ndType,
-	}, nil
+	}
+	if eq, e := peek(ctx); e == nil && eq.KeywType == t.KwEqual {
+		consume(ctx)
+		first, e := peek(ctx)
+		if e != nil {
+			return t.NodeArg{}, e
+		}
+		arg.Default, e = parseExpression(ctx, first, 0)
+		if e != nil {
+			return t.NodeArg{}, e
+		}
+		_, end := ctx.Toks[ctx.TokIdx-1].Span()
+		if int(end.Offset) <= len(ctx.Fctx.Content) && first.Pos.Offset < end.Offset {
+			arg.DefaultSource = string(ctx.Fctx.Content[first.Pos.Offset:end.Offset])
+		}
+		if !isConstantDefault(arg.Default) {
+			return t.NodeArg{}, comp_err.CompilationErrorToken(
+				ctx.Fctx,
+				&first,
+				fmt.Sprintf("default value of '%s' must be a constant expression", name.Repr),
+				"use literals combined with operators, for example `= 8` or `= -1`",
+			)
+		}
+	}
+	return arg, nil
+}
+
+// isConstantDefault reports whether a default value is built only from
+// literals and operators. Names are left out: a default is evaluated at each
+// call site, where the declaring module's names may not be visible.
+func isConstantDefault(expr t.NodeExpr) bool {
+	switch n := expr.(type) {
+	case *t.NodeExprLit:
+		return true
+	case *t.NodeExprUnary:
+		switch n.Operator {
+		case t.KwMinus, t.KwExclam, t.KwTilde:
+			return isConstantDefault(n.Operand)
+		}
+	case *t.NodeExprBinary:
+		return isConstantDefault(n.Left) && isConstantDefault(n.Right)
+	}
+	return false
 }
 
 func parseArgsList(ctx *ParseCtx) (t.NodeArgList, error) {
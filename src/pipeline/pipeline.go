@@ -177,9 +177,9 @@ func pipelineSyncPrelude(shared *types.SharedState, c chan error, filePath strin
 
 func DoMain(shared *types.SharedState, filePath string) error {
 	shared.Target = magmatarget.WithCompilerKnownDefaults(shared.Target)
-	if err := Do(shared, filePath, "", filePath, nil); err != nil {
-		return err
-	}
+	// Syntax errors leave a recovered tree for the root. The support modules
+	// are loaded regardless, so that editors can analyze the rest of the file.
+	syntaxErr := Do(shared, filePath, "", filePath, nil)
 	mainFile := shared.Files[filePath]
 	if mainFile == nil {
 		absPath, err := makeabs.MakeAbs(filePath, filePath)
@@ -188,11 +188,14 @@ func DoMain(shared *types.SharedState, filePath string) error {
 		}
 	}
 	if mainFile == nil || mainFile.GlNode == nil {
+		if syntaxErr != nil {
+			return syntaxErr
+		}
 		return fmt.Errorf("main compilation unit was not registered")
 	}
 	corePath, err := findCorePath(shared)
 	if err != nil {
-		return err
+		return comp_err.Join(syntaxErr, err)
 	}
 	// Compiler support modules are compilation units, but they are not implicit
 	// source imports of the root. Their own source imports remain ordinary graph
@@ -200,14 +203,14 @@ func DoMain(shared *types.SharedState, filePath string) error {
 	// every real dependency of core or context_default when that dependency is
 	// opened as an editor root.
 	if err := Do(shared, corePath, "__core", mainFile.FilePath, mainFile.GlNode); err != nil {
-		return err
+		return comp_err.Join(syntaxErr, err)
 	}
 	contextDefaultPath := filepath.Join(shared.StdRoot, "context_default.mg")
 	if info, err := os.Stat(contextDefaultPath); err != nil || info.IsDir() {
-		return fmt.Errorf("standard library context_default module does not exist at %q", contextDefaultPath)
+		return comp_err.Join(syntaxErr, fmt.Errorf("standard library context_default module does not exist at %q", contextDefaultPath))
 	}
 	contextDefaultPath = filepath.Clean(contextDefaultPath)
-	return Do(shared, contextDefaultPath, "__context_default", mainFile.FilePath, mainFile.GlNode)
+	return comp_err.Join(syntaxErr, Do(shared, contextDefaultPath, "__context_default", mainFile.FilePath, mainFile.GlNode))
 }
 
 func findCorePath(shared *types.SharedState) (string, error) {
@@ -1,6 +1,7 @@
 package pipelineasync
 
 import (
+	"Magma/src/comp_err"
 	"Magma/src/debug"
 	"Magma/src/parser"
 	scopeinfo "Magma/src/scope_info"
@@ -27,12 +28,11 @@ func PipelineAsync(shared *types.SharedState, c chan error, fCtx *types.FileCtx,
 		tokenizer.PrintTokens(fCtx.Tokens)
 	}
 
-	fCtx.GlNode, err = parser.Parse(shared, fCtx)
-	if err != nil {
-		c <- err
-		close(c)
-		return
-	}
+	// The parser recovers from syntax errors and always returns a tree. Its
+	// scopes are still built so editors can analyze the rest of the file; the
+	// syntax errors are published with the unit's result.
+	var syntaxErr error
+	fCtx.GlNode, syntaxErr = parser.Parse(shared, fCtx)
 
 	if debug.Enabled() {
 		fCtx.GlNode.Print(0)
@@ -40,7 +40,7 @@ func PipelineAsync(shared *types.SharedState, c chan error, fCtx *types.FileCtx,
 
 	fCtx.ScopeTree, err = scopeinfo.BuildScopeTree(fCtx, fCtx.GlNode)
 	if err != nil {
-		c <- err
+		c <- comp_err.Join(syntaxErr, err)
 		close(c)
 		return
 	}
@@ -49,6 +49,6 @@ func PipelineAsync(shared *types.SharedState, c chan error, fCtx *types.FileCtx,
 		scopeinfo.PrintScopeTree(&fCtx.ScopeTree, 0)
 	}
 
-	c <- nil
+	c <- syntaxErr
 	close(c)
 }
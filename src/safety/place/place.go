@@ -5,8 +5,6 @@ package place
 import (
 	"Magma/src/types"
 	"fmt"
-	"strconv"
-	"strings"
 )
 
 type ProjectionKind uint8
@@ -194,16 +192,7 @@ func appendField(out *Place, access *types.MemberAccess, token types.Token) erro
 func constantIndex(expr types.NodeExpr) (uint64, bool) {
 	switch node := expr.(type) {
 	case *types.NodeExprLit:
-		if node.LitType != types.TokLitNum {
-			return 0, false
-		}
-		repr := strings.ReplaceAll(node.Value, "_", "")
-		base := 10
-		if len(repr) > 2 && repr[0] == '0' {
-			base = 0
-		}
-		value, err := strconv.ParseUint(repr, base, 64)
-		return value, err == nil
+		return node.UintValue()
 	case *types.NodeExprName:
 		variable, ok := node.AssociatedNode.(*types.NodeExprVarDef)
 		if !ok || !variable.IsConst || variable.Initializer == nil {
@@ -417,6 +417,13 @@ func bldGlobal(ctx *lcx, gl *t.NodeGlobal) error {
 	return nil
 }
 
+// DeclareFunction adds fn, created by a later stage, to the global scope of a
+// tree BuildScopeTree has already built.
+func DeclareFunction(fileCtx *t.FileCtx, global *t.Scope, fn *t.NodeFuncDef) error {
+	ctx := &lcx{GlScope: global, CurrScope: global, FileCtx: fileCtx}
+	return bldGlDecl(ctx, fn)
+}
+
 func BuildScopeTree(fileCtx *t.FileCtx, gl *t.NodeGlobal) (t.Scope, error) {
 	ctx := &lcx{FileCtx: fileCtx}
 
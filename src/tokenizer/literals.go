@@ -0,0 +1,231 @@
+package tokenizer
+
+import (
+	"Magma/src/comp_err"
+	t "Magma/src/types"
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// simpleEscape maps the character after a backslash to the rune it denotes.
+// Unknown escapes keep the escaped character.
+func simpleEscape(r rune) rune {
+	switch r {
+	case 'a':
+		return '\a'
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'v':
+		return '\v'
+	}
+	return r
+}
+
+// numericEscape decodes `xNN` or `u{N...}` at the start of text, which
+// follows a backslash. It returns the denoted value, whether it is a raw byte
+// rather than a code point, and the number of bytes consumed.
+func numericEscape(text []byte) (value rune, isByte bool, size int, err error) {
+	if len(text) > 0 && text[0] == 'x' {
+		if len(text) < 3 {
+			return 0, false, 0, fmt.Errorf("'\\x' must be followed by two hexadecimal digits")
+		}
+		byteValue, parseErr := strconv.ParseUint(string(text[1:3]), 16, 8)
+		if parseErr != nil {
+			return 0, false, 0, fmt.Errorf("'\\x' must be followed by two hexadecimal digits")
+		}
+		return rune(byteValue), true, 3, nil
+	}
+	end := bytes.IndexByte(text, '}')
+	if len(text) < 2 || text[1] != '{' || end < 3 || end > 8 {
+		return 0, false, 0, fmt.Errorf("'\\u' must be followed by one to six hexadecimal digits in braces, as in '\\u{1F600}'")
+	}
+	codePoint, parseErr := strconv.ParseUint(string(text[2:end]), 16, 32)
+	if parseErr != nil || codePoint > utf8.MaxRune || (codePoint >= 0xD800 && codePoint <= 0xDFFF) {
+		return 0, false, 0, fmt.Errorf("'\\u{%s}' is not a Unicode scalar value", text[2:end])
+	}
+	return rune(codePoint), false, end + 1, nil
+}
+
+// lexNumericEscape appends a `\x` or `\u{...}` string escape to the token
+// buffer. The backslash has been consumed and ctx.Idx points at 'x' or 'u'.
+func lexNumericEscape(ctx *TkCtx) error {
+	value, isByte, size, err := numericEscape(ctx.Content[ctx.Idx:])
+	if err != nil {
+		return comp_err.CompilationErrorToken(ctx.fCtx, &t.Token{Repr: "\\" + string(ctx.Content[ctx.Idx]), Pos: ctx.Pos}, "invalid escape sequence in string literal", err.Error())
+	}
+	if isByte {
+		ctx.TokReprBuff = append(ctx.TokReprBuff, byte(value))
+	} else {
+		ctx.TokReprBuff = utf8.AppendRune(ctx.TokReprBuff, value)
+	}
+	consumeSize(ctx, size)
+	ctx.Pos.Col += uint32(size) - 1
+	return nil
+}
+
+// lexChar reads a character literal starting at the opening quote. Its token
+// holds the decimal code point, or the byte value of a `\xNN` escape.
+func lexChar(ctx *TkCtx) error {
+	start := ctx.Pos
+	text := ctx.Content[ctx.Idx+1:]
+	fail := func(detail string) error {
+		end := min(len(text), max(strings.IndexAny(string(text), "'\n"), 0))
+		return comp_err.CompilationErrorToken(ctx.fCtx, &t.Token{Repr: "'" + string(text[:end]) + "'", Pos: start}, "invalid character literal", detail)
+	}
+	r, size := utf8.DecodeRune(text)
+	switch {
+	case size == 0 || r == '\n':
+		return fail("character literal is missing its closing quote")
+	case r == '\'':
+		return fail("character literal is empty; write a single character such as 'a'")
+	case r == utf8.RuneError && size == 1:
+		return fail("character literal is not valid UTF-8")
+	case r == '\\':
+		escaped, escapedSize := utf8.DecodeRune(text[size:])
+		switch escaped {
+		case 'x', 'u':
+			value, _, escapeSize, err := numericEscape(text[size:])
+			if err != nil {
+				return fail(err.Error())
+			}
+			r, size = value, size+escapeSize
+		default:
+			if escapedSize == 0 || escaped == '\n' {
+				return fail("character literal is missing its closing quote")
+			}
+			r, size = simpleEscape(escaped), size+escapedSize
+		}
+	}
+	if size >= len(text) || text[size] != '\'' {
+		return fail("character literals contain exactly one character; use double quotes for strings")
+	}
+	length := size + 2
+	runes := uint32(utf8.RuneCount(ctx.Content[ctx.Idx : ctx.Idx+length]))
+	end := t.FilePos{Line: start.Line, Col: start.Col + runes, Offset: start.Offset + uint32(length)}
+	pushToken(ctx, t.Token{Repr: strconv.Itoa(int(r)), Pos: start, End: end, Type: t.TokLitChar})
+	consumeSize(ctx, length)
+	ctx.Pos.Col += runes - 1
+	return nil
+}
+
+// lexRawString reads a backtick-delimited string starting at the opening
+// backtick. Its contents are kept verbatim, including newlines, except that
+// carriage returns are dropped so sources read the same on every platform.
+func lexRawString(ctx *TkCtx) error {
+	opening := ctx.Pos
+	pos := ctx.Pos
+	pos.Col++
+	pos.Offset++
+	tk := t.Token{Pos: pos, Type: t.TokLitStr}
+	var content strings.Builder
+	for idx := ctx.Idx + 1; idx < len(ctx.Content); {
+		r, size := utf8.DecodeRune(ctx.Content[idx:])
+		if r == utf8.RuneError && size == 1 {
+			return comp_err.CompilationErrorToken(ctx.fCtx, &t.Token{Repr: "`", Pos: pos}, "raw string literal is not valid UTF-8", "")
+		}
+		if r == '`' {
+			tk.Repr, tk.End = content.String(), pos
+			pushToken(ctx, tk)
+			consumeSize(ctx, idx+size-ctx.Idx)
+			ctx.Pos = pos
+			return nil
+		}
+		if r != '\r' {
+			content.WriteRune(r)
+		}
+		idx += size
+		pos.Offset = uint32(idx)
+		if r == '\n' {
+			pos.Line++
+			pos.Col = 1
+		} else {
+			pos.Col++
+		}
+	}
+	return comp_err.CompilationErrorToken(ctx.fCtx, &t.Token{Repr: "`", Pos: opening}, "unterminated raw string literal", "close the string with a matching '`'")
+}
+
+func isDigitOfBase(c byte, base int) bool {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') < base
+	case c >= 'a' && c <= 'f':
+		return base == 16
+	case c >= 'A' && c <= 'F':
+		return base == 16
+	}
+	return false
+}
+
+var (
+	// Integer literals must fit the widest integer types, i128 and u128.
+	minLiteral = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	maxLiteral = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+)
+
+// normalizeNumber validates a number token and returns the canonical spelling
+// carried by the AST: digit separators are removed, binary, octal, and
+// hexadecimal integers become LLVM's `u0x` form, and exponent floats always
+// contain a '.' as LLVM requires.
+func normalizeNumber(text string) (string, error) {
+	base, digits := 10, text
+	if len(text) > 1 && text[0] == '0' {
+		switch text[1] {
+		case 'x', 'X':
+			base, digits = 16, text[2:]
+		case 'b', 'B':
+			base, digits = 2, text[2:]
+		case 'o', 'O':
+			base, digits = 8, text[2:]
+		}
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] == '_' && (i == 0 || i == len(digits)-1 || !isDigitOfBase(digits[i-1], base) || !isDigitOfBase(digits[i+1], base)) {
+			return "", fmt.Errorf("'_' may only separate digits")
+		}
+	}
+	digits = strings.ReplaceAll(digits, "_", "")
+	if base != 10 {
+		value, ok := new(big.Int).SetString(digits, base)
+		if !ok {
+			return "", fmt.Errorf("expected base-%d digits after '%s'", base, text[:2])
+		}
+		if value.Cmp(maxLiteral) > 0 {
+			return "", fmt.Errorf("integer literal does not fit in 128 bits")
+		}
+		if base == 16 {
+			return "u0x" + digits, nil
+		}
+		return fmt.Sprintf("u0x%X", value), nil
+	}
+	if strings.ContainsAny(digits, ".eE") {
+		if _, err := strconv.ParseFloat(digits, 64); err != nil {
+			return "", fmt.Errorf("malformed floating-point literal")
+		}
+		if !strings.Contains(digits, ".") {
+			exponent := strings.IndexAny(digits, "eE")
+			digits = digits[:exponent] + ".0" + digits[exponent:]
+		}
+		return digits, nil
+	}
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return "", fmt.Errorf("malformed integer literal")
+	}
+	if value.Cmp(minLiteral) < 0 || value.Cmp(maxLiteral) > 0 {
+		return "", fmt.Errorf("integer literal does not fit in 128 bits")
+	}
+	return digits, nil
+}
@@ -16,7 +16,13 @@ const (
 	tkModeString
 	tkModeComment
 	tkModeNumber
-	tkModeHexNum
+	tkModeRadixNum
+)
+
+var (
+	radixPrefixes = map[rune]int{'x': 16, 'X': 16, 'o': 8, 'O': 8, 'b': 2, 'B': 2}
+	radixNames    = map[int]string{16: "hexadecimal", 10: "decimal", 8: "octal", 2: "binary"}
+	radixDigits   = map[int]string{16: "[0-9a-fA-F_]", 10: "[0-9._eE+-]", 8: "[0-7_]", 2: "[01_]"}
 )
 
 type TkCtx struct {
@@ -25,7 +31,7 @@ type TkCtx struct {
 	Content []byte
 
 	CurrTok     t.Token
-	TokReprBuff []rune
+	TokReprBuff []byte
 
 	Tokens []t.Token
 
@@ -34,6 +40,14 @@ type TkCtx struct {
 	Idx       int
 	Mode      tkMode
 	IsEscaped bool
+
+	// numBase is the radix of the integer literal read in tkModeRadixNum.
+	numBase int
+
+	// commentStart is the byte index of the current comment's '#', and
+	// commentTrailing records whether code precedes it on the same line.
+	commentStart    int
+	commentTrailing bool
 }
 
 func decodeFirst(ctx *TkCtx) (rune, int, error) {
@@ -100,6 +114,9 @@ func toggleMode(ctx *TkCtx, mode tkMode) {
 }
 
 func pushToken(ctx *TkCtx, tk t.Token) {
+	if tk.End.Line == 0 {
+		_, tk.End = tk.Span()
+	}
 	ctx.Tokens = append(ctx.Tokens, tk)
 }
 
@@ -110,20 +127,31 @@ func clearTokenBuff(ctx *TkCtx) {
 	}
 	ctx.CurrTok.Pos.Col++
 
-	ctx.TokReprBuff = make([]rune, 0, 16)
+	ctx.TokReprBuff = make([]byte, 0, 16)
 }
 
 func pushTokenAndClearBuff(ctx *TkCtx, tk t.Token) {
-	ctx.Tokens = append(ctx.Tokens, tk)
+	pushToken(ctx, tk)
 	clearTokenBuff(ctx)
 }
 
-func pushTokenBuff(ctx *TkCtx) {
+func pushTokenBuff(ctx *TkCtx) error {
 	if len(ctx.TokReprBuff) == 0 && ctx.CurrTok.Type != t.TokLitStr {
-		return
+		return nil
 	}
 
 	ctx.CurrTok.Repr = string(ctx.TokReprBuff)
+	// The buffered token ends where the rune which terminated it begins; for
+	// strings that is the closing quote, matching Pos at the first inner rune.
+	ctx.CurrTok.End = ctx.Pos
+
+	if ctx.CurrTok.Type == t.TokLitNum {
+		normalized, err := normalizeNumber(ctx.CurrTok.Repr)
+		if err != nil {
+			return comp_err.CompilationErrorToken(ctx.fCtx, &ctx.CurrTok, fmt.Sprintf("invalid number literal '%s'", ctx.CurrTok.Repr), err.Error())
+		}
+		ctx.CurrTok.Repr = normalized
+	}
 
 	if ctx.CurrTok.Type == t.TokNone || ctx.CurrTok.Type == t.TokName {
 		kwType, ok := t.KwReprToType[ctx.CurrTok.Repr]
@@ -135,6 +163,7 @@ func pushTokenBuff(ctx *TkCtx) {
 
 	pushToken(ctx, ctx.CurrTok)
 	clearTokenBuff(ctx)
+	return nil
 }
 
 func handleNonAlphaKeyword(ctx *TkCtx, first rune) (t.Token, int, error) {
@@ -193,7 +222,7 @@ func Tokenize(fCtx *t.FileCtx, bytes []byte) (tokens []t.Token, err error) {
 				Col:  0,
 			},
 		},
-		TokReprBuff: make([]rune, 0, 16),
+		TokReprBuff: make([]byte, 0, 16),
 		Tokens:      make([]t.Token, 0, 256),
 
 		Pos: t.FilePos{
@@ -216,9 +245,11 @@ func Tokenize(fCtx *t.FileCtx, bytes []byte) (tokens []t.Token, err error) {
 		}
 
 		ctx.Pos.Col++
+		ctx.Pos.Offset = uint32(ctx.Idx)
 
 		if r == '\n' && ctx.Mode == tkModeComment {
 			ctx.Mode = tkModeNormal
+			recordPragma(ctx)
 
 			pushToken(ctx, t.Token{
 				Repr:     "\n",
@@ -239,48 +270,62 @@ func Tokenize(fCtx *t.FileCtx, bytes []byte) (tokens []t.Token, err error) {
 			continue
 		}
 
-		if (ctx.Mode == tkModeNormal || ctx.Mode == tkModeNumber || ctx.Mode == tkModeHexNum) && r == '#' {
-			pushTokenBuff(ctx)
+		lexingCode := ctx.Mode == tkModeNormal || ctx.Mode == tkModeNumber || ctx.Mode == tkModeRadixNum
+
+		if lexingCode && r == '#' {
+			if err := pushTokenBuff(ctx); err != nil {
+				return nil, err
+			}
 			toggleMode(ctx, tkModeComment)
+			ctx.commentStart = ctx.Idx
+			last := len(ctx.Tokens) - 1
+			ctx.commentTrailing = last >= 0 && ctx.Tokens[last].Pos.Line == ctx.Pos.Line && ctx.Tokens[last].KeywType != t.KwNewline
 
 			consume(ctx)
 			continue
 		}
 
+		if lexingCode && (r == '\'' || r == '`') {
+			if err := pushTokenBuff(ctx); err != nil {
+				return nil, err
+			}
+			ctx.Mode = tkModeNormal
+			if r == '\'' {
+				err = lexChar(ctx)
+			} else {
+				err = lexRawString(ctx)
+			}
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		if r == '"' && !ctx.IsEscaped {
-			pushTokenBuff(ctx)
+			if err := pushTokenBuff(ctx); err != nil {
+				return nil, err
+			}
 			toggleMode(ctx, tkModeString)
 
 			if ctx.Mode == tkModeString {
 				ctx.CurrTok.Type = t.TokLitStr
+				ctx.CurrTok.Pos = ctx.Pos
+				ctx.CurrTok.Pos.Col++
+				ctx.CurrTok.Pos.Offset++
 			}
 			consume(ctx)
 			continue
 		}
 
 		if ctx.Mode == tkModeString && ctx.IsEscaped {
-			switch r {
-			case 'a':
-				r = '\a'
-			case 'b':
-				r = '\b'
-			case 'f':
-				r = '\f'
-			case 'n':
-				r = '\n'
-			case 'r':
-				r = '\r'
-			case 't':
-				r = '\t'
-			case 'v':
-				r = '\v'
-			case '\\':
-				r = '\\'
-			case '\'':
-				r = '\''
-			case '"':
-				r = '"'
+			if r == 'x' || r == 'u' {
+				ctx.IsEscaped = false
+				if err := lexNumericEscape(ctx); err != nil {
+					return nil, err
+				}
+				continue
 			}
+			r = simpleEscape(r)
 		}
 
 		if ctx.Mode == tkModeString && r == '\\' && !ctx.IsEscaped {
@@ -291,8 +336,10 @@ func Tokenize(fCtx *t.FileCtx, bytes []byte) (tokens []t.Token, err error) {
 
 		ctx.IsEscaped = false
 
-		if (ctx.Mode == tkModeNormal || ctx.Mode == tkModeNumber || ctx.Mode == tkModeHexNum) && unicode.IsSpace(r) {
-			pushTokenBuff(ctx)
+		if lexingCode && unicode.IsSpace(r) {
+			if err := pushTokenBuff(ctx); err != nil {
+				return nil, err
+			}
 
 			if r == '\n' {
 				pushToken(ctx, t.Token{
@@ -332,22 +379,29 @@ func Tokenize(fCtx *t.FileCtx, bytes []byte) (tokens []t.Token, err error) {
 				runes, err := peekMany(ctx, 2)
 				if err != nil {
 					goto write_num
-				} else if runes[1] == 'x' || runes[1] == 'X' {
-					ctx.TokReprBuff = append(ctx.TokReprBuff, 'u') // prefix for LLVM
-					ctx.Mode = tkModeHexNum
+				}
+				if base, ok := radixPrefixes[runes[1]]; ok {
+					ctx.numBase = base
+					ctx.Mode = tkModeRadixNum
 				} else {
 					goto write_num
 				}
 			}
 		}
 
-		if (ctx.Mode == tkModeNormal || ctx.Mode == tkModeNumber || ctx.Mode == tkModeHexNum) && !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+		if lexingCode && !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
 
 			if ctx.Mode == tkModeNumber && r == '.' {
 				goto write_num
 			}
+			// The sign of an exponent belongs to the number: `1.5e-3`.
+			if last := len(ctx.TokReprBuff) - 1; ctx.Mode == tkModeNumber && (r == '-' || r == '+') && last >= 0 && (ctx.TokReprBuff[last] == 'e' || ctx.TokReprBuff[last] == 'E') {
+				goto write_num
+			}
 
-			pushTokenBuff(ctx)
+			if err := pushTokenBuff(ctx); err != nil {
+				return nil, err
+			}
 			ctx.Mode = tkModeNormal
 
 			tk, size, err := handleNonAlphaKeyword(ctx, r)
@@ -356,31 +410,34 @@ func Tokenize(fCtx *t.FileCtx, bytes []byte) (tokens []t.Token, err error) {
 			}
 			pushTokenAndClearBuff(ctx, tk)
 			consumeSize(ctx, size)
+			// The loop advances the column once per iteration; account for the
+			// remaining runes of multi-rune operators such as `:=`.
+			ctx.Pos.Col += uint32(utf8.RuneCountInString(tk.Repr)) - 1
 			continue
 		}
 
 	write_num:
 		if ctx.Mode == tkModeNumber {
-			if !(unicode.IsDigit(r) || r == '.' || r == '-') {
-				ctx.TokReprBuff = append(ctx.TokReprBuff, r)
+			if !(unicode.IsDigit(r) || r == '.' || r == '-' || r == '+' || r == '_' || r == 'e' || r == 'E') {
+				ctx.TokReprBuff = utf8.AppendRune(ctx.TokReprBuff, r)
 				return nil, comp_err.CompilationErrorToken(
 					ctx.fCtx,
 					&t.Token{Repr: string(ctx.TokReprBuff), Pos: ctx.CurrTok.Pos},
 					fmt.Sprintf("invalid character '%c' in number literal", r),
-					"valid characters in number literal are: [0-9.]",
+					fmt.Sprintf("valid characters in a %s literal are: %s; prefix it with 0x, 0o or 0b for %s, %s or %s digits, or quote a single character as 'c'", radixNames[10], radixDigits[10], radixNames[16], radixNames[8], radixNames[2]),
 				)
 			}
 		}
 
-		if ctx.Mode == tkModeHexNum {
-			isPrefixMarker := (r == 'x' || r == 'X') && string(ctx.TokReprBuff) == "u0"
-			if !(isPrefixMarker || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')) {
-				ctx.TokReprBuff = append(ctx.TokReprBuff, r)
+		if ctx.Mode == tkModeRadixNum {
+			isPrefixMarker := radixPrefixes[r] == ctx.numBase && string(ctx.TokReprBuff) == "0"
+			if !(isPrefixMarker || r == '_' || (r < utf8.RuneSelf && isDigitOfBase(byte(r), ctx.numBase))) {
+				ctx.TokReprBuff = utf8.AppendRune(ctx.TokReprBuff, r)
 				return nil, comp_err.CompilationErrorToken(
 					ctx.fCtx,
 					&t.Token{Repr: string(ctx.TokReprBuff), Pos: ctx.CurrTok.Pos},
-					fmt.Sprintf("invalid character '%c' in hex number literal", r),
-					"valid characters in a hexadecimal literal are: [0-9a-fA-F]",
+					fmt.Sprintf("invalid character '%c' in %s number literal", r, radixNames[ctx.numBase]),
+					fmt.Sprintf("valid characters in a %s literal are: %s", radixNames[ctx.numBase], radixDigits[ctx.numBase]),
 				)
 			}
 		}
@@ -389,17 +446,55 @@ func Tokenize(fCtx *t.FileCtx, bytes []byte) (tokens []t.Token, err error) {
 			ctx.CurrTok.Pos = ctx.Pos
 		}
 
-		ctx.TokReprBuff = append(ctx.TokReprBuff, r)
+		ctx.TokReprBuff = utf8.AppendRune(ctx.TokReprBuff, r)
+		if ctx.Mode == tkModeString && r == '\n' && ctx.Content[ctx.Idx] == '\n' {
+			// A literal line break inside a string continues on the next line.
+			ctx.Pos.Line++
+			ctx.Pos.Col = 0
+		}
 		consume(ctx)
 		continue
 	}
 
+	if ctx.Mode == tkModeComment {
+		recordPragma(ctx)
+	}
+	if ctx.Mode == tkModeString {
+		return nil, comp_err.CompilationErrorToken(ctx.fCtx, &ctx.CurrTok, "unterminated string literal", "close the string with a matching '\"'")
+	}
 	if len(ctx.TokReprBuff) > 0 {
-		pushTokenBuff(ctx)
+		ctx.Pos.Col++
+		ctx.Pos.Offset = uint32(ctx.Idx)
+		if err := pushTokenBuff(ctx); err != nil {
+			return nil, err
+		}
 	}
 	return ctx.Tokens, nil
 }
 
+// recordPragma registers a `# @allow("code", ...)` comment which just ended.
+// After code it suppresses warnings on its own line; on a line of its own it
+// suppresses warnings on the next line.
+func recordPragma(ctx *TkCtx) {
+	text := strings.TrimSpace(string(ctx.Content[ctx.commentStart+1 : ctx.Idx]))
+	if ctx.fCtx == nil || !strings.HasPrefix(text, "@allow(") || !strings.HasSuffix(text, ")") {
+		return
+	}
+	codes := []string{}
+	for _, code := range strings.Split(text[len("@allow("):len(text)-1], ",") {
+		if code = strings.Trim(strings.TrimSpace(code), `"`); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	line := ctx.Pos.Line
+	if !ctx.commentTrailing {
+		line++
+	}
+	if len(codes) > 0 {
+		ctx.fCtx.Allowances = append(ctx.fCtx.Allowances, t.WarningAllowance{Codes: codes, Line: line, EndLine: line})
+	}
+}
+
 func PrintTokens(toks []t.Token) {
 	fmt.Printf("Tokens:\n")
 	for _, tk := range toks {
@@ -35,3 +35,93 @@ func TestSingleTrailingNumberDoesNotBecomeDecodeFailure(t *testing.T) {
 		t.Fatalf("tokens = %#v", tokens)
 	}
 }
+
+func TestAllowCommentPragmasTargetTheirLine(t *testing.T) {
+	source := "mod main\nvalue := 1 # @allow(\"unused-global\")\n# @allow(numeric-conversion, unused-variable)\nother := 2\n# allow(not-a-pragma)\n# @allow(\"cleanup\")"
+	ctx := &types.FileCtx{FilePath: "pragma.mg", Content: []byte(source)}
+	if _, err := Tokenize(ctx, ctx.Content); err != nil {
+		t.Fatal(err)
+	}
+	if !ctx.Allows("unused-global", 2) || ctx.Allows("unused-global", 3) {
+		t.Fatalf("trailing pragma did not apply to its own line: %#v", ctx.Allowances)
+	}
+	if !ctx.Allows("numeric-conversion", 4) || !ctx.Allows("unused-variable", 4) || ctx.Allows("unused-variable", 3) {
+		t.Fatalf("standalone pragma did not apply to the next line: %#v", ctx.Allowances)
+	}
+	if len(ctx.Allowances) != 3 || !ctx.Allows("cleanup", 7) {
+		t.Fatalf("allowances = %#v", ctx.Allowances)
+	}
+}
+
+func TestTokensRecordByteOffsetsAndEndPositions(t *testing.T) {
+	source := "x := \"é\" <= y\nlast"
+	tokens, err := Tokenize(&types.FileCtx{FilePath: "span.mg"}, []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		text       string
+		col, width uint32
+	}{{"x", 1, 1}, {":=", 3, 2}, {"é", 7, 1}, {"<=", 10, 2}, {"y", 13, 1}, {"\n", 14, 1}, {"last", 1, 4}}
+	if len(tokens) != len(want) {
+		t.Fatalf("tokens = %#v", tokens)
+	}
+	for i, tk := range tokens {
+		if got := source[tk.Pos.Offset:tk.End.Offset]; got != want[i].text {
+			t.Errorf("token %d covers %q, want %q", i, got, want[i].text)
+		}
+		if tk.Pos.Col != want[i].col || tk.End.Col-tk.Pos.Col != want[i].width || tk.End.Line != tk.Pos.Line {
+			t.Errorf("token %q spans %v-%v, want column %d width %d", tk.Repr, tk.Pos, tk.End, want[i].col, want[i].width)
+		}
+	}
+}
+
+func TestRichLiteralsAreNormalized(t *testing.T) {
+	source := "'a' '\\n' '\\u{1F600}' '\\xFF' 0b1010_0101 0o17 0xF_F 1_000 1.5e-3 2E5 \"\\x41\\u{e9}\" `raw \"text\"\r\n\\n`"
+	tokens, err := Tokenize(&types.FileCtx{FilePath: "literals.mg"}, []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		kind types.TokType
+		repr string
+	}{
+		{types.TokLitChar, "97"}, {types.TokLitChar, "10"}, {types.TokLitChar, "128512"}, {types.TokLitChar, "255"},
+		{types.TokLitNum, "u0xA5"}, {types.TokLitNum, "u0xF"}, {types.TokLitNum, "u0xFF"},
+	}
+	if len(tokens) < len(want) {
+		t.Fatalf("tokens = %#v", tokens)
+	}
+	for i, expected := range want {
+		if tokens[i].Type != expected.kind || tokens[i].Repr != expected.repr {
+			t.Errorf("token %d = %s %q, want %s %q", i, types.TokTypeToRepr[tokens[i].Type], tokens[i].Repr, types.TokTypeToRepr[expected.kind], expected.repr)
+		}
+	}
+	if _, err := Tokenize(&types.FileCtx{FilePath: "literals.mg"}, []byte("0x_ff")); err == nil {
+		t.Error("separator directly after a radix prefix was accepted")
+	}
+	rest := tokens[len(want):]
+	if len(rest) != 5 || rest[0].Repr != "1000" || rest[1].Repr != "1.5e-3" || rest[2].Repr != "2.0E5" || rest[3].Repr != "A\u00e9" || rest[4].Repr != "raw \"text\"\n\\n" {
+		t.Fatalf("remaining tokens = %#v", rest)
+	}
+	if raw := rest[4]; raw.Pos.Line != 1 || raw.End.Line != 2 || raw.End.Col != 3 {
+		t.Fatalf("raw string spans %v-%v, want it to end on line 2", raw.Pos, raw.End)
+	}
+}
+
+func TestNumberLiteralHintsNameTheRadix(t *testing.T) {
+	for source, hint := range map[string]string{"12z": "decimal literal are: [0-9._eE+-]; prefix it with 0x, 0o or 0b", "0o19": "octal literal are: [0-7_]"} {
+		_, err := Tokenize(&types.FileCtx{FilePath: "bad.mg"}, []byte(source))
+		if diagnostics := comp_err.Diagnostics(err); len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Additional, hint) {
+			t.Errorf("%q: diagnostics = %#v", source, diagnostics)
+		}
+	}
+}
+
+func TestMalformedLiteralsAreRejected(t *testing.T) {
+	for _, source := range []string{"''", "'ab'", "'a", "0b102", "0o8", "1__0", "10_", "1.5e", "0x", "\"\\x4\"", "\"\\u{D800}\"", "`open", "\"open", "340282366920938463463374607431768211456"} {
+		if _, err := Tokenize(&types.FileCtx{FilePath: "bad.mg"}, []byte(source)); err == nil {
+			t.Errorf("%q was accepted", source)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package types
+
+import "reflect"
+
+// WalkAST visits, in pre-order, the values reachable from root through the
+// fields written in source: each non-nil pointer once, and each struct, slice
+// element, and map value. Fields set by semantic analysis are not followed, so
+// a walk stays within the source text of root. visit returns whether to walk
+// the children of the value it was given.
+func WalkAST(root any, visit func(any) bool) {
+	seen := map[uintptr]bool{}
+	var walk func(reflect.Value)
+	walk = func(value reflect.Value) {
+		switch value.Kind() {
+		case reflect.Interface:
+			if !value.IsNil() {
+				walk(value.Elem())
+			}
+		case reflect.Pointer:
+			if value.IsNil() || seen[value.Pointer()] {
+				return
+			}
+			seen[value.Pointer()] = true
+			if visit(value.Interface()) {
+				walk(value.Elem())
+			}
+		case reflect.Struct:
+			if !visit(value.Interface()) {
+				return
+			}
+			valueType := value.Type()
+			for i := 0; i < value.NumField(); i++ {
+				field := valueType.Field(i)
+				if field.PkgPath == "" && !SemanticField(field.Name) {
+					walk(value.Field(i))
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				walk(value.Index(i))
+			}
+		case reflect.Map:
+			iterator := value.MapRange()
+			for iterator.Next() {
+				walk(iterator.Value())
+			}
+		}
+	}
+	walk(reflect.ValueOf(root))
+}
+
+// SemanticField reports whether a node field of this name links to what
+// semantic analysis resolved or inferred, such as a declaration, scope, or
+// type, rather than holding a child written in source. A field added to a node
+// for analysis results belongs here, which keeps every AST walk from
+// following it.
+func SemanticField(name string) bool {
+	switch name {
+	case "Parent", "Scope", "AssociatedNode", "AssociatedFnDef", "Destructor", "Destructors",
+		"Implementation", "Upcast", "ProtoDispatch", "Devirtualized",
+		"InfType", "ThrowingType", "MemberOwnerType":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkASTFollowsSourceChildrenOnly(t *testing.T) {
+	declaration := &NodeFuncDef{}
+	inferred := &NodeType{}
+	operand := &NodeExprLit{Value: "1"}
+	skipped := &NodeExprLit{Value: "2"}
+	call := &NodeExprCall{
+		Args:            []NodeExpr{operand, &NodeExprUnary{Operand: skipped}},
+		AssociatedFnDef: declaration,
+		InfType:         inferred,
+	}
+	visited := map[any]bool{}
+	WalkAST(call, func(value any) bool {
+		if reflect.ValueOf(value).Kind() == reflect.Pointer {
+			visited[value] = true
+		}
+		_, unary := value.(*NodeExprUnary)
+		return !unary
+	})
+	if !visited[call] || !visited[operand] {
+		t.Fatalf("walk missed source children: %v", visited)
+	}
+	if visited[declaration] || visited[inferred] {
+		t.Fatal("walk followed a field set by semantic analysis")
+	}
+	if visited[skipped] {
+		t.Fatal("walk entered a value whose visit returned false")
+	}
+}
@@ -16,6 +16,9 @@ func SourceName(name string) string {
 		if generic := strings.Index(part, "__g__"); generic >= 0 {
 			parts[i] = part[:generic]
 		}
+		if requirement, ok := ProtoDefaultRequirement(parts[i]); ok {
+			parts[i] = requirement
+		}
 	}
 	return strings.Join(parts, ".")
 }
@@ -0,0 +1,87 @@
+package types
+
+// IntrinsicKind marks a compiler-known function declared with `@intrinsic`.
+// Its declaration has no body: the checker validates each call and lowering
+// emits the operation in place of the call.
+type IntrinsicKind uint8
+
+const (
+	IntrinsicNone IntrinsicKind = iota
+	IntrinsicAtomicLoad
+	IntrinsicAtomicStore
+	IntrinsicAtomicExchange
+	IntrinsicAtomicCompareExchange
+	IntrinsicAtomicCompareExchangeWeak
+	IntrinsicAtomicFetchAdd
+	IntrinsicAtomicFetchSub
+	IntrinsicAtomicFetchAnd
+	IntrinsicAtomicFetchOr
+	IntrinsicAtomicFetchXor
+	IntrinsicAtomicFence
+)
+
+// Intrinsics maps the names accepted by `@intrinsic` to their kinds.
+var Intrinsics = map[string]IntrinsicKind{
+	"atomic.load":                IntrinsicAtomicLoad,
+	"atomic.store":               IntrinsicAtomicStore,
+	"atomic.exchange":            IntrinsicAtomicExchange,
+	"atomic.compareExchange":     IntrinsicAtomicCompareExchange,
+	"atomic.compareExchangeWeak": IntrinsicAtomicCompareExchangeWeak,
+	"atomic.fetchAdd":            IntrinsicAtomicFetchAdd,
+	"atomic.fetchSub":            IntrinsicAtomicFetchSub,
+	"atomic.fetchAnd":            IntrinsicAtomicFetchAnd,
+	"atomic.fetchOr":             IntrinsicAtomicFetchOr,
+	"atomic.fetchXor":            IntrinsicAtomicFetchXor,
+	"atomic.fence":               IntrinsicAtomicFence,
+}
+
+// IntrinsicName returns the `@intrinsic` name of kind.
+func IntrinsicName(kind IntrinsicKind) string {
+	for name, candidate := range Intrinsics {
+		if candidate == kind {
+			return name
+		}
+	}
+	return ""
+}
+
+// AtomicOrderingCount is the number of trailing ordering arguments taken by
+// an atomic intrinsic.
+func AtomicOrderingCount(kind IntrinsicKind) int {
+	switch kind {
+	case IntrinsicNone:
+		return 0
+	case IntrinsicAtomicCompareExchange, IntrinsicAtomicCompareExchangeWeak:
+		return 2
+	}
+	return 1
+}
+
+// AtomicOrdering is the memory ordering of an atomic intrinsic. The values
+// are those of the ordering constants of std:atomic.
+type AtomicOrdering uint8
+
+const (
+	AtomicRelaxed AtomicOrdering = iota
+	AtomicAcquire
+	AtomicRelease
+	AtomicAcqRel
+	AtomicSeqCst
+)
+
+// String returns the LLVM spelling of the ordering.
+func (o AtomicOrdering) String() string {
+	switch o {
+	case AtomicRelaxed:
+		return "monotonic"
+	case AtomicAcquire:
+		return "acquire"
+	case AtomicRelease:
+		return "release"
+	case AtomicAcqRel:
+		return "acq_rel"
+	case AtomicSeqCst:
+		return "seq_cst"
+	}
+	return ""
+}
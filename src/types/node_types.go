@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -281,6 +282,34 @@ func (n *NodeExprLit) GetInferredType() *NodeType {
 	return n.InfType
 }
 
+// UintValue returns the value of a non-negative integer or character literal.
+// It accepts source spellings as well as the tokenizer's canonical ones, in
+// which radix literals use LLVM's `u0x` form.
+func (n *NodeExprLit) UintValue() (uint64, bool) {
+	if n.LitType != TokLitNum && n.LitType != TokLitChar {
+		return 0, false
+	}
+	repr := strings.TrimPrefix(strings.ReplaceAll(n.Value, "_", ""), "u")
+	base := 10
+	if len(repr) > 2 && repr[0] == '0' && strings.ContainsRune("xXbBoO", rune(repr[1])) {
+		base = 0
+	}
+	value, err := strconv.ParseUint(repr, base, 64)
+	return value, err == nil
+}
+
+// IsFloat reports whether a number literal has a fraction or an exponent. Like
+// UintValue it accepts both source and canonical radix spellings, whose hex
+// digits may include 'e'.
+func (n *NodeExprLit) IsFloat() bool {
+	if n.LitType != TokLitNum {
+		return false
+	}
+	repr := strings.TrimPrefix(n.Value, "u")
+	radix := len(repr) > 2 && repr[0] == '0' && strings.ContainsRune("xXbBoO", rune(repr[1]))
+	return !radix && strings.ContainsAny(repr, ".eE")
+}
+
 func (n *NodeExprLit) Print(indent int) {
 	PrintIndent(indent)
 	fmt.Printf("ExprLit(type=%s, '%s')\n", TokTypeToRepr[n.LitType], strings.ReplaceAll(n.Value, "\n", "\\n"))
@@ -335,6 +364,10 @@ type NodeExprCall struct {
 	Callee      NodeExpr
 	Args        []NodeExpr
 	GenericArgs []*NodeType
+	// ArgNames parallels Args with the name of each `name=value` argument; a
+	// positional argument has an empty name. Linking puts the arguments in
+	// parameter order, adding defaults, and reorders the names with them.
+	ArgNames []Token
 
 	AssociatedFnDef *NodeFuncDef
 	InfType         *NodeType
@@ -356,6 +389,14 @@ type NodeExprCall struct {
 	IsFuncPointer bool
 	FuncPtrType   *NodeType
 	FuncPtrOwner  *NodeExprName
+
+	// Devirtualized is the function a prototype dispatch call is known to
+	// reach. Lowering calls it directly: with the implementation pointer of
+	// the view, or with the view itself when it is a default body.
+	Devirtualized *NodeFuncDef
+	// Orderings are the memory orderings of a call to an atomic intrinsic,
+	// decoded by the type checker from its constant ordering arguments.
+	Orderings []AtomicOrdering
 }
 
 type NodeStructFieldInit struct {
@@ -372,6 +413,9 @@ type NodeExprStructInit struct {
 	Type   *NodeType
 	Fields []NodeStructFieldInit
 	Tk     Token
+	// Defaults are the fields the constructor omits, in declaration order,
+	// resolved by linking. Each holds the field's declared default value.
+	Defaults []NodeStructFieldInit
 }
 
 // NodeExprProtoView creates the two-word borrowed view of an implementation.
@@ -382,6 +426,10 @@ type NodeExprProtoView struct {
 	Implementation  *ProtoImpl
 	TargetIsPointer bool
 	InfType         *NodeType
+	// Upcast is set when Target is itself a view of a prototype which extends
+	// ProtoType: the `extends` relations leading from the target's prototype
+	// to ProtoType. Implementation is then the last of them.
+	Upcast []*ProtoImpl
 }
 
 func (n *NodeExprProtoView) GetInferredType() *NodeType { return n.InfType }
@@ -421,7 +469,11 @@ func (n *NodeExprCall) Print(indent int) {
 
 	PrintIndent(indent + 1)
 	fmt.Printf("ArgExprs\n")
-	for _, expr := range n.Args {
+	for i, expr := range n.Args {
+		if i < len(n.ArgNames) && n.ArgNames[i].Repr != "" {
+			PrintIndent(indent + 2)
+			fmt.Printf("Named(%s)\n", n.ArgNames[i].Repr)
+		}
 		expr.Print(indent + 2)
 	}
 	if len(n.GenericArgs) > 0 {
@@ -704,6 +756,49 @@ func (n *NodeExprMove) Print(indent int) {
 	n.Expr.Print(indent + 1)
 }
 
+// CastMode selects how an explicit numeric cast treats a value which the
+// target type cannot represent.
+type CastMode uint8
+
+const (
+	// CastPlain converts exactly like an implicit numeric conversion.
+	CastPlain CastMode = iota
+	// CastWrapping keeps the low bits of an integer in two's complement.
+	CastWrapping
+	// CastSaturating clamps to the nearest value of the integer target.
+	CastSaturating
+	// CastChecked throws when the value is outside the integer target's range.
+	CastChecked
+)
+
+var CastModeToRepr []string = []string{
+	CastPlain:      "",
+	CastWrapping:   "wrapping",
+	CastSaturating: "saturating",
+	CastChecked:    "checked",
+}
+
+// NodeExprCast is an explicit numeric conversion written `value as T`, with an
+// optional `wrapping`, `saturating`, or `checked` mode before the type.
+type NodeExprCast struct {
+	Tk   Token
+	Expr NodeExpr
+	Type *NodeType
+	Mode CastMode
+	// ErrorMode records, for a checked cast, whether an enclosing try (1) or
+	// destructuring assignment (2) handles the range failure.
+	ErrorMode uint8
+	InfType   *NodeType
+}
+
+func (n *NodeExprCast) GetInferredType() *NodeType { return n.InfType }
+func (n *NodeExprCast) Print(indent int) {
+	PrintIndent(indent)
+	fmt.Printf("ExprCast %s\n", CastModeToRepr[n.Mode])
+	n.Expr.Print(indent + 1)
+	n.Type.Print(indent + 1)
+}
+
 func (n *NodeExprAddrof) GetInferredType() *NodeType {
 	//fmt.Println("ExprAddrof")
 	return n.InfType
@@ -874,6 +969,19 @@ func (n *NodeStmtExpr) Print(indent int) {
 	n.Expression.Print(indent + 1)
 }
 
+// NodeError stands in for a statement or global declaration which failed to
+// parse. The parser records the syntax error and resumes after the offending
+// line, so later passes still see the rest of the file; they treat the node as
+// empty.
+type NodeError struct {
+	Tk Token
+}
+
+func (n *NodeError) Print(indent int) {
+	PrintIndent(indent)
+	fmt.Printf("Error '%s'\n", n.Tk.Repr)
+}
+
 type NodeStmtThrow struct {
 	Tk         Token
 	Expression NodeExpr
@@ -939,12 +1047,19 @@ type NodeArg struct {
 	Tk       Token
 	Name     string
 	TypeNode *NodeType
+	// Default is the constant expression a call which omits the parameter
+	// passes in its place, and DefaultSource its text for signatures.
+	Default       NodeExpr
+	DefaultSource string
 }
 
 func (n *NodeArg) Print(indent int) {
 	PrintIndent(indent)
 	fmt.Printf("Arg(name=%s)\n", n.Name)
 	n.TypeNode.Print(indent + 1)
+	if n.Default != nil {
+		n.Default.Print(indent + 1)
+	}
 }
 
 type NodeArgList struct {
@@ -1034,8 +1149,16 @@ type NodeFuncDef struct {
 	// prototype methods. Their bodies are lowered directly through the vtable.
 	ProtoDispatch           *ProtoMethod
 	NeedsNativeContextThunk bool
+	// Derived marks members synthesized by a `@derive` directive. Their tokens
+	// all point at the operation's name in the directive.
+	Derived bool
+	// Intrinsic marks a bodyless declaration whose calls the compiler lowers
+	// itself.
+	Intrinsic IntrinsicKind
 }
 
+// ErrorPredicateKind marks the built-in core error predicates. User helpers
+// derived from them are summarized by the destroy checker instead.
 type ErrorPredicateKind uint8
 
 const (
@@ -1136,6 +1259,7 @@ func (*NodeExprDestructor) IsExpr()        {}
 func (*NodeExprSizeof) IsExpr()            {}
 func (*NodeExprAddrof) IsExpr()            {}
 func (*NodeExprMove) IsExpr()              {}
+func (*NodeExprCast) IsExpr()              {}
 func (*NodeTypeNamed) IsType()             {}
 func (*NodeTypePointer) IsType()           {}
 func (*NodeTypeRfc) IsType()               {}
@@ -1158,9 +1282,11 @@ func (*NodeStmtBounded) IsStatement()      {}
 func (*NodeStmtUnsafe) IsStatement()       {}
 func (*NodeLlvm) IsStatement()             {}
 func (*NodeStmtDefer) IsStatement()        {}
+func (*NodeError) IsStatement()            {}
 func (*NodeExprVarDef) IsGlobalDecl()      {}
 func (*NodeFuncDef) IsGlobalDecl()         {}
 func (*NodeStructDef) IsGlobalDecl()       {}
 func (*NodeTypeAlias) IsGlobalDecl()       {}
 func (*NodeLlvm) IsGlobalDecl()            {}
 func (*NodeConstDef) IsGlobalDecl()        {}
+func (*NodeError) IsGlobalDecl()           {}
@@ -1,5 +1,7 @@
 package types
 
+import "unicode/utf8"
+
 type TokType uint8
 
 const (
@@ -10,6 +12,8 @@ const (
 	TokLitBool
 	TokLitNone
 	TokKeyword
+	// TokLitChar is a character literal; Repr holds its decimal value.
+	TokLitChar
 )
 
 var TokTypeToRepr []string = []string{
@@ -20,6 +24,7 @@ var TokTypeToRepr []string = []string{
 	TokLitBool: "LitBool",
 	TokLitNone: "LitNone",
 	TokKeyword: "Keyword",
+	TokLitChar: "LitChar",
 }
 
 type KwType uint8
@@ -228,13 +233,32 @@ var KwReprToType map[string]KwType = map[string]KwType{
 }
 
 type Token struct {
-	Repr     string
-	Pos      FilePos
+	Repr string
+	Pos  FilePos
+	// End is the position just past the token's last source character. Tokens
+	// synthesized after tokenization leave it zero; Span derives one from Repr.
+	End      FilePos
 	Type     TokType
 	KeywType KwType
 }
 
+// FilePos is a 1-based line and rune column, plus the 0-based byte offset of
+// the same position in the file content.
 type FilePos struct {
-	Line uint32
-	Col  uint32
+	Line   uint32
+	Col    uint32
+	Offset uint32
+}
+
+// Span returns the source range covered by the token. The end is exclusive
+// and always at least one column past the start, so even empty or synthesized
+// tokens can be underlined.
+func (tk *Token) Span() (FilePos, FilePos) {
+	if tk.End.Line > tk.Pos.Line || (tk.End.Line == tk.Pos.Line && tk.End.Col > tk.Pos.Col) {
+		return tk.Pos, tk.End
+	}
+	end := tk.Pos
+	end.Col += max(uint32(utf8.RuneCountInString(tk.Repr)), 1)
+	end.Offset += max(uint32(len(tk.Repr)), 1)
+	return tk.Pos, end
 }
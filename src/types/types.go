@@ -2,6 +2,7 @@ package types
 
 import (
 	"Magma/src/target"
+	"strings"
 	"sync"
 )
 
@@ -14,12 +15,18 @@ type StructDef struct {
 	FieldNb    map[string]int
 	Fields     map[string]*NodeType
 	FieldOrder []string
-	Funcs      map[string]*NodeFuncDef
+	// Defaults holds the constant default value of each field which declares
+	// one. A constructor may omit those fields.
+	Defaults map[string]NodeExpr
+	Funcs    map[string]*NodeFuncDef
 	// Implements records the prototype types named by `impl` on this struct.
 	// Resolution fills Proto after imports and named types are available.
 	Implements []*ProtoImpl
 	IsProto    bool
 	Proto      *ProtoDef
+	// Derives are the operations requested by `@derive`. The parser synthesizes
+	// their members; linking checks that every field supports them.
+	Derives []Derive
 
 	Destructor  *NodeFuncDef
 	Destructors []*NodeFuncDef
@@ -33,6 +40,14 @@ type ProtoDef struct {
 	Methods    []*ProtoMethod
 	MethodMap  map[string]*ProtoMethod
 	VtableName string
+	// Extends are the prototypes named by `extends`, in declaration order.
+	// The vtable holds, after the slots of Methods, one pointer per base to
+	// the implementation's vtable for that base. Linking resolves Proto and
+	// sets Owner to the extending prototype.
+	Extends []*ProtoImpl
+	// Inherited are the requirements of the bases which this prototype does
+	// not declare itself. Linking fills them in.
+	Inherited []*ProtoMethod
 }
 
 type ProtoMethod struct {
@@ -44,6 +59,13 @@ type ProtoMethod struct {
 	Tk         Token
 	FnDef      *NodeFuncDef
 	Proto      *ProtoDef
+	// Default is the body declared as a member of the prototype with the
+	// requirement's name. Implementations which lack the method use it.
+	Default *NodeFuncDef
+	// Origin is set on inherited requirements: the requirement of
+	// Proto.Extends[Base] which is dispatched through that base's vtable.
+	Origin *ProtoMethod
+	Base   int
 }
 
 type ProtoImpl struct {
@@ -51,6 +73,39 @@ type ProtoImpl struct {
 	Proto *ProtoDef
 	Owner *StructDef
 	Tk    Token
+	// Implied is set on the bases of an implemented prototype, which the
+	// implementation satisfies without naming them.
+	Implied bool
+}
+
+// Derive is one operation named by a `@derive` directive. Method is the member
+// it synthesizes on the struct, which calls the member of the same name on
+// every field.
+type Derive struct {
+	Name   string
+	Method string
+	Tk     Token
+}
+
+// DeriveImport is the alias under which a file with `@derive` directives
+// imports std:derive. It has no `use` declaration, so it can neither collide
+// with a source alias nor be reported as unused.
+const DeriveImport = "__derive"
+
+// protoDefaultPrefix starts the member name under which the default body of
+// a prototype requirement is declared.
+const protoDefaultPrefix = "__default_"
+
+// ProtoDefaultName is the member name under which the default body of a
+// prototype requirement is declared.
+func ProtoDefaultName(method string) string {
+	return protoDefaultPrefix + method
+}
+
+// ProtoDefaultRequirement returns the requirement whose default body is
+// declared under member, or false when member is not such a name.
+func ProtoDefaultRequirement(member string) (string, bool) {
+	return strings.CutPrefix(member, protoDefaultPrefix)
 }
 
 func ProtoVtableSymbol(implementation *StructDef, proto *ProtoDef) string {
@@ -91,6 +146,37 @@ type FileCtx struct {
 	Tokens          []Token
 	GlNode          *NodeGlobal
 	ScopeTree       Scope
+	// Allowances are the `@allow(...)` directives and `# @allow(...)` comment
+	// pragmas of this file.
+	Allowances []WarningAllowance
+}
+
+// WarningAllowance suppresses warnings with one of Codes on the source lines
+// from Line through EndLine: the declaration following a directive, or the
+// single line a comment pragma applies to.
+type WarningAllowance struct {
+	Codes   []string
+	Line    uint32
+	EndLine uint32
+}
+
+// Allows reports whether a warning with code on line is suppressed by an
+// `@allow(...)` directive.
+func (f *FileCtx) Allows(code string, line uint32) bool {
+	if f == nil {
+		return false
+	}
+	for _, allowance := range f.Allowances {
+		if line < allowance.Line || line > allowance.EndLine {
+			continue
+		}
+		for _, allowed := range allowance.Codes {
+			if allowed == code {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type SharedState struct {
@@ -101,7 +187,10 @@ type SharedState struct {
 	// shard. It is a power of two so generated code can mask instead of divide.
 	ErrorTraceSlots uint64
 	NullContext     bool
-	Target          target.Target
+	// ExplainSafety asks the ownership checker to attach the event chain which
+	// led to each of its diagnostics. It costs extra flow-state copies.
+	ExplainSafety bool
+	Target        target.Target
 
 	ImportedFiles  map[string]<-chan error
 	ImportedFilesM sync.Mutex
@@ -131,8 +220,82 @@ type SharedState struct {
 	PipelineFunc func(shared *SharedState, filePath string, alias string, fromAbs string, fromGl *NodeGlobal) <-chan error
 	WaitGroup    sync.WaitGroup
 
+	// Instantiations lists every declaration specialized from a generic
+	// template, in the order monomorphization created them.
+	Instantiations []*Instantiation
+
 	// Warnings are non-fatal semantic diagnostics collected after parsing.
-	Warnings []Diagnostic
+	// Producers append through Warn so suppression is applied uniformly.
+	Warnings      []Diagnostic
+	WarningPolicy WarningPolicy
+}
+
+// Instantiation records one declaration specialized from a generic template.
+// Monomorphization records where it was requested; LLVM lowering records its
+// IR size and whether it was folded into an identical instantiation.
+type Instantiation struct {
+	// Kind is "struct", "function", or "method".
+	Kind string
+	// Name is the source-level name with its type arguments.
+	Name string
+	// Symbol is the absolute name of the specialized declaration.
+	Symbol string
+	// Requester is the Symbol of the declaration whose signature or body
+	// requested the instantiation, and RequesterName its source-level name.
+	// Both are empty for requests from module-level initializers.
+	Requester     string
+	RequesterName string
+	File          string
+	Pos           FilePos
+
+	// IrBytes is the size of the emitted definition, or 0 when lowering
+	// pruned it.
+	IrBytes int
+	// FoldedInto is the Symbol of the instantiation which replaced this one
+	// because their lowered definitions were identical.
+	FoldedInto string
+}
+
+// WarningPolicy selects the warnings a compilation reports and the warnings
+// which fail it. Each rule names a Diagnostic.Code or a Diagnostic.Stage; a
+// rule for a code takes precedence over a rule for its stage.
+type WarningPolicy struct {
+	Disabled map[string]bool
+	Enabled  map[string]bool
+	// AllAsErrors promotes every reported warning. Otherwise AsErrors lists
+	// the promoted codes and stages.
+	AllAsErrors bool
+	AsErrors    map[string]bool
+}
+
+// Reports reports whether the policy keeps warning d.
+func (p *WarningPolicy) Reports(d *Diagnostic) bool {
+	if d.Code != "" && p.Enabled[d.Code] {
+		return true
+	}
+	if d.Code != "" && p.Disabled[d.Code] {
+		return false
+	}
+	return p.Enabled[d.Stage] || !p.Disabled[d.Stage]
+}
+
+// Promotes reports whether the policy turns warning d into an error.
+func (p *WarningPolicy) Promotes(d *Diagnostic) bool {
+	return p.AllAsErrors || d.Code != "" && p.AsErrors[d.Code] || p.AsErrors[d.Stage]
+}
+
+// Warn records a non-fatal diagnostic unless an in-source `@allow` or the
+// warning policy suppresses it. Warnings the policy promotes keep their code
+// and stage but are recorded with error severity; the driver fails the
+// compilation once every stage has reported.
+func (s *SharedState) Warn(d Diagnostic) {
+	if d.Ctx.Allows(d.Code, d.Token.Pos.Line) || !s.WarningPolicy.Reports(&d) {
+		return
+	}
+	if s.WarningPolicy.Promotes(&d) {
+		d.Severity = SeverityError
+	}
+	s.Warnings = append(s.Warnings, d)
 }
 
 type DiagnosticSeverity uint8
@@ -154,12 +317,18 @@ type Diagnostic struct {
 	Ctx      *FileCtx
 	FilePath string
 	Token    Token
-	Message  string
+	// End optionally extends the reported span past Token, for example to the
+	// last token of an offending expression.
+	End     FilePos
+	Message string
 	// ShortDesc is the legacy name for Message. Constructors keep both set.
 	ShortDesc  string
 	Additional string
 	Cause      error
 	Related    []DiagnosticRelated
+	// Trace is the optional explain-mode chain of analysis events, in source
+	// order of discovery, which led the checker to this conclusion.
+	Trace []DiagnosticRelated
 }
 
 // DiagnosticRelated points at an earlier operation which caused a later
@@ -178,6 +347,15 @@ func (d *Diagnostic) Error() string {
 }
 func (d *Diagnostic) Unwrap() error { return d.Cause }
 
+// Span returns the exclusive source range the diagnostic points at.
+func (d *Diagnostic) Span() (FilePos, FilePos) {
+	start, end := d.Token.Span()
+	if d.End.Line > end.Line || (d.End.Line == end.Line && d.End.Col > end.Col) {
+		end = d.End
+	}
+	return start, end
+}
+
 // Warning remains an alias while older consumers migrate to Diagnostic.
 type Warning = Diagnostic
 
@@ -0,0 +1,33 @@
+package types
+
+import "testing"
+
+func TestWarningPolicyPrefersCodeRulesOverStageRules(t *testing.T) {
+	state := &SharedState{WarningPolicy: WarningPolicy{
+		Disabled: map[string]bool{"type checking": true, "cleanup": true},
+		Enabled:  map[string]bool{"numeric-conversion": true},
+		AsErrors: map[string]bool{"ownership checking": true},
+	}}
+	state.Warn(Diagnostic{Severity: SeverityWarning, Code: "numeric-conversion", Stage: "type checking"})
+	state.Warn(Diagnostic{Severity: SeverityWarning, Code: "other", Stage: "type checking"})
+	state.Warn(Diagnostic{Severity: SeverityWarning, Code: "cleanup", Stage: "ownership checking"})
+	state.Warn(Diagnostic{Severity: SeverityWarning, Code: "use-after-move", Stage: "ownership checking"})
+	if len(state.Warnings) != 2 || state.Warnings[0].Code != "numeric-conversion" || state.Warnings[1].Code != "use-after-move" {
+		t.Fatalf("reported warnings = %#v", state.Warnings)
+	}
+	if state.Warnings[0].Severity != SeverityWarning || state.Warnings[1].Severity != SeverityError {
+		t.Fatalf("promotion applied to the wrong warnings: %#v", state.Warnings)
+	}
+}
+
+func TestWarnHonorsInSourceAllowances(t *testing.T) {
+	file := &FileCtx{Allowances: []WarningAllowance{{Codes: []string{"unused-variable"}, Line: 3, EndLine: 5}}}
+	state := &SharedState{}
+	for _, line := range []uint32{2, 4, 6} {
+		state.Warn(Diagnostic{Severity: SeverityWarning, Code: "unused-variable", Ctx: file, Token: Token{Pos: FilePos{Line: line}}})
+	}
+	state.Warn(Diagnostic{Severity: SeverityWarning, Code: "unused-import", Ctx: file, Token: Token{Pos: FilePos{Line: 4}}})
+	if len(state.Warnings) != 3 {
+		t.Fatalf("reported warnings = %#v, want lines 2 and 6 and the other code", state.Warnings)
+	}
+}
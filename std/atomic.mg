@@ -1,9 +1,11 @@
 mod atomic
-# Sequentially consistent atomic numeric values for cross-thread coordination.
+# Atomic operations with explicit memory orderings on integer, pointer, and bool
+# places, and atomic numeric values for cross-thread coordination.
 # @warning Do not copy an atomic value after publishing it to other threads.
 
-# Atomic operations use sequential consistency, matching the default ordering
-# of C++ atomics. Do not copy an atomic value after publishing it to threads.
+# Methods of the atomic values use sequential consistency unless their name
+# says otherwise, matching the default ordering of C++ atomics. Do not copy an
+# atomic value after publishing it to threads.
 
 # Atomically accessed unsigned 8-bit value.
 # @warning Initialize with newU8 before sharing its address between threads.
@@ -78,11 +80,7 @@ pub newF64(value f64) F64:
 # @example
 #   flag.store(1)
 U8.store(value u8) void:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  store atomic i8 %value, ptr %this seq_cst, align 1\n"
-        llvm "  ret void\n"
-    ..
+    store(addrof this.value, value, SEQ_CST)
 ..
 
 # Reads the value with sequential consistency.
@@ -90,11 +88,7 @@ U8.store(value u8) void:
 # @example
 #   ready := flag.load() != 0
 U8.load() u8:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %value = load atomic i8, ptr %this seq_cst, align 1\n"
-        llvm "  ret i8 %value\n"
-    ..
+    ret load(addrof this.value, SEQ_CST)
 ..
 
 # Atomically replaces the value and returns its previous value.
@@ -102,11 +96,7 @@ U8.load() u8:
 # @example
 #   wasSet := flag.exchange(1) != 0
 U8.exchange(value u8) u8:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw xchg ptr %this, i8 %value seq_cst, align 1\n"
-        llvm "  ret i8 %previous\n"
-    ..
+    ret exchange(addrof this.value, value, SEQ_CST)
 ..
 
 # Reads with acquire ordering, observing writes published before a matching release.
@@ -114,11 +104,7 @@ U8.exchange(value u8) u8:
 # @example
 #   ready := flag.loadAcquire() != 0
 U8.loadAcquire() u8:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %value = load atomic i8, ptr %this acquire, align 1\n"
-        llvm "  ret i8 %value\n"
-    ..
+    ret load(addrof this.value, ACQUIRE)
 ..
 
 # Stores with release ordering, publishing prior writes to acquiring threads.
@@ -126,11 +112,7 @@ U8.loadAcquire() u8:
 # @example
 #   flag.storeRelease(1)
 U8.storeRelease(value u8) void:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  store atomic i8 %value, ptr %this release, align 1\n"
-        llvm "  ret void\n"
-    ..
+    store(addrof this.value, value, RELEASE)
 ..
 
 # Atomically adds value and returns the value from before the addition.
@@ -139,11 +121,7 @@ U8.storeRelease(value u8) void:
 # @example
 #   previous := counter.fetchAdd(1)
 U8.fetchAdd(value u8) u8:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw add ptr %this, i8 %value seq_cst, align 1\n"
-        llvm "  ret i8 %previous\n"
-    ..
+    ret fetchAdd(addrof this.value, value, SEQ_CST)
 ..
 
 # Atomically subtracts value and returns the value from before subtraction.
@@ -152,11 +130,7 @@ U8.fetchAdd(value u8) u8:
 # @example
 #   previous := counter.fetchSub(1)
 U8.fetchSub(value u8) u8:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw sub ptr %this, i8 %value seq_cst, align 1\n"
-        llvm "  ret i8 %previous\n"
-    ..
+    ret fetchSub(addrof this.value, value, SEQ_CST)
 ..
 
 # Replaces the value with sequential consistency.
@@ -164,11 +138,7 @@ U8.fetchSub(value u8) u8:
 # @example
 #   state.store(2)
 U32.store(value u32) void:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  store atomic i32 %value, ptr %this seq_cst, align 4\n"
-        llvm "  ret void\n"
-    ..
+    store(addrof this.value, value, SEQ_CST)
 ..
 
 # Reads the value with sequential consistency.
@@ -176,11 +146,7 @@ U32.store(value u32) void:
 # @example
 #   current := state.load()
 U32.load() u32:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %value = load atomic i32, ptr %this seq_cst, align 4\n"
-        llvm "  ret i32 %value\n"
-    ..
+    ret load(addrof this.value, SEQ_CST)
 ..
 
 # Atomically replaces the value and returns its previous value.
@@ -188,11 +154,7 @@ U32.load() u32:
 # @example
 #   previous := state.exchange(2)
 U32.exchange(value u32) u32:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw xchg ptr %this, i32 %value seq_cst, align 4\n"
-        llvm "  ret i32 %previous\n"
-    ..
+    ret exchange(addrof this.value, value, SEQ_CST)
 ..
 
 # Reads with acquire ordering, observing writes published before a matching release.
@@ -200,11 +162,7 @@ U32.exchange(value u32) u32:
 # @example
 #   current := state.loadAcquire()
 U32.loadAcquire() u32:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %value = load atomic i32, ptr %this acquire, align 4\n"
-        llvm "  ret i32 %value\n"
-    ..
+    ret load(addrof this.value, ACQUIRE)
 ..
 
 # Stores with release ordering, publishing prior writes to acquiring threads.
@@ -212,11 +170,7 @@ U32.loadAcquire() u32:
 # @example
 #   state.storeRelease(1)
 U32.storeRelease(value u32) void:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  store atomic i32 %value, ptr %this release, align 4\n"
-        llvm "  ret void\n"
-    ..
+    store(addrof this.value, value, RELEASE)
 ..
 
 # Atomically adds value with sequential consistency and returns the previous value.
@@ -225,11 +179,7 @@ U32.storeRelease(value u32) void:
 # @example
 #   ticket := counter.fetchAdd(1)
 U32.fetchAdd(value u32) u32:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw add ptr %this, i32 %value seq_cst, align 4\n"
-        llvm "  ret i32 %previous\n"
-    ..
+    ret fetchAdd(addrof this.value, value, SEQ_CST)
 ..
 
 # Adds with release ordering and returns the previous value, publishing prior writes.
@@ -238,11 +188,7 @@ U32.fetchAdd(value u32) u32:
 # @example
 #   previous := counter.fetchAddRelease(1)
 U32.fetchAddRelease(value u32) u32:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw add ptr %this, i32 %value release, align 4\n"
-        llvm "  ret i32 %previous\n"
-    ..
+    ret fetchAdd(addrof this.value, value, RELEASE)
 ..
 
 # Atomically subtracts value with sequential consistency and returns the previous value.
@@ -251,11 +197,7 @@ U32.fetchAddRelease(value u32) u32:
 # @example
 #   previous := counter.fetchSub(1)
 U32.fetchSub(value u32) u32:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw sub ptr %this, i32 %value seq_cst, align 4\n"
-        llvm "  ret i32 %previous\n"
-    ..
+    ret fetchSub(addrof this.value, value, SEQ_CST)
 ..
 
 # Subtracts with acquire-release ordering and returns the previous value.
@@ -264,11 +206,7 @@ U32.fetchSub(value u32) u32:
 # @example
 #   wasLast := references.fetchSubAcqRel(1) == 1
 U32.fetchSubAcqRel(value u32) u32:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw sub ptr %this, i32 %value acq_rel, align 4\n"
-        llvm "  ret i32 %previous\n"
-    ..
+    ret fetchSub(addrof this.value, value, ACQ_REL)
 ..
 
 # Replaces the value with sequential consistency.
@@ -276,11 +214,7 @@ U32.fetchSubAcqRel(value u32) u32:
 # @example
 #   counter.store(0)
 U64.store(value u64) void:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  store atomic i64 %value, ptr %this seq_cst, align 8\n"
-        llvm "  ret void\n"
-    ..
+    store(addrof this.value, value, SEQ_CST)
 ..
 
 # Reads the value with sequential consistency.
@@ -288,11 +222,7 @@ U64.store(value u64) void:
 # @example
 #   total := counter.load()
 U64.load() u64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %value = load atomic i64, ptr %this seq_cst, align 8\n"
-        llvm "  ret i64 %value\n"
-    ..
+    ret load(addrof this.value, SEQ_CST)
 ..
 
 # Atomically replaces the value and returns its previous value.
@@ -300,11 +230,7 @@ U64.load() u64:
 # @example
 #   batch := counter.exchange(0)
 U64.exchange(value u64) u64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw xchg ptr %this, i64 %value seq_cst, align 8\n"
-        llvm "  ret i64 %previous\n"
-    ..
+    ret exchange(addrof this.value, value, SEQ_CST)
 ..
 
 # Reads atomically without synchronizing other memory accesses.
@@ -313,11 +239,7 @@ U64.exchange(value u64) u64:
 # @example
 #   approximate := counter.loadRelaxed()
 U64.loadRelaxed() u64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %value = load atomic i64, ptr %this monotonic, align 8\n"
-        llvm "  ret i64 %value\n"
-    ..
+    ret load(addrof this.value, RELAXED)
 ..
 
 # Reads with acquire ordering, observing writes published before a matching release.
@@ -325,11 +247,7 @@ U64.loadRelaxed() u64:
 # @example
 #   published := state.loadAcquire()
 U64.loadAcquire() u64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %value = load atomic i64, ptr %this acquire, align 8\n"
-        llvm "  ret i64 %value\n"
-    ..
+    ret load(addrof this.value, ACQUIRE)
 ..
 
 # Stores atomically without publishing preceding memory accesses.
@@ -338,11 +256,7 @@ U64.loadAcquire() u64:
 # @example
 #   counter.storeRelaxed(0)
 U64.storeRelaxed(value u64) void:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  store atomic i64 %value, ptr %this monotonic, align 8\n"
-        llvm "  ret void\n"
-    ..
+    store(addrof this.value, value, RELAXED)
 ..
 
 # Stores with release ordering, publishing prior writes to acquiring threads.
@@ -350,11 +264,7 @@ U64.storeRelaxed(value u64) void:
 # @example
 #   state.storeRelease(1)
 U64.storeRelease(value u64) void:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  store atomic i64 %value, ptr %this release, align 8\n"
-        llvm "  ret void\n"
-    ..
+    store(addrof this.value, value, RELEASE)
 ..
 
 # Atomically adds value with sequential consistency and returns the previous value.
@@ -363,11 +273,7 @@ U64.storeRelease(value u64) void:
 # @example
 #   id := counter.fetchAdd(1)
 U64.fetchAdd(value u64) u64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw add ptr %this, i64 %value seq_cst, align 8\n"
-        llvm "  ret i64 %previous\n"
-    ..
+    ret fetchAdd(addrof this.value, value, SEQ_CST)
 ..
 
 # Atomically adds without synchronizing other memory and returns the previous value.
@@ -376,11 +282,7 @@ U64.fetchAdd(value u64) u64:
 # @example
 #   previous := metrics.fetchAddRelaxed(1)
 U64.fetchAddRelaxed(value u64) u64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw add ptr %this, i64 %value monotonic, align 8\n"
-        llvm "  ret i64 %previous\n"
-    ..
+    ret fetchAdd(addrof this.value, value, RELAXED)
 ..
 
 # Atomically subtracts value with sequential consistency and returns the previous value.
@@ -389,11 +291,7 @@ U64.fetchAddRelaxed(value u64) u64:
 # @example
 #   previous := counter.fetchSub(1)
 U64.fetchSub(value u64) u64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw sub ptr %this, i64 %value seq_cst, align 8\n"
-        llvm "  ret i64 %previous\n"
-    ..
+    ret fetchSub(addrof this.value, value, SEQ_CST)
 ..
 
 # Replaces the signed value with sequential consistency.
@@ -401,11 +299,7 @@ U64.fetchSub(value u64) u64:
 # @example
 #   balance.store(0)
 I64.store(value i64) void:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  store atomic i64 %value, ptr %this seq_cst, align 8\n"
-        llvm "  ret void\n"
-    ..
+    store(addrof this.value, value, SEQ_CST)
 ..
 
 # Reads the signed value with sequential consistency.
@@ -413,11 +307,7 @@ I64.store(value i64) void:
 # @example
 #   current := balance.load()
 I64.load() i64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %value = load atomic i64, ptr %this seq_cst, align 8\n"
-        llvm "  ret i64 %value\n"
-    ..
+    ret load(addrof this.value, SEQ_CST)
 ..
 
 # Atomically replaces the signed value and returns its previous value.
@@ -425,11 +315,7 @@ I64.load() i64:
 # @example
 #   previous := balance.exchange(0)
 I64.exchange(value i64) i64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw xchg ptr %this, i64 %value seq_cst, align 8\n"
-        llvm "  ret i64 %previous\n"
-    ..
+    ret exchange(addrof this.value, value, SEQ_CST)
 ..
 
 # Atomically adds value and returns the value from before the addition.
@@ -438,11 +324,7 @@ I64.exchange(value i64) i64:
 # @example
 #   previous := balance.fetchAdd(delta)
 I64.fetchAdd(value i64) i64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw add ptr %this, i64 %value seq_cst, align 8\n"
-        llvm "  ret i64 %previous\n"
-    ..
+    ret fetchAdd(addrof this.value, value, SEQ_CST)
 ..
 
 # Atomically subtracts value and returns the value from before subtraction.
@@ -451,11 +333,7 @@ I64.fetchAdd(value i64) i64:
 # @example
 #   previous := balance.fetchSub(cost)
 I64.fetchSub(value i64) i64:
-    # SAFETY: this audited implementation injects the required low-level IR.
-    unsafe:
-        llvm "  %previous = atomicrmw sub ptr %this, i64 %value seq_cst, align 8\n"
-        llvm "  ret i64 %previous\n"
-    ..
+    ret fetchSub(addrof this.value, value, SEQ_CST)
 ..
 
 # Atomically replaces the floating-point value with sequential consistency.
@@ -498,3 +376,121 @@ F64.exchange(value f64) f64:
         llvm "  ret double %previous\n"
     ..
 ..
+
+# Memory ordering of an atomic intrinsic. Pass one of the ordering constants:
+# the compiler selects the instruction from the ordering, so it must be known
+# at compile time.
+pub alias Ordering = u8
+
+# Guarantees atomicity only, without ordering other memory accesses.
+pub const RELAXED Ordering = 0
+# Later accesses stay after a load which observes a release.
+pub const ACQUIRE Ordering = 1
+# Earlier accesses stay before the store, for a matching acquire to observe.
+pub const RELEASE Ordering = 2
+# Both acquire and release, for read-modify-write operations and fences.
+pub const ACQ_REL Ordering = 3
+# Acquire and release, within a single total order of all such operations.
+pub const SEQ_CST Ordering = 4
+
+# Atomically reads the integer, pointer, or bool at place.
+# Accepts RELAXED, ACQUIRE, or SEQ_CST.
+# @complexity O(1)
+# @example
+#   ready := atomic.load(addrof flag, atomic.ACQUIRE)
+@intrinsic("atomic.load")
+pub noctx load[T](place T*, order Ordering) T:
+..
+
+# Atomically writes value to place.
+# Accepts RELAXED, RELEASE, or SEQ_CST.
+# @complexity O(1)
+# @example
+#   atomic.store(addrof flag, true, atomic.RELEASE)
+@intrinsic("atomic.store")
+pub noctx store[T](place T*, value T, order Ordering) void:
+..
+
+# Atomically replaces the value at place and returns its previous value.
+# @complexity O(1)
+# @example
+#   previous := atomic.exchange(addrof head, node, atomic.ACQ_REL)
+@intrinsic("atomic.exchange")
+pub noctx exchange[T](place T*, value T, order Ordering) T:
+..
+
+# Replaces the value at place with desired if it equals the value at expected,
+# and reports whether it did. Either way, expected then holds the value found
+# at place. The failure ordering applies when no replacement happens and
+# cannot be RELEASE or ACQ_REL.
+# @complexity O(1)
+# @example
+#   claimed := atomic.compareExchange(addrof owner, addrof seen, self, atomic.ACQ_REL, atomic.ACQUIRE)
+@intrinsic("atomic.compareExchange")
+pub noctx compareExchange[T](place T*, expected T*, desired T, success Ordering, failure Ordering) bool:
+..
+
+# Like compareExchange, but may fail even when the values are equal, which
+# is cheaper on some machines. Use it in a loop which retries on failure.
+# @complexity O(1)
+# @example
+#   loop atomic.compareExchangeWeak(addrof top, addrof seen, node, atomic.RELEASE, atomic.RELAXED) == false:
+#       node.next = seen
+#   ..
+@intrinsic("atomic.compareExchangeWeak")
+pub noctx compareExchangeWeak[T](place T*, expected T*, desired T, success Ordering, failure Ordering) bool:
+..
+
+# Atomically adds value to the integer at place and returns its previous value.
+# The addition wraps on overflow.
+# @complexity O(1)
+# @example
+#   ticket := atomic.fetchAdd(addrof next, 1, atomic.RELAXED)
+@intrinsic("atomic.fetchAdd")
+pub noctx fetchAdd[T](place T*, value T, order Ordering) T:
+..
+
+# Atomically subtracts value from the integer at place and returns its
+# previous value. The subtraction wraps on overflow.
+# @complexity O(1)
+# @example
+#   remaining := atomic.fetchSub(addrof references, 1, atomic.ACQ_REL) - 1
+@intrinsic("atomic.fetchSub")
+pub noctx fetchSub[T](place T*, value T, order Ordering) T:
+..
+
+# Atomically applies a bitwise and to the integer or bool at place and returns
+# its previous value.
+# @complexity O(1)
+# @example
+#   before := atomic.fetchAnd(addrof flags, ~WAITING, atomic.RELEASE)
+@intrinsic("atomic.fetchAnd")
+pub noctx fetchAnd[T](place T*, value T, order Ordering) T:
+..
+
+# Atomically applies a bitwise or to the integer or bool at place and returns
+# its previous value.
+# @complexity O(1)
+# @example
+#   before := atomic.fetchOr(addrof flags, WAITING, atomic.ACQ_REL)
+@intrinsic("atomic.fetchOr")
+pub noctx fetchOr[T](place T*, value T, order Ordering) T:
+..
+
+# Atomically applies a bitwise exclusive or to the integer or bool at place and
+# returns its previous value.
+# @complexity O(1)
+# @example
+#   before := atomic.fetchXor(addrof phase, 1, atomic.ACQ_REL)
+@intrinsic("atomic.fetchXor")
+pub noctx fetchXor[T](place T*, value T, order Ordering) T:
+..
+
+# Orders memory accesses around the fence without accessing memory itself.
+# Accepts ACQUIRE, RELEASE, ACQ_REL, or SEQ_CST.
+# @complexity O(1)
+# @example
+#   atomic.fence(atomic.SEQ_CST)
+@intrinsic("atomic.fence")
+pub noctx fence(order Ordering) void:
+..
@@ -16,8 +16,9 @@ slice.count() u64:
     ..
 ..
 
-# Canonical error predicates. Besides being convenient, these are the only
-# predicates used by ownership flow refinement for destructured throwing calls.
+# Canonical error predicates. Besides being convenient, these are the base
+# predicates used by ownership flow refinement for destructured throwing calls;
+# small user helpers derived from them are summarized by the checker.
 # @complexity O(1)
 # @example
 #   if resultError.ok():
@@ -0,0 +1,288 @@
+mod derive
+# Support for `@derive`: field implementations for primitive types and the
+# helpers used by synthesized struct members.
+# @note Derived members call the method of the same name on every field, so a
+# primitive field type is supported when this module declares that method.
+
+pub use "std:fmt" fmt
+pub use "std:writer" writer
+use "std:cast" cast
+use "std:hash" hash
+use "std:json" json
+use "std:strings" strings
+
+# Initial state of a derived hash.
+pub const SEED u64 = 14695981039346656037
+
+# Digits after the decimal point in derived formatting of floats.
+pub const FLOAT_PRECISION u64 = 6
+
+# Folds value into the running hash h.
+# @complexity O(1)
+# @example
+#   h := derive.mix(derive.SEED, 42)
+pub mix(h u64, value u64) u64:
+    x := (h ^ value) * 0xBF58476D1CE4E5B9
+    x = x ^ (x >> 31)
+    x = x * 0x94D049BB133111EB
+    ret x ^ (x >> 29)
+..
+
+# Reports whether both values are equal.
+i8.eq(other i8*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+i8.hash() u64:
+    ret mix(SEED, cast.itou(*this))
+..
+
+# Appends the value in decimal.
+i8.format(f fmt.Format*) void:
+    *f = f.int(*this)
+..
+
+# Writes the value as a JSON number.
+i8.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeInt64(*this)
+..
+
+# Reports whether both values are equal.
+i16.eq(other i16*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+i16.hash() u64:
+    ret mix(SEED, cast.itou(*this))
+..
+
+# Appends the value in decimal.
+i16.format(f fmt.Format*) void:
+    *f = f.int(*this)
+..
+
+# Writes the value as a JSON number.
+i16.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeInt64(*this)
+..
+
+# Reports whether both values are equal.
+i32.eq(other i32*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+i32.hash() u64:
+    ret mix(SEED, cast.itou(*this))
+..
+
+# Appends the value in decimal.
+i32.format(f fmt.Format*) void:
+    *f = f.int(*this)
+..
+
+# Writes the value as a JSON number.
+i32.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeInt64(*this)
+..
+
+# Reports whether both values are equal.
+i64.eq(other i64*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+i64.hash() u64:
+    ret mix(SEED, cast.itou(*this))
+..
+
+# Appends the value in decimal.
+i64.format(f fmt.Format*) void:
+    *f = f.int(*this)
+..
+
+# Writes the value as a JSON number.
+i64.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeInt64(*this)
+..
+
+# Reports whether both values are equal.
+u8.eq(other u8*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+u8.hash() u64:
+    ret mix(SEED, *this)
+..
+
+# Appends the value in decimal.
+u8.format(f fmt.Format*) void:
+    *f = f.uint(*this)
+..
+
+# Writes the value as a JSON number.
+u8.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeUint64(*this)
+..
+
+# Reports whether both values are equal.
+u16.eq(other u16*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+u16.hash() u64:
+    ret mix(SEED, *this)
+..
+
+# Appends the value in decimal.
+u16.format(f fmt.Format*) void:
+    *f = f.uint(*this)
+..
+
+# Writes the value as a JSON number.
+u16.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeUint64(*this)
+..
+
+# Reports whether both values are equal.
+u32.eq(other u32*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+u32.hash() u64:
+    ret mix(SEED, *this)
+..
+
+# Appends the value in decimal.
+u32.format(f fmt.Format*) void:
+    *f = f.uint(*this)
+..
+
+# Writes the value as a JSON number.
+u32.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeUint64(*this)
+..
+
+# Reports whether both values are equal.
+u64.eq(other u64*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+u64.hash() u64:
+    ret mix(SEED, *this)
+..
+
+# Appends the value in decimal.
+u64.format(f fmt.Format*) void:
+    *f = f.uint(*this)
+..
+
+# Writes the value as a JSON number.
+u64.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeUint64(*this)
+..
+
+# Reports whether both values are equal. NaN is not equal to itself.
+f32.eq(other f32*) bool:
+    ret *this == *other
+..
+
+# Hashes the value. Both zeros hash alike, as they compare equal.
+f32.hash() u64:
+    if *this == 0:
+        ret mix(SEED, 0)
+    ..
+    value := *this
+    bits u32* = cast.reinterpret[u32](addrof value)
+    ret mix(SEED, *bits)
+..
+
+# Appends the value with FLOAT_PRECISION digits after the decimal point.
+f32.format(f fmt.Format*) void:
+    *f = f.float(*this, FLOAT_PRECISION)
+..
+
+# Writes the value as a JSON number.
+# @throws invalidArgument if the value is NaN or infinite
+f32.writeJson(w writer.Writer, precision u64) !void:
+    try json.numberFloat(*this).write(w, precision)
+..
+
+# Reports whether both values are equal. NaN is not equal to itself.
+f64.eq(other f64*) bool:
+    ret *this == *other
+..
+
+# Hashes the value. Both zeros hash alike, as they compare equal.
+f64.hash() u64:
+    if *this == 0:
+        ret mix(SEED, 0)
+    ..
+    value := *this
+    bits u64* = cast.reinterpret[u64](addrof value)
+    ret mix(SEED, *bits)
+..
+
+# Appends the value with FLOAT_PRECISION digits after the decimal point.
+f64.format(f fmt.Format*) void:
+    *f = f.float(*this, FLOAT_PRECISION)
+..
+
+# Writes the value as a JSON number.
+# @throws invalidArgument if the value is NaN or infinite
+f64.writeJson(w writer.Writer, precision u64) !void:
+    try json.numberFloat(*this).write(w, precision)
+..
+
+# Reports whether both values are equal.
+bool.eq(other bool*) bool:
+    ret *this == *other
+..
+
+# Hashes the value.
+bool.hash() u64:
+    if *this:
+        ret mix(SEED, 1)
+    ..
+    ret mix(SEED, 0)
+..
+
+# Appends `true` or `false`.
+bool.format(f fmt.Format*) void:
+    *f = f.bool(*this)
+..
+
+# Writes the value as a JSON boolean.
+bool.writeJson(w writer.Writer, precision u64) !void:
+    try w.writeBool(*this)
+..
+
+# Reports whether both strings hold the same bytes.
+# @complexity O(N)
+str.eq(other str*) bool:
+    ret strings.compare(*this, *other)
+..
+
+# Hashes the string's bytes.
+# @complexity O(N)
+str.hash() u64:
+    ret mix(SEED, hash.string(*this))
+..
+
+# Appends the string between double quotes. The string is borrowed until
+# the Format is consumed or freed.
+str.format(f fmt.Format*) void:
+    *f = f.str("\"").str(*this).str("\"")
+..
+
+# Writes the string as an escaped JSON string.
+# @complexity O(N)
+str.writeJson(w writer.Writer, precision u64) !void:
+    try json.stringBorrowed(*this).write(w, precision)
+..
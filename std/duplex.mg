@@ -6,10 +6,7 @@ use "std:errors" errors
 use "std:writer" writer
 use "std:reader" reader
 
-pub proto Duplex impl writer.Writer reader.Reader(
-    write(bytes str) !u64
-    readRaw(buff u8[], nBytes u64) !u64
-)
+pub proto Duplex extends writer.Writer, reader.Reader()
 
 Duplex.writer() writer.Writer:
     ret this.proto()
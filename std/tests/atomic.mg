@@ -39,6 +39,51 @@ pub main() !void:
         throw errors.failure("i64 atomic arithmetic failed")
     ..
 
+    count u32 = 5
+    if atomic.load(addrof count, atomic.ACQUIRE) != 5 || atomic.fetchAdd(addrof count, 3, atomic.ACQ_REL) != 5 || atomic.fetchSub(addrof count, 1, atomic.RELEASE) != 8:
+        throw errors.failure("intrinsic arithmetic failed")
+    ..
+    if atomic.fetchAnd(addrof count, 6, atomic.RELAXED) != 7 || atomic.fetchOr(addrof count, 9, atomic.SEQ_CST) != 6 || atomic.fetchXor(addrof count, 15, atomic.RELAXED) != 15 || atomic.load(addrof count, atomic.RELAXED) != 0:
+        throw errors.failure("intrinsic bitwise operation failed")
+    ..
+    atomic.store(addrof count, 40, atomic.RELEASE)
+    atomic.fence(atomic.SEQ_CST)
+    if atomic.exchange(addrof count, 41, atomic.ACQ_REL) != 40:
+        throw errors.failure("intrinsic exchange failed")
+    ..
+
+    expected u32 = 7
+    if atomic.compareExchange(addrof count, addrof expected, 50, atomic.ACQ_REL, atomic.ACQUIRE) || expected != 41:
+        throw errors.failure("mismatched compare-exchange replaced the value")
+    ..
+    if atomic.compareExchange(addrof count, addrof expected, 50, atomic.SEQ_CST, atomic.RELAXED) == false || atomic.load(addrof count, atomic.SEQ_CST) != 50:
+        throw errors.failure("matching compare-exchange kept the value")
+    ..
+    expected = 50
+    loop atomic.compareExchangeWeak(addrof count, addrof expected, 51, atomic.RELEASE, atomic.RELAXED) == false:
+    ..
+    if atomic.load(addrof count, atomic.SEQ_CST) != 51:
+        throw errors.failure("weak compare-exchange failed")
+    ..
+
+    ready := false
+    if atomic.exchange(addrof ready, true, atomic.ACQ_REL) || atomic.load(addrof ready, atomic.ACQUIRE) == false:
+        throw errors.failure("bool exchange failed")
+    ..
+    if atomic.fetchXor(addrof ready, true, atomic.RELAXED) == false || atomic.fetchOr(addrof ready, false, atomic.RELAXED):
+        throw errors.failure("bool bitwise operation failed")
+    ..
+
+    target u64 = 9
+    slot ptr = none
+    empty ptr = none
+    if atomic.compareExchange[ptr](addrof slot, addrof empty, addrof target, atomic.RELEASE, atomic.RELAXED) == false:
+        throw errors.failure("pointer compare-exchange failed")
+    ..
+    if atomic.load[ptr](addrof slot, atomic.ACQUIRE) != addrof target:
+        throw errors.failure("pointer load failed")
+    ..
+
     floating := atomic.newF64(1.5)
     floating.store(2.5)
     if floating.load() != 2.5 || floating.exchange(3.5) != 2.5 || floating.load() != 3.5:
@@ -0,0 +1,93 @@
+mod main
+
+use "std:allocator" allocator
+use "std:errors" errors
+use "std:fmt" fmt
+use "std:heap" heap
+use "std:strings" strings
+use "std:writer" writer
+
+Capture impl writer.Writer(
+    buffer u8*
+    count u64
+)
+
+Capture.write(bytes str) !u64:
+    count := bytes.countBytes()
+    # SAFETY: impl is a Capture and main provides a 128-byte buffer, larger
+    # than every write made by this test.
+    unsafe:
+        i u64 = 0
+        loop i < count:
+            this.buffer[this.count + i] = strings.byteAt(bytes, i)
+            i = i + 1
+        ..
+        this.count = this.count + count
+    ..
+    ret count
+..
+
+@derive("eq", "hash", "format", "json")
+Inner(ratio f64, active bool)
+
+@derive("eq", "hash", "format", "json")
+Pair[T](
+    left T
+    right T
+    label str
+    inner Inner
+)
+
+@derive("eq", "format")
+Empty()
+
+pub main() !void:
+    a allocator.Allocator = heap.allocator()
+    first := Pair[i32](left=-1, right=2, label="a \"b\"", inner=Inner(ratio=1.5, active=true))
+    second := Pair[i32](left=-1, right=2, label="a \"b\"", inner=Inner(ratio=1.5, active=true))
+    if first.eq(addrof second) == false || first.hash() != second.hash():
+        throw errors.failure("derived eq or hash differs for equal values")
+    ..
+    second.inner.active = false
+    if first.eq(addrof second) || first.hash() == second.hash():
+        throw errors.failure("derived eq or hash ignores a nested field")
+    ..
+    negative := Inner(ratio=-0.0, active=true)
+    positive := Inner(ratio=0.0, active=true)
+    if negative.eq(addrof positive) == false || negative.hash() != positive.hash():
+        throw errors.failure("derived float hash distinguishes signed zeros")
+    ..
+
+    plain := Pair[u8](left=1, right=2, label="c", inner=Inner(ratio=1.5, active=true))
+    format := fmt.new(a)
+    plain.format(addrof format)
+    rendered $str, renderErr error = format.toStr(a)
+    if renderErr.nok():
+        throw renderErr
+    ..
+    defer rendered.free(a)
+    if strings.compare(rendered, "Pair(left=1, right=2, label=\"c\", inner=Inner(ratio=1.500000, active=true))") == false:
+        throw errors.failure("derived format changed")
+    ..
+
+    empty Empty
+    emptyFormat := fmt.new(a)
+    empty.format(addrof emptyFormat)
+    emptyRendered $str, emptyErr error = emptyFormat.toStr(a)
+    if emptyErr.nok():
+        throw emptyErr
+    ..
+    defer emptyRendered.free(a)
+    if strings.compare(emptyRendered, "Empty()") == false || empty.eq(addrof empty) == false:
+        throw errors.failure("derived members of an empty struct changed")
+    ..
+
+    captureBytes := try strings.alloc(128)
+    defer captureBytes.free(a)
+    capture := Capture(buffer=strings.toPtr(captureBytes), count=0)
+    try first.writeJson(capture.proto[writer.Writer](), 2)
+    captured := strings.fromPtrNoCopy(capture.buffer, capture.count)
+    if strings.compare(captured, "{\"left\":-1,\"right\":2,\"label\":\"a \\\"b\\\"\",\"inner\":{\"ratio\":1.50,\"active\":true}}") == false:
+        throw errors.failure("derived json changed")
+    ..
+..
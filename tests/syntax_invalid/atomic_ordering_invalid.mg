@@ -0,0 +1,8 @@
+mod main
+
+use "std:atomic" atomic
+
+pub main() void:
+    count u64 = 0
+    value := atomic.load(addrof count, atomic.RELEASE)
+..
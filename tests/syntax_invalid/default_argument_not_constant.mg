@@ -0,0 +1,4 @@
+mod main
+
+connect(host str, port u16 = host) void:
+..
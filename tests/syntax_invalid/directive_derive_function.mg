@@ -0,0 +1,4 @@
+mod main
+@derive("eq")
+main() void:
+..
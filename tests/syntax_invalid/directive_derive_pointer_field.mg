@@ -0,0 +1,5 @@
+mod main
+@derive("eq")
+Node(value i32, next Node*)
+main() void:
+..
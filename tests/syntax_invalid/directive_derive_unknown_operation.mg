@@ -0,0 +1,5 @@
+mod main
+@derive("compare")
+Point(x i32, y i32)
+main() void:
+..
@@ -0,0 +1,9 @@
+mod main
+same[T](a T, b T) T:
+    ret a
+..
+main() void:
+    size u64 = 1
+    text := "x"
+    value := same(size, text)
+..
@@ -0,0 +1,7 @@
+mod main
+make[T]() T*:
+    ret none
+..
+main() void:
+    value := make()
+..
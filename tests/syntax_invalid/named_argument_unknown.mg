@@ -0,0 +1,7 @@
+mod main
+
+connect(host str, port u16 = 80) void:
+..
+pub main() void:
+    connect("localhost", prot=8080)
+..
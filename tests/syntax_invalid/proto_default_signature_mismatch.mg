@@ -0,0 +1,7 @@
+mod main
+proto Counter(count() u64)
+Counter.count(step u64) u64:
+    ret step
+..
+main() void:
+..
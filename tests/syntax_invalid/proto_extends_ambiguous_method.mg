@@ -0,0 +1,6 @@
+mod main
+proto Left(run() void)
+proto Right(run() u64)
+proto Both extends Left, Right()
+main() void:
+..
@@ -0,0 +1,5 @@
+mod main
+proto First extends Second()
+proto Second extends First()
+main() void:
+..
@@ -0,0 +1,8 @@
+mod main
+
+Handle(fd u64)
+destr Handle.close() void: this.fd = 0 ..
+Holder(handle Handle, count u64 = 1)
+pub main() void:
+    holder := Holder(count=2)
+..
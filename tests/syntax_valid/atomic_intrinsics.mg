@@ -0,0 +1,18 @@
+mod main
+
+use "std:atomic" atomic
+
+Counter(hits u64, ready bool)
+pub main() void:
+    counter := Counter(hits=0, ready=false)
+    atomic.fetchAdd(addrof counter.hits, 1, atomic.RELAXED)
+    atomic.store(addrof counter.ready, true, atomic.RELEASE)
+    atomic.fence(atomic.ACQUIRE)
+    seen := atomic.load(addrof counter.hits, atomic.ACQUIRE)
+    small u8 = 3
+    atomic.fetchXor(addrof small, 1, atomic.SEQ_CST)
+    slot ptr = none
+    atomic.exchange[ptr](addrof slot, addrof small, atomic.ACQ_REL)
+    expected := seen
+    atomic.compareExchange(addrof counter.hits, addrof expected, seen + 1, atomic.ACQ_REL, atomic.ACQUIRE)
+..
@@ -0,0 +1,8 @@
+mod main
+@derive("eq", "hash", "format", "json")
+Point[T](x T, y T, label str)
+pub main() void:
+    a := Point[i32](x=1, y=2, label="a")
+    b := Point[i32](x=1, y=2, label="a")
+    same := a.eq(addrof b) && a.hash() == b.hash()
+..
@@ -0,0 +1,34 @@
+mod main
+use "std:pair" pair
+
+Box[T](value T)
+Box[T].map[U](f (T) U) Box[U]:
+    ret Box[U](value=f(this.value))
+..
+first[A, B](p pair.Pair[A, B]) A:
+    ret p.first
+..
+unbox[T](b Box[T]*) T:
+    ret b.value
+..
+count[T](items T[]) u64:
+    ret 0
+..
+identity[T](value T) T:
+    ret value
+..
+double(value u64) u64:
+    ret value * 2
+..
+pub main() void:
+    size u64 = 3
+    p := pair.new(true, size)
+    flag := first(p)
+    b := Box[u64](value=size)
+    doubled := b.map(double)
+    value := unbox(addrof doubled)
+    items := array u64[2]
+    n := count(items)
+    ratio := 1.5e-3
+    scaled f64 = identity(ratio) * 2.0
+..
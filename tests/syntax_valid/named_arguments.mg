@@ -0,0 +1,25 @@
+mod main
+
+Pool(workers u64)
+Pool.resize(minWorkers u64 = 1, maxWorkers u64 = 4) u64:
+    ret this.workers + minWorkers + maxWorkers
+..
+spawn(name str, minWorkers u64 = 2, maxWorkers u64 = 8, verbose bool = false) Pool:
+    ret Pool(workers=minWorkers + maxWorkers)
+..
+largest[T](a T, b T = 0) T:
+    if a > b:
+        ret a
+    ..
+    ret b
+..
+pub main() void:
+    p := spawn("io", maxWorkers=16)
+    q := spawn(name="cpu", verbose=true, minWorkers=1)
+    n := p.resize(maxWorkers=2)
+    m := largest(a=n)
+    r := scaled(factor=3)
+..
+scaled(factor u64, offset i64 = -1 + 2) i64:
+    ret factor as i64 + offset
+..
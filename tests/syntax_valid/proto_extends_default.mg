@@ -0,0 +1,23 @@
+mod main
+proto Reader(read() u64)
+proto Writer(write(value u64) void)
+proto Duplex extends Reader, Writer(
+    copy() void
+)
+Duplex.copy() void:
+    this.write(this.read())
+..
+Pipe impl Duplex(value u64)
+Pipe.read() u64:
+    ret this.value
+..
+Pipe.write(value u64) void:
+    this.value = value
+..
+pub main() void:
+    pipe := Pipe(value=1)
+    duplex := pipe.proto[Duplex]()
+    duplex.copy()
+    reader Reader = duplex.proto()
+    value := reader.read()
+..
@@ -0,0 +1,21 @@
+mod main
+
+Options(
+    retries u64 = 3
+    scale i32 = -2 * 4
+    verbose bool = false
+    name str
+)
+Limits(low u64, high u64 = 10)
+Handle(fd u64)
+destr Handle.close() void: this.fd = 0 ..
+Holder(handle Handle, count u64 = 1)
+Slot[T](value T = 7)
+const DEFAULT_LIMITS := Limits(low=1)
+pub main() void:
+    quiet := Options(name="job")
+    loud := Options(name="job", verbose=true, retries=5)
+    limits := DEFAULT_LIMITS
+    slot := Slot[u64]()
+    holder := Holder(handle=Handle(fd=3))
+..